@@ -0,0 +1,179 @@
+// docs-export 把文档目录导出为静态站点
+//
+// 使用与服务端 /docs 相同的文档站点（pkg/docsite）与模板（resources/templates/markdown），
+// 输出每个页面的 HTML、复制图片等静态资源，并生成 sitemap.xml 与搜索索引 search-index.json。
+//
+// 用法：
+//
+//	go run ./cmd/docs-export -out dist/docs -base /docs -site-url https://example.com
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"qwqserver/pkg/docsite"
+	"qwqserver/pkg/util"
+)
+
+// options 命令行参数
+type options struct {
+	src       string
+	templates string
+	out       string
+	base      string
+	siteURL   string
+	clean     bool
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.src, "src", util.WorkDir("resources/docs"), "文档目录")
+	flag.StringVar(&opts.templates, "templates", util.WorkDir("resources/templates/markdown"), "模板目录")
+	flag.StringVar(&opts.out, "out", "dist/docs", "输出目录")
+	flag.StringVar(&opts.base, "base", "/", "站点部署的路径前缀，如 /docs")
+	flag.StringVar(&opts.siteURL, "site-url", "", "站点地址，如 https://example.com，用于生成 sitemap.xml，为空时不生成")
+	flag.BoolVar(&opts.clean, "clean", false, "导出前清空输出目录")
+	flag.Parse()
+
+	if err := export(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "导出失败:", err)
+		os.Exit(1)
+	}
+}
+
+// export 导出静态站点
+func export(opts options) error {
+	site, err := docsite.New(opts.src, docsite.Options{BaseURL: opts.base, PageSuffix: ".html"})
+	if err != nil {
+		return err
+	}
+	for _, w := range site.Warnings() {
+		fmt.Println("警告:", w)
+	}
+
+	tpl, err := template.ParseGlob(filepath.Join(opts.templates, "*.html"))
+	if err != nil {
+		return fmt.Errorf("加载模板失败: %w", err)
+	}
+
+	if opts.clean {
+		if err := os.RemoveAll(opts.out); err != nil {
+			return fmt.Errorf("清空输出目录失败: %w", err)
+		}
+	}
+
+	searchURL := site.BaseURL() + "/search.html"
+	view := func(data map[string]any) map[string]any {
+		data["HomeURL"] = site.HomeURL()
+		data["SearchURL"] = searchURL
+		return data
+	}
+
+	// 页面
+	pages := site.AllPages()
+	for _, p := range pages {
+		err := writeTemplate(opts.out, outputPath(site, p.URL), tpl, "markdown.html", view(map[string]any{
+			"Title":   p.Title,
+			"Content": template.HTML(p.HTML),
+			"Page":    p,
+			"Nav":     site.Nav(p.Path),
+		}))
+		if err != nil {
+			return err
+		}
+	}
+
+	// 搜索页：在浏览器中加载搜索索引检索
+	err = writeTemplate(opts.out, outputPath(site, searchURL), tpl, "list.html", view(map[string]any{
+		"Title":          "搜索",
+		"Nav":            site.Nav(""),
+		"SearchIndexURL": site.BaseURL() + "/search-index.json",
+	}))
+	if err != nil {
+		return err
+	}
+
+	// 搜索索引
+	err = writeFile(opts.out, "search-index.json", func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(site.SearchIndex())
+	})
+	if err != nil {
+		return err
+	}
+
+	// sitemap.xml
+	if opts.siteURL != "" {
+		err = writeFile(opts.out, "sitemap.xml", func(w io.Writer) error {
+			return site.WriteSitemap(w, opts.siteURL)
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		fmt.Println("未指定 -site-url，跳过 sitemap.xml")
+	}
+
+	// 静态资源
+	assets, err := site.Assets()
+	if err != nil {
+		return err
+	}
+	for _, rel := range assets {
+		if err := copyFile(filepath.Join(opts.src, filepath.FromSlash(rel)), filepath.Join(opts.out, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("导出完成: %d 个页面, %d 个静态资源 -> %s\n", len(pages), len(assets), opts.out)
+	return nil
+}
+
+// outputPath 由页面链接得到输出文件的相对路径
+func outputPath(site *docsite.Site, url string) string {
+	return strings.TrimPrefix(path.Clean(strings.TrimPrefix(url, site.BaseURL())), "/")
+}
+
+// writeTemplate 渲染模板并写入文件
+func writeTemplate(outDir, rel string, tpl *template.Template, name string, data any) error {
+	return writeFile(outDir, rel, func(w io.Writer) error {
+		return tpl.ExecuteTemplate(w, name, data)
+	})
+}
+
+// writeFile 创建输出文件（含上级目录）并写入
+func writeFile(outDir, rel string, write func(w io.Writer) error) error {
+	file := filepath.Join(outDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("写入 %s 失败: %w", rel, err)
+	}
+	return f.Close()
+}
+
+// copyFile 复制文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("读取静态资源失败: %w", err)
+	}
+	defer in.Close()
+	return writeFile(filepath.Dir(dst), filepath.Base(dst), func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
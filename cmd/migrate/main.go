@@ -0,0 +1,166 @@
+// migrate 管理数据库迁移
+//
+// 使用与服务端相同的配置加载（configs/config.yaml、.env、环境变量、命令行参数），
+// 配置参数写在子命令之前。
+//
+// 用法：
+//
+//	go run ./cmd/migrate up                 执行全部未执行的迁移
+//	go run ./cmd/migrate up 20250601000100  执行到指定版本
+//	go run ./cmd/migrate down [N]           回滚最近的 N 个迁移，默认 1
+//	go run ./cmd/migrate status             查看迁移状态
+//	go run ./cmd/migrate create <名称> [go|sql]  在 internal/migrations 中生成新迁移
+//	go run ./cmd/migrate -config prod.yaml status
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"qwqserver/internal/config"
+	"qwqserver/internal/migrations"
+	"qwqserver/pkg/database"
+	"qwqserver/pkg/migrate"
+)
+
+// migrationsDir create 生成文件的目录，相对于仓库根目录
+const migrationsDir = "internal/migrations"
+
+const usage = `用法: migrate [配置参数] <命令> [参数]
+
+命令:
+  up [版本号]          执行未执行的迁移，指定版本号时只执行到该版本
+  down [N]             回滚最近的 N 个迁移，默认 1
+  status               查看迁移状态
+  create <名称> [go|sql]  生成新迁移，默认 go
+
+配置参数与服务端相同，使用 -h 查看`
+
+func main() {
+	loaded, err := config.Load(config.Options{Args: os.Args[1:]})
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "配置加载失败:", err)
+		os.Exit(2)
+	}
+	if len(loaded.Args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := run(loaded.Config, loaded.Args[0], loaded.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "迁移失败:", err)
+		os.Exit(1)
+	}
+}
+
+// run 执行子命令
+func run(cfg *config.Config, cmd string, args []string) error {
+	if cmd == "create" {
+		if len(args) == 0 {
+			return errors.New("缺少迁移名称")
+		}
+		kind := ""
+		if len(args) > 1 {
+			kind = args[1]
+		}
+		files, err := migrate.Create(migrationsDir, args[0], kind, time.Now())
+		for _, f := range files {
+			fmt.Println("已创建", f)
+		}
+		return err
+	}
+
+	conn, m, err := newMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	switch cmd {
+	case "up":
+		var version int64
+		if len(args) > 0 {
+			if version, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+				return fmt.Errorf("版本号无效: %w", err)
+			}
+		}
+		done, err := m.UpTo(ctx, version)
+		if err == nil && len(done) == 0 {
+			fmt.Println("没有待执行的迁移")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return fmt.Errorf("回滚数量无效: %q", args[0])
+			}
+		}
+		done, err := m.Down(ctx, steps)
+		if err == nil && len(done) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+		return err
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(status)
+		return nil
+	}
+	return fmt.Errorf("未知命令 %q\n\n%s", cmd, usage)
+}
+
+// newMigrator 按配置连接数据库并创建迁移执行器，调用方负责关闭连接
+func newMigrator(cfg *config.Config) (*database.Conn, *migrate.Migrator, error) {
+	conn, err := database.Open(&database.Config{
+		Driver:          cfg.Database.Driver,
+		DSN:             cfg.Database.DSN,
+		LogLevel:        cfg.Database.LogLevel,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	m, err := migrations.New(conn.DB())
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	m.Log = func(format string, args ...any) {
+		fmt.Printf(format+"\n", args...)
+	}
+	return conn, m, nil
+}
+
+// printStatus 以表格输出迁移状态
+func printStatus(status []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "版本\t名称\t状态\t执行时间")
+	for _, s := range status {
+		state, at := "待执行", ""
+		if s.Applied {
+			state = "已执行"
+			at = s.AppliedAt.Local().Format(time.DateTime)
+		}
+		if s.Missing {
+			state = "已执行（代码中不存在）"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, at)
+	}
+	w.Flush()
+}
@@ -1,12 +1,29 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"os"
 	"qwqserver/internal/app"
 	"qwqserver/internal/config"
+	"sync/atomic"
 )
 
+// 日志级别，与 listen.log_level 的取值对应
+var logLevels = map[string]int32{"debug": 0, "info": 1, "warn": 2, "error": 3, "silent": 4}
+
 type qwqLog struct {
+	level *atomic.Int32
+}
+
+// SetLevel 设置最低输出级别，未知的级别按 debug 处理
+func (q qwqLog) SetLevel(level string) {
+	q.level.Store(logLevels[level])
+}
+
+func (q qwqLog) enabled(level string) bool {
+	return logLevels[level] >= q.level.Load()
 }
 
 func (q qwqLog) Log(msg string, args ...any) {
@@ -15,36 +32,71 @@ func (q qwqLog) Log(msg string, args ...any) {
 }
 
 func (q qwqLog) Info(msg string, args ...any) {
-	q.Log(msg, args)
+	if q.enabled("info") {
+		q.Log(msg, args...)
+	}
 }
 
 func (q qwqLog) Error(msg string, args ...any) {
-	q.Log(msg, args)
+	if q.enabled("error") {
+		q.Log(msg, args...)
+	}
 }
 
 func (q qwqLog) Warn(msg string, args ...any) {
-	q.Log(msg)
+	if q.enabled("warn") {
+		q.Log(msg, args...)
+	}
 }
 
 func (q qwqLog) Debug(msg string, args ...any) {
-	q.Log(msg)
+	if q.enabled("debug") {
+		q.Log(msg, args...)
+	}
 }
 
 func (q qwqLog) Fatal(msg string, args ...any) {
-	q.Log(msg, args)
+	q.Log(msg, args...)
 }
 
 func (q qwqLog) Panic(msg string, args ...any) {
-	q.Log(msg, args)
+	q.Log(msg, args...)
 }
 
 func main() {
-	l := qwqLog{}
-	cfg := config.New()
+	l := qwqLog{level: new(atomic.Int32)}
+
+	// 默认值 → YAML → .env → 环境变量 → 命令行参数
+	opts := config.Options{Args: os.Args[1:]}
+	loaded, err := config.Load(opts)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "配置加载失败:", err)
+		os.Exit(2)
+	}
+	if loaded.PrintConfig {
+		loaded.Print(os.Stdout)
+		return
+	}
+	cfg := config.Set(loaded.Config)
+	l.SetLevel(cfg.Listen.LogLevel)
+
+	// 配置文件变化时热更新支持的配置项
+	watcher := config.NewWatcher(loaded, opts, l)
+	watcher.Subscribe(func(cfg *config.Config, _ config.Diff) {
+		l.SetLevel(cfg.Listen.LogLevel)
+	}, "listen.log_level")
+
 	a := app.New(&app.Config{
-		L:      l,
-		Config: cfg,
+		L:       l,
+		Config:  cfg,
+		Watcher: watcher,
 	})
+	if a == nil {
+		os.Exit(1)
+	}
 
 	defer a.Close()
 
@@ -2,18 +2,35 @@ package app
 
 import (
 	"context"
+	"path/filepath"
 	"qwqserver/internal/base"
 	"qwqserver/pkg/util/singleton"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
+	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"qwqserver/internal/config"
-	"qwqserver/internal/model"
+	"qwqserver/internal/counter"
+	"qwqserver/internal/editing"
+	"qwqserver/internal/locale"
+	"qwqserver/internal/middleware"
+	"qwqserver/internal/migrations"
+	"qwqserver/internal/ranking"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/search"
 	"qwqserver/internal/server"
-	"qwqserver/pkg/cache"
+	"qwqserver/internal/service"
+	"qwqserver/internal/session"
+	"qwqserver/internal/trash"
 	"qwqserver/pkg/database"
+	"qwqserver/pkg/docsite"
+	"qwqserver/pkg/hotrank"
+	"qwqserver/pkg/markup"
+	"qwqserver/pkg/util"
+	"qwqserver/pkg/util/passsec"
 )
 
 type Application struct {
@@ -22,6 +39,24 @@ type Application struct {
 	// 其他组件...\
 	Redis *redis.Client
 	Log   base.Logger
+	// 数据仓库
+	Repos *repository.Repositories
+	// 服务层依赖，经处理器传递给各服务
+	Deps *service.Deps
+	// 路由
+	Router *gin.Engine
+	// 帖子计数写回
+	Counter *counter.Counter
+	// 热门排行
+	Ranker *ranking.Ranker
+	// 回收站定期清理
+	Purger *trash.Purger
+	// 文档站点
+	Docs *docsite.Site
+	// 配置热更新
+	Watcher *config.Watcher
+	// 数据库连接池，关闭应用时关闭
+	conn *database.Conn
 	//PasswordStore *security.PasswordStore
 	//PasswordSvc   security.PasswordService
 }
@@ -29,6 +64,8 @@ type Application struct {
 type Config struct {
 	L      base.Logger
 	Config *config.Config
+	// Watcher 配置文件监视器，为空时不支持热更新
+	Watcher *config.Watcher
 }
 
 func IsLogger(l interface{}) bool {
@@ -114,8 +151,25 @@ func NewApplication(appConfigs ...*Config) *Application {
 	//	logger.WithConsoleOutput(true),
 	//)
 
+	// 加载多语言消息目录
+	if cfg.I18n != nil {
+		dir := cfg.I18n.Dir
+		if dir != "" && !filepath.IsAbs(dir) {
+			dir = util.WorkDir(dir)
+		}
+		if err := locale.Init(locale.Config{Dir: dir, DefaultLang: cfg.I18n.DefaultLang}); err != nil {
+			l.Error("多语言消息加载失败 Error: %v", err)
+		}
+	}
+
+	// 密码策略
+	if cfg.Password != nil {
+		passsec.SetPolicy(passwordPolicy(cfg.Password, l))
+		passsec.SetHasher(passwordHasher(cfg.Password, l))
+	}
+
 	// 初始化数据库
-	db, err := database.InitDB(&database.Config{
+	conn, err := database.Open(&database.Config{
 		Driver:              cfg.Database.Driver,
 		DSN:                 cfg.Database.DSN,
 		LogLevel:            cfg.Database.LogLevel,
@@ -124,31 +178,20 @@ func NewApplication(appConfigs ...*Config) *Application {
 		ConnMaxLifetime:     cfg.Database.ConnMaxLifetime,
 		ConnMaxIdleTime:     cfg.Database.ConnMaxIdleTime,
 		HealthCheckInterval: 3 * time.Second,
+		Replicas:            replicaDSNs(cfg.Database.Replicas),
+		StickyWindow:        cfg.Database.StickyWindow,
+		Logger:              l,
 	})
 	if err != nil {
 		l.Error("数据库初始化失败 Error: %v", err)
+		return nil
 	}
+	db := conn.DB()
 
 	//  初始化Redis缓存
 	var redisClient *redis.Client
 	if cfg.Redis != nil {
-		//redisClient = cache.InitRedis(&cache.Config{
-		//	Addr:         cfg.Redis.Addr,
-		//	Password:     cfg.Redis.Password,
-		//	DB:           cfg.Redis.DB,
-		//	PoolSize:     cfg.Redis.PoolSize,
-		//	MinIdleConns: cfg.Redis.MinIdleConns,
-		//	DialTimeout:  cfg.Redis.DialTimeout,
-		//	ReadTimeout:  cfg.Redis.ReadTimeout,
-		//	WriteTimeout: cfg.Redis.WriteTimeout,
-		//	IdleTimeout:  cfg.Redis.IdleTimeout,
-		//})
-		//if err != nil {
-		//	l.Error("Redis初始化失败 Error: %v", err)
-		//} else {
-		//	app.Redis = redisClient
-		//}
-		redisClient = cache.InitRedis(&cache.Config{
+		redisClient = redis.NewClient(&redis.Options{
 			Addr:     cfg.Redis.Addr,
 			DB:       cfg.Redis.DB,
 			Password: cfg.Redis.Password,
@@ -156,21 +199,93 @@ func NewApplication(appConfigs ...*Config) *Application {
 		app.Redis = redisClient
 	}
 
-	// 自动迁移模型
+	// 执行未执行的数据库迁移
 	ctx := context.Background()
 	if cfg.Database.AutoMigrate {
-		if err := database.AutoMigrate(
-			ctx,
-			&model.User{},
-			&model.Post{},
-			// 添加其他模型...
-		); err != nil {
-			l.Error("数据库自动迁移失败 Error: %v", err)
+		if _, err := migrations.Up(ctx, db, l.Info); err != nil {
+			l.Error("数据库迁移失败 Error: %v", err)
 		}
 	}
 
+	// 初始化数据仓库
+	repos := repository.New(db, redisClient)
+
+	// 初始化帖子计数写回
+	postCounter := counter.New(redisClient, repos.Posts, counter.Config{
+		FlushInterval:   cfg.Counter.FlushInterval,
+		FlushBatchSize:  cfg.Counter.FlushBatchSize,
+		ViewDedupWindow: cfg.Counter.ViewDedupWindow,
+	}, l)
+
+	// 初始化热门排行
+	hotrank.SetDefault(hotrankParams(cfg.Ranking))
+	ranker := ranking.New(redisClient, repos.Posts, ranking.Config{
+		RecomputeInterval: cfg.Ranking.RecomputeInterval,
+		Window:            cfg.Ranking.Window,
+	}, l)
+
+	// 初始化回收站定期清理
+	purger := trash.New(repos, trash.Config{
+		Retention:     cfg.Trash.Retention,
+		PurgeInterval: cfg.Trash.PurgeInterval,
+		PurgeBatch:    cfg.Trash.PurgeBatch,
+	}, l)
+
+	// 初始化自动保存草稿与编辑状态
+	postEditing := editing.New(redisClient, editing.Config{
+		DraftTTL:     cfg.Editing.DraftTTL,
+		MaxDraftSize: cfg.Editing.MaxDraftSize,
+		PresenceTTL:  cfg.Editing.PresenceTTL,
+	})
+
+	// 初始化帖子搜索
+	searchEngine := search.New(search.Config{
+		Engine:        cfg.Search.Engine,
+		SnippetLength: cfg.Search.SnippetLength,
+		TitleBoost:    cfg.Search.TitleBoost,
+		RebuildBatch:  cfg.Search.RebuildBatch,
+	}, repos.Posts, l)
+
+	// 初始化帖子内容渲染
+	markup.SetDefault(markup.NewRenderer(markup.Options{
+		MentionURL: cfg.Render.MentionURL,
+		TagURL:     cfg.Render.TagURL,
+	}, cfg.Render.CacheSize))
+
+	// 初始化文档站点，后台轮询文档目录，变化时重建
+	docs, err := docsite.New(util.WorkDir(cfg.Docs.Dir), docsite.Options{BaseURL: "/docs"})
+	if err != nil {
+		l.Error("文档站点构建失败 Error: %v", err)
+	}
+	for _, w := range docs.Warnings() {
+		l.Warn("文档警告: %v", w)
+	}
+	docs.Watch(cfg.Docs.PollInterval, func(err error) {
+		l.Error("文档目录检查失败 Error: %v", err)
+	})
+	docsite.SetDefault(docs)
+
+	// 初始化登录令牌存储，未配置 Redis 时令牌只在当前进程中有效
+	sessions := session.NewMemory()
+	if redisClient != nil {
+		sessions = session.NewRedis(redisClient)
+	} else {
+		l.Warn("未配置 Redis，登录令牌保存在进程内存中，多实例部署时无法共享")
+	}
+
+	// 服务层依赖
+	deps := &service.Deps{
+		Repos:    repos,
+		Counter:  postCounter,
+		Ranker:   ranker,
+		Search:   searchEngine,
+		Editing:  postEditing,
+		Sessions: sessions,
+	}
+
 	// 初始化路由
-	server.RouterApiV1()
+	server.SetLogger(l)
+	router := server.RouterApiV1(deps)
 	// 启动服务器
 	//err = server.Run(cfg.ListenAddress())
 	//if err != nil {
@@ -179,31 +294,163 @@ func NewApplication(appConfigs ...*Config) *Application {
 	//}
 	//l.Info("服务器启动成功, address: %v", cfg.ListenAddress())
 
+	// 配置热更新
+	if w := appCfg.Watcher; w != nil {
+		subscribeConfig(w, l)
+		if cfg.Reload != nil && cfg.Reload.Enabled {
+			w.Start(cfg.Reload.Interval)
+		}
+	}
+
 	return &Application{
-		Config: cfg,
-		DB:     db,
-		Redis:  redisClient,
+		Config:  cfg,
+		DB:      db,
+		Redis:   redisClient,
+		Log:     l,
+		Repos:   repos,
+		Deps:    deps,
+		Router:  router,
+		Counter: postCounter,
+		Ranker:  ranker,
+		Purger:  purger,
+		Docs:    docs,
+		Watcher: appCfg.Watcher,
+		conn:    conn,
 	}
 }
 
 func (app *Application) Close() {
+	if app.Watcher != nil {
+		app.Watcher.Close()
+	}
+	// 后台任务需在关闭数据库之前停止
+	app.Purger.Close()
+	app.Ranker.Close()
+	app.Counter.Close()
+	// 停止文档目录轮询并断开实时刷新连接
+	app.Docs.Close()
 	// 关闭数据库连接
-	err := database.Close()
+	err := app.conn.Close()
 	if err != nil {
 		app.Log.Error("数据库关闭失败 Error: %v", err)
 	}
 	// 关闭Redis连接
-	//err = cache.CloseRedis()
-	//if err != nil {
-	//	app.Log.Error("Redis关闭失败 Error: %v", err)
-	//}
+	if app.Redis != nil {
+		if err = app.Redis.Close(); err != nil {
+			app.Log.Error("Redis关闭失败 Error: %v", err)
+		}
+	}
 }
 
 func (app *Application) Listen() {
 	// 启动服务器
-	err := server.Run(app.Config.ListenAddress())
+	err := app.Router.Run(app.Config.ListenAddress())
 	if err != nil {
 		app.Log.Error("服务器启动失败 Error: %v", err)
 		return
 	}
 }
+
+// passwordPolicy 由配置生成密码策略，配置错误时记录日志并使用默认值
+func passwordPolicy(cfg *config.Password, l base.Logger) passsec.Policy {
+	minStrength, err := passsec.ParseStrength(cfg.MinStrength)
+	if err != nil {
+		l.Error("密码策略配置错误 Error: %v", err)
+		minStrength = passsec.DefaultPolicy.MinStrength
+	}
+	policy := passsec.Policy{
+		MinStrength:      minStrength,
+		MinLength:        cfg.MinLength,
+		MaxLength:        cfg.MaxLength,
+		MinClasses:       cfg.MinClasses,
+		DisallowUserInfo: cfg.DisallowUserInfo,
+		HistorySize:      cfg.HistorySize,
+		MaxAge:           cfg.MaxAge,
+	}
+
+	switch {
+	case cfg.BreachedFile != "":
+		list := passsec.NewHashList()
+		if cfg.CheckBreached {
+			list = passsec.NewCommonHashList()
+		}
+		path := cfg.BreachedFile
+		if !filepath.IsAbs(path) {
+			path = util.WorkDir(path)
+		}
+		if err := list.LoadFile(path); err != nil {
+			l.Error("泄露密码列表加载失败 Error: %v", err)
+		}
+		policy.Breached = list
+	case cfg.CheckBreached:
+		policy.Breached = passsec.CommonPasswords()
+	}
+	return policy
+}
+
+// passwordHasher 由配置生成新密码使用的哈希算法，配置错误时记录日志并使用 bcrypt
+func passwordHasher(cfg *config.Password, l base.Logger) passsec.Hasher {
+	switch cfg.Algorithm {
+	case "", passsec.AlgBcrypt:
+		if cfg.BcryptCost != 0 && (cfg.BcryptCost < passsec.MinCost || cfg.BcryptCost > passsec.MaxCost) {
+			l.Error("bcrypt 工作因子配置错误 Error: %d 不在 %d-%d 之间", cfg.BcryptCost, passsec.MinCost, passsec.MaxCost)
+			return passsec.Bcrypt{}
+		}
+		return passsec.Bcrypt{Cost: cfg.BcryptCost}
+	case passsec.AlgArgon2id:
+		h := passsec.DefaultArgon2id
+		if cfg.Argon2Memory > 0 {
+			h.Memory = cfg.Argon2Memory
+		}
+		if cfg.Argon2Time > 0 {
+			h.Time = cfg.Argon2Time
+		}
+		if cfg.Argon2Threads > 0 {
+			h.Threads = cfg.Argon2Threads
+		}
+		return h
+	default:
+		l.Error("密码哈希算法配置错误 Error: 不支持 %q", cfg.Algorithm)
+		return passsec.Bcrypt{Cost: cfg.BcryptCost}
+	}
+}
+
+// hotrankParams 由配置生成热门排序参数
+func hotrankParams(cfg *config.Ranking) hotrank.Params {
+	return hotrank.Params{
+		LikeWeight:     cfg.LikeWeight,
+		CommentWeight:  cfg.CommentWeight,
+		ViewWeight:     cfg.ViewWeight,
+		BookmarkWeight: cfg.BookmarkWeight,
+		Gravity:        cfg.Gravity,
+		AgeOffset:      cfg.AgeOffset,
+	}
+}
+
+// subscribeConfig 订阅支持热更新的配置项（reload:"hot"）
+func subscribeConfig(w *config.Watcher, l base.Logger) {
+	w.Subscribe(func(cfg *config.Config, _ config.Diff) {
+		middleware.SetQueueLimit(cfg.Listen.QueueLimitMaxConcurrent)
+	}, "listen.queue_limit_max_concurrent")
+	w.Subscribe(func(cfg *config.Config, _ config.Diff) {
+		database.SetLogLevel(cfg.Database.LogLevel)
+	}, "database.log_level")
+	w.Subscribe(func(cfg *config.Config, _ config.Diff) {
+		hotrank.SetDefault(hotrankParams(cfg.Ranking))
+	}, "ranking.")
+	w.Subscribe(func(cfg *config.Config, _ config.Diff) {
+		passsec.SetPolicy(passwordPolicy(cfg.Password, l))
+		passsec.SetHasher(passwordHasher(cfg.Password, l))
+	}, "password.")
+}
+
+// replicaDSNs 拆分以 ; 分隔的只读副本 DSN
+func replicaDSNs(s string) []string {
+	var dsns []string
+	for _, dsn := range strings.Split(s, ";") {
+		if dsn = strings.TrimSpace(dsn); dsn != "" {
+			dsns = append(dsns, dsn)
+		}
+	}
+	return dsns
+}
@@ -5,8 +5,13 @@ const (
 	RegisterPath = "/register"
 	LogoutPath   = "/logout"
 	DelIDPath    = "/del"
+	RestorePath  = "/restore"
+	LocalePath   = "/locale"
 	ProfilePath  = "/profile"
 	Identity     = "/identity"
+
+	ChangePasswordPath = "/password/change"
+	ResetPasswordPath  = "/password/reset"
 )
 
 // Auth Middleware 鉴权状态码
@@ -30,3 +35,10 @@ const (
 	IdentityOK                       // OK 认证成功
 	IdentitySkipped                  // Skipped 跳过认证
 )
+
+// Gin 上下文中由认证中间件设置的 Key
+const (
+	ContextUserIDKey   = "user_id"
+	ContextPlatformKey = "platform"
+	ContextDeviceIDKey = "device_id"
+)
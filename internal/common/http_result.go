@@ -1,7 +1,94 @@
 package common
 
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/locale"
+	"qwqserver/pkg/i18n"
+	"qwqserver/pkg/validate"
+)
+
+// HTTPResult 统一响应
+// 出错时 Error 为错误码（见 errcode），Msg 为按请求语言本地化的提示；成功时 Error 为空
 type HTTPResult struct {
-	Code int         `json:"code"`
-	Msg  string      `json:"msg"`
-	Data interface{} `json:"data"`
+	Code      int          `json:"code"`                 // HTTP 状态码
+	Error     errcode.Code `json:"error,omitempty"`      // 错误码
+	Msg       string       `json:"msg"`                  // 提示消息
+	Data      interface{}  `json:"data"`                 // 数据，出错时为错误的附加信息
+	RequestID string       `json:"request_id,omitempty"` // 请求 ID，与响应头 X-Request-ID 一致
+
+	err     *errcode.Error
+	msgKey  string
+	msgArgs i18n.Args
+}
+
+// Success 成功响应，key 为提示消息在消息目录（internal/locale）中的键，输出时按请求语言生成消息
+func Success(key string, data any) *HTTPResult {
+	return &HTTPResult{Code: http.StatusOK, Msg: locale.T(locale.DefaultLang(), key, nil), Data: data, msgKey: key}
+}
+
+// OK 成功响应，提示消息为 ok
+func OK(data any) *HTTPResult {
+	return Success("common.ok", data)
+}
+
+// WithArgs 设置提示消息的参数，参数 count 同时用于选择复数形式
+func (r *HTTPResult) WithArgs(args i18n.Args) *HTTPResult {
+	r.msgArgs = args
+	if r.msgKey != "" {
+		r.Msg = locale.T(locale.DefaultLang(), r.msgKey, args)
+	}
+	return r
+}
+
+// Fail 错误响应：*errcode.Error 使用其错误码与状态码，其他错误视为内部错误，原因不返回给客户端
+// 消息在输出时按请求语言生成
+func Fail(err error) *HTTPResult {
+	e := errcode.From(err)
+	return &HTTPResult{
+		Code:  e.Status(),
+		Error: e.Code,
+		Msg:   e.Message(locale.DefaultLang()),
+		Data:  e.Details,
+		err:   e,
+	}
+}
+
+// Err 响应对应的错误，成功或未使用错误码的响应返回 nil
+func (r *HTTPResult) Err() *errcode.Error {
+	return r.err
+}
+
+// Message 提示消息在指定语言下的文本，未使用消息键的响应返回原消息
+func (r *HTTPResult) Message(lang string) string {
+	switch {
+	case r.err != nil:
+		return r.err.Message(lang)
+	case r.msgKey != "":
+		return locale.T(lang, r.msgKey, r.msgArgs)
+	}
+	return r.Msg
+}
+
+// BindError 参数绑定失败的响应
+//   - 标签校验未通过：VALIDATION_FAILED，data 为全部字段的错误
+//   - 字段类型错误：INVALID_PARAM，指出字段
+//   - 校验规则声明错误：内部错误
+//   - 其他错误（如 JSON 格式错误）：请求参数错误
+func BindError(err error) *HTTPResult {
+	var verrs validate.Errors
+	if errors.As(err, &verrs) {
+		return Fail(errcode.Invalid(verrs))
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Fail(errcode.Wrap(errcode.InvalidParam, err).With("field", typeErr.Field))
+	}
+	var tagErr *validate.TagError
+	if errors.As(err, &tagErr) {
+		return Fail(err)
+	}
+	return Fail(errcode.Wrap(errcode.BadRequest, err))
 }
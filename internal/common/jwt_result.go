@@ -9,4 +9,6 @@ type JWTResult struct {
 	Error        string `json:"error"`
 	Platform     string `json:"platform"`
 	DeviceID     string `json:"device_id"`
+
+	PasswordExpired bool `json:"password_expired,omitempty"` // 密码已超过有效期，应提示用户修改
 }
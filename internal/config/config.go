@@ -5,10 +5,10 @@ import (
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
-	"qwqserver/pkg/defaultvalue"
-	"qwqserver/pkg/util/singleton"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type Config struct {
@@ -16,10 +16,21 @@ type Config struct {
 	*Database  `yaml:"database"`
 	*Redis     `yaml:"redis"`
 	*AdminUser `yaml:"admin_user"`
+	*Counter   `yaml:"counter"`
+	*Ranking   `yaml:"ranking"`
+	*Search    `yaml:"search"`
+	*Render    `yaml:"render"`
+	*Editing   `yaml:"editing"`
+	*Docs      `yaml:"docs"`
+	*I18n      `yaml:"i18n"`
+	*Password  `yaml:"password"`
+	*Reload    `yaml:"reload"`
+	*Trash     `yaml:"trash"`
 }
 
 var (
-	configSingleton = singleton.NewSingleton[Config]()
+	current   atomic.Pointer[Config]
+	currentMu sync.Mutex
 )
 
 // DBDriverName 获取数据库驱动名称（已弃用）
@@ -131,36 +142,39 @@ func cloneConfig(original interface{}) interface{} {
 	return newObj
 }
 
+// New 全局配置，首次调用时按层加载（不解析命令行），已通过 Set 设置时直接返回
+// 配置热更新后返回新的配置，需要跟随变化的组件应通过 Watcher.Subscribe 订阅
 func New(s ...string) *Config {
-	cfg := configSingleton.Get(func() *Config {
-		var filename string
-		if len(s) == 0 {
-			filename = "configs/config.yaml"
-		} else {
-			filename = strings.Join(s, "")
-		}
-
-		defaultConfig := &Config{}
-		err := defaultvalue.SetDefaults(defaultConfig)
-		if err != nil {
-			panic(fmt.Sprintf("配置初始化默认数据失败: %v", err))
-		}
-
-		// 加载或创建配置
-		config, err := LoadOrCreateYAML(
-			filename,
-			defaultConfig,
-			0755, // 目录权限
-			0644, // 文件权限
-		)
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
 
-		if err != nil {
-			panic(fmt.Sprintf("配置初始化失败: %v", err))
-		}
+	var filename string
+	if len(s) == 0 {
+		filename = DefaultFile
+	} else {
+		filename = strings.Join(s, "")
+	}
+	loaded, err := Load(Options{File: filename})
+	if err != nil {
+		panic(fmt.Sprintf("配置初始化失败: %v", err))
+	}
+	current.Store(loaded.Config)
+	return loaded.Config
+}
 
-		// 类型断言获取具体配置
-		cfg2 := config.(*Config)
-		return cfg2
-	})
+// Set 设置全局配置，需在首次调用 New 之前，之后调用无效并返回已有的配置
+func Set(cfg *Config) *Config {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if cur := current.Load(); cur != nil {
+		return cur
+	}
+	current.Store(cfg)
 	return cfg
 }
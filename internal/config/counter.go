@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// Counter 帖子计数（浏览/点赞/收藏）写回配置
+type Counter struct {
+	FlushInterval   time.Duration `yaml:"flush_interval" validate:"min=1s" env:"COUNTER_FLUSH_INTERVAL" env-default:"30s" qwq-default:"30s"`      // 批量落库间隔
+	FlushBatchSize  int           `yaml:"flush_batch_size" validate:"min=1" env:"COUNTER_FLUSH_BATCH_SIZE" env-default:"200" qwq-default:"200"`   // 每批落库的帖子数
+	ViewDedupWindow time.Duration `yaml:"view_dedup_window" validate:"min=0" env:"COUNTER_VIEW_DEDUP_WINDOW" env-default:"30m" qwq-default:"30m"` // 同一用户/IP 浏览去重窗口
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// Docs 文档站点配置
+type Docs struct {
+	Dir          string        `yaml:"dir" validate:"required" env:"DOCS_DIR" env-default:"resources/docs" qwq-default:"resources/docs"` // 文档目录，相对工作目录
+	PollInterval time.Duration `yaml:"poll_interval" validate:"min=0" env:"DOCS_POLL_INTERVAL" env-default:"1s" qwq-default:"1s"`        // 检查文档变化的轮询间隔
+	LiveReload   bool          `yaml:"live_reload" env:"DOCS_LIVE_RELOAD" env-default:"false" qwq-default:"false"`                       // 开发模式：文档变化时通知已打开的页面自动刷新
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// Editing 协同编辑（自动保存草稿与编辑状态）配置
+type Editing struct {
+	DraftTTL     time.Duration `yaml:"draft_ttl" validate:"min=1m" env:"EDITING_DRAFT_TTL" env-default:"72h" qwq-default:"72h"`                  // 草稿保留时间
+	MaxDraftSize int           `yaml:"max_draft_size" validate:"min=1" env:"EDITING_MAX_DRAFT_SIZE" env-default:"1048576" qwq-default:"1048576"` // 草稿最大字节数
+	PresenceTTL  time.Duration `yaml:"presence_ttl" validate:"min=1s" env:"EDITING_PRESENCE_TTL" env-default:"30s" qwq-default:"30s"`            // 编辑状态心跳超时
+}
@@ -0,0 +1,7 @@
+package config
+
+// I18n 多语言配置
+type I18n struct {
+	Dir         string `yaml:"dir" env:"I18N_DIR" env-default:"" qwq-default:""`                                                 // 额外的消息目录，其中的 <语言>.yaml 覆盖或补充内置消息，为空时只使用内置消息
+	DefaultLang string `yaml:"default_lang" validate:"required" env:"I18N_DEFAULT_LANG" env-default:"zh-CN" qwq-default:"zh-CN"` // 默认语言，无法协商或缺少消息时使用
+}
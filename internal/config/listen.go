@@ -4,11 +4,11 @@ import "fmt"
 
 type Listen struct {
 	Host                    string `yaml:"host" env:"SERVER_HOST" env-default:"localhost" qwq-default:""`
-	Port                    int    `yaml:"port" env:"SERVER_PORT" env-default:"8080" qwq-default:"5000"`
-	LogLevel                string `yaml:"log_level" env:"SERVER_LOG_LEVEL" env-default:"debug" qwq-default:"debug"`
+	Port                    int    `yaml:"port" validate:"min=0,max=65535" env:"SERVER_PORT" env-default:"8080" qwq-default:"5000"`
+	LogLevel                string `yaml:"log_level" validate:"oneof=silent debug info warn error" reload:"hot" env:"SERVER_LOG_LEVEL" env-default:"debug" qwq-default:"debug"`
 	Mode                    string `yaml:"mode" env:"SERVER_MODE" env-default:"debug" default-value:"debug"`
-	MaxConcurrent           int    `yaml:"max_concurrent" env:"SERVER_MAX_CONCURRENT" env-default:"100" qwq-default:"100"`
-	QueueLimitMaxConcurrent int    `yaml:"queue_limit_max_concurrent" env:"SERVER_QUEUE_LIMIT_MAX_CONCURRENT" env-default:"100" qwq-default:"100"`
+	MaxConcurrent           int    `yaml:"max_concurrent" validate:"min=1" env:"SERVER_MAX_CONCURRENT" env-default:"100" qwq-default:"100"`
+	QueueLimitMaxConcurrent int    `yaml:"queue_limit_max_concurrent" validate:"min=0" reload:"hot" env:"SERVER_QUEUE_LIMIT_MAX_CONCURRENT" env-default:"100" qwq-default:"100"`
 }
 
 func (l *Listen) ListenAddress() string {
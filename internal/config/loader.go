@@ -0,0 +1,420 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"qwqserver/pkg/defaultvalue"
+)
+
+// 配置按以下顺序逐层覆盖，后面的优先：
+//
+//	默认值（qwq-default，缺省时 env-default）→ YAML 文件 → .env 文件 → 环境变量（env 标签）→ 命令行参数
+//
+// 命令行参数按 YAML 路径命名，例如 -listen.port 8080、-database.log_level=info。
+// 带 secret:"true" 标签的字段在 --print-config 中只显示掩码。
+
+// 默认文件路径
+const (
+	DefaultFile    = "configs/config.yaml"
+	DefaultEnvFile = ".env"
+)
+
+// Source 配置值的来源
+type Source string
+
+// 配置来源，按优先级从低到高
+const (
+	SourceDefault Source = "default"
+	SourceYAML    Source = "yaml"
+	SourceDotEnv  Source = "dotenv"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Origin 配置值的来源与来源中的名称（文件路径、环境变量名或参数名）
+type Origin struct {
+	Source Source
+	Name   string
+	Refs   []string // 值中解析过的密钥引用，如 env:DB_PASSWORD、file:/run/secrets/redis
+}
+
+func (o Origin) String() string {
+	s := string(o.Source)
+	if o.Name != "" {
+		s += " " + o.Name
+	}
+	for _, r := range o.Refs {
+		s += " ${" + r + "}"
+	}
+	return s
+}
+
+// Options 配置加载选项
+type Options struct {
+	File    string    // YAML 文件路径，为空时使用 DefaultFile，可被 -config 覆盖
+	EnvFile string    // .env 文件路径，为空时使用 DefaultEnvFile，可被 -env-file 覆盖，文件不存在时忽略
+	Args    []string  // 命令行参数（不含程序名），nil 表示不解析命令行
+	Environ []string  // 环境变量（KEY=VALUE），nil 时使用 os.Environ()
+	Output  io.Writer // 命令行帮助与错误的输出，nil 时为标准错误
+}
+
+// Loaded 加载结果
+type Loaded struct {
+	Config      *Config
+	Origins     map[string]Origin // YAML 路径 -> 来源
+	File        string            // 实际使用的 YAML 文件
+	PrintConfig bool              // 命令行指定了 --print-config
+	Args        []string          // 命令行参数之后的位置参数，如 cmd/migrate 的子命令
+}
+
+// field 配置中的一个叶子字段
+type field struct {
+	path     string // YAML 路径，如 database.dsn
+	env      string
+	secret   bool
+	hot      bool   // reload:"hot"，支持热更新
+	validate string // validate 标签，见 schema.go
+	value    reflect.Value
+}
+
+// fields 按声明顺序列出配置的全部叶子字段
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(sf.Name)
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				walk(fv, name)
+				continue
+			}
+			out = append(out, field{
+				path:     name,
+				env:      sf.Tag.Get("env"),
+				secret:   sf.Tag.Get("secret") == "true",
+				hot:      sf.Tag.Get("reload") == "hot",
+				validate: sf.Tag.Get("validate"),
+				value:    fv,
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+// Default 只包含默认值的配置
+func Default() (*Config, error) {
+	cfg := &Config{}
+	if err := defaultvalue.SetDefaultsWithTags(cfg, defaultvalue.DefaultValTagName, "env-default"); err != nil {
+		return nil, fmt.Errorf("配置初始化默认数据失败: %w", err)
+	}
+	return cfg, nil
+}
+
+// Load 按层加载配置并校验，YAML 文件不存在时以默认值创建
+// 各层的字符串值都可以包含 ${env:NAME} 与 ${file:PATH} 密钥引用，在所有层合并后解析
+func Load(opts Options) (*Loaded, error) {
+	cfg, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	l := &Loaded{Config: cfg, Origins: map[string]Origin{}, File: opts.File}
+	fs := fields(cfg)
+	for _, f := range fs {
+		l.Origins[f.path] = Origin{Source: SourceDefault}
+	}
+
+	// 先解析命令行，确定文件路径，覆盖值在最后应用
+	envFile := opts.EnvFile
+	var flagValues []flagValue
+	if opts.Args != nil {
+		set := flag.NewFlagSet("qwqserver", flag.ContinueOnError)
+		if opts.Output != nil {
+			set.SetOutput(opts.Output)
+		}
+		set.StringVar(&l.File, "config", orDefault(l.File, DefaultFile), "YAML 配置文件")
+		set.StringVar(&envFile, "env-file", orDefault(envFile, DefaultEnvFile), ".env 文件，不存在时忽略")
+		set.BoolVar(&l.PrintConfig, "print-config", false, "打印生效的配置与每项的来源后退出，敏感值以掩码显示")
+		values := make([]*flagValue, len(fs))
+		for i, f := range fs {
+			values[i] = &flagValue{field: f}
+			usage := "覆盖配置 " + f.path
+			if f.env != "" {
+				usage += "（环境变量 " + f.env + "）"
+			}
+			set.Var(values[i], f.path, usage)
+		}
+		if err := set.Parse(opts.Args); err != nil {
+			return nil, err
+		}
+		l.Args = set.Args()
+		for _, v := range values {
+			if v.set {
+				flagValues = append(flagValues, *v)
+			}
+		}
+	}
+	l.File = orDefault(l.File, DefaultFile)
+	envFile = orDefault(envFile, DefaultEnvFile)
+
+	if err := l.loadYAML(cfg); err != nil {
+		return nil, err
+	}
+
+	dotenv, err := ReadEnvFile(envFile)
+	if err != nil {
+		return nil, err
+	}
+	environ := opts.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+	env := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	for _, f := range fs {
+		if f.env == "" {
+			continue
+		}
+		if v, ok := env[f.env]; ok {
+			if err := setValue(f, v); err != nil {
+				return nil, fmt.Errorf("环境变量 %s: %w", f.env, err)
+			}
+			l.Origins[f.path] = Origin{Source: SourceEnv, Name: f.env}
+		} else if v, ok := dotenv[f.env]; ok {
+			if err := setValue(f, v); err != nil {
+				return nil, fmt.Errorf("%s 中的 %s: %w", envFile, f.env, err)
+			}
+			l.Origins[f.path] = Origin{Source: SourceDotEnv, Name: envFile}
+		}
+	}
+
+	for _, v := range flagValues {
+		if err := setValue(v.field, v.raw); err != nil {
+			return nil, fmt.Errorf("参数 -%s: %w", v.field.path, err)
+		}
+		l.Origins[v.field.path] = Origin{Source: SourceFlag, Name: "-" + v.field.path}
+	}
+
+	lookupEnv := func(name string) (string, bool) {
+		if v, ok := env[name]; ok {
+			return v, true
+		}
+		v, ok := dotenv[name]
+		return v, ok
+	}
+	for _, f := range fs {
+		if f.value.Kind() != reflect.String {
+			continue
+		}
+		resolved, refs, err := resolveRefs(f.value.String(), lookupEnv)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+		f.value.SetString(resolved)
+		if len(refs) > 0 {
+			origin := l.Origins[f.path]
+			origin.Refs = refs
+			l.Origins[f.path] = origin
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("配置校验失败:\n%w", err)
+	}
+	return l, nil
+}
+
+// loadYAML 读取 YAML 文件覆盖默认值，并记录文件中出现的键
+func (l *Loaded) loadYAML(cfg *Config) error {
+	data, err := os.ReadFile(l.File)
+	if errors.Is(err, os.ErrNotExist) {
+		out, err := yaml.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal default config: %w", err)
+		}
+		return WriteDefaultIfNotExists(l.File, out, 0755, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	var mark func(n *yaml.Node, prefix string)
+	mark = func(n *yaml.Node, prefix string) {
+		if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+			mark(n.Content[0], prefix)
+			return
+		}
+		if n.Kind != yaml.MappingNode {
+			if _, ok := l.Origins[prefix]; ok {
+				l.Origins[prefix] = Origin{Source: SourceYAML, Name: l.File}
+			}
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			mark(n.Content[i+1], key)
+		}
+	}
+	mark(&root, "")
+	return nil
+}
+
+// ReadEnvFile 读取 .env 文件：每行 KEY=VALUE，支持 export 前缀、# 注释与单双引号，文件不存在时返回空
+func ReadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseEnv(bytes.NewReader(data))
+}
+
+// ParseEnv 解析 .env 格式的内容
+func ParseEnv(r io.Reader) (map[string]string, error) {
+	env := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		s = strings.TrimPrefix(s, "export ")
+		key, value, ok := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf(".env 第 %d 行格式错误: %q", line, s)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf(".env 第 %d 行引号错误: %w", line, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		env[key] = value
+	}
+	return env, scanner.Err()
+}
+
+// Print 按 YAML 路径输出生效的配置与来源，敏感值只显示掩码
+func (l *Loaded) Print(w io.Writer) {
+	fs := fields(l.Config)
+	width := 0
+	for _, f := range fs {
+		width = max(width, len(f.path))
+	}
+	for _, f := range fs {
+		fmt.Fprintf(w, "%-*s = %-40s # %s\n", width, f.path, displayValue(f), l.Origins[f.path])
+	}
+}
+
+// displayValue 字段的显示值，敏感值非空时显示掩码
+func displayValue(f field) string {
+	if f.secret {
+		if f.value.IsZero() {
+			return `""`
+		}
+		return "******"
+	}
+	switch v := f.value.Interface().(type) {
+	case string:
+		return strconv.Quote(v)
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// setValue 将字符串解析为字段的类型并赋值
+func setValue(f field, s string) error {
+	return defaultvalue.SetField(f.value, s)
+}
+
+// flagValue 命令行中的一个配置覆盖，解析时只做类型检查，在最后一层应用
+type flagValue struct {
+	field field
+	raw   string
+	set   bool
+}
+
+func (v *flagValue) String() string { return v.raw }
+
+func (v *flagValue) Set(s string) error {
+	probe := reflect.New(v.field.value.Type()).Elem()
+	if err := defaultvalue.SetField(probe, s); err != nil {
+		return err
+	}
+	v.raw, v.set = s, true
+	return nil
+}
+
+// IsBoolFlag 布尔配置可写作 -database.auto_migrate，等同于 =true
+func (v *flagValue) IsBoolFlag() bool {
+	return v.field.value.Kind() == reflect.Bool
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// Password 密码策略配置，注册、修改与重置密码时生效，支持热更新
+type Password struct {
+	MinStrength      string        `yaml:"min_strength" validate:"oneof=veryweak weak moderate strong verystrong 0 1 2 3 4" reload:"hot" env:"PASSWORD_MIN_STRENGTH" env-default:"moderate" qwq-default:"moderate"` // 最低强度 veryweak/weak/moderate/strong/verystrong
+	MinLength        int           `yaml:"min_length" validate:"min=0" reload:"hot" env:"PASSWORD_MIN_LENGTH" env-default:"8" qwq-default:"8"`                                                                      // 最小长度（字符数）
+	MaxLength        int           `yaml:"max_length" validate:"min=0" reload:"hot" env:"PASSWORD_MAX_LENGTH" env-default:"72" qwq-default:"72"`                                                                    // 最大长度（字节数），bcrypt 只使用前 72 字节
+	MinClasses       int           `yaml:"min_classes" validate:"min=0,max=4" reload:"hot" env:"PASSWORD_MIN_CLASSES" env-default:"2" qwq-default:"2"`                                                              // 至少包含的字符种类数（小写、大写、数字、符号）
+	DisallowUserInfo bool          `yaml:"disallow_user_info" reload:"hot" env:"PASSWORD_DISALLOW_USER_INFO" env-default:"true" qwq-default:"true"`                                                                 // 禁止包含用户名或邮箱
+	CheckBreached    bool          `yaml:"check_breached" reload:"hot" env:"PASSWORD_CHECK_BREACHED" env-default:"true" qwq-default:"true"`                                                                         // 检查内置的常见弱密码列表
+	BreachedFile     string        `yaml:"breached_file" reload:"hot" env:"PASSWORD_BREACHED_FILE" env-default:"" qwq-default:""`                                                                                   // 额外的泄露密码列表（每行一个 SHA-1，可带 :次数），与内置列表合并
+	HistorySize      int           `yaml:"history_size" validate:"min=0,max=100" reload:"hot" env:"PASSWORD_HISTORY_SIZE" env-default:"5" qwq-default:"5"`                                                          // 禁止与最近 N 个密码相同，0 为不检查
+	MaxAge           time.Duration `yaml:"max_age" validate:"min=0" reload:"hot" env:"PASSWORD_MAX_AGE" env-default:"0s" qwq-default:"0s"`                                                                          // 密码有效期，过期后登录结果提示修改密码，0 为不过期
+
+	Algorithm     string `yaml:"algorithm" validate:"oneof=bcrypt argon2id" reload:"hot" env:"PASSWORD_ALGORITHM" env-default:"bcrypt" qwq-default:"bcrypt"` // 新密码的哈希算法 bcrypt/argon2id，旧算法的哈希在登录时重新哈希
+	BcryptCost    int    `yaml:"bcrypt_cost" validate:"min=4,max=31" reload:"hot" env:"PASSWORD_BCRYPT_COST" env-default:"12" qwq-default:"12"`              // bcrypt 工作因子（4-31）
+	Argon2Memory  uint32 `yaml:"argon2_memory" validate:"min=8" reload:"hot" env:"PASSWORD_ARGON2_MEMORY" env-default:"65536" qwq-default:"65536"`           // argon2id 内存（KiB）
+	Argon2Time    uint32 `yaml:"argon2_time" validate:"min=1" reload:"hot" env:"PASSWORD_ARGON2_TIME" env-default:"3" qwq-default:"3"`                       // argon2id 迭代次数
+	Argon2Threads uint8  `yaml:"argon2_threads" validate:"min=1" reload:"hot" env:"PASSWORD_ARGON2_THREADS" env-default:"2" qwq-default:"2"`                 // argon2id 并行度
+}
@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// Ranking 热门排序配置
+type Ranking struct {
+	LikeWeight        float64       `yaml:"like_weight" validate:"min=0" reload:"hot" env:"RANKING_LIKE_WEIGHT" env-default:"1" qwq-default:"1"`             // 点赞权重
+	CommentWeight     float64       `yaml:"comment_weight" validate:"min=0" reload:"hot" env:"RANKING_COMMENT_WEIGHT" env-default:"2" qwq-default:"2"`       // 评论权重
+	ViewWeight        float64       `yaml:"view_weight" validate:"min=0" reload:"hot" env:"RANKING_VIEW_WEIGHT" env-default:"0.05" qwq-default:"0.05"`       // 浏览权重
+	BookmarkWeight    float64       `yaml:"bookmark_weight" validate:"min=0" reload:"hot" env:"RANKING_BOOKMARK_WEIGHT" env-default:"1.5" qwq-default:"1.5"` // 收藏权重
+	Gravity           float64       `yaml:"gravity" validate:"min=0" reload:"hot" env:"RANKING_GRAVITY" env-default:"1.8" qwq-default:"1.8"`                 // 时间衰减指数
+	AgeOffset         float64       `yaml:"age_offset" validate:"min=0" reload:"hot" env:"RANKING_AGE_OFFSET" env-default:"2" qwq-default:"2"`               // 年龄偏移（小时）
+	RecomputeInterval time.Duration `yaml:"recompute_interval" validate:"min=1s" env:"RANKING_RECOMPUTE_INTERVAL" env-default:"5m" qwq-default:"5m"`         // 衰减重算间隔
+	Window            time.Duration `yaml:"window" validate:"min=1h" env:"RANKING_WINDOW" env-default:"720h" qwq-default:"720h"`                             // 参与排序的帖子时间窗口
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+// Reload 配置热更新，只有带 reload:"hot" 标签的配置项会在运行时生效，其余变化需重启
+type Reload struct {
+	Enabled  bool          `yaml:"enabled" env:"CONFIG_RELOAD_ENABLED" env-default:"true" qwq-default:"true"`                // 轮询配置文件，变化时重新加载
+	Interval time.Duration `yaml:"interval" validate:"min=0" env:"CONFIG_RELOAD_INTERVAL" env-default:"2s" qwq-default:"2s"` // 轮询间隔
+}
@@ -0,0 +1,8 @@
+package config
+
+// Render 帖子内容渲染配置
+type Render struct {
+	CacheSize  int    `yaml:"cache_size" validate:"min=0" env:"RENDER_CACHE_SIZE" env-default:"1024" qwq-default:"1024"`  // 渲染结果缓存条数，0 为不缓存
+	MentionURL string `yaml:"mention_url" env:"RENDER_MENTION_URL" env-default:"/user/{name}" qwq-default:"/user/{name}"` // @用户 链接模板
+	TagURL     string `yaml:"tag_url" env:"RENDER_TAG_URL" env-default:"/tag/{name}" qwq-default:"/tag/{name}"`           // #标签 链接模板
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 配置项的取值约束写在 validate 标签中，逗号分隔：
+//
+//	required       不能为零值（字符串非空、数字非 0）
+//	min=N / max=N  数值范围，time.Duration 的参数写作时长，如 min=1m
+//	oneof=a b c    枚举，空格分隔
+//
+// 跨字段的约束（如 max_idle_conns 不大于 max_open_conns）在 Validate 中检查。
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// checkField 按 validate 标签检查一个配置项
+func checkField(f field) []error {
+	var errs []error
+	for _, part := range strings.Split(f.validate, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if err := checkRule(f, name, param); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.path, err))
+		}
+	}
+	return errs
+}
+
+func checkRule(f field, name, param string) error {
+	v := f.value
+	switch name {
+	case "":
+		return nil
+	case "required":
+		if v.IsZero() {
+			return errors.New("不能为空")
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		options := strings.Fields(param)
+		for _, o := range options {
+			if s == o {
+				return nil
+			}
+		}
+		return fmt.Errorf("必须是 %s 之一，当前为 %q", strings.Join(options, "/"), s)
+	case "min", "max":
+		n, limit, err := compare(v, param)
+		if err != nil {
+			return fmt.Errorf("validate 标签错误: %w", err)
+		}
+		if name == "min" && n < limit {
+			return fmt.Errorf("不能小于 %s，当前为 %s", param, displayValue(f))
+		}
+		if name == "max" && n > limit {
+			return fmt.Errorf("不能大于 %s，当前为 %s", param, displayValue(f))
+		}
+	default:
+		return fmt.Errorf("validate 标签错误: 未知规则 %q", name)
+	}
+	return nil
+}
+
+// compare 字段的数值与解析后的规则参数
+func compare(v reflect.Value, param string) (float64, float64, error) {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(param)
+		return float64(v.Int()), float64(d), err
+	}
+	limit, err := strconv.ParseFloat(param, 64)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), limit, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), limit, err
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), limit, err
+	case reflect.String:
+		return float64(len([]rune(v.String()))), limit, err
+	}
+	return 0, 0, fmt.Errorf("%s 不支持 min/max", v.Type())
+}
+
+// 密钥引用：字符串配置中的 ${env:NAME} 与 ${file:PATH} 在加载时替换为环境变量（含 .env）的值
+// 或文件内容（去掉末尾换行），$${ 表示字面的 ${
+var refPattern = regexp.MustCompile(`\$?\$\{(env|file):([^}]*)\}`)
+
+// resolveRefs 替换 s 中的密钥引用，返回替换后的值与引用列表
+func resolveRefs(s string, lookupEnv func(string) (string, bool)) (string, []string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil, nil
+	}
+	var refs []string
+	var firstErr error
+	out := refPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+		sub := refPattern.FindStringSubmatch(m)
+		kind, name := sub[1], strings.TrimSpace(sub[2])
+		refs = append(refs, kind+":"+name)
+		switch kind {
+		case "env":
+			v, ok := lookupEnv(name)
+			if !ok && firstErr == nil {
+				firstErr = fmt.Errorf("引用的环境变量 %s 未设置", name)
+			}
+			return v
+		default:
+			data, err := os.ReadFile(name)
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("读取引用的文件失败: %w", err)
+			}
+			return strings.TrimRight(string(data), "\r\n")
+		}
+	})
+	return out, refs, firstErr
+}
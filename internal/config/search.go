@@ -0,0 +1,9 @@
+package config
+
+// Search 帖子搜索配置
+type Search struct {
+	Engine        string  `yaml:"engine" validate:"oneof=memory sql" env:"SEARCH_ENGINE" env-default:"memory" qwq-default:"memory"` // 搜索引擎 memory（内置倒排索引）/ sql（LIKE 查询）
+	SnippetLength int     `yaml:"snippet_length" validate:"min=1" env:"SEARCH_SNIPPET_LENGTH" env-default:"120" qwq-default:"120"`  // 高亮摘要长度（字符）
+	TitleBoost    float64 `yaml:"title_boost" validate:"min=0" env:"SEARCH_TITLE_BOOST" env-default:"3" qwq-default:"3"`            // 标题命中权重
+	RebuildBatch  int     `yaml:"rebuild_batch" validate:"min=1" env:"SEARCH_REBUILD_BATCH" env-default:"500" qwq-default:"500"`    // 启动时重建索引每批读取的帖子数
+}
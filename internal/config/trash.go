@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// Trash 回收站配置
+type Trash struct {
+	Retention     time.Duration `yaml:"retention" validate:"min=1h" env:"TRASH_RETENTION" env-default:"720h" qwq-default:"720h"`       // 回收站保留时间，超过后彻底删除
+	PurgeInterval time.Duration `yaml:"purge_interval" validate:"min=1m" env:"TRASH_PURGE_INTERVAL" env-default:"1h" qwq-default:"1h"` // 清理过期记录的间隔
+	PurgeBatch    int           `yaml:"purge_batch" validate:"min=1" env:"TRASH_PURGE_BATCH" env-default:"500" qwq-default:"500"`      // 每个事务彻底删除的记录数
+}
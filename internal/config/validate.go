@@ -0,0 +1,26 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate 按 validate 标签与跨字段约束检查配置，返回全部问题
+// 启动时未通过检查则拒绝启动，热更新时未通过检查的新配置不会生效
+func (c *Config) Validate() error {
+	var errs []error
+	for _, f := range fields(c) {
+		errs = append(errs, checkField(f)...)
+	}
+
+	if d := c.Database; d != nil && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("database.max_idle_conns: 不能大于 max_open_conns（%d > %d）", d.MaxIdleConns, d.MaxOpenConns))
+	}
+	if p := c.Password; p != nil && p.MaxLength > 0 && p.MinLength > p.MaxLength {
+		errs = append(errs, fmt.Errorf("password.min_length: 不能大于 max_length（%d > %d）", p.MinLength, p.MaxLength))
+	}
+	if r := c.Reload; r != nil && r.Enabled && r.Interval <= 0 {
+		errs = append(errs, errors.New("reload.interval: 启用热更新时必须大于 0"))
+	}
+	return errors.Join(errs...)
+}
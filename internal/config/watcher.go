@@ -0,0 +1,239 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"qwqserver/internal/base"
+)
+
+// Change 一项配置的变化，值为 --print-config 中的显示形式（敏感值为掩码）
+type Change struct {
+	Key string
+	Old string
+	New string
+	Hot bool // 是否支持热更新，不支持的变化被忽略，需重启后生效
+}
+
+// Diff 一次重新加载中的全部变化
+type Diff []Change
+
+// Changed 是否有以任一前缀开头的配置项发生变化，例如 "password." 或 "listen.log_level"
+func (d Diff) Changed(prefixes ...string) bool {
+	for _, c := range d {
+		for _, p := range prefixes {
+			if strings.HasPrefix(c.Key, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d Diff) String() string {
+	parts := make([]string, len(d))
+	for i, c := range d {
+		parts[i] = fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diff 比较两份配置，按声明顺序返回变化的配置项
+func diff(old, cur *Config) Diff {
+	var d Diff
+	of, nf := fields(old), fields(cur)
+	for i := range nf {
+		if of[i].value.Interface() == nf[i].value.Interface() {
+			continue
+		}
+		o, n := displayValue(of[i]), displayValue(nf[i])
+		if o == n {
+			n += "（已修改）"
+		}
+		d = append(d, Change{Key: nf[i].path, Old: o, New: n, Hot: nf[i].hot})
+	}
+	return d
+}
+
+// Subscriber 配置变化的订阅者，cfg 为新的全局配置，diff 只包含已生效的变化
+type Subscriber func(cfg *Config, diff Diff)
+
+type subscription struct {
+	fn       Subscriber
+	prefixes []string
+}
+
+// Watcher 轮询配置文件，内容变化时重新加载、校验并通知订阅者
+//
+// 重新加载使用与启动时相同的 Options，环境变量与命令行参数仍然覆盖文件中的值。
+// 校验失败时保留当前配置；不支持热更新的配置项（没有 reload:"hot" 标签，如 database.driver）
+// 发生变化时记录警告并保持原值，其余变化生效。
+type Watcher struct {
+	opts Options
+	file string
+	log  base.Logger
+
+	mu  sync.Mutex // 串行化重新加载
+	sum [sha256.Size]byte
+	cfg *Config // 当前生效的配置
+
+	subMu sync.RWMutex
+	subs  []subscription
+
+	watchMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewWatcher 创建配置监视器，loaded 与 opts 为启动时加载的结果与使用的选项
+func NewWatcher(loaded *Loaded, opts Options, l base.Logger) *Watcher {
+	opts.Output = io.Discard
+	w := &Watcher{opts: opts, file: loaded.File, cfg: loaded.Config, log: l}
+	w.sum, _ = w.fileSum()
+	return w
+}
+
+// Config 当前生效的配置
+func (w *Watcher) Config() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cfg
+}
+
+// Subscribe 订阅配置变化，指定前缀时只在相关配置项变化时调用
+func (w *Watcher) Subscribe(fn Subscriber, prefixes ...string) {
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	w.subs = append(w.subs, subscription{fn: fn, prefixes: prefixes})
+}
+
+func (w *Watcher) fileSum() ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(w.file)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// Reload 立即重新加载配置，返回已生效的变化
+func (w *Watcher) Reload() (Diff, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sum, err := w.fileSum(); err == nil {
+		w.sum = sum
+	}
+
+	loaded, err := Load(w.opts)
+	if err != nil {
+		return nil, fmt.Errorf("重新加载配置失败: %w", err)
+	}
+	next := loaded.Config
+	if err := next.Validate(); err != nil {
+		return nil, fmt.Errorf("新配置校验失败，保留当前配置: %w", err)
+	}
+
+	old := w.cfg
+	var applied Diff
+	of, nf := fields(old), fields(next)
+	for _, c := range diff(old, next) {
+		if c.Hot {
+			applied = append(applied, c)
+			continue
+		}
+		// 不支持热更新的配置保持原值，使全局配置与实际运行状态一致
+		for i := range nf {
+			if nf[i].path == c.Key {
+				nf[i].value.Set(of[i].value)
+			}
+		}
+		if w.log != nil {
+			w.log.Warn("配置项 %s 不支持热更新，需重启后生效（%s -> %s）", c.Key, c.Old, c.New)
+		}
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	w.cfg = next
+	// 全局配置来自本监视器时一并替换
+	current.CompareAndSwap(old, next)
+	w.subMu.RLock()
+	subs := append([]subscription(nil), w.subs...)
+	w.subMu.RUnlock()
+	for _, s := range subs {
+		if len(s.prefixes) == 0 || applied.Changed(s.prefixes...) {
+			s.fn(next, applied)
+		}
+	}
+	return applied, nil
+}
+
+// check 配置文件内容变化时重新加载
+func (w *Watcher) check() {
+	sum, err := w.fileSum()
+	if err != nil {
+		if w.log != nil {
+			w.log.Error("读取配置文件失败 Error: %v", err)
+		}
+		return
+	}
+	w.mu.Lock()
+	changed := sum != w.sum
+	w.mu.Unlock()
+	if !changed {
+		return
+	}
+	diff, err := w.Reload()
+	if w.log == nil {
+		return
+	}
+	if err != nil {
+		w.log.Error("配置热更新失败 Error: %v", err)
+	} else if len(diff) > 0 {
+		w.log.Info("配置已更新: %s", diff)
+	}
+}
+
+// Start 启动后台轮询，重复调用无效，interval 小于等于 0 时使用 2 秒
+func (w *Watcher) Start(interval time.Duration) {
+	w.watchMu.Lock()
+	defer w.watchMu.Unlock()
+	if w.stop != nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}(w.stop, w.done)
+}
+
+// Close 停止后台轮询
+func (w *Watcher) Close() {
+	w.watchMu.Lock()
+	defer w.watchMu.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		<-w.done
+		w.stop = nil
+	}
+}
@@ -0,0 +1,279 @@
+// Package counter 帖子计数（浏览/点赞/评论/收藏）的 Redis 写回缓冲
+//
+// 计数先累加在 Redis 哈希 post_counter:{id} 中，并把帖子ID记入脏集合，
+// 后台协程按固定间隔批量取出增量合并写入数据库，避免热门帖子每次浏览都执行一次 UPDATE。
+// Redis 不可用时直接写数据库。
+package counter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"qwqserver/internal/repository"
+)
+
+// errNotInitialized 计数器未创建
+var errNotInitialized = errors.New("计数器未初始化")
+
+// Redis 键名
+const (
+	keyCounterPrefix = "post_counter:"      // 帖子计数增量哈希
+	keyDirtySet      = "post_counter_dirty" // 待落库的帖子ID集合
+	keyViewDedup     = "post_view:"         // 浏览去重键前缀
+)
+
+// Config 计数写回配置
+type Config struct {
+	FlushInterval   time.Duration // 批量落库间隔
+	FlushBatchSize  int           // 每批落库的帖子数
+	ViewDedupWindow time.Duration // 同一访客浏览去重窗口
+}
+
+// Logger 日志接口
+type Logger interface {
+	Error(msg string, args ...any)
+}
+
+// Counter 帖子计数写回缓冲
+type Counter struct {
+	redis *redis.Client
+	posts repository.PostRepository
+	cfg   Config
+	log   Logger
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// New 创建计数器并启动后台落库协程，redisClient 为 nil 时计数直接写库
+func New(redisClient *redis.Client, posts repository.PostRepository, cfg Config, l Logger) *Counter {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 30 * time.Second
+	}
+	if cfg.FlushBatchSize <= 0 {
+		cfg.FlushBatchSize = 200
+	}
+	c := &Counter{
+		redis: redisClient,
+		posts: posts,
+		cfg:   cfg,
+		log:   l,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if redisClient != nil {
+		go c.run()
+	} else {
+		close(c.done)
+	}
+	return c
+}
+
+// Buffered 计数是否经由 Redis 缓冲
+func (c *Counter) Buffered() bool {
+	return c != nil && c.redis != nil
+}
+
+// Close 停止后台协程并把剩余增量全部落库
+func (c *Counter) Close() {
+	if c == nil {
+		return
+	}
+	c.once.Do(func() {
+		close(c.stop)
+		<-c.done
+	})
+}
+
+// ViewerKey 生成访客标识，已登录用户按用户ID，游客按IP
+func ViewerKey(userID uint, ip string) string {
+	if userID != 0 {
+		return "u" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "ip" + ip
+}
+
+// RecordView 记录一次浏览，去重窗口内同一访客重复浏览不计数
+// 返回本次浏览是否被计数
+func (c *Counter) RecordView(ctx context.Context, postID uint, viewer string) (bool, error) {
+	if !c.Buffered() {
+		return true, c.directApply(ctx, postID, repository.CountColumnView, 1)
+	}
+
+	if c.cfg.ViewDedupWindow > 0 {
+		key := fmt.Sprintf("%s%d:%s", keyViewDedup, postID, viewer)
+		fresh, err := c.redis.SetNX(ctx, key, 1, c.cfg.ViewDedupWindow).Result()
+		if err != nil {
+			return true, c.directApply(ctx, postID, repository.CountColumnView, 1)
+		}
+		if !fresh {
+			return false, nil
+		}
+	}
+	return true, c.Add(ctx, postID, repository.CountColumnView, 1)
+}
+
+// Add 累加计数增量
+func (c *Counter) Add(ctx context.Context, postID uint, column string, delta int64) error {
+	if !c.Buffered() {
+		return c.directApply(ctx, postID, column, delta)
+	}
+
+	pipe := c.redis.TxPipeline()
+	pipe.HIncrBy(ctx, counterKey(postID), column, delta)
+	pipe.SAdd(ctx, keyDirtySet, postID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		// Redis 异常时退回直接写库，保证计数不丢失
+		return c.directApply(ctx, postID, column, delta)
+	}
+	return nil
+}
+
+// Pending 获取尚未落库的计数增量，用于在返回帖子时合并展示
+func (c *Counter) Pending(ctx context.Context, postID uint) repository.CountDelta {
+	delta := repository.CountDelta{}
+	if !c.Buffered() {
+		return delta
+	}
+	values, err := c.redis.HGetAll(ctx, counterKey(postID)).Result()
+	if err != nil {
+		return delta
+	}
+	for column, v := range values {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			delta[column] = n
+		}
+	}
+	return delta
+}
+
+// Flush 把脏集合中的计数增量批量写入数据库，返回落库的帖子数
+func (c *Counter) Flush(ctx context.Context) (int, error) {
+	if !c.Buffered() {
+		return 0, nil
+	}
+
+	flushed := 0
+	for {
+		ids, err := c.redis.SPopN(ctx, keyDirtySet, int64(c.cfg.FlushBatchSize)).Result()
+		if err != nil {
+			return flushed, fmt.Errorf("读取待落库计数失败: %w", err)
+		}
+		if len(ids) == 0 {
+			return flushed, nil
+		}
+
+		for _, raw := range ids {
+			id, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				continue
+			}
+			postID := uint(id)
+
+			delta, err := c.take(ctx, postID)
+			if err != nil {
+				c.logError("读取帖子 %d 计数增量失败: %v", postID, err)
+				c.redis.SAdd(ctx, keyDirtySet, postID)
+				continue
+			}
+			if len(delta) == 0 {
+				continue
+			}
+
+			if err := c.posts.ApplyCountDelta(ctx, postID, delta); err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					// 帖子已删除，丢弃增量
+					continue
+				}
+				c.logError("帖子 %d 计数落库失败: %v", postID, err)
+				c.restore(ctx, postID, delta)
+				continue
+			}
+			flushed++
+		}
+
+		if len(ids) < c.cfg.FlushBatchSize {
+			return flushed, nil
+		}
+	}
+}
+
+// take 原子地取出并清空帖子的计数增量
+func (c *Counter) take(ctx context.Context, postID uint) (repository.CountDelta, error) {
+	key := counterKey(postID)
+	pipe := c.redis.TxPipeline()
+	getCmd := pipe.HGetAll(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	delta := repository.CountDelta{}
+	for column, v := range getCmd.Val() {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n == 0 {
+			continue
+		}
+		delta[column] = n
+	}
+	return delta, nil
+}
+
+// restore 落库失败时把增量放回 Redis，等待下次重试
+func (c *Counter) restore(ctx context.Context, postID uint, delta repository.CountDelta) {
+	pipe := c.redis.TxPipeline()
+	for column, n := range delta {
+		pipe.HIncrBy(ctx, counterKey(postID), column, n)
+	}
+	pipe.SAdd(ctx, keyDirtySet, postID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logError("帖子 %d 计数增量回写 Redis 失败，增量丢失: %v (%v)", postID, err, delta)
+	}
+}
+
+// run 后台定时落库
+func (c *Counter) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := c.Flush(context.Background()); err != nil {
+				c.logError("计数落库失败: %v", err)
+			}
+		case <-c.stop:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if _, err := c.Flush(ctx); err != nil {
+				c.logError("退出前计数落库失败: %v", err)
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+func (c *Counter) logError(msg string, args ...any) {
+	if c.log != nil {
+		c.log.Error(msg, args...)
+	}
+}
+
+func counterKey(postID uint) string {
+	return keyCounterPrefix + strconv.FormatUint(uint64(postID), 10)
+}
+
+// directApply 直接写数据库
+func (c *Counter) directApply(ctx context.Context, postID uint, column string, delta int64) error {
+	if c == nil {
+		return errNotInitialized
+	}
+	return c.posts.ApplyCountDelta(ctx, postID, repository.CountDelta{column: delta})
+}
@@ -0,0 +1,240 @@
+// Package editing 帖子协同编辑辅助：自动保存草稿与编辑状态
+//
+// Redis 中维护：
+//   - post_draft:{postID}:{userID}  用户对帖子的未提交草稿（JSON），带过期时间；postID 为 0 表示新帖
+//   - post_editors:{postID}         正在编辑帖子的用户（有序集合，分数为心跳过期时间）
+//   - post_edit_lock:{postID}       编辑锁持有者，第一个进入编辑的用户持有，心跳续期
+//
+// 编辑锁为提示性质，不阻止其他人保存，真正的冲突由帖子的乐观锁版本号检测。
+// Redis 不可用时草稿与编辑状态功能不可用，返回 ErrUnavailable。
+package editing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 键名前缀
+const (
+	keyDraftPrefix   = "post_draft:"
+	keyEditorsPrefix = "post_editors:"
+	keyLockPrefix    = "post_edit_lock:"
+)
+
+var (
+	// ErrUnavailable Redis 未配置，功能不可用
+	ErrUnavailable = errors.New("编辑服务不可用")
+
+	// ErrDraftTooLarge 草稿超过大小限制
+	ErrDraftTooLarge = errors.New("草稿内容过大")
+
+	// ErrNoPost 编辑状态与编辑锁只针对已存在的帖子，新帖（帖子ID为 0）不共享编辑状态
+	ErrNoPost = errors.New("新帖没有编辑状态")
+)
+
+// Config 编辑辅助配置
+type Config struct {
+	DraftTTL     time.Duration // 草稿保留时间
+	MaxDraftSize int           // 草稿最大字节数
+	PresenceTTL  time.Duration // 编辑状态心跳超时
+}
+
+// Draft 自动保存的草稿
+type Draft struct {
+	PostID      uint      `json:"post_id"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	Mod         string    `json:"mod"`
+	BaseVersion int64     `json:"base_version"` // 开始编辑时帖子的版本号，用于提示草稿是否基于旧版本
+	SavedAt     time.Time `json:"saved_at"`
+}
+
+// Presence 帖子的编辑状态
+type Presence struct {
+	LockHolder uint   `json:"lock_holder"` // 编辑锁持有者，0 表示无人持有
+	Editors    []uint `json:"editors"`     // 正在编辑的用户（含锁持有者）
+}
+
+// Editing 编辑辅助
+type Editing struct {
+	redis *redis.Client
+	cfg   Config
+}
+
+// New 创建编辑辅助，redisClient 为 nil 时功能不可用
+func New(redisClient *redis.Client, cfg Config) *Editing {
+	if cfg.DraftTTL <= 0 {
+		cfg.DraftTTL = 72 * time.Hour
+	}
+	if cfg.MaxDraftSize <= 0 {
+		cfg.MaxDraftSize = 1 << 20
+	}
+	if cfg.PresenceTTL <= 0 {
+		cfg.PresenceTTL = 30 * time.Second
+	}
+	return &Editing{redis: redisClient, cfg: cfg}
+}
+
+// Available 功能是否可用
+func (e *Editing) Available() bool {
+	return e != nil && e.redis != nil
+}
+
+// PresenceTTL 心跳超时，客户端应以小于该值的间隔发送心跳
+func (e *Editing) PresenceTTL() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.cfg.PresenceTTL
+}
+
+func draftKey(postID, userID uint) string {
+	return fmt.Sprintf("%s%d:%d", keyDraftPrefix, postID, userID)
+}
+
+func editorsKey(postID uint) string {
+	return keyEditorsPrefix + strconv.FormatUint(uint64(postID), 10)
+}
+
+func lockKey(postID uint) string {
+	return keyLockPrefix + strconv.FormatUint(uint64(postID), 10)
+}
+
+// SaveDraft 保存草稿并刷新过期时间
+func (e *Editing) SaveDraft(ctx context.Context, userID uint, draft *Draft) error {
+	if !e.Available() {
+		return ErrUnavailable
+	}
+	if len(draft.Title)+len(draft.Content) > e.cfg.MaxDraftSize {
+		return ErrDraftTooLarge
+	}
+	draft.SavedAt = time.Now()
+	data, err := json.Marshal(draft)
+	if err != nil {
+		return err
+	}
+	if err := e.redis.Set(ctx, draftKey(draft.PostID, userID), data, e.cfg.DraftTTL).Err(); err != nil {
+		return fmt.Errorf("保存草稿失败: %w", err)
+	}
+	return nil
+}
+
+// GetDraft 获取草稿，不存在时返回 nil
+func (e *Editing) GetDraft(ctx context.Context, userID, postID uint) (*Draft, error) {
+	if !e.Available() {
+		return nil, ErrUnavailable
+	}
+	data, err := e.redis.Get(ctx, draftKey(postID, userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取草稿失败: %w", err)
+	}
+	var draft Draft
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return nil, fmt.Errorf("解析草稿失败: %w", err)
+	}
+	return &draft, nil
+}
+
+// DeleteDraft 删除草稿，帖子保存成功后调用
+func (e *Editing) DeleteDraft(ctx context.Context, userID, postID uint) error {
+	if !e.Available() {
+		return ErrUnavailable
+	}
+	return e.redis.Del(ctx, draftKey(postID, userID)).Err()
+}
+
+// renewLock 续期或获取编辑锁：锁空闲或由自己持有时设置为自己，返回当前持有者
+var renewLock = redis.NewScript(`
+local holder = redis.call("GET", KEYS[1])
+if not holder or holder == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return ARGV[1]
+end
+return holder
+`)
+
+// releaseLock 仅在自己持有时释放编辑锁
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Heartbeat 标记用户正在编辑帖子，并尝试获取或续期编辑锁，返回最新的编辑状态
+func (e *Editing) Heartbeat(ctx context.Context, postID, userID uint) (*Presence, error) {
+	if postID == 0 {
+		return nil, ErrNoPost
+	}
+	if !e.Available() {
+		return nil, ErrUnavailable
+	}
+	now := time.Now()
+	member := strconv.FormatUint(uint64(userID), 10)
+
+	pipe := e.redis.TxPipeline()
+	pipe.ZAdd(ctx, editorsKey(postID), redis.Z{Score: float64(now.Add(e.cfg.PresenceTTL).UnixMilli()), Member: member})
+	pipe.PExpire(ctx, editorsKey(postID), e.cfg.PresenceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("更新编辑状态失败: %w", err)
+	}
+	if err := renewLock.Run(ctx, e.redis, []string{lockKey(postID)}, member, e.cfg.PresenceTTL.Milliseconds()).Err(); err != nil {
+		return nil, fmt.Errorf("获取编辑锁失败: %w", err)
+	}
+	return e.Presence(ctx, postID)
+}
+
+// Leave 用户退出编辑，释放自己持有的编辑锁
+func (e *Editing) Leave(ctx context.Context, postID, userID uint) error {
+	if postID == 0 {
+		return ErrNoPost
+	}
+	if !e.Available() {
+		return ErrUnavailable
+	}
+	member := strconv.FormatUint(uint64(userID), 10)
+	if err := e.redis.ZRem(ctx, editorsKey(postID), member).Err(); err != nil {
+		return fmt.Errorf("更新编辑状态失败: %w", err)
+	}
+	return releaseLock.Run(ctx, e.redis, []string{lockKey(postID)}, member).Err()
+}
+
+// Presence 获取帖子的编辑状态
+func (e *Editing) Presence(ctx context.Context, postID uint) (*Presence, error) {
+	if postID == 0 {
+		return nil, ErrNoPost
+	}
+	if !e.Available() {
+		return nil, ErrUnavailable
+	}
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	pipe := e.redis.Pipeline()
+	// 清理心跳超时的用户
+	pipe.ZRemRangeByScore(ctx, editorsKey(postID), "-inf", "("+now)
+	editorsCmd := pipe.ZRange(ctx, editorsKey(postID), 0, -1)
+	lockCmd := pipe.Get(ctx, lockKey(postID))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("读取编辑状态失败: %w", err)
+	}
+
+	p := &Presence{Editors: []uint{}}
+	for _, m := range editorsCmd.Val() {
+		if id, err := strconv.ParseUint(m, 10, 64); err == nil {
+			p.Editors = append(p.Editors, uint(id))
+		}
+	}
+	if holder, err := strconv.ParseUint(lockCmd.Val(), 10, 64); err == nil {
+		p.LockHolder = uint(holder)
+	}
+	return p, nil
+}
@@ -0,0 +1,51 @@
+package errcode
+
+import "net/http"
+
+// 错误码与 HTTP 状态码，消息见 internal/locale/messages
+func init() {
+	// 通用错误
+	Register(BadRequest, http.StatusBadRequest)
+	Register(ValidationFailed, http.StatusBadRequest)
+	Register(InvalidParam, http.StatusBadRequest)
+	Register(MissingParam, http.StatusBadRequest)
+	Register(InvalidEnum, http.StatusBadRequest)
+	Register(Unauthorized, http.StatusUnauthorized)
+	Register(Forbidden, http.StatusForbidden)
+	Register(NotFound, http.StatusNotFound)
+	Register(MethodNotAllowed, http.StatusMethodNotAllowed)
+	Register(Conflict, http.StatusConflict)
+	Register(PayloadTooLarge, http.StatusRequestEntityTooLarge)
+	Register(TooManyRequests, http.StatusServiceUnavailable)
+	Register(Internal, http.StatusInternalServerError)
+	Register(Unavailable, http.StatusServiceUnavailable)
+
+	// 认证
+	Register(AuthTokenMissing, http.StatusUnauthorized)
+	Register(AuthTokenMalformed, http.StatusUnauthorized)
+	Register(AuthTokenInvalid, http.StatusUnauthorized)
+	Register(AuthTokenExpired, http.StatusUnauthorized)
+	Register(AuthLoginRequired, http.StatusUnauthorized)
+	Register(AuthAlreadyLoggedIn, http.StatusConflict)
+	Register(AuthInvalidCredentials, http.StatusUnauthorized)
+	Register(AuthPermissionDenied, http.StatusForbidden)
+
+	// 用户
+	Register(UserNotFound, http.StatusNotFound)
+	Register(UserUsernameTaken, http.StatusConflict)
+	Register(UserEmailTaken, http.StatusConflict)
+	Register(PasswordPolicy, http.StatusBadRequest)
+	Register(UserDisabled, http.StatusForbidden)
+
+	// 帖子与编辑
+	Register(PostNotFound, http.StatusNotFound)
+	Register(PostVersionConflict, http.StatusConflict)
+	Register(PostFormatUnsupported, http.StatusBadRequest)
+	Register(PostNotFeatured, http.StatusNotFound)
+	Register(FeatureExpiryInPast, http.StatusBadRequest)
+	Register(CollectionNotFound, http.StatusNotFound)
+	Register(RevisionNotFound, http.StatusNotFound)
+	Register(DraftNotFound, http.StatusNotFound)
+	Register(DraftTooLarge, http.StatusRequestEntityTooLarge)
+	Register(EditingUnavailable, http.StatusServiceUnavailable)
+}
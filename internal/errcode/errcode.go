@@ -0,0 +1,239 @@
+// Package errcode 错误码目录
+//
+// 每个错误码是稳定的、供客户端判断的机器可读字符串（如 AUTH_TOKEN_EXPIRED），
+// 在目录中登记对应的 HTTP 状态码；各语言的提示消息在 internal/locale 的消息目录中，键为 error.<错误码>。
+// 服务与处理函数返回 *Error，由统一响应渲染为 {code, error, msg, data, request_id}，
+// Error.Err 中的内部原因（如数据库错误）只记录日志，不返回给客户端。
+package errcode
+
+import (
+	"errors"
+	"net/http"
+	"qwqserver/internal/locale"
+	"sort"
+	"sync"
+)
+
+// Code 错误码
+type Code string
+
+// 通用错误
+const (
+	BadRequest       Code = "BAD_REQUEST"
+	ValidationFailed Code = "VALIDATION_FAILED"
+	InvalidParam     Code = "INVALID_PARAM" // 参数 field 错误
+	MissingParam     Code = "MISSING_PARAM" // 缺少参数 field
+	InvalidEnum      Code = "INVALID_ENUM"  // 参数 field 只能是 values 之一
+	Unauthorized     Code = "UNAUTHORIZED"
+	Forbidden        Code = "FORBIDDEN"
+	NotFound         Code = "NOT_FOUND"
+	MethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	Conflict         Code = "CONFLICT"
+	PayloadTooLarge  Code = "PAYLOAD_TOO_LARGE"
+	TooManyRequests  Code = "TOO_MANY_REQUESTS"
+	Internal         Code = "INTERNAL_ERROR"
+	Unavailable      Code = "SERVICE_UNAVAILABLE"
+)
+
+// 认证
+const (
+	AuthTokenMissing       Code = "AUTH_TOKEN_MISSING"
+	AuthTokenMalformed     Code = "AUTH_TOKEN_MALFORMED"
+	AuthTokenInvalid       Code = "AUTH_TOKEN_INVALID"
+	AuthTokenExpired       Code = "AUTH_TOKEN_EXPIRED"
+	AuthLoginRequired      Code = "AUTH_LOGIN_REQUIRED"
+	AuthAlreadyLoggedIn    Code = "AUTH_ALREADY_LOGGED_IN"
+	AuthInvalidCredentials Code = "AUTH_INVALID_CREDENTIALS"
+	AuthPermissionDenied   Code = "AUTH_PERMISSION_DENIED"
+)
+
+// 用户
+const (
+	UserNotFound      Code = "USER_NOT_FOUND"
+	UserUsernameTaken Code = "USER_USERNAME_TAKEN"
+	UserEmailTaken    Code = "USER_EMAIL_TAKEN"
+	UserDisabled      Code = "USER_DISABLED"
+	PasswordPolicy    Code = "PASSWORD_POLICY_VIOLATION" // 附加信息为未满足的策略项
+)
+
+// 帖子与编辑
+const (
+	PostNotFound          Code = "POST_NOT_FOUND"
+	PostVersionConflict   Code = "POST_VERSION_CONFLICT"
+	PostFormatUnsupported Code = "POST_FORMAT_UNSUPPORTED" // 不支持的内容格式 format
+	PostNotFeatured       Code = "POST_NOT_FEATURED"
+	FeatureExpiryInPast   Code = "FEATURE_EXPIRY_IN_PAST"
+	CollectionNotFound    Code = "COLLECTION_NOT_FOUND"
+	RevisionNotFound      Code = "REVISION_NOT_FOUND"
+	DraftNotFound         Code = "DRAFT_NOT_FOUND"
+	DraftTooLarge         Code = "DRAFT_TOO_LARGE"
+	EditingUnavailable    Code = "EDITING_UNAVAILABLE"
+)
+
+// Entry 错误码目录项
+type Entry struct {
+	Code   Code `json:"code"`
+	Status int  `json:"status"`
+}
+
+var (
+	catalog   = map[Code]Entry{}
+	catalogMu sync.RWMutex
+)
+
+// Register 登记错误码，重复登记时覆盖
+func Register(code Code, status int) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	catalog[code] = Entry{Code: code, Status: status}
+}
+
+// Lookup 查询错误码，未登记时返回 false
+func Lookup(code Code) (Entry, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	e, ok := catalog[code]
+	return e, ok
+}
+
+// Catalog 全部错误码，按错误码排序
+func Catalog() []Entry {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	entries := make([]Entry, 0, len(catalog))
+	for _, e := range catalog {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// Status 错误码对应的 HTTP 状态码，未登记的错误码按内部错误处理
+func (c Code) Status() int {
+	if e, ok := Lookup(c); ok {
+		return e.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// MessageKey 错误码消息在消息目录中的键
+func (c Code) MessageKey() string {
+	return "error." + string(c)
+}
+
+// Message 错误码在指定语言下的消息，消息中的 {name} 由 args 替换；没有消息时返回错误码本身
+func (c Code) Message(lang string, args map[string]any) string {
+	if _, ok := locale.Bundle().Lookup(lang, c.MessageKey()); !ok {
+		return string(c)
+	}
+	return locale.T(lang, c.MessageKey(), args)
+}
+
+// ForStatus HTTP 状态码对应的通用错误码，用于尚未使用错误码的响应
+func ForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return BadRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusRequestEntityTooLarge:
+		return PayloadTooLarge
+	case http.StatusTooManyRequests:
+		return TooManyRequests
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	}
+	if status >= 500 {
+		return Internal
+	}
+	return BadRequest
+}
+
+// Error 带错误码的错误
+type Error struct {
+	Code    Code
+	Args    map[string]any // 消息参数
+	Details any            // 附加信息（如字段校验错误），作为响应的 data 返回
+	Err     error          // 内部原因，只记录日志，不返回给客户端
+}
+
+// New 创建错误
+func New(code Code) *Error {
+	return &Error{Code: code}
+}
+
+// Wrap 创建带内部原因的错误
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+// With 设置消息参数
+func (e *Error) With(key string, value any) *Error {
+	if e.Args == nil {
+		e.Args = map[string]any{}
+	}
+	e.Args[key] = value
+	return e
+}
+
+// WithDetails 设置附加信息
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+	return e
+}
+
+// Status HTTP 状态码
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
+
+// Message 指定语言下的消息
+func (e *Error) Message(lang string) string {
+	return e.Code.Message(lang, e.Args)
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code) + ": " + e.Message(locale.DefaultLang())
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 错误码相同即视为同一错误，支持 errors.Is(err, errcode.New(errcode.UserNotFound))
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// From 把任意错误转换为 *Error，非 *Error 的错误视为内部错误
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Wrap(Internal, err)
+}
+
+// CodeOf 错误的错误码，非 *Error 的错误返回 Internal，nil 返回空
+func CodeOf(err error) Code {
+	if e := From(err); e != nil {
+		return e.Code
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package errcode
+
+import (
+	"qwqserver/internal/locale"
+	"qwqserver/pkg/util/passsec"
+	"qwqserver/pkg/validate"
+)
+
+// Localizer 按请求语言生成的附加信息，统一响应输出前调用 Localize
+type Localizer interface {
+	Localize(lang string) any
+}
+
+// FieldError 字段校验错误，Message 为按请求语言生成的提示
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldErrors 全部字段的校验错误，作为 VALIDATION_FAILED 的附加信息返回
+type FieldErrors struct {
+	Fields []FieldError `json:"fields"`
+
+	kinds []string
+}
+
+// Localize 生成各字段的提示消息：依次查找 validation.<规则>.<字段类别>、validation.<规则>、validation.invalid
+func (f FieldErrors) Localize(lang string) any {
+	out := FieldErrors{Fields: make([]FieldError, len(f.Fields))}
+	for i, fe := range f.Fields {
+		args := map[string]any{"field": fe.Field, "param": fe.Param}
+		key := "validation.invalid"
+		for _, k := range []string{"validation." + fe.Rule + "." + f.kinds[i], "validation." + fe.Rule} {
+			if _, ok := locale.Bundle().Lookup(lang, k); ok {
+				key = k
+				break
+			}
+		}
+		fe.Message = locale.T(lang, key, args)
+		out.Fields[i] = fe
+	}
+	return out
+}
+
+// Invalid 参数校验错误：VALIDATION_FAILED，附加信息为各字段的错误
+func Invalid(errs validate.Errors) *Error {
+	return InvalidAs(ValidationFailed, errs)
+}
+
+// InvalidAs 以指定错误码返回字段错误，如密码不符合策略
+func InvalidAs(code Code, errs validate.Errors) *Error {
+	details := FieldErrors{Fields: make([]FieldError, len(errs)), kinds: make([]string, len(errs))}
+	for i, fe := range errs {
+		details.Fields[i] = FieldError{Field: fe.Field, Rule: fe.Rule, Param: fe.Param}
+		details.kinds[i] = fe.Kind
+	}
+	return New(code).With("count", len(errs)).WithDetails(details)
+}
+
+// PasswordViolations 密码不符合策略：PASSWORD_POLICY_VIOLATION，附加信息为字段 field 未满足的策略项，
+// 规则名为 password_<策略项>（见 passsec.Rule*）
+func PasswordViolations(field string, violations passsec.Violations) *Error {
+	errs := make(validate.Errors, len(violations))
+	for i, v := range violations {
+		errs[i] = validate.FieldError{Field: field, Rule: "password_" + v.Rule, Param: v.Param, Kind: "string"}
+	}
+	return InvalidAs(PasswordPolicy, errs)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/middleware"
+	"qwqserver/pkg/docsite"
+	"qwqserver/pkg/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// docsSearchLimit 文档搜索返回的最大结果数
+	docsSearchLimit = 50
+
+	// docsLiveReloadPath 文档实时刷新（SSE）地址
+	docsLiveReloadPath = "/api/v1/docs/livereload"
+
+	// docsLiveReloadPing 实时刷新连接的心跳间隔，防止代理断开空闲连接
+	docsLiveReloadPing = 30 * time.Second
+)
+
+// DocsHandler 文档站点处理
+type DocsHandler struct {
+	HandleBaseImpl
+	site       *docsite.Site
+	liveReload bool
+}
+
+// NewDocs 创建文档站点处理，liveReload 为 true 时页面会连接实时刷新并在文档变化后自动刷新
+func NewDocs(site *docsite.Site, liveReload bool) *DocsHandler {
+	return &DocsHandler{site: site, liveReload: liveReload}
+}
+
+// view 页面公共的模板数据
+func (handle *DocsHandler) view(data gin.H) gin.H {
+	data["HomeURL"] = handle.site.HomeURL()
+	data["SearchURL"] = handle.site.BaseURL()
+	if handle.liveReload {
+		data["LiveReloadURL"] = docsLiveReloadPath + "?v=" + strconv.FormatUint(handle.site.Version(), 10)
+	}
+	return data
+}
+
+// notModified 设置缓存校验头（ETag、Last-Modified），客户端缓存仍然有效时返回 304
+// 页面包含整个站点的目录，因此使用站点级的校验值，任一文档变化都会使所有页面失效
+func (handle *DocsHandler) notModified(c *gin.Context) bool {
+	etag := handle.site.ETag()
+	modTime := handle.site.LastModified()
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if !modTime.IsZero() {
+		c.Header("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				c.Status(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !modTime.IsZero() {
+		if !modTime.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// docsResult 搜索结果页中的一项
+type docsResult struct {
+	URL     string
+	Title   template.HTML
+	Snippet template.HTML
+	ModTime time.Time
+}
+
+// Page 渲染文档页面，q 参数不为空时渲染搜索结果
+// 路径不是文档时按文档目录中的静态资源（如图片）处理
+func (handle *DocsHandler) Page(c *gin.Context) {
+	handle.site.Refresh()
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		handle.searchPage(c, q)
+		return
+	}
+
+	p := c.Param("path")
+	page := handle.site.Page(p)
+	if page == nil {
+		handle.asset(c, p)
+		return
+	}
+	if handle.notModified(c) {
+		return
+	}
+
+	c.HTML(http.StatusOK, "markdown.html", handle.view(gin.H{
+		"Title":   page.Title,
+		"Content": template.HTML(page.HTML),
+		"Page":    page,
+		"Nav":     handle.site.Nav(page.Path),
+	}))
+}
+
+// searchPage 渲染搜索结果页
+func (handle *DocsHandler) searchPage(c *gin.Context, q string) {
+	if handle.notModified(c) {
+		return
+	}
+	hits := handle.site.Search(q, docsSearchLimit)
+	results := make([]docsResult, 0, len(hits))
+	for _, h := range hits {
+		results = append(results, docsResult{
+			URL:     h.Page.URL,
+			Title:   template.HTML(h.Title),
+			Snippet: template.HTML(h.Snippet),
+			ModTime: h.Page.ModTime,
+		})
+	}
+
+	c.HTML(http.StatusOK, "list.html", handle.view(gin.H{
+		"Title":   "搜索：" + q,
+		"Query":   q,
+		"Results": results,
+		"Nav":     handle.site.Nav(""),
+	}))
+}
+
+// asset 输出文档目录中的静态资源，兼容旧的 /docs/img/ 前缀
+func (handle *DocsHandler) asset(c *gin.Context, p string) {
+	rel := strings.TrimPrefix(path.Clean("/"+p), "/")
+	for _, candidate := range []string{rel, strings.TrimPrefix(rel, "img/")} {
+		if candidate == "" || strings.EqualFold(path.Ext(candidate), ".md") {
+			continue
+		}
+		file := filepath.Join(handle.site.Root(), filepath.FromSlash(candidate))
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			c.File(file)
+			return
+		}
+	}
+	middleware.Abort(c, errcode.New(errcode.NotFound))
+}
+
+// Search 搜索文档（JSON），供搜索框实时提示使用
+func (handle *DocsHandler) Search(c *gin.Context) *common.HTTPResult {
+	handle.site.Refresh()
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return missingParam("q")
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > docsSearchLimit {
+		limit = docsSearchLimit
+	}
+
+	results := handle.site.Search(q, limit)
+	if results == nil {
+		results = []docsite.SearchResult{}
+	}
+	return common.Success("docs.found", gin.H{"list": results, "total": len(results)}).
+		WithArgs(i18n.Args{i18n.CountArg: len(results)})
+}
+
+// LiveReload 文档实时刷新（SSE）：文档变化时向已打开的页面推送 reload 事件
+// 页面在连接时带上渲染时的站点版本 v，连接前已发生的变化会立即推送
+func (handle *DocsHandler) LiveReload(c *gin.Context) {
+	updates, cancel := handle.site.Subscribe()
+	defer cancel()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	if v, err := strconv.ParseUint(c.Query("v"), 10, 64); err == nil && v != handle.site.Version() {
+		c.SSEvent("reload", handle.site.Version())
+		c.Writer.Flush()
+		return
+	}
+
+	ping := time.NewTicker(docsLiveReloadPing)
+	defer ping.Stop()
+	c.SSEvent("ready", handle.site.Version())
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case v, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("reload", v)
+			return true
+		case <-ping.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
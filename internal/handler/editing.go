@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/service"
+)
+
+// EditingHandler 自动保存草稿与编辑状态处理
+type EditingHandler struct {
+	HandleBaseImpl
+}
+
+// NewEditing 创建自动保存草稿与编辑状态处理
+func NewEditing(d *service.Deps) *EditingHandler {
+	return &EditingHandler{HandleBaseImpl: HandleBaseImpl{Deps: d}}
+}
+
+// SaveDraft 保存草稿
+func (handle *EditingHandler) SaveDraft(c *gin.Context) *common.HTTPResult {
+	serv := &service.DraftService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Save(handle.Deps, currentUserID(c))
+}
+
+// GetDraft 获取草稿，post_id 为 0 或缺省时获取新帖草稿
+func (handle *EditingHandler) GetDraft(c *gin.Context) *common.HTTPResult {
+	serv := &service.DraftService{PostID: queryUint(c, "post_id")}
+	return serv.Get(handle.Deps, currentUserID(c))
+}
+
+// DeleteDraft 删除草稿
+func (handle *EditingHandler) DeleteDraft(c *gin.Context) *common.HTTPResult {
+	serv := &service.DraftService{PostID: queryUint(c, "post_id")}
+	return serv.Delete(handle.Deps, currentUserID(c))
+}
+
+// bind 绑定帖子ID参数（编辑状态只针对已存在的帖子）
+func (handle *EditingHandler) bind(c *gin.Context) (*service.EditingService, *common.HTTPResult) {
+	serv := &service.EditingService{PostID: queryUint(c, "post_id")}
+	if serv.PostID == 0 && c.Request.Method == "POST" {
+		_ = c.ShouldBindJSON(serv)
+	}
+	if serv.PostID == 0 {
+		return nil, invalidParam("post_id")
+	}
+	return serv, nil
+}
+
+// Heartbeat 编辑心跳
+func (handle *EditingHandler) Heartbeat(c *gin.Context) *common.HTTPResult {
+	serv, res := handle.bind(c)
+	if res != nil {
+		return res
+	}
+	return serv.Heartbeat(handle.Deps, currentUserID(c))
+}
+
+// Status 查看编辑状态
+func (handle *EditingHandler) Status(c *gin.Context) *common.HTTPResult {
+	serv, res := handle.bind(c)
+	if res != nil {
+		return res
+	}
+	return serv.Status(handle.Deps, currentUserID(c))
+}
+
+// Leave 退出编辑
+func (handle *EditingHandler) Leave(c *gin.Context) *common.HTTPResult {
+	serv, res := handle.bind(c)
+	if res != nil {
+		return res
+	}
+	return serv.Leave(handle.Deps, currentUserID(c))
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/service"
+	"strconv"
+)
+
+// FeatureHandler 精华内容与专题处理
+type FeatureHandler struct {
+	HandleBaseImpl
+}
+
+// NewFeature 创建精华内容处理
+func NewFeature(d *service.Deps) *FeatureHandler {
+	return &FeatureHandler{HandleBaseImpl: HandleBaseImpl{Deps: d}}
+}
+
+// Feature 设置精华
+func (handle *FeatureHandler) Feature(c *gin.Context) *common.HTTPResult {
+	serv := &service.FeatureService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Feature(handle.Deps, currentUserID(c))
+}
+
+// Unfeature 取消精华
+func (handle *FeatureHandler) Unfeature(c *gin.Context) *common.HTTPResult {
+	serv := &service.FeatureService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Unfeature(handle.Deps, currentUserID(c))
+}
+
+// Featured 公开的精华帖子列表
+func (handle *FeatureHandler) Featured(c *gin.Context) *common.HTTPResult {
+	page, pageSize := pageParams(c)
+	return service.FeaturedList(handle.Deps, page, pageSize)
+}
+
+// Recommended 当前用户的推荐帖子
+func (handle *FeatureHandler) Recommended(c *gin.Context) *common.HTTPResult {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+	return service.RecommendedList(handle.Deps, currentUserID(c), limit)
+}
+
+// CreateCollection 创建专题
+func (handle *FeatureHandler) CreateCollection(c *gin.Context) *common.HTTPResult {
+	serv := &service.CollectionService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Create(handle.Deps, currentUserID(c))
+}
+
+// UpdateCollection 更新专题
+func (handle *FeatureHandler) UpdateCollection(c *gin.Context) *common.HTTPResult {
+	serv := &service.CollectionService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Update(handle.Deps, currentUserID(c))
+}
+
+// SetCollectionPosts 重置专题中的帖子及顺序
+func (handle *FeatureHandler) SetCollectionPosts(c *gin.Context) *common.HTTPResult {
+	serv := &service.CollectionService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	if serv.ID == 0 {
+		return missingParam("id")
+	}
+	return serv.SetPosts(handle.Deps, currentUserID(c))
+}
+
+// DeleteCollection 删除专题
+func (handle *FeatureHandler) DeleteCollection(c *gin.Context) *common.HTTPResult {
+	serv := &service.CollectionService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	if serv.ID == 0 {
+		return missingParam("id")
+	}
+	return serv.Delete(handle.Deps, currentUserID(c))
+}
+
+// CollectionDetail 专题详情
+func (handle *FeatureHandler) CollectionDetail(c *gin.Context) *common.HTTPResult {
+	id := queryUint(c, "id")
+	if id == 0 {
+		return invalidParam("id")
+	}
+	return service.CollectionDetail(handle.Deps, id)
+}
+
+// CollectionList 专题列表
+func (handle *FeatureHandler) CollectionList(c *gin.Context) *common.HTTPResult {
+	page, pageSize := pageParams(c)
+	return service.CollectionList(handle.Deps, page, pageSize)
+}
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"qwqserver/internal/auth"
 	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/service"
+	"strconv"
 )
 
 type HandleBase interface {
@@ -14,12 +17,12 @@ type HandleBase interface {
 type HandleBaseImpl struct {
 	AuthCode  auth.CodeType
 	AuthAbort bool
+	Deps      *service.Deps // 服务层依赖
 }
 
 // AuthMiddleware 认证中间件 true abort false continue
 func (h *HandleBaseImpl) AuthMiddleware(c *gin.Context, authAbort ...bool) (bool, *common.HTTPResult) {
-	res := &common.HTTPResult{}
-	res.Msg = "认证成功"
+	res := common.Success("auth.authenticated", nil)
 	isValid := true
 	if len(authAbort) >= 1 {
 		isValid = authAbort[0]
@@ -28,29 +31,17 @@ func (h *HandleBaseImpl) AuthMiddleware(c *gin.Context, authAbort ...bool) (bool
 	//
 
 	authCode, ok := c.Get(auth.IdentityStatusKey)
-	if !ok {
-		res.Code = http.StatusUnauthorized
-		res.Msg = "认证中间件错误"
-	} else if authCode.(auth.CodeType) != auth.IdentityOK {
-		res.Code = http.StatusUnauthorized
-		res.Msg = "认证失败"
+	if !ok || authCode.(auth.CodeType) != auth.IdentityOK {
+		res = common.Fail(errcode.New(errcode.Unauthorized))
 	}
 	if authCode == auth.IdentityErrNoToken {
-		//msg = "未提供认证令牌"
-		res.Code = http.StatusUnauthorized
-		res.Msg = "未提供认证令牌"
+		res = common.Fail(errcode.New(errcode.AuthTokenMissing))
 	} else if authCode == auth.IdentityErrTokenFormat {
-		//msg = "令牌格式错误"
-		res.Code = http.StatusUnauthorized
-		res.Msg = "令牌格式错误"
+		res = common.Fail(errcode.New(errcode.AuthTokenMalformed))
 	} else if authCode == auth.IdentityErrInvalidToken {
-		//msg = "无效令牌"
-		res.Code = http.StatusUnauthorized
-		res.Msg = "无效令牌"
+		res = common.Fail(errcode.New(errcode.AuthTokenInvalid))
 	} else if authCode == auth.IdentityErrTokenExpired {
-		//msg = "令牌已过期"
-		res.Code = http.StatusUnauthorized
-		res.Msg = "令牌已过期"
+		res = common.Fail(errcode.New(errcode.AuthTokenExpired))
 	}
 	// 此条件用于特殊需求，比如注册、登录...如果允许放行，并且未存在令牌，则放行
 	if !isValid {
@@ -58,9 +49,7 @@ func (h *HandleBaseImpl) AuthMiddleware(c *gin.Context, authAbort ...bool) (bool
 		return true, res
 	}
 	if authCode == auth.IdentityOK {
-		//msg = "请不要重复登录"
-		res.Code = http.StatusUnauthorized
-		res.Msg = "请不要重复登录"
+		res = common.Fail(errcode.New(errcode.AuthAlreadyLoggedIn))
 		//c.AbortWithStatusJSON(401, res)
 		c.Abort()
 		return false, res
@@ -69,3 +58,61 @@ func (h *HandleBaseImpl) AuthMiddleware(c *gin.Context, authAbort ...bool) (bool
 	c.Abort()
 	return false, res
 }
+
+// 分页参数默认值与上限
+const (
+	defaultPageSize = repository.DefaultPageSize
+	maxPageSize     = repository.MaxPageSize
+)
+
+// currentUserID 获取认证中间件写入的用户ID，未登录返回 0
+func currentUserID(c *gin.Context) uint {
+	v, ok := c.Get(auth.ContextUserIDKey)
+	if !ok {
+		return 0
+	}
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// pageParams 解析查询参数中的 page 与 page_size
+func pageParams(c *gin.Context) (page, pageSize int) {
+	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// invalidParam 参数错误响应
+func invalidParam(field string) *common.HTTPResult {
+	return common.Fail(errcode.New(errcode.InvalidParam).With("field", field))
+}
+
+// missingParam 缺少参数响应
+func missingParam(field string) *common.HTTPResult {
+	return common.Fail(errcode.New(errcode.MissingParam).With("field", field))
+}
+
+// queryUint 解析查询参数中的无符号整数，解析失败返回 0
+func queryUint(c *gin.Context, key string) uint {
+	v, err := strconv.ParseUint(c.Query(key), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(v)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/util/network/client"
+	"strconv"
+)
+
+// InteractionHandler 点赞、收藏与浏览处理
+type InteractionHandler struct {
+	HandleBaseImpl
+}
+
+// NewInteraction 创建点赞、收藏与浏览处理
+func NewInteraction(d *service.Deps) *InteractionHandler {
+	return &InteractionHandler{HandleBaseImpl: HandleBaseImpl{Deps: d}}
+}
+
+// bind 绑定帖子ID参数
+func (handle *InteractionHandler) bind(c *gin.Context) (*service.InteractionService, *common.HTTPResult) {
+	serv := &service.InteractionService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return nil, common.BindError(err)
+	}
+	return serv, nil
+}
+
+// Like 点赞
+func (handle *InteractionHandler) Like(c *gin.Context) *common.HTTPResult {
+	serv, res := handle.bind(c)
+	if res != nil {
+		return res
+	}
+	return serv.Like(handle.Deps, currentUserID(c))
+}
+
+// Unlike 取消点赞
+func (handle *InteractionHandler) Unlike(c *gin.Context) *common.HTTPResult {
+	serv, res := handle.bind(c)
+	if res != nil {
+		return res
+	}
+	return serv.Unlike(handle.Deps, currentUserID(c))
+}
+
+// Bookmark 收藏
+func (handle *InteractionHandler) Bookmark(c *gin.Context) *common.HTTPResult {
+	serv, res := handle.bind(c)
+	if res != nil {
+		return res
+	}
+	return serv.Bookmark(handle.Deps, currentUserID(c))
+}
+
+// Unbookmark 取消收藏
+func (handle *InteractionHandler) Unbookmark(c *gin.Context) *common.HTTPResult {
+	serv, res := handle.bind(c)
+	if res != nil {
+		return res
+	}
+	return serv.Unbookmark(handle.Deps, currentUserID(c))
+}
+
+// Bookmarks 当前用户的收藏列表
+func (handle *InteractionHandler) Bookmarks(c *gin.Context) *common.HTTPResult {
+	page, pageSize := pageParams(c)
+	return service.BookmarkList(handle.Deps, currentUserID(c), page, pageSize)
+}
+
+// Detail 帖子详情（记录浏览）
+func (handle *InteractionHandler) Detail(c *gin.Context) *common.HTTPResult {
+	id := queryUint(c, "id")
+	if id == 0 {
+		return invalidParam("id")
+	}
+	return service.PostDetail(handle.Deps, id, currentUserID(c), client.GetClientIP(c.Request))
+}
+
+// Popular 热门帖子，可按 board_id 查询版块热门
+func (handle *InteractionHandler) Popular(c *gin.Context) *common.HTTPResult {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if days < 1 {
+		days = 7
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	var boardID *uint
+	if c.Query("board_id") != "" {
+		id := queryUint(c, "board_id")
+		boardID = &id
+	}
+	return service.PopularList(handle.Deps, boardID, days, limit)
+}
@@ -11,22 +11,111 @@ type PostHandler struct {
 	*HandleBaseImpl
 }
 
-func NewPost() *PostHandler {
-	return &PostHandler{}
-}
-
-func (handle *PostHandler) Create(c *gin.Context) (res *common.HTTPResult) {
-	res = &common.HTTPResult{}
-	post := &model.Post{}
-	post.AuthorID = 1
-	if err := c.ShouldBindJSON(post); err != nil || post.Title == "" || post.Content == "" {
-		res.Code = 400
-		res.Msg = "参数错误: "
-		if err != nil {
-			res.Msg += err.Error()
-		}
-		return
-	}
-	serv := service.NewPost(post)
-	return serv.Create()
+func NewPost(d *service.Deps) *PostHandler {
+	return &PostHandler{HandleBaseImpl: &HandleBaseImpl{Deps: d}}
+}
+
+// Create 创建文章，作者为当前登录用户
+func (handle *PostHandler) Create(c *gin.Context) *common.HTTPResult {
+	serv := &service.CreatePostRequest{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Create(handle.Deps, currentUserID(c))
+}
+
+// Update 更新文章
+func (handle *PostHandler) Update(c *gin.Context) *common.HTTPResult {
+	serv := service.NewPost(&model.Post{})
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	if serv.ID == 0 {
+		return missingParam("ID")
+	}
+	return serv.Update(handle.Deps, currentUserID(c))
+}
+
+// Delete 删除文章
+func (handle *PostHandler) Delete(c *gin.Context) *common.HTTPResult {
+	postID := queryUint(c, "id")
+	if postID == 0 {
+		return invalidParam("id")
+	}
+	return service.DeletePost(handle.Deps, postID, currentUserID(c))
+}
+
+// Trash 回收站中的文章，支持 board_id/author_id 过滤、sort 排序与 cursor 游标分页
+func (handle *PostHandler) Trash(c *gin.Context) *common.HTTPResult {
+	return service.PostTrash(handle.Deps, currentUserID(c), c.Request.URL.Query())
+}
+
+// Restore 从回收站恢复文章
+func (handle *PostHandler) Restore(c *gin.Context) *common.HTTPResult {
+	postID := queryUint(c, "id")
+	if postID == 0 {
+		return invalidParam("id")
+	}
+	return service.RestorePost(handle.Deps, postID, currentUserID(c))
+}
+
+// Purge 彻底删除回收站中的文章
+func (handle *PostHandler) Purge(c *gin.Context) *common.HTTPResult {
+	postID := queryUint(c, "id")
+	if postID == 0 {
+		return invalidParam("id")
+	}
+	return service.PurgePost(handle.Deps, postID, currentUserID(c))
+}
+
+// List 文章列表，支持 status/board_id/author_id 过滤、sort 排序与 cursor 游标分页
+func (handle *PostHandler) List(c *gin.Context) *common.HTTPResult {
+	return service.PostList(handle.Deps, currentUserID(c), c.Request.URL.Query())
+}
+
+// Search 搜索文章，支持 q/tag/board_id/author_id/from/to 参数
+func (handle *PostHandler) Search(c *gin.Context) *common.HTTPResult {
+	serv := &service.SearchService{}
+	if err := c.ShouldBindQuery(serv); err != nil {
+		return common.BindError(err)
+	}
+	page, pageSize := pageParams(c)
+	return serv.Search(handle.Deps, page, pageSize)
+}
+
+// Revisions 修订列表
+func (handle *PostHandler) Revisions(c *gin.Context) *common.HTTPResult {
+	serv := &service.RevisionService{PostID: queryUint(c, "post_id")}
+	if serv.PostID == 0 {
+		return invalidParam("post_id")
+	}
+	page, pageSize := pageParams(c)
+	return serv.List(handle.Deps, currentUserID(c), page, pageSize)
+}
+
+// Revision 指定版本详情
+func (handle *PostHandler) Revision(c *gin.Context) *common.HTTPResult {
+	serv := &service.RevisionService{}
+	if err := c.ShouldBindQuery(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Detail(handle.Deps, currentUserID(c))
+}
+
+// RevisionDiff 比较两个版本
+func (handle *PostHandler) RevisionDiff(c *gin.Context) *common.HTTPResult {
+	serv := &service.RevisionDiffService{}
+	if err := c.ShouldBindQuery(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Diff(handle.Deps, currentUserID(c))
+}
+
+// Rollback 回滚到指定版本
+func (handle *PostHandler) Rollback(c *gin.Context) *common.HTTPResult {
+	serv := &service.RevisionService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Rollback(handle.Deps, currentUserID(c))
 }
@@ -13,6 +13,10 @@ type UserHandlerInterface interface {
 	Login(c *gin.Context) *common.HTTPResult
 	Logout(c *gin.Context) *common.HTTPResult
 	DelID(c *gin.Context) *common.HTTPResult
+	SetLocale(c *gin.Context) *common.HTTPResult
+	ChangePassword(c *gin.Context) *common.HTTPResult
+	ResetPassword(c *gin.Context) *common.HTTPResult
+	RestoreUser(c *gin.Context) *common.HTTPResult
 }
 
 // UserHandler 用户处理
@@ -22,66 +26,97 @@ type UserHandler struct {
 }
 
 // NewUserHandler 创建用户处理
-func NewUserHandler() UserHandlerInterface {
+func NewUserHandler(d *service.Deps) UserHandlerInterface {
 	return &UserHandler{
-		Service: &service.AuthService{},
+		Service:        &service.AuthService{},
+		HandleBaseImpl: HandleBaseImpl{Deps: d},
 	}
 }
 
 // Register 注册
 func (handle *UserHandler) Register(c *gin.Context) *common.HTTPResult {
-	serv := handle.Service
-	if err := c.ShouldBindJSON(serv); err != nil || serv.Username == "" || serv.Password == "" {
-		return &common.HTTPResult{
-			Code: 400,
-			Msg:  "用户注册参数错误",
-		}
+	var req service.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
 	}
-	return serv.Register()
+	serv := &service.AuthService{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+		Nickname: req.Nickname,
+		Locale:   req.Locale,
+	}
+	return serv.Register(handle.Deps)
 }
 
 // Login 登录
 func (handle *UserHandler) Login(c *gin.Context) *common.HTTPResult {
-	serv := handle.Service
-	if err := c.ShouldBindJSON(serv); err != nil || serv.Password == "" {
-		return &common.HTTPResult{
-			Code: 400,
-			Msg:  "用户登录参数错误",
-		}
+	var req service.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
 	}
-	if serv.Username == "" && serv.Email == "" {
-		return &common.HTTPResult{
-			Code: 400,
-			Msg:  "请使用邮箱或者用户名进行登录",
-		}
+	// 使用用户名或邮箱登录
+	if req.Username == "" && req.Email == "" {
+		return missingParam("username")
 	}
+	serv := &service.AuthService{Username: req.Username, Email: req.Email, Password: req.Password}
 	deviceID := client.JsonDeviceHandler(c.Writer, c.Request).DeviceType
-	return serv.Login("qwq", deviceID)
+	return serv.Login(handle.Deps, "qwq", deviceID)
 }
 
 // Logout 登出
 func (handle *UserHandler) Logout(c *gin.Context) *common.HTTPResult {
-	serv := handle.Service
-	if err := c.ShouldBindJSON(serv); err != nil || serv.ID == 0 {
-		return &common.HTTPResult{
-			Code: 400,
-			Msg:  "用户登出参数错误",
-		}
+	var req service.UserIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
 	}
-
 	deviceID := client.JsonDeviceHandler(c.Writer, c.Request).DeviceType
-	return serv.Logout(serv.ID, common.PlatformSign(), deviceID)
+	return handle.Service.Logout(handle.Deps, req.ID, common.PlatformSign(), deviceID)
 }
 
 // DelID 根据ID删除用户
 func (handle *UserHandler) DelID(c *gin.Context) *common.HTTPResult {
-	serv := handle.Service
-	if err := c.ShouldBindJSON(serv); err != nil {
-		return &common.HTTPResult{
-			Code: 400,
-			Msg:  "用户删除参数错误",
-		}
+	var req service.UserIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
 	}
 	deviceID := client.JsonDeviceHandler(c.Writer, c.Request).DeviceType
-	return serv.Del(serv.ID, common.PlatformSign(), deviceID)
+	return handle.Service.Del(handle.Deps, req.ID, common.PlatformSign(), deviceID)
+}
+
+// RestoreUser 从回收站恢复用户
+func (handle *UserHandler) RestoreUser(c *gin.Context) *common.HTTPResult {
+	var req service.UserIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
+	}
+	return service.RestoreUser(handle.Deps, req.ID, currentUserID(c))
+}
+
+// SetLocale 设置当前用户的语言偏好
+func (handle *UserHandler) SetLocale(c *gin.Context) *common.HTTPResult {
+	var req service.LocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
+	}
+	serv := &service.AuthService{Locale: req.Locale}
+	return serv.SetLocale(handle.Deps, currentUserID(c))
+}
+
+// ChangePassword 修改当前用户的密码
+func (handle *UserHandler) ChangePassword(c *gin.Context) *common.HTTPResult {
+	serv := &service.ChangePasswordService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Change(handle.Deps, currentUserID(c))
+}
+
+// ResetPassword 重置指定用户的密码
+func (handle *UserHandler) ResetPassword(c *gin.Context) *common.HTTPResult {
+	serv := &service.ResetPasswordService{}
+	if err := c.ShouldBindJSON(serv); err != nil {
+		return common.BindError(err)
+	}
+	return serv.Reset(handle.Deps, currentUserID(c))
 }
@@ -0,0 +1,99 @@
+// Package locale 应用的多语言消息
+//
+// 内置消息目录 messages/<语言>.yaml 编译进程序，配置 i18n.dir 指定的目录中的同名文件
+// 在启动时加载，覆盖或补充内置消息（也可以添加新的语言）。
+// 错误码消息的键为 error.<错误码>，成功提示按业务分组（如 post.created）。
+package locale
+
+import (
+	"embed"
+	"fmt"
+	"sync/atomic"
+
+	"qwqserver/pkg/i18n"
+)
+
+// 内置语言
+const (
+	ZH = "zh-CN"
+	EN = "en-US"
+)
+
+// Config 多语言配置
+type Config struct {
+	Dir         string // 额外的消息目录，为空时只使用内置消息
+	DefaultLang string // 默认语言，为空时为 zh-CN
+}
+
+//go:embed messages/*.yaml
+var builtin embed.FS
+
+var bundle atomic.Pointer[i18n.Bundle]
+
+func init() {
+	b, err := load(Config{})
+	if err != nil {
+		panic(fmt.Sprintf("加载内置消息目录失败: %v", err))
+	}
+	bundle.Store(b)
+}
+
+// load 加载内置消息与配置目录中的消息
+func load(cfg Config) (*i18n.Bundle, error) {
+	if cfg.DefaultLang == "" {
+		cfg.DefaultLang = ZH
+	}
+	b := i18n.NewBundle(cfg.DefaultLang)
+	if err := b.LoadFS(builtin, "messages"); err != nil {
+		return nil, err
+	}
+	if cfg.Dir != "" {
+		if err := b.LoadDir(cfg.Dir); err != nil {
+			return nil, fmt.Errorf("加载消息目录 %s 失败: %w", cfg.Dir, err)
+		}
+	}
+	if _, ok := b.Canonical(cfg.DefaultLang); !ok {
+		return nil, fmt.Errorf("默认语言 %s 没有消息目录", cfg.DefaultLang)
+	}
+	return b, nil
+}
+
+// Init 按配置重新加载消息目录，失败时保留原有目录
+func Init(cfg Config) error {
+	b, err := load(cfg)
+	if err != nil {
+		return err
+	}
+	bundle.Store(b)
+	return nil
+}
+
+// Bundle 当前的消息目录
+func Bundle() *i18n.Bundle {
+	return bundle.Load()
+}
+
+// DefaultLang 默认语言
+func DefaultLang() string {
+	return Bundle().Fallback()
+}
+
+// T 翻译消息，args 可为 nil；缺少消息时回退到默认语言，仍缺少时返回键本身
+func T(lang, key string, args i18n.Args) string {
+	return Bundle().T(lang, key, args)
+}
+
+// Negotiate 协商响应语言：用户偏好优先，其次 Accept-Language，最后默认语言
+func Negotiate(preference, acceptLanguage string) string {
+	return Bundle().Negotiate(preference, acceptLanguage)
+}
+
+// Canonical 支持的语言的规范标签，不支持时返回 false
+func Canonical(lang string) (string, bool) {
+	return Bundle().Canonical(lang)
+}
+
+// Languages 支持的语言
+func Languages() []string {
+	return Bundle().Languages()
+}
@@ -2,10 +2,10 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"qwqserver/internal/auth"
 	"qwqserver/internal/common"
-	"qwqserver/pkg/cache"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/session"
 	"strings"
 	"time"
 )
@@ -17,9 +17,7 @@ var excludePaths = map[string]ExcludeRouter{
 		IsValid: true,
 		HandlerFunc: func(c *gin.Context) (auth.CodeType, *common.HTTPResult) {
 			if isLoggedIn(c) {
-				c.AbortWithStatusJSON(http.StatusOK, common.HTTPResult{
-					Msg: "已登录，请不要重复注册",
-				})
+				Abort(c, errcode.New(errcode.AuthAlreadyLoggedIn))
 				return auth.IdentitySkipped, nil
 			}
 			c.Next()
@@ -30,15 +28,35 @@ var excludePaths = map[string]ExcludeRouter{
 		IsValid: true,
 		HandlerFunc: func(c *gin.Context) (auth.CodeType, *common.HTTPResult) {
 			if isLoggedIn(c) {
-				c.AbortWithStatusJSON(http.StatusOK, common.HTTPResult{
-					Msg: "已登录，请不要重复登录",
-				})
+				Abort(c, errcode.New(errcode.AuthAlreadyLoggedIn))
 				return auth.IdentitySkipped, nil
 			}
 			c.Next()
 			return auth.IdentitySkipped, nil
 		},
 	},
+	// 帖子详情、精华内容与专题为公开内容
+	"/api/v1/post/detail":       optionalAuth,
+	"/api/v1/post/list":         optionalAuth,
+	"/api/v1/post/popular":      optionalAuth,
+	"/api/v1/post/search":       optionalAuth,
+	"/api/v1/docs/search":       optionalAuth,
+	"/api/v1/docs/livereload":   optionalAuth,
+	"/api/v1/post/featured":     optionalAuth,
+	"/api/v1/collection/list":   optionalAuth,
+	"/api/v1/collection/detail": optionalAuth,
+}
+
+// optionalAuth 公开路由：携带有效令牌时解析身份，否则以游客身份放行
+var optionalAuth = ExcludeRouter{
+	IsValid: true,
+	HandlerFunc: func(c *gin.Context) (auth.CodeType, *common.HTTPResult) {
+		if c.GetHeader("Authorization") != "" {
+			JWTAuth(c)
+		}
+		c.Next()
+		return auth.IdentitySkipped, nil
+	},
 }
 
 type ExcludeRouter struct {
@@ -46,8 +64,14 @@ type ExcludeRouter struct {
 	HandlerFunc HandlerFunc
 }
 
-func AuthMiddleware() gin.HandlerFunc {
+// sessionStoreKey 上下文中登录令牌存储的键
+const sessionStoreKey = "auth_session_store"
+
+// AuthMiddleware 认证中间件，令牌须与 store 中保存的一致；store 为 nil 时所有令牌均视为已失效
+func AuthMiddleware(store session.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set(sessionStoreKey, store)
+
 		// 1. 检查排除路径
 		if router, ok := excludePaths[c.Request.URL.Path]; ok && router.IsValid {
 			router.HandlerFunc(c)
@@ -65,12 +89,10 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.Next()
 		default:
 			if res == nil {
-				res = &common.HTTPResult{
-					Code: http.StatusUnauthorized,
-					Msg:  "认证失败",
-				}
+				res = common.Fail(errcode.New(errcode.Unauthorized))
 			}
-			c.AbortWithStatusJSON(res.Code, res)
+			Render(c, res)
+			c.Abort()
 		}
 	}
 }
@@ -93,9 +115,27 @@ func isLoggedIn(c *gin.Context) bool {
 		return false
 	}
 
-	// 检查Redis中的token有效性
-	redisKey := common.RedisTokenPrefix + claims.UserID + ":" + claims.Platform + ":" + claims.DeviceID
-	storedToken, err := cache.Get(redisKey)
+	// 检查令牌存储中的token有效性
+	return tokenStored(c, sessionKey(claims), tokenString)
+}
+
+func sessionKey(claims *auth.CustomClaims) session.Key {
+	return session.Key{UserID: claims.UserID, Platform: claims.Platform, DeviceID: claims.DeviceID}
+}
+
+func sessionStore(c *gin.Context) session.Store {
+	store, _ := c.Get(sessionStoreKey)
+	s, _ := store.(session.Store)
+	return s
+}
+
+// tokenStored 令牌是否为该设备当前有效的令牌
+func tokenStored(c *gin.Context, key session.Key, tokenString string) bool {
+	store := sessionStore(c)
+	if store == nil {
+		return false
+	}
+	storedToken, err := store.Token(c.Request.Context(), key)
 	return err == nil && storedToken == tokenString
 }
 
@@ -103,58 +143,40 @@ func JWTAuth(c *gin.Context) (auth.CodeType, *common.HTTPResult) {
 	// 获取Authorization header
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		return auth.IdentityErrNoToken, &common.HTTPResult{
-			Code: http.StatusUnauthorized,
-			Msg:  "未提供认证令牌",
-		}
+		return auth.IdentityErrNoToken, common.Fail(errcode.New(errcode.AuthTokenMissing))
 	}
 
 	// 检查Bearer格式
 	parts := strings.SplitN(authHeader, " ", 2)
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		return auth.IdentityErrTokenFormat, &common.HTTPResult{
-			Code: http.StatusUnauthorized,
-			Msg:  "令牌格式错误",
-		}
+		return auth.IdentityErrTokenFormat, common.Fail(errcode.New(errcode.AuthTokenMalformed))
 	}
 
 	tokenString := parts[1]
 	claims, err := auth.ParseToken(tokenString)
 	if err != nil {
-		return auth.IdentityErrInvalidToken, &common.HTTPResult{
-			Code: http.StatusUnauthorized,
-			Msg:  "无效令牌",
-		}
+		return auth.IdentityErrInvalidToken, common.Fail(errcode.New(errcode.AuthTokenInvalid))
 	}
 
-	// 检查Redis中token有效性
-	redisKey := common.RedisTokenPrefix + claims.UserID + ":" + claims.Platform + ":" + claims.DeviceID
-	storedToken, err := cache.Get(redisKey)
-	if err != nil || storedToken != tokenString {
-		return auth.IdentityErrTokenExpired, &common.HTTPResult{
-			Code: http.StatusUnauthorized,
-			Msg:  "令牌已失效",
-		}
+	// 检查令牌存储中token有效性
+	key := sessionKey(claims)
+	if !tokenStored(c, key, tokenString) {
+		return auth.IdentityErrTokenExpired, common.Fail(errcode.New(errcode.AuthTokenExpired))
 	}
 
-	// 无感刷新
+	// 无感刷新，保存失败时继续使用旧令牌
 	if time.Until(claims.ExpiresAt.Time) < common.TokenRefreshInterval {
 		newToken, err := auth.GenerateToken(claims.UserID, claims.Platform, claims.DeviceID)
-		if err == nil {
+		if err == nil && sessionStore(c).SetToken(c.Request.Context(), key, newToken) == nil {
 			c.Header("New-Token", newToken)
-			cache.Set(redisKey, newToken, common.TokenExpireTime)
 		}
 	}
 
 	// 设置上下文信息
 	c.Set(auth.IdentityStatusKey, auth.IdentityOK)
-	c.Set("user_id", claims.UserID)
-	c.Set("platform", claims.Platform)
-	c.Set("device_id", claims.DeviceID)
-
-	return auth.IdentityOK, &common.HTTPResult{
-		Code: http.StatusOK,
-		Msg:  "认证成功",
-		Data: claims,
-	}
+	c.Set(auth.ContextUserIDKey, claims.UserID)
+	c.Set(auth.ContextPlatformKey, claims.Platform)
+	c.Set(auth.ContextDeviceIDKey, claims.DeviceID)
+
+	return auth.IdentityOK, common.Success("auth.authenticated", claims)
 }
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"qwqserver/internal/auth"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/locale"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// HeaderRequestID 请求 ID 请求头与响应头
+	HeaderRequestID = "X-Request-ID"
+
+	// ContextRequestIDKey 上下文中的请求 ID
+	ContextRequestIDKey = "request_id"
+
+	// ContextLanguageKey 上下文中协商得到的响应语言
+	ContextLanguageKey = "language"
+
+	// maxRequestIDLen 客户端传入的请求 ID 最大长度，超出或含非法字符时重新生成
+	maxRequestIDLen = 64
+)
+
+// ErrorMiddleware 错误处理中间件
+//   - 为每个请求分配请求 ID（沿用客户端传入的 X-Request-ID），写入上下文与响应头
+//   - 处理函数通过 c.Error(err) 返回的错误在请求结束后统一渲染为错误响应
+//   - 捕获 panic 并返回内部错误
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		RequestID(c)
+
+		defer func() {
+			if r := recover(); r != nil {
+				if c.Writer.Written() {
+					slog.Error("请求处理 panic", "request_id", RequestID(c), "path", c.Request.URL.Path, "panic", r)
+					c.Abort()
+					return
+				}
+				Abort(c, errcode.Wrap(errcode.Internal, fmt.Errorf("panic: %v", r)))
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			Abort(c, c.Errors.Last().Err)
+		}
+	}
+}
+
+// RequestID 获取请求 ID，未分配时生成并写入响应头
+func RequestID(c *gin.Context) string {
+	if v, ok := c.Get(ContextRequestIDKey); ok {
+		if id, ok := v.(string); ok {
+			return id
+		}
+	}
+	id := c.GetHeader(HeaderRequestID)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	c.Set(ContextRequestIDKey, id)
+	c.Header(HeaderRequestID, id)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// UserLocaleFunc 查询用户的语言偏好，未设置时返回空
+type UserLocaleFunc func(ctx context.Context, userID string) string
+
+var userLocale atomic.Pointer[UserLocaleFunc]
+
+// SetUserLocaleResolver 设置用户语言偏好的查询函数，登录用户的偏好优先于 Accept-Language
+func SetUserLocaleResolver(fn UserLocaleFunc) {
+	userLocale.Store(&fn)
+}
+
+// Language 请求的响应语言：登录用户的语言偏好优先，其次 Accept-Language，最后默认语言
+// 结果缓存在上下文中，同一请求只协商一次
+func Language(c *gin.Context) string {
+	if lang := c.GetString(ContextLanguageKey); lang != "" {
+		return lang
+	}
+	var preference string
+	if fn := userLocale.Load(); fn != nil {
+		if userID := c.GetString(auth.ContextUserIDKey); userID != "" {
+			preference = (*fn)(c.Request.Context(), userID)
+		}
+	}
+	lang := locale.Negotiate(preference, c.GetHeader("Accept-Language"))
+	c.Set(ContextLanguageKey, lang)
+	return lang
+}
+
+// Render 输出统一响应
+//   - 带错误码或消息键的响应按请求语言生成消息，错误的附加信息实现 errcode.Localizer 时同样本地化
+//   - 未使用错误码的错误响应按状态码补充通用错误码
+//   - 5xx 响应的原始消息（可能包含数据库等内部错误）只记录日志，返回通用的内部错误消息
+func Render(c *gin.Context, res *common.HTTPResult) {
+	out := *res
+	out.RequestID = RequestID(c)
+	lang := Language(c)
+	out.Msg = res.Message(lang)
+
+	if e := res.Err(); e != nil {
+		if l, ok := e.Details.(errcode.Localizer); ok {
+			out.Data = l.Localize(lang)
+		}
+		if e.Err != nil {
+			slog.Error("请求处理失败", "request_id", out.RequestID, "path", c.Request.URL.Path, "error", e.Err)
+		}
+	} else if res.Code >= http.StatusBadRequest && res.Error == "" {
+		out.Error = errcode.ForStatus(res.Code)
+		if res.Code >= http.StatusInternalServerError {
+			slog.Error("请求处理失败", "request_id", out.RequestID, "path", c.Request.URL.Path, "error", res.Msg)
+			out.Msg = out.Error.Message(lang, nil)
+		}
+	}
+	c.Header("Content-Language", lang)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	c.JSON(out.Code, &out)
+}
+
+// Abort 输出错误响应并中止后续处理
+func Abort(c *gin.Context, err error) {
+	Render(c, common.Fail(err))
+	c.Abort()
+}
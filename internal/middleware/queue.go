@@ -2,40 +2,78 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"qwqserver/internal/config"
+	"qwqserver/internal/errcode"
+	"sync"
 )
 
+// concurrencyLimiter 可在运行时调整上限的并发计数器
+// 调小上限时已在处理的请求不受影响，计数回落到新上限以下后才接受新请求
+type concurrencyLimiter struct {
+	mu     sync.Mutex
+	max    int
+	active int
+}
+
+func (l *concurrencyLimiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active >= l.max {
+		return false
+	}
+	l.active++
+	return true
+}
+
+func (l *concurrencyLimiter) release() {
+	l.mu.Lock()
+	l.active--
+	l.mu.Unlock()
+}
+
 // 最大并发连接数
-var maxConcurrent int
-
-// 信号量控制器
-var semaphore chan struct{}
-
-func init() {
-	// 设置最大并发连接数
-	cfg := config.New()
-	if cfg.Listen.QueueLimitMaxConcurrent > 0 {
-		maxConcurrent = cfg.Listen.QueueLimitMaxConcurrent
-	} else {
-		maxConcurrent = 1
+var queueLimiter concurrencyLimiter
+
+var semaphoreOnce sync.Once
+
+// initSemaphore 按配置设置并发上限，在首次创建中间件时读取配置而非包初始化时，避免导入即加载配置
+func initSemaphore() {
+	SetQueueLimit(config.New().Listen.QueueLimitMaxConcurrent)
+}
+
+// SetQueueLimit 调整队列限制的最大并发数，小于 1 时按 1 处理，用于配置热更新
+func SetQueueLimit(n int) {
+	if n < 1 {
+		n = 1
 	}
-	semaphore = make(chan struct{}, maxConcurrent)
+	queueLimiter.mu.Lock()
+	queueLimiter.max = n
+	queueLimiter.mu.Unlock()
+}
+
+// streamRoutes 不占用并发名额的长连接路由（路由模板 → true）
+var streamRoutes sync.Map
+
+// SkipQueueLimit 登记不占用并发名额的长连接路由（如 SSE），fullPath 为注册时的路由模板
+func SkipQueueLimit(fullPath string) {
+	streamRoutes.Store(fullPath, true)
 }
 
 // QueueLimitMiddleware 请求中间件：队列限制
+// 通过 SkipQueueLimit 登记的长连接路由不占用并发名额；按匹配到的路由判断，不受请求头影响
 func QueueLimitMiddleware() gin.HandlerFunc {
+	semaphoreOnce.Do(initSemaphore)
 	return func(c *gin.Context) {
-		select {
-		case semaphore <- struct{}{}:
-			// 成功获取信号量，允许继续
-			defer func() { <-semaphore }()
+		if _, ok := streamRoutes.Load(c.FullPath()); ok {
 			c.Next()
-		default:
+			return
+		}
+		if !queueLimiter.acquire() {
 			// 达到最大并发限制
-			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
-				"error": "too many concurrent requests",
-			})
+			Abort(c, errcode.New(errcode.TooManyRequests))
+			return
 		}
+		defer queueLimiter.release()
+		c.Next()
 	}
 }
@@ -0,0 +1,181 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"qwqserver/pkg/migrate"
+)
+
+// 基线表结构快照
+//
+// 以下结构体冻结了基线迁移编写时 internal/model 中的表结构，之后修改模型不会改变基线建出的表，
+// 表结构的变化必须写成新的迁移。类型名与原模型同名（小写），
+// 因为多对多关联表的列名与外键约束名由类型名推导（post_tags.post_id、fk_post_tags_post 等）。
+
+type user struct {
+	ID                uint           `gorm:"primarykey"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime;comment:注册时间"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime;comment:更新时间"`
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+	Username          string         `gorm:"type:varchar(128);uniqueIndex;not null;comment:用户名"`
+	Nickname          string         `gorm:"type:varchar(1024);default:'新用户';comment:用户昵称"`
+	Email             string         `gorm:"type:varchar(128);uniqueIndex;comment:邮箱地址"`
+	Password          string         `gorm:"type:varchar(1024);not null;comment:密码哈希 自描述格式 $算法$参数$..."`
+	PasswordChangedAt *time.Time     `gorm:"comment:密码修改时间 为空时按注册时间;default:NULL"`
+	FailedAttempts    int            `gorm:"default:0;comment:登录失败次数"`
+	LastLoginAt       *time.Time     `gorm:"comment:上次登录时间;default:NULL"`
+	LastFailedAttempt *time.Time     `gorm:"comment:上次登录失败时间;default:NULL"`
+	Perms             uint64         `gorm:"type:BIGINT UNSIGNED;default:0;comment:权限位掩码"`
+	Status            uint8          `gorm:"default:1;comment:状态 1=正常"`
+	IpAddress         string         `gorm:"type:varchar(1024);comment:用户IP"`
+	Locale            string         `gorm:"type:varchar(16);default:'';comment:界面语言偏好 为空时按 Accept-Language"`
+}
+
+func (*user) TableName() string { return "users" }
+
+type post struct {
+	ID            uint           `gorm:"primarykey"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime;comment:创建时间"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime;comment:更新时间"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	AuthorID      uint64         `gorm:"not null;column:author_id;comment:作者ID"`
+	Author        user           `gorm:"foreignKey:AuthorID;references:ID"`
+	BoardID       uint           `gorm:"index;not null;default:0;comment:版块ID 0为未分版块"`
+	Mod           string         `gorm:"size:1024;not null;comment:内容模型 markdown/plain/html"`
+	Title         string         `gorm:"size:1024;comment:标题"`
+	Content       string         `gorm:"type:longtext;comment:内容"`
+	Status        string         `gorm:"size:16;default:'draft';comment:状态 draft/published/pending/trash"`
+	IsSticky      bool           `gorm:"default:false;comment:是否置顶"`
+	CommentStatus string         `gorm:"size:16;default:'open';comment:评论状态 open/closed"`
+	PublishedAt   *time.Time     `gorm:"comment:发布时间"`
+	IsDeleted     bool           `gorm:"default:false;comment:删除标记"`
+	ViewCount     int64          `gorm:"not null;default:0;comment:浏览量"`
+	LikeCount     int64          `gorm:"not null;default:0;comment:点赞数"`
+	CommentCount  int64          `gorm:"not null;default:0;comment:评论数"`
+	BookmarkCount int64          `gorm:"not null;default:0;comment:收藏数"`
+	Version       int64          `gorm:"not null;default:1;comment:乐观锁版本号 每次编辑递增"`
+	Tags          []tag          `gorm:"many2many:post_tags;"`
+}
+
+func (*post) TableName() string { return "posts" }
+
+type tag struct {
+	TagID uint   `gorm:"primaryKey;autoIncrement"`
+	Name  string `gorm:"size:50;unique;not null"`
+	Posts []post `gorm:"many2many:post_tags;"`
+}
+
+func (*tag) TableName() string { return "tags" }
+
+type postFeature struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	PostID     uint           `gorm:"not null;uniqueIndex;comment:帖子ID"`
+	Post       *post          `gorm:"foreignKey:PostID"`
+	OperatorID uint           `gorm:"not null;comment:操作人ID"`
+	Reason     string         `gorm:"size:512;comment:加精理由"`
+	ExpireAt   *time.Time     `gorm:"index;comment:过期时间 为空则永久"`
+}
+
+func (*postFeature) TableName() string { return "post_features" }
+
+type collection struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt   `gorm:"index"`
+	Title       string           `gorm:"size:256;not null;comment:专题标题"`
+	Description string           `gorm:"size:2048;comment:专题描述"`
+	CreatorID   uint             `gorm:"not null;comment:创建人ID"`
+	Items       []collectionItem `gorm:"foreignKey:CollectionID"`
+}
+
+func (*collection) TableName() string { return "collections" }
+
+type collectionItem struct {
+	ID           uint  `gorm:"primaryKey;autoIncrement"`
+	CollectionID uint  `gorm:"not null;uniqueIndex:idx_collection_post;index:idx_collection_sort;comment:专题ID"`
+	PostID       uint  `gorm:"not null;uniqueIndex:idx_collection_post;comment:帖子ID"`
+	Post         *post `gorm:"foreignKey:PostID"`
+	Sort         int   `gorm:"not null;default:0;index:idx_collection_sort;comment:排序"`
+}
+
+func (*collectionItem) TableName() string { return "collection_items" }
+
+type postLike struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_like_user_post;comment:用户ID"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_like_user_post;index;comment:帖子ID"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:点赞时间"`
+}
+
+func (*postLike) TableName() string { return "post_likes" }
+
+type bookmark struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_bookmark_user_post;comment:用户ID"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_bookmark_user_post;index;comment:帖子ID"`
+	Post      *post     `gorm:"foreignKey:PostID"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:收藏时间"`
+}
+
+func (*bookmark) TableName() string { return "bookmarks" }
+
+type postRevision struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_revision_post_version;comment:帖子ID"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_revision_post_version;comment:版本号"`
+	Title     string    `gorm:"size:1024;comment:标题"`
+	Content   string    `gorm:"comment:内容"`
+	Mod       string    `gorm:"size:1024;comment:内容模型"`
+	EditorID  uint      `gorm:"not null;default:0;comment:编辑者ID 0为未知"`
+	Summary   string    `gorm:"size:255;comment:修订说明"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:修订时间"`
+}
+
+func (*postRevision) TableName() string { return "post_revisions" }
+
+type passwordHistory struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `gorm:"not null;index;comment:用户ID"`
+	Hash      string    `gorm:"type:varchar(255);not null;comment:密码哈希"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:设置时间"`
+}
+
+func (*passwordHistory) TableName() string { return "password_histories" }
+
+// baselineModels 基线迁移创建的表，按依赖顺序排列
+var baselineModels = []any{
+	&user{},
+	&post{},
+	&tag{},
+	&postFeature{},
+	&collection{},
+	&collectionItem{},
+	&postLike{},
+	&bookmark{},
+	&postRevision{},
+	&passwordHistory{},
+}
+
+// 基线：按上面冻结的表结构建表，取代原先启动时的 AutoMigrate
+// 已由 AutoMigrate 建好表的数据库执行时只补齐缺少的列与索引
+func init() {
+	register(migrate.Migration{
+		Version: 20250601000000,
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(baselineModels...)
+		},
+		Down: func(tx *gorm.DB) error {
+			tables := []any{"post_tags"}
+			for i := len(baselineModels) - 1; i >= 0; i-- {
+				tables = append(tables, baselineModels[i])
+			}
+			return tx.Migrator().DropTable(tables...)
+		},
+	})
+}
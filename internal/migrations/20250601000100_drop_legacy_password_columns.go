@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"qwqserver/internal/model"
+	"qwqserver/pkg/migrate"
+	"qwqserver/pkg/util/passsec"
+)
+
+// legacyPasswordColumns users 表中已由自描述哈希取代的旧列
+var legacyPasswordColumns = []string{"password_hash", "password_salt", "iterations"}
+
+// legacyPasswordRow 旧列中的密码数据
+type legacyPasswordRow struct {
+	ID           uint
+	Password     string
+	PasswordHash string
+	PasswordSalt string
+	Iterations   int
+}
+
+// 清理 users 表中冗余的密码列
+//
+// 旧数据在 password、password_hash、password_salt 中重复保存同一个 bcrypt 哈希，
+// 或者按 multih256 方案在 password_hash、password_salt、iterations 中分列保存。
+// password 不是可识别的自描述哈希时，将分列的 multih256 数据合并为 $multih256$ 格式写回 password，
+// 随后删除旧列。旧列不存在时不做任何操作，可重复执行。合并后的数据无法拆回旧列，不可回滚。
+func init() {
+	register(migrate.Migration{
+		Version: 20250601000100,
+		Name:    "drop_legacy_password_columns",
+		Up:      migrateLegacyPasswordColumns,
+	})
+}
+
+func migrateLegacyPasswordColumns(db *gorm.DB) error {
+	m := db.Migrator()
+	var columns []string
+	for _, c := range legacyPasswordColumns {
+		if m.HasColumn(&model.User{}, c) {
+			columns = append(columns, c)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	if len(columns) == len(legacyPasswordColumns) {
+		var rows []legacyPasswordRow
+		err := db.Model(&model.User{}).Unscoped().
+			Select("id", "password", "password_hash", "password_salt", "iterations").
+			Find(&rows).Error
+		if err != nil {
+			return fmt.Errorf("读取旧密码列失败: %w", err)
+		}
+		for _, row := range rows {
+			if _, err := passsec.Identify(row.Password); err == nil {
+				continue
+			}
+			hash := legacyMultiH256(row)
+			if hash == "" {
+				continue
+			}
+			if err := db.Model(&model.User{}).Unscoped().Where("id = ?", row.ID).
+				UpdateColumn("password", hash).Error; err != nil {
+				return fmt.Errorf("迁移用户 %d 的密码失败: %w", row.ID, err)
+			}
+		}
+	}
+
+	for _, c := range columns {
+		if err := m.DropColumn(&model.User{}, c); err != nil {
+			return fmt.Errorf("删除列 %s 失败: %w", c, err)
+		}
+	}
+	return nil
+}
+
+// legacyMultiH256 由分列保存的 multih256 数据生成自描述哈希，数据无效时返回空串
+func legacyMultiH256(row legacyPasswordRow) string {
+	if row.Iterations < 1 || !isHex(row.PasswordHash) || (row.PasswordSalt != "" && !isHex(row.PasswordSalt)) {
+		return ""
+	}
+	return passsec.EncodeMultiH256(strings.ToLower(row.PasswordHash), strings.ToLower(row.PasswordSalt), row.Iterations)
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return s != "" && err == nil
+}
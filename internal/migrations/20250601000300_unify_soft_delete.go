@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"qwqserver/internal/model"
+	"qwqserver/pkg/migrate"
+)
+
+// 统一软删除标记为 deleted_at
+//
+// posts 表曾同时使用 deleted_at、is_deleted 与 status='trash' 表示删除。
+// is_deleted 为真或状态为 trash 且 deleted_at 为空的帖子，以更新时间作为删除时间移入回收站，
+// trash 状态改为 draft（恢复后为草稿），随后删除 is_deleted 列并补回删除列时丢失的索引。可重复执行。
+// 回滚时重新添加 is_deleted 列并按 deleted_at 填充，trash 状态无法区分，不再恢复。
+func init() {
+	register(migrate.Migration{
+		Version: 20250601000300,
+		Name:    "unify_soft_delete",
+		Up:      migrateUnifySoftDelete,
+		Down:    rollbackUnifySoftDelete,
+	})
+}
+
+// postsIndexes posts 表的普通索引（索引名 → 列）
+var postsIndexes = map[string]string{
+	"idx_posts_board_id":   "board_id",
+	"idx_posts_deleted_at": "deleted_at",
+}
+
+func migrateUnifySoftDelete(db *gorm.DB) error {
+	posts := func() *gorm.DB { return db.Model(&model.Post{}).Unscoped() }
+	m := db.Migrator()
+
+	if m.HasColumn(&model.Post{}, "is_deleted") {
+		if err := posts().Where("is_deleted = ? AND deleted_at IS NULL", true).
+			UpdateColumn("deleted_at", gorm.Expr("updated_at")).Error; err != nil {
+			return fmt.Errorf("迁移 is_deleted 失败: %w", err)
+		}
+		if err := m.DropColumn(&model.Post{}, "is_deleted"); err != nil {
+			return fmt.Errorf("删除列 is_deleted 失败: %w", err)
+		}
+	}
+	// SQLite 删除列时会重建表并丢失索引，补回
+	for name, column := range postsIndexes {
+		if m.HasIndex("posts", name) {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE INDEX %s ON posts (%s)", name, column)).Error; err != nil {
+			return fmt.Errorf("创建索引 %s 失败: %w", name, err)
+		}
+	}
+
+	if err := posts().Where("status = ? AND deleted_at IS NULL", "trash").
+		UpdateColumn("deleted_at", gorm.Expr("updated_at")).Error; err != nil {
+		return fmt.Errorf("迁移 trash 状态失败: %w", err)
+	}
+	if err := posts().Where("status = ?", "trash").UpdateColumn("status", "draft").Error; err != nil {
+		return fmt.Errorf("迁移 trash 状态失败: %w", err)
+	}
+	return nil
+}
+
+func rollbackUnifySoftDelete(db *gorm.DB) error {
+	if db.Migrator().HasColumn(&model.Post{}, "is_deleted") {
+		return nil
+	}
+	if err := db.Exec("ALTER TABLE posts ADD COLUMN is_deleted BOOLEAN NOT NULL DEFAULT FALSE").Error; err != nil {
+		return fmt.Errorf("添加列 is_deleted 失败: %w", err)
+	}
+	if err := db.Model(&model.Post{}).Unscoped().Where("deleted_at IS NOT NULL").
+		UpdateColumn("is_deleted", true).Error; err != nil {
+		return fmt.Errorf("回填 is_deleted 失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Package migrations qwqserver 的数据库迁移
+//
+// Go 迁移在各自文件的 init 中通过 register 注册，SQL 迁移放在 sql 目录下并嵌入二进制，
+// 命名规则见 pkg/migrate。新迁移使用 go run ./cmd/migrate create <名称> [go|sql] 生成。
+//
+// 基线迁移按冻结在迁移包中的表结构快照建表（不引用 internal/model），之后的迁移在全新数据库上也会在基线之后执行，
+// 因此修改表结构的迁移需要可重复执行（先检查列或索引是否已是目标状态）。
+package migrations
+
+import (
+	"context"
+	"embed"
+
+	"gorm.io/gorm"
+	"qwqserver/pkg/migrate"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+var goMigrations []migrate.Migration
+
+// register 注册 Go 迁移
+func register(m migrate.Migration) {
+	goMigrations = append(goMigrations, m)
+}
+
+// All 全部迁移（Go 迁移与嵌入的 SQL 迁移）
+func All() ([]migrate.Migration, error) {
+	sqlMigrations, err := migrate.LoadFS(sqlFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]migrate.Migration(nil), goMigrations...), sqlMigrations...), nil
+}
+
+// New 使用全部迁移创建迁移执行器
+func New(db *gorm.DB) (*migrate.Migrator, error) {
+	ms, err := All()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, ms)
+}
+
+// Up 执行全部未执行的迁移，用于启动时自动迁移
+func Up(ctx context.Context, db *gorm.DB, log func(format string, args ...any)) ([]migrate.Migration, error) {
+	m, err := New(db)
+	if err != nil {
+		return nil, err
+	}
+	m.Log = log
+	return m.Up(ctx)
+}
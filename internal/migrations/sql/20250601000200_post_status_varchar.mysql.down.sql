@@ -0,0 +1,2 @@
+ALTER TABLE posts MODIFY status enum('draft','published','pending','trash') DEFAULT 'draft' COMMENT '状态';
+ALTER TABLE posts MODIFY comment_status enum('open','closed') DEFAULT 'open' COMMENT '评论状态';
@@ -0,0 +1,3 @@
+-- posts 的状态列由 MySQL 专有的 enum 改为 varchar，与 PostgreSQL、SQLite 使用相同的列类型
+ALTER TABLE posts MODIFY status varchar(16) DEFAULT 'draft' COMMENT '状态 draft/published/pending/trash';
+ALTER TABLE posts MODIFY comment_status varchar(16) DEFAULT 'open' COMMENT '评论状态 open/closed';
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// PostFeature 精华/推荐记录
+type PostFeature struct {
+	Model
+	PostID     uint       `gorm:"not null;uniqueIndex;comment:帖子ID" json:"post_id"`
+	Post       *Post      `gorm:"foreignKey:PostID" json:"post,omitempty"`
+	OperatorID uint       `gorm:"not null;comment:操作人ID" json:"operator_id"`
+	Reason     string     `gorm:"size:512;comment:加精理由" json:"reason"`
+	ExpireAt   *time.Time `gorm:"index;comment:过期时间 为空则永久" json:"expire_at"`
+}
+
+// TableName 精华记录表名
+func (f *PostFeature) TableName() string {
+	return "post_features"
+}
+
+// Active 判断精华是否仍在有效期内
+func (f *PostFeature) Active(now time.Time) bool {
+	return f.ExpireAt == nil || f.ExpireAt.After(now)
+}
+
+// Collection 专题（有序的帖子合集）
+type Collection struct {
+	Model
+	Title       string           `gorm:"size:256;not null;comment:专题标题" json:"title"`
+	Description string           `gorm:"size:2048;comment:专题描述" json:"description"`
+	CreatorID   uint             `gorm:"not null;comment:创建人ID" json:"creator_id"`
+	Items       []CollectionItem `gorm:"foreignKey:CollectionID" json:"items,omitempty"`
+}
+
+// TableName 专题表名
+func (c *Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionItem 专题中的帖子及其顺序
+type CollectionItem struct {
+	ID           uint  `gorm:"primaryKey;autoIncrement" json:"id"`
+	CollectionID uint  `gorm:"not null;uniqueIndex:idx_collection_post;index:idx_collection_sort;comment:专题ID" json:"collection_id"`
+	PostID       uint  `gorm:"not null;uniqueIndex:idx_collection_post;comment:帖子ID" json:"post_id"`
+	Post         *Post `gorm:"foreignKey:PostID" json:"post,omitempty"`
+	Sort         int   `gorm:"not null;default:0;index:idx_collection_sort;comment:排序" json:"sort"`
+}
+
+// TableName 专题条目表名
+func (i *CollectionItem) TableName() string {
+	return "collection_items"
+}
@@ -1,11 +1,12 @@
 package model
 
+import "qwqserver/internal/common"
+
 type HTTPResult interface {
 	Error() error
 }
 
-type Result struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
-}
+// Result 统一响应
+//
+// Deprecated: 使用 common.HTTPResult，两者已统一为同一类型（消息字段为 msg）
+type Result = common.HTTPResult
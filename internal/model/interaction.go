@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// PostLike 用户点赞记录
+type PostLike struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_like_user_post;comment:用户ID" json:"user_id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_like_user_post;index;comment:帖子ID" json:"post_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:点赞时间" json:"created_at"`
+}
+
+// TableName 点赞记录表名
+func (l *PostLike) TableName() string {
+	return "post_likes"
+}
+
+// Bookmark 用户收藏记录
+type Bookmark struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_bookmark_user_post;comment:用户ID" json:"user_id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_bookmark_user_post;index;comment:帖子ID" json:"post_id"`
+	Post      *Post     `gorm:"foreignKey:PostID" json:"post,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:收藏时间" json:"created_at"`
+}
+
+// TableName 收藏记录表名
+func (b *Bookmark) TableName() string {
+	return "bookmarks"
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Model 可软删除模型的公共字段，取代 gorm.Model
+// DeletedAt 是唯一的删除标记：非空表示记录在回收站中，普通查询自动排除，可以恢复或彻底删除
+type Model struct {
+	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index;comment:删除时间 非空表示在回收站中" json:"deleted_at,omitempty"`
+}
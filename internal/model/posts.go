@@ -1,26 +1,26 @@
 package model
 
-import (
-	"gorm.io/gorm"
-	"time"
-)
+import "time"
 
 // Post 帖子模型
 type Post struct {
-	gorm.Model
-	// ID            uint64     `gorm:"primaryKey;autoIncrement;comment:帖子ID"`
+	Model
 	AuthorID      uint64     `gorm:"not null;column:author_id;comment:作者ID" json:"author_id"`
 	Author        User       `gorm:"foreignKey:AuthorID;references:ID" json:"author"`
-	Mod           string     `gorm:"size:1024;not null;comment:内容模型" json:"mod"`
-	Title         string     `gorm:"size:1024;comment:标题" json:"title"`
-	Content       string     `gorm:"type:longtext;comment:内容" json:"content"`
-	Status        string     `gorm:"type:enum('draft','published','pending','trash');default:'draft';comment:状态" json:"status"`
+	BoardID       uint       `gorm:"index;not null;default:0;comment:版块ID 0为未分版块" json:"board_id"`
+	Mod           string     `gorm:"size:1024;not null;comment:内容模型 markdown/plain/html" json:"mod"`
+	Title         string     `gorm:"size:1024;comment:标题" json:"title" binding:"required,max=1024"`
+	Content       string     `gorm:"comment:内容" json:"content" binding:"required"`
+	Status        string     `gorm:"size:16;default:'draft';comment:状态 draft/published/pending" json:"status"`
 	IsSticky      bool       `gorm:"default:false;comment:是否置顶" json:"is_sticky"`
-	CommentStatus string     `gorm:"type:enum('open','closed');default:'open';comment:评论状态" json:"comment_status"`
-	CreatedAt     time.Time  `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
-	UpdatedAt     time.Time  `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
+	CommentStatus string     `gorm:"size:16;default:'open';comment:评论状态 open/closed" json:"comment_status"`
 	PublishedAt   *time.Time `gorm:"comment:发布时间" json:"published_at"`
-	IsDeleted     bool       `gorm:"default:false;comment:删除标记" json:"is_deleted"`
+	ViewCount     int64      `gorm:"not null;default:0;comment:浏览量" json:"view_count"`
+	LikeCount     int64      `gorm:"not null;default:0;comment:点赞数" json:"like_count"`
+	CommentCount  int64      `gorm:"not null;default:0;comment:评论数" json:"comment_count"`
+	BookmarkCount int64      `gorm:"not null;default:0;comment:收藏数" json:"bookmark_count"`
+	Version       int64      `gorm:"not null;default:1;comment:乐观锁版本号 每次编辑递增" json:"version"`
+	Tags          []Tag      `gorm:"many2many:post_tags;" json:"tags,omitempty"`
 }
 
 // TableName 帖子模型
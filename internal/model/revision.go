@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// PostRevision 帖子修订记录，保存每次编辑后的完整快照
+// Version 从 1 开始递增，版本 1 为首次编辑前的原始内容
+type PostRevision struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_revision_post_version;comment:帖子ID" json:"post_id"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_revision_post_version;comment:版本号" json:"version"`
+	Title     string    `gorm:"size:1024;comment:标题" json:"title"`
+	Content   string    `gorm:"comment:内容" json:"content,omitempty"`
+	Mod       string    `gorm:"size:1024;comment:内容模型" json:"mod"`
+	EditorID  uint      `gorm:"not null;default:0;comment:编辑者ID 0为未知" json:"editor_id"`
+	Summary   string    `gorm:"size:255;comment:修订说明" json:"summary"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:修订时间" json:"created_at"`
+}
+
+// TableName 帖子修订记录表名
+func (r *PostRevision) TableName() string {
+	return "post_revisions"
+}
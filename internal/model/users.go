@@ -1,29 +1,22 @@
 package model
 
-import (
-	"gorm.io/gorm"
-	"time"
-)
+import "time"
 
 type User struct {
-	gorm.Model
-	//ID       uint   `gorm:"primaryKey;autoIncrement;comment:用户唯一标识符" json:"id"`
+	Model
 	Username string `gorm:"type:varchar(128);uniqueIndex;not null;comment:用户名" json:"username"`
 	Nickname string `gorm:"type:varchar(1024);default:'新用户';comment:用户昵称" json:"nickname"`
 	Email    string `gorm:"type:varchar(128);uniqueIndex;comment:邮箱地址" json:"email"`
 
-	Password          string     `gorm:"type:varchar(1024);not null;comment:密码" json:"-"`
-	PasswordHash      string     `gorm:"type:varchar(1024);not null;comment:密码哈希" json:"-"`
-	PasswordSalt      string     `gorm:"type:varchar(1024);not null;comment:密码盐值" json:"-"`
-	Iterations        int        `gorm:"default:10000;comment:哈希迭代次数" json:"-"`
+	Password          string     `gorm:"type:varchar(1024);not null;comment:密码哈希 自描述格式 $算法$参数$..." json:"-"`
+	PasswordChangedAt *time.Time `gorm:"comment:密码修改时间 为空时按注册时间;default:NULL" json:"-"`
 	FailedAttempts    int        `gorm:"default:0;comment:登录失败次数" json:"failed_attempts"`
 	LastLoginAt       *time.Time `gorm:"comment:上次登录时间;default:NULL" json:"last_login_at,omitempty"`
 	LastFailedAttempt *time.Time `gorm:"comment:上次登录失败时间;default:NULL" json:"last_failed_attempt,omitempty"`
-	Perms             uint64     `gorm:"type:BIGINT UNSIGNED;default:0;comment:权限位掩码" json:"perms"`
+	Perms             uint64     `gorm:"default:0;comment:权限位掩码" json:"perms"`
 	Status            uint8      `gorm:"default:1;comment:状态 1=正常" json:"status"`
-	CreatedAt         time.Time  `gorm:"autoCreateTime;comment:注册时间" json:"created_at"`
-	UpdatedAt         time.Time  `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
 	IpAddress         string     `gorm:"type:varchar(1024);comment:用户IP" json:"ip_address"`
+	Locale            string     `gorm:"type:varchar(16);default:'';comment:界面语言偏好 为空时按 Accept-Language" json:"locale"`
 }
 
 // RegisterAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;comment:注册时间"`
@@ -32,3 +25,16 @@ type User struct {
 func (u *User) TableName() string {
 	return "users"
 }
+
+// PasswordHistory 用户使用过的密码哈希，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;index;comment:用户ID" json:"user_id"`
+	Hash      string    `gorm:"type:varchar(255);not null;comment:密码哈希" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:设置时间" json:"created_at"`
+}
+
+// TableName 密码历史表名
+func (h *PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -0,0 +1,406 @@
+// Package ranking 基于 Redis 有序集合的热门帖子排行
+//
+// Redis 中维护：
+//   - post_hot:points       未衰减的互动得分，随点赞/浏览等事件增量更新
+//   - post_hot:meta         帖子的发布时间与版块，用于计算衰减和版块榜单
+//   - post_hot:global       全站热度榜（衰减后的分数）
+//   - post_hot:board:{id}   版块热度榜
+//
+// 事件发生时立即重算该帖子的热度；后台协程按间隔重算所有帖子的衰减热度并清理超出时间窗口的帖子。
+// Redis 不可用时由调用方退回 PostRepository.ListPopular 的 SQL 计算。
+package ranking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/hotrank"
+)
+
+// Redis 键名
+const (
+	keyPoints      = "post_hot:points"
+	keyMeta        = "post_hot:meta"
+	keyGlobal      = "post_hot:global"
+	keyBoardPrefix = "post_hot:board:"
+)
+
+// scanBatch 重算时每批处理的帖子数
+const scanBatch = 500
+
+// Config 热门排行配置
+type Config struct {
+	RecomputeInterval time.Duration // 衰减重算间隔
+	Window            time.Duration // 参与排序的帖子时间窗口
+}
+
+// Logger 日志接口
+type Logger interface {
+	Error(msg string, args ...any)
+}
+
+// Ranker 热门排行
+type Ranker struct {
+	redis *redis.Client
+	posts repository.PostRepository
+	cfg   Config
+	log   Logger
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// New 创建排行并启动后台重算协程，redisClient 为 nil 时排行不可用
+func New(redisClient *redis.Client, posts repository.PostRepository, cfg Config, l Logger) *Ranker {
+	if cfg.RecomputeInterval <= 0 {
+		cfg.RecomputeInterval = 5 * time.Minute
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 30 * 24 * time.Hour
+	}
+	r := &Ranker{
+		redis: redisClient,
+		posts: posts,
+		cfg:   cfg,
+		log:   l,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if redisClient != nil {
+		go r.run()
+	} else {
+		close(r.done)
+	}
+	return r
+}
+
+// Available 排行是否可用
+func (r *Ranker) Available() bool {
+	return r != nil && r.redis != nil
+}
+
+// Close 停止后台协程
+func (r *Ranker) Close() {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+	})
+}
+
+// meta 帖子排序元数据
+type meta struct {
+	createdAt time.Time
+	boardID   uint
+}
+
+func (m meta) String() string {
+	return fmt.Sprintf("%d:%d", m.createdAt.Unix(), m.boardID)
+}
+
+func parseMeta(s string) (meta, bool) {
+	created, board, ok := strings.Cut(s, ":")
+	if !ok {
+		return meta{}, false
+	}
+	unix, err1 := strconv.ParseInt(created, 10, 64)
+	boardID, err2 := strconv.ParseUint(board, 10, 64)
+	if err1 != nil || err2 != nil {
+		return meta{}, false
+	}
+	return meta{createdAt: time.Unix(unix, 0), boardID: uint(boardID)}, true
+}
+
+func member(postID uint) string {
+	return strconv.FormatUint(uint64(postID), 10)
+}
+
+func boardKey(boardID uint) string {
+	return keyBoardPrefix + strconv.FormatUint(uint64(boardID), 10)
+}
+
+// Touch 登记或刷新帖子，按帖子当前计数重置互动得分
+func (r *Ranker) Touch(ctx context.Context, post *model.Post) error {
+	if !r.Available() || post == nil {
+		return nil
+	}
+	if post.Status != "published" || post.DeletedAt.Valid {
+		return r.Remove(ctx, post.ID, post.BoardID)
+	}
+
+	params := hotrank.Default()
+	m := meta{createdAt: post.CreatedAt, boardID: post.BoardID}
+	points := params.Points(hotrank.Counts{
+		Likes:     post.LikeCount,
+		Comments:  post.CommentCount,
+		Views:     post.ViewCount,
+		Bookmarks: post.BookmarkCount,
+	})
+	score := params.Decay(points, m.createdAt, time.Now())
+
+	id := member(post.ID)
+	pipe := r.redis.TxPipeline()
+	// 版块变更时从旧版块榜单移除
+	if raw, err := r.redis.HGet(ctx, keyMeta, id).Result(); err == nil {
+		if old, ok := parseMeta(raw); ok && old.boardID != m.boardID {
+			pipe.ZRem(ctx, boardKey(old.boardID), id)
+		}
+	}
+	pipe.HSet(ctx, keyMeta, id, m.String())
+	pipe.ZAdd(ctx, keyPoints, redis.Z{Score: points, Member: id})
+	pipe.ZAdd(ctx, keyGlobal, redis.Z{Score: score, Member: id})
+	pipe.ZAdd(ctx, boardKey(m.boardID), redis.Z{Score: score, Member: id})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("更新帖子热度失败: %w", err)
+	}
+	return nil
+}
+
+// Incr 按事件增量更新帖子热度，kind 取值见 hotrank.KindXxx
+func (r *Ranker) Incr(ctx context.Context, postID uint, kind string, delta int64) error {
+	if !r.Available() {
+		return nil
+	}
+	weight := hotrank.Default().Weight(kind)
+	if weight == 0 || delta == 0 {
+		return nil
+	}
+
+	id := member(postID)
+	raw, err := r.redis.HGet(ctx, keyMeta, id).Result()
+	if errors.Is(err, redis.Nil) {
+		// 首次出现的帖子从数据库登记
+		if err := r.touchFromDB(ctx, postID); err != nil {
+			return err
+		}
+		raw, err = r.redis.HGet(ctx, keyMeta, id).Result()
+		if errors.Is(err, redis.Nil) {
+			// 帖子未发布或已删除
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("读取帖子热度元数据失败: %w", err)
+	}
+	m, ok := parseMeta(raw)
+	if !ok {
+		return fmt.Errorf("帖子热度元数据格式错误: %s", raw)
+	}
+	if time.Since(m.createdAt) > r.cfg.Window {
+		return nil
+	}
+
+	points, err := r.redis.ZIncrBy(ctx, keyPoints, weight*float64(delta), id).Result()
+	if err != nil {
+		return fmt.Errorf("更新帖子互动得分失败: %w", err)
+	}
+	score := hotrank.Default().Decay(points, m.createdAt, time.Now())
+
+	pipe := r.redis.TxPipeline()
+	pipe.ZAdd(ctx, keyGlobal, redis.Z{Score: score, Member: id})
+	pipe.ZAdd(ctx, boardKey(m.boardID), redis.Z{Score: score, Member: id})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("更新帖子热度失败: %w", err)
+	}
+	return nil
+}
+
+// Remove 从所有榜单中移除帖子
+func (r *Ranker) Remove(ctx context.Context, postID, boardID uint) error {
+	if !r.Available() {
+		return nil
+	}
+	id := member(postID)
+	pipe := r.redis.TxPipeline()
+	pipe.HDel(ctx, keyMeta, id)
+	pipe.ZRem(ctx, keyPoints, id)
+	pipe.ZRem(ctx, keyGlobal, id)
+	pipe.ZRem(ctx, boardKey(boardID), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("移除帖子热度失败: %w", err)
+	}
+	return nil
+}
+
+// Top 获取热度最高的帖子ID，boardID 为 nil 时取全站榜，仅返回 days 天内发布的帖子
+func (r *Ranker) Top(ctx context.Context, boardID *uint, days, limit int) ([]uint, error) {
+	if !r.Available() {
+		return nil, errors.New("热门排行不可用")
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	key := keyGlobal
+	if boardID != nil {
+		key = boardKey(*boardID)
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	ids := make([]uint, 0, limit)
+	batch := int64(limit * 2)
+	for start := int64(0); len(ids) < limit; start += batch {
+		members, err := r.redis.ZRevRange(ctx, key, start, start+batch-1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("读取热门榜单失败: %w", err)
+		}
+		if len(members) == 0 {
+			break
+		}
+		metas, err := r.redis.HMGet(ctx, keyMeta, members...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("读取帖子热度元数据失败: %w", err)
+		}
+		for i, v := range members {
+			raw, _ := metas[i].(string)
+			m, ok := parseMeta(raw)
+			if !ok || m.createdAt.Before(since) {
+				continue
+			}
+			id, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			ids = append(ids, uint(id))
+			if len(ids) == limit {
+				break
+			}
+		}
+		if int64(len(members)) < batch {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// Recompute 重算所有帖子的衰减热度，并移除超出时间窗口的帖子
+func (r *Ranker) Recompute(ctx context.Context) error {
+	if !r.Available() {
+		return nil
+	}
+	params := hotrank.Default()
+	now := time.Now()
+
+	var cursor uint64
+	for {
+		values, next, err := r.redis.ZScan(ctx, keyPoints, cursor, "", scanBatch).Result()
+		if err != nil {
+			return fmt.Errorf("扫描帖子互动得分失败: %w", err)
+		}
+
+		// ZSCAN 结果为 member, score 交替排列
+		members := make([]string, 0, len(values)/2)
+		points := make([]float64, 0, len(values)/2)
+		for i := 0; i+1 < len(values); i += 2 {
+			p, err := strconv.ParseFloat(values[i+1], 64)
+			if err != nil {
+				continue
+			}
+			members = append(members, values[i])
+			points = append(points, p)
+		}
+
+		if len(members) > 0 {
+			metas, err := r.redis.HMGet(ctx, keyMeta, members...).Result()
+			if err != nil {
+				return fmt.Errorf("读取帖子热度元数据失败: %w", err)
+			}
+
+			pipe := r.redis.Pipeline()
+			for i, id := range members {
+				raw, _ := metas[i].(string)
+				m, ok := parseMeta(raw)
+				if !ok || now.Sub(m.createdAt) > r.cfg.Window {
+					pipe.HDel(ctx, keyMeta, id)
+					pipe.ZRem(ctx, keyPoints, id)
+					pipe.ZRem(ctx, keyGlobal, id)
+					if ok {
+						pipe.ZRem(ctx, boardKey(m.boardID), id)
+					}
+					continue
+				}
+				score := params.Decay(points[i], m.createdAt, now)
+				pipe.ZAdd(ctx, keyGlobal, redis.Z{Score: score, Member: id})
+				pipe.ZAdd(ctx, boardKey(m.boardID), redis.Z{Score: score, Member: id})
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("写入衰减热度失败: %w", err)
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Rebuild 从数据库重建时间窗口内已发布帖子的热度
+func (r *Ranker) Rebuild(ctx context.Context) error {
+	if !r.Available() {
+		return nil
+	}
+	days := int(r.cfg.Window.Hours()/24) + 1
+	for page := 1; ; page++ {
+		posts, _, err := r.posts.ListPublishedSince(ctx, time.Now().AddDate(0, 0, -days), page, scanBatch)
+		if err != nil {
+			return err
+		}
+		for _, p := range posts {
+			if err := r.Touch(ctx, p); err != nil {
+				return err
+			}
+		}
+		if len(posts) < scanBatch {
+			return nil
+		}
+	}
+}
+
+// touchFromDB 从数据库读取帖子并登记
+func (r *Ranker) touchFromDB(ctx context.Context, postID uint) error {
+	post, err := r.posts.FindByID(ctx, postID)
+	if err != nil || post == nil {
+		return err
+	}
+	return r.Touch(ctx, post)
+}
+
+// run 启动时重建榜单，之后按间隔重算衰减热度
+func (r *Ranker) run() {
+	defer close(r.done)
+
+	if err := r.Rebuild(context.Background()); err != nil {
+		r.logError("重建热门榜单失败: %v", err)
+	}
+
+	ticker := time.NewTicker(r.cfg.RecomputeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Recompute(context.Background()); err != nil {
+				r.logError("重算热门榜单失败: %v", err)
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *Ranker) logError(msg string, args ...any) {
+	if r.log != nil {
+		r.log.Error(msg, args...)
+	}
+}
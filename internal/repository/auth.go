@@ -3,11 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
-	"fmt"
-	"github.com/go-redis/redis/v8"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"qwqserver/internal/model"
-	"qwqserver/pkg/database"
 	"time"
 )
 
@@ -75,21 +73,13 @@ func (r *authRepository) RevokeAccessToken(ctx context.Context, token string) er
 }
 
 // NewAuthRepository 创建新的认证仓库
-func NewAuthRepository() (AuthRepository, error) {
-	db, err := database.GetDB()
-	if err != nil {
-		return nil, fmt.Errorf("获取数据库连接失败: %w", err)
-	}
-	return &authRepository{db: db}, nil
+func NewAuthRepository(db *gorm.DB, rdb *redis.Client) AuthRepository {
+	return &authRepository{db: db, cache: rdb}
 }
 
 // Authenticate 用户认证
 func (r *authRepository) Authenticate(ctx context.Context, email, password string) (*model.User, error) {
-	userRepo, err := NewUserRepository()
-	if err != nil {
-		return nil, err
-	}
-
+	userRepo := NewUserRepository(r.db, r.cache)
 	user, err := userRepo.FindByEmail(ctx, email)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"qwqserver/internal/model"
+	"time"
+)
+
+// FeatureRepository 精华内容与专题仓库接口
+type FeatureRepository interface {
+	WithTransaction(ctx context.Context, fn func(repo FeatureRepository) error) error
+
+	// --------- 精华 相关操作 --------- //
+
+	// Feature 设置精华（已存在则更新理由与过期时间）
+	Feature(ctx context.Context, feature *model.PostFeature) error
+
+	// Unfeature 取消精华
+	Unfeature(ctx context.Context, postID uint) error
+
+	// FindFeature 获取帖子的精华记录，不存在返回 nil
+	FindFeature(ctx context.Context, postID uint) (*model.PostFeature, error)
+
+	// ListFeatured 获取有效期内的精华帖子（按加精时间倒序）
+	ListFeatured(ctx context.Context, page, pageSize int) ([]*model.PostFeature, int64, error)
+
+	// --------- 专题 相关操作 --------- //
+
+	// CreateCollection 创建专题
+	CreateCollection(ctx context.Context, collection *model.Collection) error
+
+	// UpdateCollection 更新专题信息
+	UpdateCollection(ctx context.Context, collection *model.Collection) error
+
+	// DeleteCollection 删除专题及其条目
+	DeleteCollection(ctx context.Context, id uint) error
+
+	// FindCollection 获取专题及按顺序排列的帖子
+	FindCollection(ctx context.Context, id uint) (*model.Collection, error)
+
+	// ListCollections 获取专题列表（不含条目）
+	ListCollections(ctx context.Context, page, pageSize int) ([]*model.Collection, int64, error)
+
+	// SetCollectionPosts 按给定顺序重置专题中的帖子
+	SetCollectionPosts(ctx context.Context, collectionID uint, postIDs []uint) error
+}
+
+// featureRepository 精华内容仓库实现
+type featureRepository struct {
+	*BaseRepository[model.PostFeature]
+}
+
+// NewFeatureRepository 创建新的精华内容仓库
+func NewFeatureRepository(db *gorm.DB, rdb *redis.Client) FeatureRepository {
+	return &featureRepository{BaseRepository: NewBaseRepository[model.PostFeature](db, rdb)}
+}
+
+// WithTransaction 在事务中执行精华内容操作
+func (r *featureRepository) WithTransaction(ctx context.Context, fn func(repo FeatureRepository) error) error {
+	return r.BaseRepository.WithTransaction(ctx, func(txRepo *BaseRepository[model.PostFeature]) error {
+		return fn(&featureRepository{BaseRepository: txRepo})
+	})
+}
+
+// Feature 设置精华
+func (r *featureRepository) Feature(ctx context.Context, feature *model.PostFeature) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"operator_id", "reason", "expire_at", "updated_at", "deleted_at"}),
+	}).Create(feature).Error
+	if err != nil {
+		return fmt.Errorf("设置精华失败: %w", err)
+	}
+	return nil
+}
+
+// Unfeature 取消精华
+func (r *featureRepository) Unfeature(ctx context.Context, postID uint) error {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("post_id = ?", postID).
+		Delete(&model.PostFeature{})
+	if result.Error != nil {
+		return fmt.Errorf("取消精华失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("帖子未被设置为精华: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// FindFeature 获取帖子的精华记录
+func (r *featureRepository) FindFeature(ctx context.Context, postID uint) (*model.PostFeature, error) {
+	return r.First(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("post_id = ?", postID)
+	})
+}
+
+// activeFeatures 有效期内且帖子已发布的精华记录
+func activeFeatures(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Model(&model.PostFeature{}).
+		Joins("JOIN posts ON posts.id = post_features.post_id AND posts.deleted_at IS NULL").
+		Where("posts.status = ?", "published").
+		Where("post_features.expire_at IS NULL OR post_features.expire_at > ?", now)
+}
+
+// ListFeatured 获取有效期内的精华帖子
+func (r *featureRepository) ListFeatured(ctx context.Context, page, pageSize int) ([]*model.PostFeature, int64, error) {
+	now := time.Now()
+	features, total, err := findPage[model.PostFeature](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return activeFeatures(db, now)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Post").Order("post_features.updated_at DESC")
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询精华帖子失败: %w", err)
+	}
+	return features, total, nil
+}
+
+// CreateCollection 创建专题
+func (r *featureRepository) CreateCollection(ctx context.Context, collection *model.Collection) error {
+	if err := r.db.WithContext(ctx).Omit("Items").Create(collection).Error; err != nil {
+		return fmt.Errorf("创建专题失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateCollection 更新专题信息
+func (r *featureRepository) UpdateCollection(ctx context.Context, collection *model.Collection) error {
+	result := r.db.WithContext(ctx).Model(collection).
+		Select("title", "description").
+		Updates(collection)
+	if result.Error != nil {
+		return fmt.Errorf("更新专题失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("专题不存在: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteCollection 删除专题及其条目
+func (r *featureRepository) DeleteCollection(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&model.CollectionItem{}).Error; err != nil {
+			return fmt.Errorf("删除专题条目失败: %w", err)
+		}
+		result := tx.Delete(&model.Collection{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("删除专题失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("专题不存在: %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// FindCollection 获取专题及按顺序排列的帖子，只包含已发布的帖子
+func (r *featureRepository) FindCollection(ctx context.Context, id uint) (*model.Collection, error) {
+	var collection model.Collection
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort ASC, id ASC")
+		}).
+		Preload("Items.Post", "status = ?", "published").
+		First(&collection, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查找专题失败: %w", err)
+	}
+
+	// 加入专题后被删除、撤回或移入回收站的帖子不再展示
+	items := collection.Items[:0]
+	for _, item := range collection.Items {
+		if item.Post != nil {
+			items = append(items, item)
+		}
+	}
+	collection.Items = items
+	return &collection, nil
+}
+
+// ListCollections 获取专题列表
+func (r *featureRepository) ListCollections(ctx context.Context, page, pageSize int) ([]*model.Collection, int64, error) {
+	collections, total, err := findPage[model.Collection](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return db
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询专题列表失败: %w", err)
+	}
+	return collections, total, nil
+}
+
+// SetCollectionPosts 按给定顺序重置专题中的帖子
+func (r *featureRepository) SetCollectionPosts(ctx context.Context, collectionID uint, postIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Collection{}).Where("id = ?", collectionID).Count(&count).Error; err != nil {
+			return fmt.Errorf("检查专题存在失败: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("专题不存在: %w", ErrNotFound)
+		}
+
+		if err := tx.Where("collection_id = ?", collectionID).Delete(&model.CollectionItem{}).Error; err != nil {
+			return fmt.Errorf("清空专题条目失败: %w", err)
+		}
+
+		// 去重并保留首次出现的位置
+		seen := make(map[uint]struct{}, len(postIDs))
+		items := make([]model.CollectionItem, 0, len(postIDs))
+		for _, postID := range postIDs {
+			if _, ok := seen[postID]; ok {
+				continue
+			}
+			seen[postID] = struct{}{}
+			items = append(items, model.CollectionItem{
+				CollectionID: collectionID,
+				PostID:       postID,
+				Sort:         len(items),
+			})
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return fmt.Errorf("写入专题条目失败: %w", err)
+		}
+		return nil
+	})
+}
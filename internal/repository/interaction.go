@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"qwqserver/internal/model"
+)
+
+// InteractionRepository 点赞与收藏仓库接口
+// Like/Unlike/Bookmark/Unbookmark 均为幂等操作，返回值表示本次调用是否实际改变了状态，
+// 调用方仅在状态改变时累加计数
+type InteractionRepository interface {
+	// Like 点赞
+	Like(ctx context.Context, userID, postID uint) (bool, error)
+
+	// Unlike 取消点赞
+	Unlike(ctx context.Context, userID, postID uint) (bool, error)
+
+	// IsLiked 是否已点赞
+	IsLiked(ctx context.Context, userID, postID uint) (bool, error)
+
+	// Bookmark 收藏
+	Bookmark(ctx context.Context, userID, postID uint) (bool, error)
+
+	// Unbookmark 取消收藏
+	Unbookmark(ctx context.Context, userID, postID uint) (bool, error)
+
+	// IsBookmarked 是否已收藏
+	IsBookmarked(ctx context.Context, userID, postID uint) (bool, error)
+
+	// ListBookmarks 获取用户收藏列表（按收藏时间倒序）
+	ListBookmarks(ctx context.Context, userID uint, page, pageSize int) ([]*model.Bookmark, int64, error)
+}
+
+// interactionRepository 点赞与收藏仓库实现
+type interactionRepository struct {
+	db *gorm.DB
+}
+
+// NewInteractionRepository 创建新的点赞与收藏仓库
+func NewInteractionRepository(db *gorm.DB) InteractionRepository {
+	return &interactionRepository{db: db}
+}
+
+// Like 点赞
+func (r *interactionRepository) Like(ctx context.Context, userID, postID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.PostLike{UserID: userID, PostID: postID})
+	if result.Error != nil {
+		return false, fmt.Errorf("点赞失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Unlike 取消点赞
+func (r *interactionRepository) Unlike(ctx context.Context, userID, postID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Delete(&model.PostLike{})
+	if result.Error != nil {
+		return false, fmt.Errorf("取消点赞失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// IsLiked 是否已点赞
+func (r *interactionRepository) IsLiked(ctx context.Context, userID, postID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.PostLike{}).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("查询点赞状态失败: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Bookmark 收藏
+func (r *interactionRepository) Bookmark(ctx context.Context, userID, postID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Omit("Post").
+		Create(&model.Bookmark{UserID: userID, PostID: postID})
+	if result.Error != nil {
+		return false, fmt.Errorf("收藏失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Unbookmark 取消收藏
+func (r *interactionRepository) Unbookmark(ctx context.Context, userID, postID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Delete(&model.Bookmark{})
+	if result.Error != nil {
+		return false, fmt.Errorf("取消收藏失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// IsBookmarked 是否已收藏
+func (r *interactionRepository) IsBookmarked(ctx context.Context, userID, postID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Bookmark{}).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("查询收藏状态失败: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ListBookmarks 获取用户收藏列表
+func (r *interactionRepository) ListBookmarks(ctx context.Context, userID uint, page, pageSize int) ([]*model.Bookmark, int64, error) {
+	bookmarks, total, err := findPage[model.Bookmark](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userID)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Post").Order("created_at DESC")
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询收藏列表失败: %w", err)
+	}
+	return bookmarks, total, nil
+}
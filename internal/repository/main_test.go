@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"qwqserver/internal/migrations"
+	"qwqserver/pkg/database"
+)
+
+// testRepos 测试共用的仓库，连接由 TestMain 创建
+var testRepos *Repositories
+
+// 仓库测试默认使用进程内的 SQLite，设置以下环境变量可在 MySQL 或 PostgreSQL 上运行同一组测试
+// （需使用空数据库，测试会执行迁移并写入数据）：
+//
+//	QWQ_TEST_DB_DRIVER=postgres QWQ_TEST_DB_DSN="host=localhost user=qwq dbname=qwq_test" go test ./internal/repository
+func TestMain(m *testing.M) {
+	os.Exit(runWithDB(m))
+}
+
+func runWithDB(m *testing.M) int {
+	driver, dsn := os.Getenv("QWQ_TEST_DB_DRIVER"), os.Getenv("QWQ_TEST_DB_DSN")
+	if driver == "" {
+		dir, err := os.MkdirTemp("", "qwq-repository")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		driver, dsn = "sqlite", filepath.Join(dir, "test.db")+"?_busy_timeout=5000&_foreign_keys=on"
+	}
+
+	conn, err := database.Open(&database.Config{
+		Driver:          driver,
+		DSN:             dsn,
+		LogLevel:        "silent",
+		MaxOpenConns:    4,
+		MaxIdleConns:    4,
+		ConnMaxLifetime: time.Hour,
+		ConnMaxIdleTime: time.Hour,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "数据库初始化失败:", err)
+		return 1
+	}
+	defer conn.Close()
+	if _, err := migrations.Up(context.Background(), conn.DB(), nil); err != nil {
+		fmt.Fprintln(os.Stderr, "数据库迁移失败:", err)
+		return 1
+	}
+	testRepos = New(conn.DB(), nil)
+	return m.Run()
+}
@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 分页默认值与上限
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListParamError 列表查询参数错误，Values 非空时表示参数只能取其中之一
+type ListParamError struct {
+	Param  string
+	Values []string
+}
+
+func (e *ListParamError) Error() string {
+	if len(e.Values) > 0 {
+		return fmt.Sprintf("参数 %s 只能是 %s 之一", e.Param, strings.Join(e.Values, ", "))
+	}
+	return fmt.Sprintf("参数 %s 错误", e.Param)
+}
+
+// FilterField 允许过滤的字段
+type FilterField struct {
+	Column string                      // 列名
+	Values []string                    // 允许的取值，为空时不限
+	Parse  func(s string) (any, error) // 把参数转换为列的值，为 nil 时按字符串比较
+}
+
+// ListSpec 列表允许的过滤与排序字段，查询参数中不在白名单内的字段被忽略（sort 中的除外）
+type ListSpec struct {
+	Filters         map[string]FilterField // 查询参数名 → 过滤字段
+	Sorts           map[string]string      // 排序名 → 列名，用于游标分页的列不能为 NULL
+	DefaultSort     string                 // 未指定 sort 时的排序，格式同 sort 参数
+	DefaultPageSize int                    // 为 0 时使用 DefaultPageSize
+	MaxPageSize     int                    // 为 0 时使用 MaxPageSize
+}
+
+// Filter 过滤条件，有多个取值时匹配其中任意一个
+type Filter struct {
+	Param  string
+	Column string
+	Values []any
+}
+
+// Sort 排序字段
+type Sort struct {
+	Param  string // 排序名，出现在游标中
+	Column string
+	Desc   bool
+}
+
+// ListQuery 列表查询：过滤、排序与分页，由 ParseListQuery 从查询参数解析
+//
+// 偏移分页使用 page 与 page_size；带有 cursor 参数时使用游标分页，第一页传空的 cursor，
+// 之后传上一页响应中的 next_cursor。游标分页不统计总数，翻页时不会因新增记录而重复或遗漏
+type ListQuery struct {
+	Filters  []Filter
+	Sorts    []Sort // 末尾总是 id，保证顺序唯一
+	Page     int
+	PageSize int
+	Keyset   bool   // 使用游标分页
+	Cursor   string // 游标分页的起点，为空时从第一条开始
+}
+
+// ParseListQuery 按白名单解析查询参数：
+//
+//	?status=published&board_id=1,2&sort=-created_at,id&page=2&page_size=20
+//	?status=published&sort=-created_at&cursor=&page_size=20
+//
+// 同一过滤参数的多个取值用逗号分隔；sort 中字段前加 - 表示降序
+func ParseListQuery(values url.Values, spec ListSpec) (*ListQuery, error) {
+	q := &ListQuery{Page: 1, PageSize: spec.DefaultPageSize}
+	maxSize := spec.MaxPageSize
+	if maxSize <= 0 {
+		maxSize = MaxPageSize
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = min(DefaultPageSize, maxSize)
+	}
+	if page, err := strconv.Atoi(values.Get("page")); err == nil && page > 0 {
+		q.Page = page
+	}
+	if size, err := strconv.Atoi(values.Get("page_size")); err == nil && size > 0 {
+		q.PageSize = min(size, maxSize)
+	}
+	if values.Has("cursor") {
+		q.Keyset, q.Cursor = true, values.Get("cursor")
+	}
+
+	sortParam := values.Get("sort")
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	if err := q.parseSort(sortParam, spec.Sorts); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(spec.Filters))
+	for name := range spec.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		field := spec.Filters[name]
+		f := Filter{Param: name, Column: field.Column}
+		for _, s := range strings.Split(raw, ",") {
+			s = strings.TrimSpace(s)
+			if len(field.Values) > 0 && !slices.Contains(field.Values, s) {
+				return nil, &ListParamError{Param: name, Values: field.Values}
+			}
+			var v any = s
+			if field.Parse != nil {
+				var err error
+				if v, err = field.Parse(s); err != nil {
+					return nil, &ListParamError{Param: name}
+				}
+			}
+			f.Values = append(f.Values, v)
+		}
+		q.Filters = append(q.Filters, f)
+	}
+	return q, nil
+}
+
+// parseSort 解析 sort 参数并在末尾追加 id
+func (q *ListQuery) parseSort(param string, allowed map[string]string) error {
+	seen := map[string]bool{}
+	for _, item := range strings.Split(param, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		s := Sort{Param: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		column, ok := allowed[s.Param]
+		if !ok {
+			names := make([]string, 0, len(allowed))
+			for name := range allowed {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return &ListParamError{Param: "sort", Values: names}
+		}
+		if seen[s.Param] {
+			continue
+		}
+		seen[s.Param] = true
+		s.Column = column
+		q.Sorts = append(q.Sorts, s)
+	}
+	if !seen["id"] {
+		tie := Sort{Param: "id", Column: "id"}
+		if n := len(q.Sorts); n > 0 {
+			tie.Desc = q.Sorts[n-1].Desc
+		}
+		q.Sorts = append(q.Sorts, tie)
+	}
+	return nil
+}
+
+// Where 追加服务端的过滤条件，不受白名单限制
+func (q *ListQuery) Where(column string, values ...any) {
+	q.Filters = append(q.Filters, Filter{Column: column, Values: values})
+}
+
+// FilterValues 查询参数中指定过滤字段的取值
+func (q *ListQuery) FilterValues(param string) []any {
+	for _, f := range q.Filters {
+		if f.Param == param {
+			return f.Values
+		}
+	}
+	return nil
+}
+
+// sortKey 排序的文本表示，游标只能用于生成它的排序
+func (q *ListQuery) sortKey() string {
+	parts := make([]string, len(q.Sorts))
+	for i, s := range q.Sorts {
+		parts[i] = s.Param
+		if s.Desc {
+			parts[i] = "-" + s.Param
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// filter 附加过滤条件
+func (q *ListQuery) filter(db *gorm.DB) *gorm.DB {
+	for _, f := range q.Filters {
+		if len(f.Values) == 1 {
+			db = db.Where(f.Column+" = ?", f.Values[0])
+		} else {
+			db = db.Where(f.Column+" IN ?", f.Values)
+		}
+	}
+	return db
+}
+
+// order 附加排序
+func (q *ListQuery) order(db *gorm.DB) *gorm.DB {
+	for _, s := range q.Sorts {
+		if s.Desc {
+			db = db.Order(s.Column + " DESC")
+		} else {
+			db = db.Order(s.Column + " ASC")
+		}
+	}
+	return db
+}
+
+// Page 分页结果，是列表接口统一的响应结构
+type Page[T any] struct {
+	List       []*T   `json:"list"`
+	Total      *int64 `json:"total,omitempty"` // 总数，游标分页时不统计
+	Page       int    `json:"page,omitempty"`  // 页码，游标分页时为空
+	PageSize   int    `json:"page_size"`
+	HasMore    bool   `json:"has_more"`              // 是否还有下一页
+	NextCursor string `json:"next_cursor,omitempty"` // 游标分页下一页的 cursor 参数
+}
+
+// NewPage 由偏移分页的查询结果创建分页结果
+func NewPage[T any](list []*T, total int64, page, pageSize int) *Page[T] {
+	if list == nil {
+		list = []*T{}
+	}
+	return &Page[T]{
+		List:     list,
+		Total:    &total,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  int64(page)*int64(pageSize) < total,
+	}
+}
+
+// findPage 偏移分页：filter 附加过滤条件，同时用于统计总数；load 附加排序、预加载等只用于查询数据的部分，可以为 nil
+func findPage[E any](db *gorm.DB, page, pageSize int, filter, load func(db *gorm.DB) *gorm.DB) ([]*E, int64, error) {
+	var total int64
+	if err := filter(db.Model(new(E))).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计记录失败: %w", err)
+	}
+
+	query := filter(db.Model(new(E)))
+	if load != nil {
+		query = load(query)
+	}
+	var list []*E
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询记录失败: %w", err)
+	}
+	return list, total, nil
+}
+
+// Paginate 按 ListQuery 过滤、排序并分页，scope 附加固定的查询条件，可以为 nil
+func (r *BaseRepository[T]) Paginate(ctx context.Context, q *ListQuery, scope func(db *gorm.DB) *gorm.DB) (*Page[T], error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		if scope != nil {
+			db = scope(db)
+		}
+		return q.filter(db)
+	}
+	if !q.Keyset {
+		list, total, err := findPage[T](r.db.WithContext(ctx), q.Page, q.PageSize, filter, q.order)
+		if err != nil {
+			return nil, err
+		}
+		return NewPage(list, total, q.Page, q.PageSize), nil
+	}
+
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, fmt.Errorf("解析模型失败: %w", err)
+	}
+	fields := make([]*schema.Field, len(q.Sorts))
+	for i, s := range q.Sorts {
+		if fields[i] = stmt.Schema.LookUpField(s.Column[strings.LastIndex(s.Column, ".")+1:]); fields[i] == nil {
+			return nil, fmt.Errorf("排序列 %s 不存在", s.Column)
+		}
+	}
+
+	db := q.order(filter(r.db.WithContext(ctx).Model(new(T))))
+	if q.Cursor != "" {
+		values, err := decodeCursor(q.Cursor, q.sortKey(), fields)
+		if err != nil {
+			return nil, err
+		}
+		cond, args := keysetCondition(q.Sorts, values)
+		db = db.Where(cond, args...)
+	}
+	var list []*T
+	if err := db.Limit(q.PageSize + 1).Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("查询记录失败: %w", err)
+	}
+
+	p := &Page[T]{List: list, PageSize: q.PageSize}
+	if len(list) > q.PageSize {
+		p.List, p.HasMore = list[:q.PageSize], true
+		cursor, err := encodeCursor(ctx, q.sortKey(), fields, p.List[q.PageSize-1])
+		if err != nil {
+			return nil, err
+		}
+		p.NextCursor = cursor
+	}
+	if p.List == nil {
+		p.List = []*T{}
+	}
+	return p, nil
+}
+
+// cursorData 游标内容：排序与最后一条记录在各排序列上的值
+type cursorData struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// encodeCursor 把记录在排序列上的值编码为不透明的游标
+func encodeCursor[T any](ctx context.Context, sortKey string, fields []*schema.Field, last *T) (string, error) {
+	c := cursorData{Sort: sortKey}
+	rv := reflect.ValueOf(last).Elem()
+	for _, f := range fields {
+		v, _ := f.ValueOf(ctx, rv)
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("编码游标失败: %w", err)
+		}
+		c.Values = append(c.Values, raw)
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("编码游标失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor 解码游标，按排序列的类型还原各个值
+func decodeCursor(cursor, sortKey string, fields []*schema.Field) ([]any, error) {
+	invalid := &ListParamError{Param: "cursor"}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var c cursorData
+	if err = json.Unmarshal(raw, &c); err != nil || c.Sort != sortKey || len(c.Values) != len(fields) {
+		return nil, invalid
+	}
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		v := reflect.New(f.FieldType)
+		if err = json.Unmarshal(c.Values[i], v.Interface()); err != nil {
+			return nil, invalid
+		}
+		values[i] = v.Elem().Interface()
+	}
+	return values, nil
+}
+
+// keysetCondition 位于游标之后的记录：(a > ?) OR (a = ? AND b > ?) OR ...，降序的列使用 <
+func keysetCondition(sorts []Sort, values []any) (string, []any) {
+	var (
+		ors  []string
+		args []any
+	)
+	for i, s := range sorts {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, sorts[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if s.Desc {
+			op = " < ?"
+		}
+		ands = append(ands, s.Column+op)
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
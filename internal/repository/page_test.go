@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"qwqserver/internal/model"
+)
+
+var testListSpec = ListSpec{
+	Filters: map[string]FilterField{
+		"status":    {Column: "status", Values: []string{"draft", "published"}},
+		"author_id": {Column: "author_id", Parse: func(s string) (any, error) { return strconv.ParseUint(s, 10, 64) }},
+	},
+	Sorts:       map[string]string{"id": "id", "created_at": "created_at", "view_count": "view_count"},
+	DefaultSort: "-created_at",
+}
+
+// listPosts 解析查询参数并查询帖子列表
+func listPosts(t *testing.T, query string) (*Page[model.Post], error) {
+	t.Helper()
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := ParseListQuery(values, testListSpec)
+	if err != nil {
+		return nil, err
+	}
+	return testRepos.Posts.List(context.Background(), q)
+}
+
+func postIDs(posts []*model.Post) []uint {
+	ids := make([]uint, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestParseListQuery(t *testing.T) {
+	q, err := ParseListQuery(url.Values{"sort": {"-view_count,created_at"}, "page": {"0"}, "page_size": {"1000"}, "unknown": {"1"}}, testListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Page != 1 || q.PageSize != MaxPageSize || len(q.Filters) != 0 {
+		t.Fatalf("分页参数 = %+v", q)
+	}
+	if got := q.sortKey(); got != "-view_count,created_at,id" {
+		t.Fatalf("sortKey = %q", got)
+	}
+
+	var pe *ListParamError
+	if _, err = ParseListQuery(url.Values{"sort": {"password"}}, testListSpec); !errors.As(err, &pe) || pe.Param != "sort" || len(pe.Values) != 3 {
+		t.Fatalf("非白名单排序字段 err = %v", err)
+	}
+	if _, err = ParseListQuery(url.Values{"status": {"published,trash"}}, testListSpec); !errors.As(err, &pe) || pe.Param != "status" {
+		t.Fatalf("非法过滤取值 err = %v", err)
+	}
+	if _, err = ParseListQuery(url.Values{"author_id": {"abc"}}, testListSpec); !errors.As(err, &pe) || pe.Param != "author_id" || len(pe.Values) != 0 {
+		t.Fatalf("无法解析的过滤取值 err = %v", err)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+	author := newTestUser(t, "page_author")
+	var posts []*model.Post
+	for i, views := range []int64{3, 1, 3, 2, 3} {
+		p := newTestPost(t, testRepos.Posts, author, "分页"+strconv.Itoa(i))
+		if err := testRepos.Posts.ApplyCountDelta(ctx, p.ID, CountDelta{CountColumnView: views}); err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, p)
+	}
+	// 按浏览量降序，浏览量相同时按 ID 降序
+	want := []uint{posts[4].ID, posts[2].ID, posts[0].ID, posts[3].ID, posts[1].ID}
+	filter := "author_id=" + strconv.FormatUint(uint64(author.ID), 10) + "&status=published&sort=-view_count"
+
+	t.Run("offset", func(t *testing.T) {
+		page, err := listPosts(t, filter+"&page=2&page_size=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total == nil || *page.Total != 5 || !page.HasMore || page.Page != 2 || page.NextCursor != "" {
+			t.Fatalf("page = %+v", page)
+		}
+		if got := postIDs(page.List); len(got) != 2 || got[0] != want[2] || got[1] != want[3] {
+			t.Fatalf("第 2 页 = %v，期望 %v", got, want[2:4])
+		}
+	})
+
+	t.Run("keyset", func(t *testing.T) {
+		var got []uint
+		cursor := ""
+		for i := 0; ; i++ {
+			page, err := listPosts(t, filter+"&page_size=2&cursor="+cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != nil {
+				t.Fatalf("游标分页不应统计总数: %+v", page)
+			}
+			got = append(got, postIDs(page.List)...)
+			if i == 0 {
+				// 翻页期间新增的帖子排在最前，不影响后续页
+				p := newTestPost(t, testRepos.Posts, author, "分页新增")
+				if err := testRepos.Posts.ApplyCountDelta(ctx, p.ID, CountDelta{CountColumnView: 10}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !page.HasMore {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if len(got) != len(want) {
+			t.Fatalf("游标分页 = %v，期望 %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("游标分页 = %v，期望 %v", got, want)
+			}
+		}
+	})
+
+	t.Run("keyset by time", func(t *testing.T) {
+		seen := map[uint]bool{}
+		cursor := ""
+		for {
+			page, err := listPosts(t, "author_id="+strconv.FormatUint(uint64(author.ID), 10)+"&sort=created_at&page_size=2&cursor="+cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range postIDs(page.List) {
+				if seen[id] {
+					t.Fatalf("帖子 %d 重复出现", id)
+				}
+				seen[id] = true
+			}
+			if !page.HasMore {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if len(seen) != len(posts)+1 {
+			t.Fatalf("按时间游标分页得到 %d 条，期望 %d", len(seen), len(posts)+1)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		page, err := listPosts(t, filter+"&page_size=1&cursor=")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pe *ListParamError
+		for _, query := range []string{
+			filter + "&cursor=not-a-cursor",
+			"author_id=" + strconv.FormatUint(uint64(author.ID), 10) + "&sort=created_at&cursor=" + page.NextCursor, // 排序不同
+		} {
+			if _, err = listPosts(t, query); !errors.As(err, &pe) || pe.Param != "cursor" {
+				t.Fatalf("%s: err = %v", query, err)
+			}
+		}
+	})
+}
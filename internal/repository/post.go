@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"qwqserver/internal/model"
+	"qwqserver/pkg/hotrank"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -22,11 +27,39 @@ type PostRepository interface {
 	// ListByUserID 获取用户的所有帖子
 	ListByUserID(ctx context.Context, userID uint, page, pageSize int) ([]*model.Post, int64, error)
 
-	// ListByCategory 获取分类下的帖子
+	// ListByCategory 获取分类（版块）下的帖子
 	ListByCategory(ctx context.Context, categoryID uint, page, pageSize int) ([]*model.Post, int64, error)
 
-	// Search 搜索帖子
-	Search(ctx context.Context, query string, page, pageSize int) ([]*model.Post, int64, error)
+	// List 按 ListQuery 过滤、排序并分页获取帖子
+	List(ctx context.Context, q *ListQuery) (*Page[model.Post], error)
+
+	// Search 搜索帖子（LIKE 匹配，作为全文检索的后备实现）
+	Search(ctx context.Context, query string, filter SearchFilter, page, pageSize int) ([]*model.Post, int64, error)
+
+	// FindByIDWithTags 获取帖子及其标签
+	FindByIDWithTags(ctx context.Context, id uint) (*model.Post, error)
+
+	// UpdateContent 更新帖子的可编辑字段（标题、内容、内容模型、版块、状态、发布时间），不触碰计数列
+	// 仅当数据库中的版本号与 post.Version 一致时更新，成功后 post.Version 加一；版本不一致返回 ErrVersionConflict
+	UpdateContent(ctx context.Context, post *model.Post) error
+
+	// --------- 修订历史 相关操作 --------- //
+
+	// UpdateWithRevision 在事务中更新帖子可编辑字段并写入修订记录，返回新修订
+	// 帖子尚无修订记录时，会先把更新前的内容记为版本 1
+	UpdateWithRevision(ctx context.Context, post *model.Post, editorID uint, summary string) (*model.PostRevision, error)
+
+	// ListRevisions 获取帖子的修订列表（按版本倒序，不含内容）
+	ListRevisions(ctx context.Context, postID uint, page, pageSize int) ([]*model.PostRevision, int64, error)
+
+	// FindRevision 获取指定版本的修订，不存在时返回 nil
+	FindRevision(ctx context.Context, postID uint, version int) (*model.PostRevision, error)
+
+	// LatestRevision 获取最新修订，不存在时返回 nil
+	LatestRevision(ctx context.Context, postID uint) (*model.PostRevision, error)
+
+	// ListForIndex 按ID升序获取 afterID 之后的已发布帖子（含标签），用于重建搜索索引
+	ListForIndex(ctx context.Context, afterID uint, limit int) ([]*model.Post, error)
 
 	// IncrementViewCount 增加帖子浏览量
 	IncrementViewCount(ctx context.Context, id uint) error
@@ -43,6 +76,9 @@ type PostRepository interface {
 	// DecrementCommentCount 减少帖子评论数
 	DecrementCommentCount(ctx context.Context, id uint) error
 
+	// ApplyCountDelta 批量累加帖子计数（计数写回使用），结果不会小于0
+	ApplyCountDelta(ctx context.Context, id uint, delta CountDelta) error
+
 	// PinPost 置顶帖子
 	PinPost(ctx context.Context, id uint) error
 
@@ -52,6 +88,15 @@ type PostRepository interface {
 	// ListPopular 获取热门帖子
 	ListPopular(ctx context.Context, days int, limit int) ([]*model.Post, error)
 
+	// ListPopularByBoard 获取版块热门帖子
+	ListPopularByBoard(ctx context.Context, boardID uint, days int, limit int) ([]*model.Post, error)
+
+	// FindByIDs 按给定ID顺序获取帖子，不存在的ID会被跳过
+	FindByIDs(ctx context.Context, ids []uint) ([]*model.Post, error)
+
+	// ListPublishedSince 分页获取指定时间之后发布的帖子（按ID升序）
+	ListPublishedSince(ctx context.Context, since time.Time, page, pageSize int) ([]*model.Post, int64, error)
+
 	// ListLatest 获取最新帖子
 	ListLatest(ctx context.Context, limit int) ([]*model.Post, error)
 
@@ -60,6 +105,20 @@ type PostRepository interface {
 
 	// Exists 检查帖子是否存在
 	Exists(ctx context.Context, id uint) (bool, error)
+
+	// --------- 回收站 相关操作 --------- //
+
+	// Trash 分页获取回收站中的帖子，scope 附加固定的查询条件，可以为 nil
+	Trash(ctx context.Context, q *ListQuery, scope func(db *gorm.DB) *gorm.DB) (*Page[model.Post], error)
+
+	// FindTrashed 获取回收站中的帖子，不存在或未删除时返回 nil
+	FindTrashed(ctx context.Context, id uint) (*model.Post, error)
+
+	// Restore 从回收站恢复帖子，帖子不在回收站中时返回 ErrNotFound
+	Restore(ctx context.Context, id uint) error
+
+	// Purge 彻底删除回收站中的帖子及其标签、点赞、收藏、修订、精华与专题条目，帖子不在回收站中时返回 ErrNotFound
+	Purge(ctx context.Context, id uint) error
 }
 
 // postRepository Post仓库实现
@@ -68,12 +127,10 @@ type postRepository struct {
 }
 
 // NewPostRepository 创建新的Post仓库
-func NewPostRepository() (PostRepository, error) {
-	baseRepo, err := NewBaseRepository[model.Post]()
-	if err != nil {
-		return nil, err
-	}
-	return &postRepository{BaseRepository: baseRepo}, nil
+func NewPostRepository(db *gorm.DB, rdb *redis.Client) PostRepository {
+	base := NewBaseRepository[model.Post](db, rdb)
+	base.beforePurge = purgePostRelations
+	return &postRepository{BaseRepository: base}
 }
 
 // WithTransaction 在事务中执行Post操作
@@ -86,84 +143,140 @@ func (r *postRepository) WithTransaction(ctx context.Context, fn func(repo PostR
 
 // ListByUserID 获取用户的所有帖子
 func (r *postRepository) ListByUserID(ctx context.Context, userID uint, page, pageSize int) ([]*model.Post, int64, error) {
-	offset := (page - 1) * pageSize
-
-	// 获取总数
-	var total int64
-	if err := r.db.WithContext(ctx).Model(&model.Post{}).
-		Where("user_id = ?", userID).
-		Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("统计用户帖子数量失败: %w", err)
-	}
-
-	// 获取分页数据
-	var posts []*model.Post
-	if err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&posts).Error; err != nil {
+	posts, total, err := findPage[model.Post](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return db.Where("author_id = ?", userID)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	})
+	if err != nil {
 		return nil, 0, fmt.Errorf("查询用户帖子失败: %w", err)
 	}
-
 	return posts, total, nil
 }
 
-// ListByCategory 获取分类下的帖子
+// ListByCategory 获取分类（版块）下的帖子
 func (r *postRepository) ListByCategory(ctx context.Context, categoryID uint, page, pageSize int) ([]*model.Post, int64, error) {
-	offset := (page - 1) * pageSize
+	posts, total, err := findPage[model.Post](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return db.Where("board_id = ?", categoryID)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("is_sticky DESC, created_at DESC") // 置顶帖子优先
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询分类帖子失败: %w", err)
+	}
+	return posts, total, nil
+}
 
-	// 获取总数
-	var total int64
-	if err := r.db.WithContext(ctx).Model(&model.Post{}).
-		Where("category_id = ?", categoryID).
-		Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("统计分类帖子数量失败: %w", err)
+// List 按 ListQuery 过滤、排序并分页获取帖子
+func (r *postRepository) List(ctx context.Context, q *ListQuery) (*Page[model.Post], error) {
+	page, err := r.Paginate(ctx, q, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询帖子列表失败: %w", err)
 	}
+	return page, nil
+}
 
-	// 获取分页数据
-	var posts []*model.Post
-	if err := r.db.WithContext(ctx).
-		Where("category_id = ?", categoryID).
-		Order("is_pinned DESC, created_at DESC"). // 置顶帖子优先
-		Offset(offset).
-		Limit(pageSize).
-		Find(&posts).Error; err != nil {
-		return nil, 0, fmt.Errorf("查询分类帖子失败: %w", err)
+// SearchFilter 搜索过滤条件，零值表示不过滤
+type SearchFilter struct {
+	Tag      string     // 标签名
+	BoardID  *uint      // 版块ID
+	AuthorID uint       // 作者ID
+	From     *time.Time // 创建时间下限（含）
+	To       *time.Time // 创建时间上限（不含）
+}
+
+// apply 把过滤条件附加到查询上
+func (f SearchFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Tag != "" {
+		db = db.Where("posts.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Table("post_tags").
+			Select("post_tags.post_id").
+			Joins("JOIN tags ON tags.tag_id = post_tags.tag_tag_id").
+			Where("tags.name = ?", f.Tag))
+	}
+	if f.BoardID != nil {
+		db = db.Where("posts.board_id = ?", *f.BoardID)
 	}
+	if f.AuthorID != 0 {
+		db = db.Where("posts.author_id = ?", f.AuthorID)
+	}
+	if f.From != nil {
+		db = db.Where("posts.created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		db = db.Where("posts.created_at < ?", *f.To)
+	}
+	return db
+}
 
+// Search 搜索帖子，查询按空白切分，每个词都需出现在标题或内容中
+// 两侧都转为小写后匹配，使 MySQL（默认排序规则不区分大小写）与 PostgreSQL、SQLite（LIKE 区分大小写）结果一致
+func (r *postRepository) Search(ctx context.Context, query string, filter SearchFilter, page, pageSize int) ([]*model.Post, int64, error) {
+	posts, total, err := findPage[model.Post](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		db = db.Where("posts.status = ?", "published")
+		for _, word := range strings.Fields(strings.ReplaceAll(query, `"`, " ")) {
+			like := "%" + strings.ToLower(word) + "%"
+			db = db.Where("LOWER(posts.title) LIKE ? OR LOWER(posts.content) LIKE ?", like, like)
+		}
+		return filter.apply(db)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("posts.created_at DESC")
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("搜索帖子失败: %w", err)
+	}
 	return posts, total, nil
 }
 
-// Search 搜索帖子
-func (r *postRepository) Search(ctx context.Context, query string, page, pageSize int) ([]*model.Post, int64, error) {
-	offset := (page - 1) * pageSize
-	searchQuery := "%" + query + "%"
+// FindByIDWithTags 获取帖子及其标签，不存在时返回 nil
+func (r *postRepository) FindByIDWithTags(ctx context.Context, id uint) (*model.Post, error) {
+	return r.First(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Tags").Where("id = ?", id)
+	})
+}
 
-	// 获取总数
-	var total int64
-	if err := r.db.WithContext(ctx).Model(&model.Post{}).
-		Where("title LIKE ? OR content LIKE ?", searchQuery, searchQuery).
-		Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("统计搜索结果失败: %w", err)
+// UpdateContent 更新帖子的可编辑字段（乐观锁）
+func (r *postRepository) UpdateContent(ctx context.Context, post *model.Post) error {
+	result := r.db.WithContext(ctx).Model(&model.Post{}).
+		Where("id = ? AND version = ?", post.ID, post.Version).
+		Updates(map[string]any{
+			"title":        post.Title,
+			"content":      post.Content,
+			"mod":          post.Mod,
+			"board_id":     post.BoardID,
+			"status":       post.Status,
+			"published_at": post.PublishedAt,
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("更新帖子失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if ok, _ := r.Exists(ctx, post.ID); !ok {
+			return fmt.Errorf("帖子不存在: %w", ErrNotFound)
+		}
+		return fmt.Errorf("帖子版本 %d 已过期: %w", post.Version, ErrVersionConflict)
 	}
+	post.Version++
+	return nil
+}
 
-	// 获取分页数据
+// ListForIndex 按ID升序获取 afterID 之后的已发布帖子（含标签）
+func (r *postRepository) ListForIndex(ctx context.Context, afterID uint, limit int) ([]*model.Post, error) {
 	var posts []*model.Post
 	if err := r.db.WithContext(ctx).
-		Where("title LIKE ? OR content LIKE ?", searchQuery, searchQuery).
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
+		Preload("Tags").
+		Where("status = ?", "published").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
 		Find(&posts).Error; err != nil {
-		return nil, 0, fmt.Errorf("搜索帖子失败: %w", err)
+		return nil, fmt.Errorf("获取待索引帖子失败: %w", err)
 	}
-
-	return posts, total, nil
+	return posts, nil
 }
 
-// IncrementViewCount 增加帖子浏览量 （未实现）
+// IncrementViewCount 增加帖子浏览量
 func (r *postRepository) IncrementViewCount(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).
 		Model(&model.Post{}).
@@ -181,7 +294,7 @@ func (r *postRepository) IncrementViewCount(ctx context.Context, id uint) error
 	return nil
 }
 
-// IncrementLikeCount 增加帖子点赞数 （未实现）
+// IncrementLikeCount 增加帖子点赞数
 func (r *postRepository) IncrementLikeCount(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).
 		Model(&model.Post{}).
@@ -199,7 +312,7 @@ func (r *postRepository) IncrementLikeCount(ctx context.Context, id uint) error
 	return nil
 }
 
-// DecrementLikeCount 减少帖子点赞数 （未实现）
+// DecrementLikeCount 减少帖子点赞数
 func (r *postRepository) DecrementLikeCount(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).
 		Model(&model.Post{}).
@@ -217,7 +330,7 @@ func (r *postRepository) DecrementLikeCount(ctx context.Context, id uint) error
 	return nil
 }
 
-// IncrementCommentCount 增加帖子评论数 （未实现）
+// IncrementCommentCount 增加帖子评论数
 func (r *postRepository) IncrementCommentCount(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).
 		Model(&model.Post{}).
@@ -235,7 +348,7 @@ func (r *postRepository) IncrementCommentCount(ctx context.Context, id uint) err
 	return nil
 }
 
-// DecrementCommentCount 减少帖子评论数（未实现）
+// DecrementCommentCount 减少帖子评论数
 func (r *postRepository) DecrementCommentCount(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).
 		Model(&model.Post{}).
@@ -253,13 +366,62 @@ func (r *postRepository) DecrementCommentCount(ctx context.Context, id uint) err
 	return nil
 }
 
+// CountDelta 帖子计数增量，键为计数列名
+type CountDelta map[string]int64
+
+// 帖子计数列
+const (
+	CountColumnView     = "view_count"
+	CountColumnLike     = "like_count"
+	CountColumnComment  = "comment_count"
+	CountColumnBookmark = "bookmark_count"
+)
+
+// countColumns 允许累加的计数列
+var countColumns = map[string]struct{}{
+	CountColumnView:     {},
+	CountColumnLike:     {},
+	CountColumnComment:  {},
+	CountColumnBookmark: {},
+}
+
+// ApplyCountDelta 批量累加帖子计数
+func (r *postRepository) ApplyCountDelta(ctx context.Context, id uint, delta CountDelta) error {
+	updates := make(map[string]interface{}, len(delta))
+	for column, n := range delta {
+		if _, ok := countColumns[column]; !ok {
+			return fmt.Errorf("不支持的计数列: %s", column)
+		}
+		if n == 0 {
+			continue
+		}
+		updates[column] = gorm.Expr(
+			fmt.Sprintf("CASE WHEN %[1]s + ? < 0 THEN 0 ELSE %[1]s + ? END", column), n, n)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&model.Post{}).
+		Where("id = ?", id).
+		UpdateColumns(updates)
+	if result.Error != nil {
+		return fmt.Errorf("累加帖子计数失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("帖子不存在: %w", ErrNotFound)
+	}
+	return nil
+}
+
 // PinPost 置顶帖子（半未实现）
 func (r *postRepository) PinPost(ctx context.Context, id uint) error {
 	// 先取消当前置顶的帖子
 	if err := r.db.WithContext(ctx).
 		Model(&model.Post{}).
-		Where("is_pinned = ?", true).
-		Update("is_pinned", false).Error; err != nil {
+		Where("is_sticky = ?", true).
+		Update("is_sticky", false).Error; err != nil {
 		return fmt.Errorf("取消当前置顶失败: %w", err)
 	}
 
@@ -267,7 +429,7 @@ func (r *postRepository) PinPost(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).
 		Model(&model.Post{}).
 		Where("id = ?", id).
-		Update("is_pinned", true)
+		Update("is_sticky", true)
 
 	if result.Error != nil {
 		return fmt.Errorf("置顶帖子失败: %w", result.Error)
@@ -285,7 +447,7 @@ func (r *postRepository) UnpinPost(ctx context.Context, id uint) error {
 	result := r.db.WithContext(ctx).
 		Model(&model.Post{}).
 		Where("id = ?", id).
-		Update("is_pinned", false)
+		Update("is_sticky", false)
 
 	if result.Error != nil {
 		return fmt.Errorf("取消置顶失败: %w", result.Error)
@@ -298,24 +460,101 @@ func (r *postRepository) UnpinPost(ctx context.Context, id uint) error {
 	return nil
 }
 
-// ListPopular 获取热门帖子（未实现）
+// popularCandidateFactor 热门候选集相对 limit 的倍数
+const popularCandidateFactor = 10
+
+// ListPopular 获取热门帖子
+// 这是不依赖 Redis 的 SQL 计算方式：先按互动数取出时间窗口内的候选帖子，再按时间衰减热度排序
 func (r *postRepository) ListPopular(ctx context.Context, days int, limit int) ([]*model.Post, error) {
-	startDate := time.Now().AddDate(0, 0, -days)
+	return r.listPopular(ctx, nil, days, limit)
+}
+
+// ListPopularByBoard 获取版块热门帖子
+func (r *postRepository) ListPopularByBoard(ctx context.Context, boardID uint, days int, limit int) ([]*model.Post, error) {
+	return r.listPopular(ctx, &boardID, days, limit)
+}
+
+func (r *postRepository) listPopular(ctx context.Context, boardID *uint, days int, limit int) ([]*model.Post, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	params := hotrank.Default()
+	now := time.Now()
+
+	db := r.db.WithContext(ctx).
+		Where("status = ?", "published").
+		Where("created_at >= ?", now.AddDate(0, 0, -days))
+	if boardID != nil {
+		db = db.Where("board_id = ?", *boardID)
+	}
 
 	var posts []*model.Post
-	err := r.db.WithContext(ctx).
-		Where("created_at >= ?", startDate).
-		Order("like_count DESC, comment_count DESC, view_count DESC").
-		Limit(limit).
+	err := db.
+		Order(clause.Expr{
+			SQL:  "(like_count * ? + comment_count * ? + bookmark_count * ? + view_count * ?) DESC",
+			Vars: []interface{}{params.LikeWeight, params.CommentWeight, params.BookmarkWeight, params.ViewWeight},
+		}).
+		Limit(limit * popularCandidateFactor).
 		Find(&posts).Error
-
 	if err != nil {
 		return nil, fmt.Errorf("获取热门帖子失败: %w", err)
 	}
 
+	scores := make(map[uint]float64, len(posts))
+	for _, p := range posts {
+		scores[p.ID] = params.Score(hotrank.Counts{
+			Likes:     p.LikeCount,
+			Comments:  p.CommentCount,
+			Views:     p.ViewCount,
+			Bookmarks: p.BookmarkCount,
+		}, p.CreatedAt, now)
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return scores[posts[i].ID] > scores[posts[j].ID]
+	})
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+// FindByIDs 按给定ID顺序获取帖子
+func (r *postRepository) FindByIDs(ctx context.Context, ids []uint) ([]*model.Post, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var found []*model.Post
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("批量获取帖子失败: %w", err)
+	}
+
+	byID := make(map[uint]*model.Post, len(found))
+	for _, p := range found {
+		byID[p.ID] = p
+	}
+	posts := make([]*model.Post, 0, len(found))
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			posts = append(posts, p)
+		}
+	}
 	return posts, nil
 }
 
+// ListPublishedSince 分页获取指定时间之后发布的帖子
+func (r *postRepository) ListPublishedSince(ctx context.Context, since time.Time, page, pageSize int) ([]*model.Post, int64, error) {
+	posts, total, err := findPage[model.Post](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", "published").Where("created_at >= ?", since)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询帖子失败: %w", err)
+	}
+	return posts, total, nil
+}
+
 // ListLatest 获取最新帖子
 func (r *postRepository) ListLatest(ctx context.Context, limit int) ([]*model.Post, error) {
 	var posts []*model.Post
@@ -331,24 +570,97 @@ func (r *postRepository) ListLatest(ctx context.Context, limit int) ([]*model.Po
 	return posts, nil
 }
 
-// ListRecommended 获取推荐帖子（未实现）
+// ListRecommended 获取推荐帖子
+// 推荐结果由两部分交替组成：
+//  1. 有效期内的精华帖子
+//  2. 用户互动过（发布、点赞、收藏）的标签下的其他帖子
+//
+// 两部分不足 limit 时使用最新发布的帖子补齐
 func (r *postRepository) ListRecommended(ctx context.Context, userID uint, limit int) ([]*model.Post, error) {
-	// 这是一个简化的推荐算法实现
-	// 实际项目中可以根据用户兴趣、历史行为等实现更复杂的推荐逻辑
+	if limit <= 0 {
+		return nil, nil
+	}
+	db := r.db.WithContext(ctx)
+	now := time.Now()
+
+	// 精华帖子
+	var featured []*model.Post
+	if err := db.Model(&model.Post{}).
+		Joins("JOIN post_features ON post_features.post_id = posts.id").
+		Where("posts.status = ?", "published").
+		Where("post_features.expire_at IS NULL OR post_features.expire_at > ?", now).
+		Order("post_features.updated_at DESC").
+		Limit(limit).
+		Find(&featured).Error; err != nil {
+		return nil, fmt.Errorf("获取精华帖子失败: %w", err)
+	}
 
-	var posts []*model.Post
-	err := r.db.WithContext(ctx).
-		Joins("JOIN user_interests ON user_interests.category_id = posts.category_id").
-		Where("user_interests.user_id = ?", userID).
-		Order("posts.like_count DESC, posts.comment_count DESC").
+	// 用户互动过的标签
+	interacted := r.interactedPostIDs(db, userID)
+	var byTag []*model.Post
+	if err := db.Model(&model.Post{}).
+		Distinct("posts.*").
+		Joins("JOIN post_tags ON post_tags.post_id = posts.id").
+		Where("post_tags.tag_tag_id IN (?)", db.Table("post_tags").Select("tag_tag_id").Where("post_id IN (?)", interacted)).
+		Where("posts.status = ?", "published").
+		Where("posts.author_id <> ?", userID).
+		Order("posts.created_at DESC").
 		Limit(limit).
-		Find(&posts).Error
+		Find(&byTag).Error; err != nil {
+		return nil, fmt.Errorf("获取兴趣标签帖子失败: %w", err)
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("获取推荐帖子失败: %w", err)
+	posts := interleavePosts(limit, featured, byTag)
+	if len(posts) >= limit {
+		return posts, nil
 	}
 
-	return posts, nil
+	// 使用最新帖子补齐
+	var latest []*model.Post
+	if err := db.Where("status = ?", "published").
+		Order("created_at DESC").
+		Limit(limit * 2).
+		Find(&latest).Error; err != nil {
+		return nil, fmt.Errorf("获取最新帖子失败: %w", err)
+	}
+	return interleavePosts(limit, append(posts, latest...)), nil
+}
+
+// interactedPostIDs 用户互动过的帖子ID子查询
+func (r *postRepository) interactedPostIDs(db *gorm.DB, userID uint) *gorm.DB {
+	liked := db.Model(&model.PostLike{}).Select("post_id").Where("user_id = ?", userID)
+	bookmarked := db.Model(&model.Bookmark{}).Select("post_id").Where("user_id = ?", userID)
+	return db.Model(&model.Post{}).Select("id").
+		Where("author_id = ?", userID).
+		Or("id IN (?)", liked).
+		Or("id IN (?)", bookmarked)
+}
+
+// interleavePosts 依次从各列表轮流取帖子并去重，最多返回 limit 条
+func interleavePosts(limit int, lists ...[]*model.Post) []*model.Post {
+	result := make([]*model.Post, 0, limit)
+	seen := make(map[uint]struct{}, limit)
+	for i := 0; len(result) < limit; i++ {
+		progressed := false
+		for _, list := range lists {
+			if i >= len(list) {
+				continue
+			}
+			progressed = true
+			if _, ok := seen[list[i].ID]; ok {
+				continue
+			}
+			seen[list[i].ID] = struct{}{}
+			result = append(result, list[i])
+			if len(result) == limit {
+				break
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	return result
 }
 
 // Exists 检查帖子是否存在
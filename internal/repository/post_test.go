@@ -2,112 +2,174 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"testing"
+	"time"
 
 	"qwqserver/internal/model"
-	"qwqserver/pkg/database"
 )
 
-func TestPostRepoRun(t *testing.T) {
-	t.Run("test-post-repo", func(t *testing.T) {
-		// 初始化数据库连接
-		cfg := &database.Config{
-			Driver:   "mysql",
-			DSN:      "user:pass@tcp(127.0.0.1:3306)/dbname?charset=utf8mb4&parseTime=True&loc=Local",
-			LogLevel: "info",
-		}
-
-		if _, err := database.InitDB(cfg); err != nil {
-			log.Fatalf("数据库初始化失败: %v", err)
-		}
-		defer database.Close()
-
-		// 创建Post仓库
-		postRepo, err := NewPostRepository()
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		// 创建新帖子
-		newPost := &model.Post{
-			Title:     "Go语言最佳实践",
-			Content:   "本文介绍Go语言开发中的最佳实践...",
-			AuthorID: 1,
-			//CategoryID: 2,
-		}
-
-		if err := postRepo.Create(context.Background(), newPost); err != nil {
-			log.Fatalf("创建帖子失败: %v", err)
-		}
-		fmt.Printf("创建帖子成功，ID: %d\n", newPost.ID)
-
-		// 获取帖子
-		post, err := postRepo.FindByID(context.Background(), newPost.ID)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if post != nil {
-			fmt.Printf("获取帖子: %s (作者ID: %d)\n", post.Title, post.AuthorID)
-		}
-
-		// 增加浏览量
-		if err := postRepo.IncrementViewCount(context.Background(), post.ID); err != nil {
-			log.Printf("增加浏览量失败: %v", err)
-		}
-
-		// 增加点赞数
-		if err := postRepo.IncrementLikeCount(context.Background(), post.ID); err != nil {
-			log.Printf("增加点赞数失败: %v", err)
-		}
+// newTestUser 创建测试用户，用户名加上测试名避免唯一索引冲突
+func newTestUser(t *testing.T, name string) *model.User {
+	t.Helper()
+	u := &model.User{
+		Username: fmt.Sprintf("%s_%d", name, time.Now().UnixNano()),
+		Email:    fmt.Sprintf("%s_%d@example.com", name, time.Now().UnixNano()),
+		Password: "$2a$10$test",
+	}
+	if err := testRepos.Users.Create(context.Background(), u); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return u
+}
 
-		// 获取用户的所有帖子
-		userPosts, total, err := postRepo.ListByUserID(context.Background(), 1, 1, 10)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Printf("用户共有 %d 篇帖子，第一页: \n", total)
-		for i, p := range userPosts {
-			fmt.Printf("%d. %s\n", i+1, p.Title)
-		}
+// newTestPost 创建已发布的测试帖子
+func newTestPost(t *testing.T, repo PostRepository, author *model.User, title string, tags ...model.Tag) *model.Post {
+	t.Helper()
+	now := time.Now()
+	p := &model.Post{
+		AuthorID:    uint64(author.ID),
+		BoardID:     7,
+		Mod:         "markdown",
+		Title:       title,
+		Content:     "本文介绍 Go 语言开发中的最佳实践...",
+		Status:      "published",
+		PublishedAt: &now,
+		Tags:        tags,
+	}
+	if err := repo.Create(context.Background(), p); err != nil {
+		t.Fatalf("创建帖子失败: %v", err)
+	}
+	return p
+}
 
-		// 获取热门帖子
-		popularPosts, err := postRepo.ListPopular(context.Background(), 7, 5)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Println("本周热门帖子:")
-		for i, p := range popularPosts {
-			//fmt.Printf("%d. %s (%d 赞)\n", i+1, p.Title, p.LikeCount)
-			fmt.Printf("%d. %s (%d 赞)\n", i+1, p.Title, p) // X
+func TestPostRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepos.Posts
+	author := newTestUser(t, "post_author")
+	post := newTestPost(t, repo, author, "Go语言最佳实践")
+
+	got, err := repo.FindByID(ctx, post.ID)
+	if err != nil || got == nil || got.Title != post.Title || got.Status != "published" || got.CommentStatus != "open" {
+		t.Fatalf("FindByID = %+v %v", got, err)
+	}
+
+	// 计数
+	if err := repo.IncrementViewCount(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.IncrementLikeCount(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ApplyCountDelta(ctx, post.ID, CountDelta{CountColumnView: 2, CountColumnComment: -5}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = repo.FindByID(ctx, post.ID)
+	if got.ViewCount != 3 || got.LikeCount != 1 || got.CommentCount != 0 {
+		t.Errorf("计数 = view %d like %d comment %d", got.ViewCount, got.LikeCount, got.CommentCount)
+	}
+	if err := repo.ApplyCountDelta(ctx, 1<<30, CountDelta{CountColumnView: 1}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不存在的帖子 err = %v", err)
+	}
+
+	// 列表
+	posts, total, err := repo.ListByUserID(ctx, author.ID, 1, 10)
+	if err != nil || total != 1 || len(posts) != 1 || posts[0].ID != post.ID {
+		t.Errorf("ListByUserID = %v %d %v", posts, total, err)
+	}
+	if err := repo.PinPost(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	posts, _, err = repo.ListByCategory(ctx, 7, 1, 10)
+	if err != nil || len(posts) == 0 || posts[0].ID != post.ID || !posts[0].IsSticky {
+		t.Errorf("ListByCategory 置顶帖子应在最前: %v %v", posts, err)
+	}
+	popular, err := repo.ListPopular(ctx, 7, 5)
+	if err != nil || len(popular) == 0 {
+		t.Errorf("ListPopular = %v %v", popular, err)
+	}
+
+	// 事务
+	err = repo.WithTransaction(ctx, func(txRepo PostRepository) error {
+		got.Content = "更新后的内容..."
+		if err := txRepo.Update(ctx, got); err != nil {
+			return err
 		}
-
-		// 置顶帖子
-		if err := postRepo.PinPost(context.Background(), newPost.ID); err != nil {
-			log.Printf("置顶帖子失败: %v", err)
+		return txRepo.IncrementCommentCount(ctx, got.ID)
+	})
+	if err != nil {
+		t.Fatalf("事务执行失败: %v", err)
+	}
+	rollback := errors.New("回滚")
+	err = repo.WithTransaction(ctx, func(txRepo PostRepository) error {
+		if err := txRepo.IncrementCommentCount(ctx, got.ID); err != nil {
+			return err
 		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("err = %v", err)
+	}
+	got, _ = repo.FindByID(ctx, post.ID)
+	if got.Content != "更新后的内容..." || got.CommentCount != 1 {
+		t.Errorf("事务结果 = %q %d", got.Content, got.CommentCount)
+	}
+
+	// 乐观锁
+	stale := *got
+	if err := repo.UpdateContent(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateContent(ctx, &stale); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("过期版本 err = %v", err)
+	}
+}
 
-		// 在事务中更新帖子
-		err = postRepo.WithTransaction(context.Background(), func(txRepo PostRepository) error {
-			// 更新帖子内容
-			post.Content = "更新后的内容..."
-			if err := txRepo.Update(context.Background(), post); err != nil {
-				return err
-			}
-
-			// 增加评论数
-			if err := txRepo.IncrementCommentCount(context.Background(), post.ID); err != nil {
-				return err
-			}
-
-			return nil
-		})
-
-		if err != nil {
-			log.Fatalf("事务执行失败: %v", err)
+func TestPostSearch(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepos.Posts
+	author := newTestUser(t, "search_author")
+	post := newTestPost(t, repo, author, "Dialect Portable SEARCH")
+
+	// 各数据库的 LIKE 大小写规则不同，Search 统一为不区分大小写
+	for _, q := range []string{"portable search", "DIALECT", "最佳实践 dialect"} {
+		posts, total, err := repo.Search(ctx, q, SearchFilter{AuthorID: author.ID}, 1, 10)
+		if err != nil || total != 1 || len(posts) != 1 || posts[0].ID != post.ID {
+			t.Errorf("Search(%q) = %v %d %v", q, posts, total, err)
 		}
+	}
+	if _, total, err := repo.Search(ctx, "portable missing", SearchFilter{AuthorID: author.ID}, 1, 10); err != nil || total != 0 {
+		t.Errorf("Search 不匹配 = %d %v", total, err)
+	}
+}
 
-		fmt.Println("帖子更新和评论数增加事务成功完成")
-	})
+func TestPostRecommended(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepos.Posts
+	interactions := testRepos.Interactions
+	author, reader := newTestUser(t, "rec_author"), newTestUser(t, "rec_reader")
+	tag := model.Tag{Name: fmt.Sprintf("rec_%d", time.Now().UnixNano())}
+	liked := newTestPost(t, repo, author, "被点赞的帖子", tag)
+	sameTag := newTestPost(t, repo, author, "同标签的帖子", liked.Tags[0])
+
+	// 重复点赞不报错，也不重复计数
+	if ok, err := interactions.Like(ctx, reader.ID, liked.ID); err != nil || !ok {
+		t.Fatalf("Like = %v %v", ok, err)
+	}
+	if ok, err := interactions.Like(ctx, reader.ID, liked.ID); err != nil || ok {
+		t.Fatalf("重复 Like = %v %v", ok, err)
+	}
+
+	posts, err := repo.ListRecommended(ctx, reader.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, p := range posts {
+		found = found || p.ID == sameTag.ID
+	}
+	if !found {
+		t.Errorf("推荐结果应包含同标签的帖子: %v", posts)
+	}
 }
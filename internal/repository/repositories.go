@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"qwqserver/internal/model"
+	"time"
+)
+
+// Repositories 全部领域仓库，由应用启动时创建并传递给服务层与后台任务
+type Repositories struct {
+	Users        UserRepository
+	Posts        PostRepository
+	Interactions InteractionRepository
+	Features     FeatureRepository
+	Auth         AuthRepository
+
+	// trash 回收站定期清理的模型，按顺序清理
+	trash []trashPurger
+}
+
+// trashPurger 可彻底删除过期回收站记录的仓库
+type trashPurger interface {
+	PurgeBefore(ctx context.Context, before time.Time, batch int) (int64, error)
+}
+
+// New 使用给定的数据库与 Redis 连接创建全部仓库，rdb 可以为 nil
+func New(db *gorm.DB, rdb *redis.Client) *Repositories {
+	collections := NewBaseRepository[model.Collection](db, rdb)
+	collections.beforePurge = purgeCollectionRelations
+	repos := &Repositories{
+		Users:        NewUserRepository(db, rdb),
+		Posts:        NewPostRepository(db, rdb),
+		Interactions: NewInteractionRepository(db),
+		Features:     NewFeatureRepository(db, rdb),
+		Auth:         NewAuthRepository(db, rdb),
+	}
+	repos.trash = []trashPurger{
+		NewBaseRepository[model.PostFeature](db, rdb),
+		collections,
+		repos.Posts.(trashPurger),
+		repos.Users.(trashPurger),
+	}
+	return repos
+}
+
+// PurgeTrash 彻底删除在 before 之前移入回收站的精华记录、专题、帖子与用户，返回删除的数量
+func (r *Repositories) PurgeTrash(ctx context.Context, before time.Time, batch int) (int64, error) {
+	var total int64
+	for _, repo := range r.trash {
+		n, err := repo.PurgeBefore(ctx, before, batch)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
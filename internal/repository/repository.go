@@ -4,32 +4,38 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/go-redis/redis/v8"
-	"qwqserver/pkg/database"
+	"github.com/redis/go-redis/v9"
 
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrNotFound 记录不存在
+	ErrNotFound = errors.New("记录不存在")
+
+	// ErrVersionConflict 乐观锁版本冲突，记录已被其他人修改
+	ErrVersionConflict = errors.New("记录已被修改")
+)
+
 // BaseRepository 提供基础的 CRUD 操作
+// 模型嵌入 model.Model 时 Delete 为软删除，记录进入回收站，见 trash.go
 type BaseRepository[T any] struct {
 	db    *gorm.DB
 	redis *redis.Client
+
+	// beforePurge 彻底删除记录前在同一事务中清理关联数据，可以为 nil
+	beforePurge func(tx *gorm.DB, ids []uint) error
 }
 
-// NewBaseRepository 创建新的基础 Repository
-func NewBaseRepository[T any]() (*BaseRepository[T], error) {
-	db, err := database.GetDB()
-	if err != nil {
-		return nil, fmt.Errorf("获取数据库连接失败: %w", err)
-	}
-	return &BaseRepository[T]{db: db}, nil
+// NewBaseRepository 使用给定的数据库连接创建基础 Repository，rdb 可以为 nil
+func NewBaseRepository[T any](db *gorm.DB, rdb *redis.Client) *BaseRepository[T] {
+	return &BaseRepository[T]{db: db, redis: rdb}
 }
 
-// WithTransaction 在事务中执行操作
+// WithTransaction 在事务中执行操作，已在事务中时使用保存点嵌套
 func (r *BaseRepository[T]) WithTransaction(ctx context.Context, fn func(txRepo *BaseRepository[T]) error) error {
-	return database.WithTransaction(ctx, func(tx *gorm.DB) error {
-		txRepo := &BaseRepository[T]{db: tx}
-		return fn(txRepo)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&BaseRepository[T]{db: tx, redis: r.redis, beforePurge: r.beforePurge})
 	})
 }
 
@@ -49,7 +55,7 @@ func (r *BaseRepository[T]) Update(ctx context.Context, entity *T) error {
 	return nil
 }
 
-// Delete 删除记录
+// Delete 删除记录，可软删除的模型移入回收站
 func (r *BaseRepository[T]) Delete(ctx context.Context, id uint) error {
 	var entity T
 	if err := r.db.WithContext(ctx).Delete(&entity, id).Error; err != nil {
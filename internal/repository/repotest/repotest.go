@@ -0,0 +1,289 @@
+// Package repotest 仓库的测试替身
+//
+//   - Users：基于 map 的 UserRepository，不需要数据库，适合只依赖用户数据的服务测试
+//   - Open：在进程内的 SQLite 内存数据库上执行迁移并创建全部仓库，每次调用互不影响
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"qwqserver/internal/migrations"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/database"
+)
+
+// dbSeq 内存数据库编号，同一进程内的每个数据库使用不同的名称
+var dbSeq atomic.Int64
+
+// Open 创建一个只在当前测试中存在的内存数据库，执行全部迁移后返回其上的仓库
+func Open(t testing.TB) *repository.Repositories {
+	t.Helper()
+	dsn := fmt.Sprintf("file:repotest_%d?mode=memory&cache=shared&_busy_timeout=5000&_foreign_keys=on", dbSeq.Add(1))
+	conn, err := database.Open(&database.Config{
+		Driver:          "sqlite",
+		DSN:             dsn,
+		LogLevel:        "silent",
+		MaxOpenConns:    4,
+		MaxIdleConns:    4, // 内存数据库在最后一个连接关闭时销毁，保持空闲连接
+		ConnMaxLifetime: time.Hour,
+		ConnMaxIdleTime: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("打开内存数据库失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if _, err := migrations.Up(context.Background(), conn.DB(), nil); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	return repository.New(conn.DB(), nil)
+}
+
+// Users 基于 map 的用户仓库，并发安全；WithTransaction 直接执行，不支持回滚
+type Users struct {
+	mu      sync.Mutex
+	nextID  uint
+	users   map[uint]model.User
+	history map[uint][]string // 用户ID → 密码哈希，新的在前
+}
+
+var _ repository.UserRepository = (*Users)(nil)
+
+// NewUsers 创建用户仓库，可传入初始用户，ID 为 0 时自动分配
+func NewUsers(users ...*model.User) *Users {
+	r := &Users{users: map[uint]model.User{}, history: map[uint][]string{}}
+	for _, u := range users {
+		if err := r.Create(context.Background(), u); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// get 按ID读取用户副本，调用方持有锁
+func (r *Users) get(id uint) (*model.User, bool) {
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt.Valid {
+		return nil, false
+	}
+	return &u, true
+}
+
+// find 查找第一个满足条件的用户，调用方持有锁
+func (r *Users) find(match func(u *model.User) bool) *model.User {
+	for _, id := range r.ids() {
+		if u, ok := r.get(id); ok && match(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+// taken 是否有用户（包括回收站中的用户）满足条件，与数据库唯一索引的范围一致，调用方持有锁
+func (r *Users) taken(match func(u *model.User) bool) bool {
+	for _, u := range r.users {
+		if match(&u) {
+			return true
+		}
+	}
+	return false
+}
+
+// ids 按ID升序排列的全部用户ID，调用方持有锁
+func (r *Users) ids() []uint {
+	ids := make([]uint, 0, len(r.users))
+	for id := range r.users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// FindByID 根据ID查找用户，不存在时返回 nil
+func (r *Users) FindByID(ctx context.Context, id uint) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, _ := r.get(id)
+	return u, nil
+}
+
+// Create 创建用户，用户名或邮箱重复时返回错误
+func (r *Users) Create(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.taken(func(u *model.User) bool { return u.Username == user.Username }) {
+		return fmt.Errorf("创建记录失败: 用户名 %q 已存在", user.Username)
+	}
+	if user.Email != "" && r.taken(func(u *model.User) bool { return u.Email == user.Email }) {
+		return fmt.Errorf("创建记录失败: 邮箱 %q 已存在", user.Email)
+	}
+	if user.ID == 0 {
+		r.nextID++
+		user.ID = r.nextID
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	if user.Status == 0 {
+		user.Status = 1
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+// Update 保存用户的全部字段
+func (r *Users) Update(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = *user
+	return nil
+}
+
+// Delete 将用户移入回收站，用户不存在时返回 repository.ErrNotFound
+func (r *Users) Delete(ctx context.Context, id uint) error {
+	if !r.update(id, func(u *model.User) { u.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true} }) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Restore 从回收站恢复用户，用户不在回收站中时返回 repository.ErrNotFound
+func (r *Users) Restore(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok || !u.DeletedAt.Valid {
+		return repository.ErrNotFound
+	}
+	u.DeletedAt = gorm.DeletedAt{}
+	r.users[id] = u
+	return nil
+}
+
+// WithTransaction 直接执行 fn，fn 返回错误时已执行的修改不会回滚
+func (r *Users) WithTransaction(ctx context.Context, fn func(repo repository.UserRepository) error) error {
+	return fn(r)
+}
+
+// FindByEmail 根据邮箱查找用户
+func (r *Users) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.find(func(u *model.User) bool { return u.Email == email }), nil
+}
+
+// FindByUsername 根据用户名查找用户
+func (r *Users) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.find(func(u *model.User) bool { return u.Username == username }), nil
+}
+
+// ExistEmail 邮箱是否已被使用，包括回收站中的用户
+func (r *Users) ExistEmail(ctx context.Context, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.taken(func(u *model.User) bool { return u.Email == email }), nil
+}
+
+// ExistUsername 用户名是否已被使用，包括回收站中的用户
+func (r *Users) ExistUsername(ctx context.Context, username string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.taken(func(u *model.User) bool { return u.Username == username }), nil
+}
+
+// List 按ID升序分页获取用户
+func (r *Users) List(ctx context.Context, page, pageSize int) ([]*model.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var all []*model.User
+	for _, id := range r.ids() {
+		if u, ok := r.get(id); ok {
+			all = append(all, u)
+		}
+	}
+	total := int64(len(all))
+	start := (page - 1) * pageSize
+	if start < 0 || start >= len(all) {
+		return nil, total, nil
+	}
+	end := min(start+pageSize, len(all))
+	return all[start:end], total, nil
+}
+
+// update 修改存在的用户，用户不存在时返回 false
+func (r *Users) update(id uint, fn func(u *model.User)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.get(id)
+	if !ok {
+		return false
+	}
+	fn(u)
+	u.UpdatedAt = time.Now()
+	r.users[id] = *u
+	return true
+}
+
+// UpdateLocale 更新语言偏好
+func (r *Users) UpdateLocale(ctx context.Context, id uint, locale string) error {
+	r.update(id, func(u *model.User) { u.Locale = locale })
+	return nil
+}
+
+// UpdatePassword 更新密码哈希与修改时间，用户不存在时返回 repository.ErrNotFound
+func (r *Users) UpdatePassword(ctx context.Context, id uint, hash string, changedAt time.Time) error {
+	if !r.update(id, func(u *model.User) {
+		u.Password = hash
+		u.PasswordChangedAt = &changedAt
+	}) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// RehashPassword 当前哈希仍为 oldHash 时替换为 newHash
+func (r *Users) RehashPassword(ctx context.Context, id uint, oldHash, newHash string) error {
+	r.update(id, func(u *model.User) {
+		if u.Password == oldHash {
+			u.Password = newHash
+		}
+	})
+	return nil
+}
+
+// PasswordHistory 最近使用过的密码哈希，新的在前
+func (r *Users) PasswordHistory(ctx context.Context, id uint, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := r.history[id]
+	if len(h) > limit {
+		h = h[:limit]
+	}
+	return append([]string(nil), h...), nil
+}
+
+// AddPasswordHistory 记录密码哈希，只保留最近 keep 条
+func (r *Users) AddPasswordHistory(ctx context.Context, id uint, hash string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := append([]string{hash}, r.history[id]...)
+	if len(h) > keep {
+		h = h[:keep]
+	}
+	r.history[id] = h
+	return nil
+}
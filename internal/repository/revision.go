@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"qwqserver/internal/model"
+)
+
+// UpdateWithRevision 在事务中更新帖子并写入修订记录
+func (r *postRepository) UpdateWithRevision(ctx context.Context, post *model.Post, editorID uint, summary string) (*model.PostRevision, error) {
+	var revision *model.PostRevision
+	err := r.WithTransaction(ctx, func(repo PostRepository) error {
+		txRepo := repo.(*postRepository)
+		db := txRepo.db.WithContext(ctx)
+
+		latest, err := txRepo.LatestRevision(ctx, post.ID)
+		if err != nil {
+			return err
+		}
+		version := 1
+		if latest == nil {
+			// 首次编辑：先保存编辑前的原始内容作为版本 1
+			var original model.Post
+			if err := db.Select("id", "author_id", "title", "content", "mod").
+				First(&original, post.ID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("帖子不存在: %w", ErrNotFound)
+				}
+				return fmt.Errorf("读取帖子失败: %w", err)
+			}
+			if err := db.Create(&model.PostRevision{
+				PostID:   post.ID,
+				Version:  1,
+				Title:    original.Title,
+				Content:  original.Content,
+				Mod:      original.Mod,
+				EditorID: uint(original.AuthorID),
+				Summary:  "原始版本",
+			}).Error; err != nil {
+				return fmt.Errorf("保存原始版本失败: %w", err)
+			}
+			version = 2
+		} else {
+			version = latest.Version + 1
+		}
+
+		if err := txRepo.UpdateContent(ctx, post); err != nil {
+			return err
+		}
+
+		revision = &model.PostRevision{
+			PostID:   post.ID,
+			Version:  version,
+			Title:    post.Title,
+			Content:  post.Content,
+			Mod:      post.Mod,
+			EditorID: editorID,
+			Summary:  summary,
+		}
+		if err := db.Create(revision).Error; err != nil {
+			return fmt.Errorf("保存修订记录失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// ListRevisions 获取帖子的修订列表
+func (r *postRepository) ListRevisions(ctx context.Context, postID uint, page, pageSize int) ([]*model.PostRevision, int64, error) {
+	revisions, total, err := findPage[model.PostRevision](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return db.Where("post_id = ?", postID)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Omit("content").Order("version DESC")
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询修订列表失败: %w", err)
+	}
+	return revisions, total, nil
+}
+
+// FindRevision 获取指定版本的修订
+func (r *postRepository) FindRevision(ctx context.Context, postID uint, version int) (*model.PostRevision, error) {
+	var revision model.PostRevision
+	if err := r.db.WithContext(ctx).
+		Where("post_id = ? AND version = ?", postID, version).
+		First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查找修订失败: %w", err)
+	}
+	return &revision, nil
+}
+
+// LatestRevision 获取最新修订
+func (r *postRepository) LatestRevision(ctx context.Context, postID uint) (*model.PostRevision, error) {
+	var revision model.PostRevision
+	if err := r.db.WithContext(ctx).
+		Where("post_id = ?", postID).
+		Order("version DESC").
+		First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查找修订失败: %w", err)
+	}
+	return &revision, nil
+}
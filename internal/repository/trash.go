@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"qwqserver/internal/model"
+)
+
+// 回收站：嵌入 model.Model 的模型删除时只设置 deleted_at，普通查询自动排除，
+// 之后可以恢复，或彻底删除（连同关联数据）。超过保留期的记录由后台任务调用 PurgeBefore 清理。
+
+// unscoped 包含回收站记录的查询，可重复使用
+func (r *BaseRepository[T]) unscoped(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Unscoped().Session(&gorm.Session{})
+}
+
+// Trash 分页获取回收站中的记录，q 的过滤、排序与分页同 Paginate，scope 附加固定的查询条件，可以为 nil
+func (r *BaseRepository[T]) Trash(ctx context.Context, q *ListQuery, scope func(db *gorm.DB) *gorm.DB) (*Page[T], error) {
+	trash := &BaseRepository[T]{db: r.unscoped(ctx)}
+	return trash.Paginate(ctx, q, func(db *gorm.DB) *gorm.DB {
+		db = db.Where("deleted_at IS NOT NULL")
+		if scope != nil {
+			db = scope(db)
+		}
+		return db
+	})
+}
+
+// FindTrashed 查找回收站中的记录，不存在或不在回收站中时返回 nil
+func (r *BaseRepository[T]) FindTrashed(ctx context.Context, id uint) (*T, error) {
+	var entity T
+	if err := r.unscoped(ctx).Where("deleted_at IS NOT NULL").First(&entity, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查找回收站记录失败: %w", err)
+	}
+	return &entity, nil
+}
+
+// Restore 从回收站恢复记录，记录不存在或不在回收站中时返回 ErrNotFound
+func (r *BaseRepository[T]) Restore(ctx context.Context, id uint) error {
+	result := r.unscoped(ctx).Model(new(T)).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		UpdateColumn("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("恢复记录失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Purge 彻底删除回收站中的记录及其关联数据，记录不存在或不在回收站中时返回 ErrNotFound
+func (r *BaseRepository[T]) Purge(ctx context.Context, id uint) error {
+	n, err := r.purge(ctx, []uint{id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PurgeBefore 彻底删除在 before 之前移入回收站的记录，每个事务处理 batch 条，返回删除的数量
+func (r *BaseRepository[T]) PurgeBefore(ctx context.Context, before time.Time, batch int) (int64, error) {
+	if batch <= 0 {
+		batch = 500
+	}
+	var total int64
+	for {
+		var ids []uint
+		if err := r.unscoped(ctx).Model(new(T)).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("id ASC").
+			Limit(batch).
+			Pluck("id", &ids).Error; err != nil {
+			return total, fmt.Errorf("查询过期回收站记录失败: %w", err)
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		n, err := r.purge(ctx, ids)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if len(ids) < batch {
+			return total, nil
+		}
+	}
+}
+
+// purge 在事务中彻底删除 ids 中仍在回收站的记录，先由 beforePurge 清理关联数据
+func (r *BaseRepository[T]) purge(ctx context.Context, ids []uint) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var trashed []uint
+		if err := tx.Unscoped().Model(new(T)).
+			Where("id IN ? AND deleted_at IS NOT NULL", ids).
+			Pluck("id", &trashed).Error; err != nil {
+			return fmt.Errorf("查询回收站记录失败: %w", err)
+		}
+		if len(trashed) == 0 {
+			return nil
+		}
+		if r.beforePurge != nil {
+			if err := r.beforePurge(tx, trashed); err != nil {
+				return err
+			}
+		}
+		result := tx.Unscoped().Where("id IN ?", trashed).Delete(new(T))
+		if result.Error != nil {
+			return fmt.Errorf("彻底删除记录失败: %w", result.Error)
+		}
+		n = result.RowsAffected
+		return nil
+	})
+	return n, err
+}
+
+// purgePostRelations 清理帖子的关联数据：标签关联、点赞、收藏、修订、精华与专题条目
+func purgePostRelations(tx *gorm.DB, postIDs []uint) error {
+	if err := tx.Exec("DELETE FROM post_tags WHERE post_id IN ?", postIDs).Error; err != nil {
+		return fmt.Errorf("删除帖子标签失败: %w", err)
+	}
+	for _, m := range []any{&model.PostLike{}, &model.Bookmark{}, &model.PostRevision{}, &model.CollectionItem{}, &model.PostFeature{}} {
+		if err := tx.Unscoped().Where("post_id IN ?", postIDs).Delete(m).Error; err != nil {
+			return fmt.Errorf("删除帖子关联数据失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// purgeUserRelations 清理用户的关联数据：彻底删除用户的全部帖子（含未删除的），
+// 删除用户的点赞、收藏与密码历史；其他帖子上的点赞、收藏计数不回退
+func purgeUserRelations(tx *gorm.DB, userIDs []uint) error {
+	var postIDs []uint
+	if err := tx.Unscoped().Model(&model.Post{}).Where("author_id IN ?", userIDs).Pluck("id", &postIDs).Error; err != nil {
+		return fmt.Errorf("查询用户帖子失败: %w", err)
+	}
+	if len(postIDs) > 0 {
+		if err := purgePostRelations(tx, postIDs); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("id IN ?", postIDs).Delete(&model.Post{}).Error; err != nil {
+			return fmt.Errorf("删除用户帖子失败: %w", err)
+		}
+	}
+	for _, m := range []any{&model.PostLike{}, &model.Bookmark{}, &model.PasswordHistory{}} {
+		if err := tx.Where("user_id IN ?", userIDs).Delete(m).Error; err != nil {
+			return fmt.Errorf("删除用户关联数据失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// purgeCollectionRelations 清理专题的条目
+func purgeCollectionRelations(tx *gorm.DB, collectionIDs []uint) error {
+	if err := tx.Where("collection_id IN ?", collectionIDs).Delete(&model.CollectionItem{}).Error; err != nil {
+		return fmt.Errorf("删除专题条目失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"qwqserver/internal/model"
+)
+
+func TestPostTrash(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepos.Posts
+	author := newTestUser(t, "trash_author")
+	reader := newTestUser(t, "trash_reader")
+	post := newTestPost(t, repo, author, "回收站", model.Tag{Name: "trash_tag"})
+
+	if _, err := testRepos.Interactions.Like(ctx, reader.ID, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testRepos.Interactions.Bookmark(ctx, reader.ID, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRepos.Features.Feature(ctx, &model.PostFeature{PostID: post.ID, OperatorID: author.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未删除的帖子不能恢复或彻底删除
+	if err := repo.Restore(ctx, post.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("恢复未删除的帖子 err = %v", err)
+	}
+	if err := repo.Purge(ctx, post.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("彻底删除未删除的帖子 err = %v", err)
+	}
+
+	// 删除后进入回收站
+	if err := repo.Delete(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.FindByID(ctx, post.ID); got != nil {
+		t.Errorf("删除后 FindByID = %+v", got)
+	}
+	trashed, err := repo.FindTrashed(ctx, post.ID)
+	if err != nil || trashed == nil || !trashed.DeletedAt.Valid {
+		t.Fatalf("FindTrashed = %+v %v", trashed, err)
+	}
+	page, err := repo.Trash(ctx, &ListQuery{Page: 1, PageSize: 10}, func(db *gorm.DB) *gorm.DB {
+		return db.Where("author_id = ?", author.ID)
+	})
+	if err != nil || len(page.List) != 1 || page.List[0].ID != post.ID {
+		t.Fatalf("Trash = %+v %v", page, err)
+	}
+
+	// 恢复
+	if err := repo.Restore(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.FindByID(ctx, post.ID); got == nil {
+		t.Fatal("恢复后找不到帖子")
+	}
+	if got, _ := repo.FindTrashed(ctx, post.ID); got != nil {
+		t.Errorf("恢复后仍在回收站: %+v", got)
+	}
+
+	// 彻底删除同时删除点赞、收藏、精华与标签关联
+	if err := repo.Delete(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Purge(ctx, post.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if got, _ := repo.FindTrashed(ctx, post.ID); got != nil {
+		t.Errorf("彻底删除后仍在回收站: %+v", got)
+	}
+	if ok, _ := testRepos.Interactions.IsLiked(ctx, reader.ID, post.ID); ok {
+		t.Error("彻底删除后点赞仍存在")
+	}
+	if ok, _ := testRepos.Interactions.IsBookmarked(ctx, reader.ID, post.ID); ok {
+		t.Error("彻底删除后收藏仍存在")
+	}
+	if f, _ := testRepos.Features.FindFeature(ctx, post.ID); f != nil {
+		t.Errorf("彻底删除后精华记录仍存在: %+v", f)
+	}
+	if err := repo.Purge(ctx, post.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("重复彻底删除 err = %v", err)
+	}
+}
+
+func TestUserTrashCascade(t *testing.T) {
+	ctx := context.Background()
+	posts, users := testRepos.Posts, testRepos.Users
+	author := newTestUser(t, "cascade_author")
+	kept := newTestPost(t, posts, author, "随用户删除")
+	deleted := newTestPost(t, posts, author, "提前删除")
+	if err := posts.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// 删除用户时其帖子一起进入回收站
+	if err := users.Delete(ctx, author.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := users.FindByID(ctx, author.ID); got != nil {
+		t.Errorf("删除后 FindByID = %+v", got)
+	}
+	if got, _ := posts.FindByID(ctx, kept.ID); got != nil {
+		t.Errorf("删除用户后帖子仍可见: %+v", got)
+	}
+
+	// 恢复用户只恢复随用户删除的帖子
+	if err := users.Restore(ctx, author.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := users.FindByID(ctx, author.ID); got == nil {
+		t.Fatal("恢复后找不到用户")
+	}
+	if got, _ := posts.FindByID(ctx, kept.ID); got == nil {
+		t.Error("恢复用户后帖子未恢复")
+	}
+	if got, _ := posts.FindByID(ctx, deleted.ID); got != nil {
+		t.Errorf("用户删除前已删除的帖子被恢复: %+v", got)
+	}
+	if err := users.Restore(ctx, author.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("重复恢复 err = %v", err)
+	}
+
+	// 超过保留期后彻底删除用户及其全部帖子
+	if err := users.Delete(ctx, author.ID); err != nil {
+		t.Fatal(err)
+	}
+	n, err := testRepos.PurgeTrash(ctx, time.Now().Add(time.Second), 1)
+	if err != nil || n < 2 {
+		t.Fatalf("PurgeTrash = %d %v", n, err)
+	}
+	for _, id := range []uint{kept.ID, deleted.ID} {
+		if got, _ := posts.FindTrashed(ctx, id); got != nil {
+			t.Errorf("帖子 %d 未被彻底删除", id)
+		}
+	}
+	if n, err := testRepos.PurgeTrash(ctx, time.Now().Add(time.Second), 1); err != nil || n != 0 {
+		t.Errorf("再次清理 = %d %v", n, err)
+	}
+}
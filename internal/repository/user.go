@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"qwqserver/internal/model"
+	"time"
 )
 
 // UserRepository 用户领域仓库接口
@@ -12,7 +15,10 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id uint) (*model.User, error)
 	Create(ctx context.Context, user *model.User) error
 	Update(ctx context.Context, user *model.User) error
+	// Delete 将用户及其帖子移入回收站
 	Delete(ctx context.Context, id uint) error
+	// Restore 从回收站恢复用户及随用户一起删除的帖子，用户不在回收站中时返回 ErrNotFound
+	Restore(ctx context.Context, id uint) error
 	WithTransaction(ctx context.Context, fn func(repo UserRepository) error) error
 
 	// ---------- User 相关操作 ----------- //
@@ -22,6 +28,11 @@ type UserRepository interface {
 	ExistEmail(ctx context.Context, email string) (bool, error)
 	ExistUsername(ctx context.Context, username string) (bool, error)
 	List(ctx context.Context, page, pageSize int) ([]*model.User, int64, error)
+	UpdateLocale(ctx context.Context, id uint, locale string) error
+	UpdatePassword(ctx context.Context, id uint, hash string, changedAt time.Time) error
+	RehashPassword(ctx context.Context, id uint, oldHash, newHash string) error
+	PasswordHistory(ctx context.Context, id uint, limit int) ([]string, error)
+	AddPasswordHistory(ctx context.Context, id uint, hash string, keep int) error
 }
 
 // userRepository 用户仓库实现
@@ -57,49 +68,151 @@ func (r userRepository) FindByUsername(ctx context.Context, username string) (*m
 	return u, nil
 }
 
-// ExistEmail 判断用户是否存在
+// ExistEmail 判断邮箱是否已被使用，包括回收站中的用户（与唯一索引的范围一致）
 func (r userRepository) ExistEmail(ctx context.Context, email string) (bool, error) {
-	user, err := r.First(ctx, func(db *gorm.DB) *gorm.DB {
-		return db.Where("email = ?", email)
-	})
-	if user == nil {
-		return false, err
-	}
-	return true, nil
+	return r.exists(ctx, "email = ?", email)
 }
 
-// ExistUsername 检测用户名是否存在 存在则为true
+// ExistUsername 检测用户名是否存在 存在则为true，包括回收站中的用户
 func (r userRepository) ExistUsername(ctx context.Context, username string) (bool, error) {
-	user, err := r.First(ctx, func(db *gorm.DB) *gorm.DB {
-		return db.Where("username = ?", username)
-	})
-	if user == nil {
-		return false, err
+	return r.exists(ctx, "username = ?", username)
+}
+
+// exists 是否存在满足条件的用户，不排除软删除的记录
+func (r userRepository) exists(ctx context.Context, query string, args ...any) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).Where(query, args...).Limit(1).Count(&n).Error
+	if err != nil {
+		return false, fmt.Errorf("查询用户失败: %w", err)
 	}
-	return true, nil
+	return n > 0, nil
 }
 
 // List 获取用户列表
 func (r userRepository) List(ctx context.Context, page, pageSize int) ([]*model.User, int64, error) {
-	offset := (page - 1) * pageSize
-
-	// 获取总数
-	total, err := r.Count(ctx, func(db *gorm.DB) *gorm.DB {
-		return db.Model(&model.User{})
+	users, total, err := findPage[model.User](r.db.WithContext(ctx), page, pageSize, func(db *gorm.DB) *gorm.DB {
+		return db
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
 	})
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, fmt.Errorf("查询用户列表失败: %w", err)
+	}
+	return users, total, nil
+}
+
+// UpdateLocale 更新用户的语言偏好
+func (r userRepository) UpdateLocale(ctx context.Context, id uint, locale string) error {
+	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("locale", locale)
+	if result.Error != nil {
+		return fmt.Errorf("更新语言偏好失败: %w", result.Error)
 	}
+	return nil
+}
 
-	// 获取分页数据
-	users, err := r.Query(ctx, func(db *gorm.DB) *gorm.DB {
-		return db.Offset(offset).Limit(pageSize)
+// UpdatePassword 更新用户的密码哈希与密码修改时间
+func (r userRepository) UpdatePassword(ctx context.Context, id uint, hash string, changedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]any{
+		"password":            hash,
+		"password_changed_at": changedAt,
 	})
+	if result.Error != nil {
+		return fmt.Errorf("更新密码失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RehashPassword 将同一密码的旧哈希替换为新哈希，期间密码已被修改时不更新
+func (r userRepository) RehashPassword(ctx context.Context, id uint, oldHash, newHash string) error {
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		Update("password", newHash)
+	if result.Error != nil {
+		return fmt.Errorf("更新密码哈希失败: %w", result.Error)
+	}
+	return nil
+}
+
+// PasswordHistory 用户最近使用过的密码哈希，新的在前
+func (r userRepository) PasswordHistory(ctx context.Context, id uint, limit int) ([]string, error) {
+	var hashes []string
+	err := r.db.WithContext(ctx).Model(&model.PasswordHistory{}).
+		Where("user_id = ?", id).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("hash", &hashes).Error
 	if err != nil {
-		return nil, 0, err
+		return nil, fmt.Errorf("查询密码历史失败: %w", err)
+	}
+	return hashes, nil
+}
+
+// AddPasswordHistory 记录密码哈希，只保留最近 keep 条，keep 不大于 0 时不记录
+func (r userRepository) AddPasswordHistory(ctx context.Context, id uint, hash string, keep int) error {
+	if keep <= 0 {
+		return nil
 	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.PasswordHistory{UserID: id, Hash: hash}).Error; err != nil {
+			return fmt.Errorf("记录密码历史失败: %w", err)
+		}
+		var ids []uint
+		if err := tx.Model(&model.PasswordHistory{}).Where("user_id = ?", id).
+			Order("id DESC").Offset(keep).Limit(1000).Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("查询密码历史失败: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&model.PasswordHistory{}).Error; err != nil {
+			return fmt.Errorf("清理密码历史失败: %w", err)
+		}
+		return nil
+	})
+}
 
-	return users, total, nil
+// Delete 将用户移入回收站，同时以相同的删除时间移入该用户未删除的帖子，恢复时据此区分
+func (r userRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 截断到毫秒，与 MySQL 默认的 datetime(3) 精度一致，保证恢复时能按删除时间匹配
+		now := time.Now().Truncate(time.Millisecond)
+		result := tx.Model(&model.User{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+		if result.Error != nil {
+			return fmt.Errorf("删除用户失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if err := tx.Model(&model.Post{}).Where("author_id = ?", id).UpdateColumn("deleted_at", now).Error; err != nil {
+			return fmt.Errorf("删除用户帖子失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// Restore 从回收站恢复用户，以及与用户同时删除的帖子；用户删除前已在回收站中的帖子保持删除
+func (r userRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("查找回收站用户失败: %w", err)
+		}
+		if err := tx.Unscoped().Model(&model.Post{}).
+			Where("author_id = ? AND deleted_at = ?", id, user.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return fmt.Errorf("恢复用户帖子失败: %w", err)
+		}
+		if err := tx.Unscoped().Model(&model.User{}).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return fmt.Errorf("恢复用户失败: %w", err)
+		}
+		return nil
+	})
 }
 
 // WithTransaction 在事务中执行用户操作
@@ -111,10 +224,8 @@ func (r userRepository) WithTransaction(ctx context.Context, fn func(repo UserRe
 }
 
 // NewUserRepository 创建新的用户仓库
-func NewUserRepository() (UserRepository, error) {
-	baseRepo, err := NewBaseRepository[model.User]()
-	if err != nil {
-		return nil, err
-	}
-	return &userRepository{BaseRepository: baseRepo}, nil
+func NewUserRepository(db *gorm.DB, rdb *redis.Client) UserRepository {
+	base := NewBaseRepository[model.User](db, rdb)
+	base.beforePurge = purgeUserRelations
+	return &userRepository{BaseRepository: base}
 }
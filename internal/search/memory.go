@@ -0,0 +1,190 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/fulltext"
+)
+
+// docMeta 过滤用的帖子元数据
+type docMeta struct {
+	authorID  uint
+	boardID   uint
+	tags      []string
+	createdAt time.Time
+}
+
+// match 是否满足过滤条件
+func (m docMeta) match(f repository.SearchFilter) bool {
+	if f.AuthorID != 0 && m.authorID != f.AuthorID {
+		return false
+	}
+	if f.BoardID != nil && m.boardID != *f.BoardID {
+		return false
+	}
+	if f.From != nil && m.createdAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && !m.createdAt.Before(*f.To) {
+		return false
+	}
+	if f.Tag != "" {
+		for _, t := range m.tags {
+			if t == f.Tag {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// memoryEngine 基于内存倒排索引的搜索引擎
+type memoryEngine struct {
+	cfg      Config
+	posts    repository.PostRepository
+	log      Logger
+	index    *fulltext.Index
+	fallback *sqlEngine
+
+	mu    sync.RWMutex
+	metas map[uint]docMeta
+
+	// ready 索引是否已重建完成，完成前查询退回 sql 引擎
+	ready atomic.Bool
+}
+
+func newMemoryEngine(cfg Config, posts repository.PostRepository, l Logger) *memoryEngine {
+	return &memoryEngine{
+		cfg:      cfg,
+		posts:    posts,
+		log:      l,
+		index:    fulltext.NewIndex(),
+		fallback: newSQLEngine(cfg, posts),
+		metas:    make(map[uint]docMeta),
+	}
+}
+
+// Name 引擎名称
+func (e *memoryEngine) Name() string {
+	return EngineMemory
+}
+
+// Index 索引帖子，未发布或已删除的帖子会从索引中移除
+func (e *memoryEngine) Index(ctx context.Context, post *model.Post) error {
+	if post == nil {
+		return nil
+	}
+	if post.Status != "published" || post.DeletedAt.Valid {
+		return e.Remove(ctx, post.ID)
+	}
+
+	tags := make([]string, 0, len(post.Tags))
+	for _, t := range post.Tags {
+		tags = append(tags, t.Name)
+	}
+
+	e.index.Add(fulltext.Document{
+		ID: uint64(post.ID),
+		Fields: []fulltext.Field{
+			{Name: "title", Text: post.Title, Boost: e.cfg.TitleBoost},
+			{Name: "content", Text: post.Content, Boost: 1},
+		},
+	})
+
+	e.mu.Lock()
+	e.metas[post.ID] = docMeta{
+		authorID:  uint(post.AuthorID),
+		boardID:   post.BoardID,
+		tags:      tags,
+		createdAt: post.CreatedAt,
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// Remove 从索引中移除帖子
+func (e *memoryEngine) Remove(ctx context.Context, postID uint) error {
+	e.index.Remove(uint64(postID))
+	e.mu.Lock()
+	delete(e.metas, postID)
+	e.mu.Unlock()
+	return nil
+}
+
+// Search 搜索帖子，结果按相关度排序
+func (e *memoryEngine) Search(ctx context.Context, q Query) (*Result, error) {
+	parsed := fulltext.ParseQuery(q.Text)
+	if parsed.Empty() || !e.ready.Load() {
+		return e.fallback.Search(ctx, q)
+	}
+
+	e.mu.RLock()
+	hits := e.index.Search(parsed, func(id uint64) bool {
+		m, ok := e.metas[uint(id)]
+		return ok && m.match(q.SearchFilter)
+	})
+	e.mu.RUnlock()
+
+	res := &Result{Engine: EngineMemory, Total: int64(len(hits))}
+	offset := (q.Page - 1) * q.PageSize
+	if offset >= len(hits) {
+		res.Hits = []*Hit{}
+		return res, nil
+	}
+	hits = hits[offset:min(offset+q.PageSize, len(hits))]
+
+	ids := make([]uint, len(hits))
+	scores := make(map[uint]float64, len(hits))
+	for i, h := range hits {
+		ids[i] = uint(h.ID)
+		scores[uint(h.ID)] = h.Score
+	}
+
+	posts, err := e.posts.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := parsed.AllTerms()
+	res.Hits = make([]*Hit, 0, len(posts))
+	for _, post := range posts {
+		res.Hits = append(res.Hits, &Hit{
+			Post:      post,
+			Score:     scores[post.ID],
+			Highlight: highlight(post, terms, e.cfg.SnippetLength),
+		})
+	}
+	return res, nil
+}
+
+// Rebuild 从数据库重建索引
+func (e *memoryEngine) Rebuild(ctx context.Context) error {
+	var afterID uint
+	for {
+		posts, err := e.posts.ListForIndex(ctx, afterID, e.cfg.RebuildBatch)
+		if err != nil {
+			return err
+		}
+		for _, post := range posts {
+			_ = e.Index(ctx, post)
+			afterID = post.ID
+		}
+		if len(posts) < e.cfg.RebuildBatch {
+			break
+		}
+	}
+	e.ready.Store(true)
+	return nil
+}
+
+func (e *memoryEngine) logError(msg string, args ...any) {
+	if e.log != nil {
+		e.log.Error(msg, args...)
+	}
+}
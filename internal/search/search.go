@@ -0,0 +1,126 @@
+// Package search 帖子全文检索
+//
+// 提供两种引擎：
+//   - memory：内置倒排索引（pkg/fulltext），支持中日韩二元组分词、短语查询与 BM25 相关度排序，
+//     启动时从数据库重建索引，帖子创建/更新/删除时增量维护
+//   - sql：数据库 LIKE 查询，作为后备实现
+//
+// memory 引擎在索引尚未重建完成或查询词为空时自动退回 sql 引擎。
+package search
+
+import (
+	"context"
+	"time"
+
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/fulltext"
+)
+
+// 引擎名称
+const (
+	EngineMemory = "memory"
+	EngineSQL    = "sql"
+)
+
+// Query 搜索条件
+type Query struct {
+	Text string // 查询词，双引号包裹的部分为短语
+	repository.SearchFilter
+	Page     int
+	PageSize int
+}
+
+// Highlight 命中片段，已做 HTML 转义，命中词以 <mark> 包裹
+type Highlight struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// Hit 搜索命中
+type Hit struct {
+	Post      *model.Post `json:"post"`
+	Score     float64     `json:"score"`
+	Highlight Highlight   `json:"highlight"`
+}
+
+// Result 搜索结果
+type Result struct {
+	Engine string `json:"engine"`
+	Hits   []*Hit `json:"list"`
+	Total  int64  `json:"total"`
+}
+
+// Engine 搜索引擎接口
+type Engine interface {
+	// Name 引擎名称
+	Name() string
+
+	// Index 索引帖子，未发布的帖子会从索引中移除
+	Index(ctx context.Context, post *model.Post) error
+
+	// Remove 从索引中移除帖子
+	Remove(ctx context.Context, postID uint) error
+
+	// Search 搜索帖子
+	Search(ctx context.Context, q Query) (*Result, error)
+}
+
+// Config 搜索配置
+type Config struct {
+	Engine        string  // 引擎名称
+	SnippetLength int     // 高亮摘要长度（字符）
+	TitleBoost    float64 // 标题命中权重
+	RebuildBatch  int     // 重建索引每批读取的帖子数
+}
+
+// Logger 日志接口
+type Logger interface {
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// New 按配置创建搜索引擎，memory 引擎会在后台从数据库重建索引
+func New(cfg Config, posts repository.PostRepository, l Logger) Engine {
+	cfg = cfg.withDefaults()
+	if cfg.Engine == EngineSQL {
+		return newSQLEngine(cfg, posts)
+	}
+
+	m := newMemoryEngine(cfg, posts, l)
+	go func() {
+		start := time.Now()
+		if err := m.Rebuild(context.Background()); err != nil {
+			m.logError("重建搜索索引失败: %v", err)
+			return
+		}
+		if l != nil {
+			l.Info("搜索索引重建完成，共 %d 篇帖子，耗时 %v", m.index.Len(), time.Since(start))
+		}
+	}()
+	return m
+}
+
+func (c Config) withDefaults() Config {
+	if c.Engine == "" {
+		c.Engine = EngineMemory
+	}
+	if c.SnippetLength <= 0 {
+		c.SnippetLength = 120
+	}
+	if c.TitleBoost <= 0 {
+		c.TitleBoost = 3
+	}
+	if c.RebuildBatch <= 0 {
+		c.RebuildBatch = 500
+	}
+	return c
+}
+
+// highlight 生成帖子的高亮片段
+func highlight(post *model.Post, terms []string, snippetLength int) Highlight {
+	return Highlight{
+		Title:   fulltext.Highlight(post.Title, terms, fulltext.HighlightOptions{}),
+		Content: fulltext.Highlight(post.Content, terms, fulltext.HighlightOptions{MaxLen: snippetLength}),
+	}
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"context"
+
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/fulltext"
+)
+
+// sqlEngine 基于数据库 LIKE 查询的搜索引擎，不维护索引
+type sqlEngine struct {
+	cfg   Config
+	posts repository.PostRepository
+}
+
+func newSQLEngine(cfg Config, posts repository.PostRepository) *sqlEngine {
+	return &sqlEngine{cfg: cfg, posts: posts}
+}
+
+// Name 引擎名称
+func (e *sqlEngine) Name() string {
+	return EngineSQL
+}
+
+// Index 数据库即索引，无需处理
+func (e *sqlEngine) Index(ctx context.Context, post *model.Post) error {
+	return nil
+}
+
+// Remove 数据库即索引，无需处理
+func (e *sqlEngine) Remove(ctx context.Context, postID uint) error {
+	return nil
+}
+
+// Search 搜索帖子，结果按创建时间倒序
+func (e *sqlEngine) Search(ctx context.Context, q Query) (*Result, error) {
+	posts, total, err := e.posts.Search(ctx, q.Text, q.SearchFilter, q.Page, q.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := fulltext.ParseQuery(q.Text).AllTerms()
+	res := &Result{Engine: EngineSQL, Total: total, Hits: make([]*Hit, 0, len(posts))}
+	for _, post := range posts {
+		res.Hits = append(res.Hits, &Hit{Post: post, Highlight: highlight(post, terms, e.cfg.SnippetLength)})
+	}
+	return res, nil
+}
@@ -0,0 +1,538 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"qwqserver/internal/common"
+	"qwqserver/internal/editing"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/middleware"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/search"
+	"qwqserver/internal/service"
+	"qwqserver/internal/service/authv2"
+	"qwqserver/pkg/diff"
+	"qwqserver/pkg/docsite"
+	"qwqserver/pkg/openapi"
+)
+
+const (
+	// openAPIPath OpenAPI 文档地址
+	openAPIPath = "/openapi.json"
+
+	// swaggerPath Swagger UI 地址
+	swaggerPath = "/swagger"
+
+	// securityBearer 访问令牌认证方式名称
+	securityBearer = "BearerAuth"
+
+	// securityRefresh 刷新令牌认证方式名称
+	securityRefresh = "RefreshToken"
+)
+
+// apiTags 接口分组说明，分组由路由组推断（/api/v1/{group}/...）
+var apiTags = []openapi.Tag{
+	{Name: "auth", Description: "用户注册、登录、登出、删除、语言偏好与密码管理"},
+	{Name: "post", Description: "帖子发布与编辑、修订历史、草稿与编辑状态、搜索、互动与精华"},
+	{Name: "collection", Description: "专题"},
+	{Name: "docs", Description: "文档站点与文档搜索"},
+	{Name: "system", Description: "健康检测与接口文档"},
+}
+
+// apiTagOf 由路由路径推断分组
+func apiTagOf(path string) string {
+	if rest, ok := strings.CutPrefix(path, "/api/v1/"); ok {
+		tag, _, _ := strings.Cut(rest, "/")
+		return tag
+	}
+	if path == "/docs" || strings.HasPrefix(path, "/docs/") {
+		return "docs"
+	}
+	return "system"
+}
+
+// 以下结构仅用于描述接口响应，字段与服务返回的 gin.H 一致
+
+// pageData 分页列表
+type pageData[T any] struct {
+	List     []T   `json:"list"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+// searchData 帖子搜索结果
+type searchData struct {
+	pageData[*search.Hit]
+	Engine string `json:"engine"`
+}
+
+// popularData 热门帖子
+type popularData struct {
+	List   []*model.Post `json:"list"`
+	Source string        `json:"source"` // 排行来源：redis 或 sql
+}
+
+// postDetailData 帖子详情
+type postDetailData struct {
+	Post       *model.Post `json:"post"`
+	Render     string      `json:"render"`               // 渲染后的 HTML
+	Liked      bool        `json:"liked,omitempty"`      // 当前用户是否已点赞，登录时返回
+	Bookmarked bool        `json:"bookmarked,omitempty"` // 当前用户是否已收藏，登录时返回
+}
+
+// postRevisionData 帖子更新与回滚结果
+type postRevisionData struct {
+	Post     *model.Post `json:"post"`
+	Revision int         `json:"revision"` // 生成的修订版本号
+}
+
+// revisionDiffData 修订版本差异
+type revisionDiffData struct {
+	From         int            `json:"from"`
+	To           int            `json:"to"`
+	TitleChanged bool           `json:"title_changed"`
+	Title        []diff.Segment `json:"title"`
+	Hunks        []diff.Hunk    `json:"hunks"`
+	Unified      string         `json:"unified"`
+	TooLarge     bool           `json:"too_large"` // 内容超过比较的大小限制，hunks 与 unified 为空
+}
+
+// draftData 草稿
+type draftData struct {
+	Draft          *editing.Draft `json:"draft"`
+	Stale          bool           `json:"stale"`                     // 草稿是否基于过期的帖子版本
+	CurrentVersion int64          `json:"current_version,omitempty"` // 帖子当前版本号
+}
+
+// editorData 正在编辑的用户
+type editorData struct {
+	ID       uint   `json:"id"`
+	Nickname string `json:"nickname"`
+	Holder   bool   `json:"holder"`
+}
+
+// presenceData 编辑状态
+type presenceData struct {
+	LockHolder       uint         `json:"lock_holder"`
+	LockedByOther    bool         `json:"locked_by_other"`
+	Editors          []editorData `json:"editors"`
+	HeartbeatSeconds int          `json:"heartbeat_seconds"`
+}
+
+// toggleData 点赞、收藏等开关操作结果
+type toggleData struct {
+	PostID  uint `json:"post_id"`
+	Changed bool `json:"changed"` // 状态是否发生变化（重复操作时为 false）
+}
+
+// idData 创建、恢复结果
+type idData struct {
+	ID uint `json:"id"`
+}
+
+// localeData 语言偏好设置结果
+type localeData struct {
+	Locale string `json:"locale"` // 语言偏好，为空表示按 Accept-Language
+}
+
+// userIDData 注册结果
+type userIDData struct {
+	UserID uint `json:"user_id"`
+}
+
+// userData 登出、删除用户结果
+type userData struct {
+	UID      uint   `json:"uid"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// docsSearchData 文档搜索结果
+type docsSearchData struct {
+	List  []docsite.SearchResult `json:"list"`
+	Total int                    `json:"total"`
+}
+
+// healthData 健康检测结果
+type healthData struct {
+	Message string `json:"message"`
+}
+
+// 常用参数
+var (
+	paramPage     = openapi.QueryParam("page", "integer", "页码，从 1 开始", false)
+	paramPageSize = openapi.QueryParam("page_size", "integer", "每页数量", false)
+	paramLimit    = openapi.QueryParam("limit", "integer", "返回数量", false)
+	paramPostID   = openapi.QueryParam("post_id", "integer", "帖子ID", true)
+)
+
+// apiDocs 接口说明，键为 "请求方法 路径"，需与 Register 中注册的路由一一对应
+var apiDocs = map[string]openapi.Route{
+	// 系统
+	"GET /health":        {Summary: "健康检测", ContentType: "application/json", Response: healthData{}},
+	"GET " + openAPIPath: {Summary: "OpenAPI 文档", ContentType: "application/json"},
+	"GET " + swaggerPath + "/*any": {
+		Summary:     "Swagger UI",
+		Description: "访问 /swagger/index.html 查看接口文档，页面使用的 Swagger UI 静态文件内置在二进制中，由同一路由提供",
+		ContentType: "text/html",
+	},
+
+	// 文档站点
+	"GET /docs": {
+		Summary:     "文档首页",
+		Description: "携带 q 参数时返回搜索结果页面",
+		Params:      []*openapi.Parameter{openapi.QueryParam("q", "string", "搜索词", false)},
+		ContentType: "text/html",
+	},
+	"GET /docs/*path": {
+		Summary:     "文档页面与文档目录中的静态资源",
+		Description: "支持 ETag / Last-Modified 条件请求",
+		ContentType: "text/html",
+	},
+	"GET /api/v1/docs/search": {
+		Summary:  "搜索文档",
+		Auth:     openapi.AuthOptional,
+		Params:   []*openapi.Parameter{openapi.QueryParam("q", "string", "搜索词", true), paramLimit},
+		Response: docsSearchData{},
+	},
+	"GET /api/v1/docs/livereload": {
+		Summary:     "文档实时刷新",
+		Description: "SSE 连接，文档变化时推送 reload 事件，仅在开启 docs.live_reload 时注册",
+		Auth:        openapi.AuthOptional,
+		Params:      []*openapi.Parameter{openapi.QueryParam("v", "integer", "页面加载时的文档版本，与当前版本不同时立即推送 reload", false)},
+		ContentType: "text/event-stream",
+	},
+
+	// 认证
+	"POST /api/v1/auth/register": {Summary: "注册", Body: service.RegisterRequest{}, Response: userIDData{}},
+	"POST /api/v1/auth/login": {
+		Summary:     "登录",
+		Description: "使用用户名或邮箱登录，返回访问令牌与刷新令牌",
+		Body:        service.LoginRequest{},
+		Response:    common.JWTResult{},
+	},
+	"POST /api/v1/auth/logout": {Summary: "登出", Auth: openapi.AuthRequired, Body: service.UserIDRequest{}, Response: userData{}},
+	"DELETE /api/v1/auth/del":  {Summary: "删除用户", Auth: openapi.AuthRequired, Body: service.UserIDRequest{}, Response: userData{}},
+	"POST /api/v1/auth/restore": {
+		Summary:     "恢复用户",
+		Description: "需要编辑任意用户资料的权限，从回收站恢复用户及随用户删除的帖子，帖子重新加入热度榜与搜索索引",
+		Auth:        openapi.AuthRequired,
+		Body:        service.UserIDRequest{},
+		Response:    idData{},
+	},
+	"POST /api/v1/auth/locale": {
+		Summary:     "设置语言偏好",
+		Description: "设置后响应消息使用该语言，优先于 Accept-Language；locale 为空时清除偏好",
+		Auth:        openapi.AuthRequired,
+		Body:        service.LocaleRequest{},
+		Response:    localeData{},
+	},
+	"POST /api/v1/auth/password/change": {
+		Summary:     "修改密码",
+		Description: "校验旧密码，新密码需满足密码策略（长度、字符种类、强度、不含用户名与邮箱、不在常见弱密码列表中、不与最近使用过的密码相同），不满足时返回 PASSWORD_POLICY_VIOLATION 与全部未满足的策略项",
+		Auth:        openapi.AuthRequired,
+		Body:        service.ChangePasswordService{},
+	},
+	"POST /api/v1/auth/password/reset": {
+		Summary:     "重置用户密码",
+		Description: "需要编辑任意用户资料的权限，新密码同样受密码策略约束",
+		Auth:        openapi.AuthRequired,
+		Body:        service.ResetPasswordService{},
+	},
+
+	// 帖子
+	"POST /api/v1/post/create": {Summary: "创建帖子", Auth: openapi.AuthRequired, Body: service.CreatePostRequest{}, Response: model.Post{}},
+	"POST /api/v1/post/update": {
+		Summary:     "更新帖子",
+		Description: "version 为编辑开始时的版本号，与当前版本不一致时返回 409 及服务器上的最新内容",
+		Auth:        openapi.AuthRequired,
+		Body:        service.PostService{},
+		Response:    postRevisionData{},
+	},
+	"DELETE /api/v1/post/delete": {
+		Summary:     "删除帖子",
+		Description: "帖子移入回收站，可以恢复，超过保留期后自动彻底删除",
+		Auth:        openapi.AuthRequired,
+		Params:      []*openapi.Parameter{openapi.QueryParam("id", "integer", "帖子ID", true)},
+	},
+	"GET /api/v1/post/trash": {
+		Summary:     "回收站",
+		Description: "拥有 PostDeleteAny 权限时返回全部已删除的帖子，否则只返回自己的。分页方式同帖子列表",
+		Auth:        openapi.AuthRequired,
+		Params: []*openapi.Parameter{
+			openapi.QueryParam("board_id", "string", "版块ID，多个用逗号分隔", false),
+			openapi.QueryParam("author_id", "string", "作者ID，多个用逗号分隔", false),
+			openapi.QueryParam("sort", "string", "排序字段，逗号分隔，前加 - 表示降序：id、created_at、deleted_at，默认 -deleted_at", false),
+			paramPage, paramPageSize,
+			openapi.QueryParam("cursor", "string", "游标", false),
+		},
+		Response: repository.Page[model.Post]{},
+	},
+	"POST /api/v1/post/restore": {
+		Summary:  "恢复帖子",
+		Auth:     openapi.AuthRequired,
+		Params:   []*openapi.Parameter{openapi.QueryParam("id", "integer", "帖子ID", true)},
+		Response: idData{},
+	},
+	"DELETE /api/v1/post/purge": {
+		Summary:     "彻底删除帖子",
+		Description: "只能彻底删除回收站中的帖子，同时删除其标签关联、点赞、收藏、修订、精华与专题条目，不可恢复",
+		Auth:        openapi.AuthRequired,
+		Params:      []*openapi.Parameter{openapi.QueryParam("id", "integer", "帖子ID", true)},
+	},
+	"GET /api/v1/post/revisions": {
+		Summary:  "修订历史",
+		Auth:     openapi.AuthRequired,
+		Params:   []*openapi.Parameter{paramPostID, paramPage, paramPageSize},
+		Response: repository.Page[model.PostRevision]{},
+	},
+	"GET /api/v1/post/revision": {
+		Summary:  "修订版本详情",
+		Auth:     openapi.AuthRequired,
+		Query:    service.RevisionService{},
+		Response: model.PostRevision{},
+	},
+	"GET /api/v1/post/revision/diff": {
+		Summary:  "比较修订版本",
+		Auth:     openapi.AuthRequired,
+		Query:    service.RevisionDiffService{},
+		Response: revisionDiffData{},
+	},
+	"POST /api/v1/post/revision/rollback": {
+		Summary:  "回滚到修订版本",
+		Auth:     openapi.AuthRequired,
+		Body:     service.RevisionService{},
+		Response: postRevisionData{},
+	},
+	"POST /api/v1/post/draft": {
+		Summary:     "自动保存草稿",
+		Description: "post_id 为 0 表示尚未创建的新帖",
+		Auth:        openapi.AuthRequired,
+		Body:        service.DraftService{},
+		Response:    editing.Draft{},
+	},
+	"GET /api/v1/post/draft": {
+		Summary:  "获取草稿",
+		Auth:     openapi.AuthRequired,
+		Params:   []*openapi.Parameter{openapi.QueryParam("post_id", "integer", "帖子ID，新帖为 0", false)},
+		Response: draftData{},
+	},
+	"DELETE /api/v1/post/draft": {
+		Summary: "删除草稿",
+		Auth:    openapi.AuthRequired,
+		Params:  []*openapi.Parameter{openapi.QueryParam("post_id", "integer", "帖子ID，新帖为 0", false)},
+	},
+	"POST /api/v1/post/editing": {
+		Summary:     "编辑心跳",
+		Description: "标记正在编辑并获取或续期编辑锁，post_id 可放在查询参数或请求体中",
+		Auth:        openapi.AuthRequired,
+		Params:      []*openapi.Parameter{openapi.QueryParam("post_id", "integer", "帖子ID", false)},
+		Body:        service.EditingService{},
+		Response:    presenceData{},
+	},
+	"GET /api/v1/post/editing": {
+		Summary:  "查看编辑状态",
+		Auth:     openapi.AuthRequired,
+		Params:   []*openapi.Parameter{paramPostID},
+		Response: presenceData{},
+	},
+	"DELETE /api/v1/post/editing": {
+		Summary: "退出编辑",
+		Auth:    openapi.AuthRequired,
+		Params:  []*openapi.Parameter{paramPostID},
+	},
+	"GET /api/v1/post/list": {
+		Summary: "帖子列表",
+		Description: "只有按自己的 author_id 查询时才返回未发布的帖子。" +
+			"带有 cursor 参数时使用游标分页：第一页传空的 cursor，之后传上一页的 next_cursor，不返回 total 与 page",
+		Auth: openapi.AuthOptional,
+		Params: []*openapi.Parameter{
+			openapi.QueryParam("status", "string", "状态：draft、pending、published，多个用逗号分隔", false),
+			openapi.QueryParam("board_id", "string", "版块ID，多个用逗号分隔", false),
+			openapi.QueryParam("author_id", "string", "作者ID，多个用逗号分隔", false),
+			openapi.QueryParam("sort", "string", "排序字段，逗号分隔，前加 - 表示降序：id、created_at、updated_at、view_count、like_count、comment_count，默认 -created_at", false),
+			paramPage, paramPageSize,
+			openapi.QueryParam("cursor", "string", "游标", false),
+		},
+		Response: repository.Page[model.Post]{},
+	},
+	"GET /api/v1/post/search": {
+		Summary:  "搜索帖子",
+		Auth:     openapi.AuthOptional,
+		Query:    service.SearchService{},
+		Params:   []*openapi.Parameter{paramPage, paramPageSize},
+		Response: searchData{},
+	},
+	"GET /api/v1/post/detail": {
+		Summary:  "帖子详情",
+		Auth:     openapi.AuthOptional,
+		Params:   []*openapi.Parameter{openapi.QueryParam("id", "integer", "帖子ID", true)},
+		Response: postDetailData{},
+	},
+	"GET /api/v1/post/popular": {
+		Summary: "热门帖子",
+		Auth:    openapi.AuthOptional,
+		Params: []*openapi.Parameter{
+			openapi.QueryParam("days", "integer", "统计天数，默认 7", false),
+			openapi.QueryParam("board_id", "integer", "版块ID", false),
+			paramLimit,
+		},
+		Response: popularData{},
+	},
+	"POST /api/v1/post/like":       {Summary: "点赞", Auth: openapi.AuthRequired, Body: service.InteractionService{}, Response: toggleData{}},
+	"DELETE /api/v1/post/like":     {Summary: "取消点赞", Auth: openapi.AuthRequired, Body: service.InteractionService{}, Response: toggleData{}},
+	"POST /api/v1/post/bookmark":   {Summary: "收藏", Auth: openapi.AuthRequired, Body: service.InteractionService{}, Response: toggleData{}},
+	"DELETE /api/v1/post/bookmark": {Summary: "取消收藏", Auth: openapi.AuthRequired, Body: service.InteractionService{}, Response: toggleData{}},
+	"GET /api/v1/post/bookmarks": {
+		Summary:  "我的收藏",
+		Auth:     openapi.AuthRequired,
+		Params:   []*openapi.Parameter{paramPage, paramPageSize},
+		Response: repository.Page[model.Bookmark]{},
+	},
+	"POST /api/v1/post/feature":   {Summary: "设置精华", Auth: openapi.AuthRequired, Body: service.FeatureService{}, Response: model.PostFeature{}},
+	"DELETE /api/v1/post/feature": {Summary: "取消精华", Auth: openapi.AuthRequired, Body: service.FeatureService{}},
+	"GET /api/v1/post/featured": {
+		Summary:  "精华帖子列表",
+		Auth:     openapi.AuthOptional,
+		Params:   []*openapi.Parameter{paramPage, paramPageSize},
+		Response: repository.Page[model.PostFeature]{},
+	},
+	"GET /api/v1/post/recommended": {
+		Summary:  "推荐帖子",
+		Auth:     openapi.AuthRequired,
+		Params:   []*openapi.Parameter{paramLimit},
+		Response: []*model.Post{},
+	},
+
+	// 专题
+	"GET /api/v1/collection/list": {
+		Summary:  "专题列表",
+		Auth:     openapi.AuthOptional,
+		Params:   []*openapi.Parameter{paramPage, paramPageSize},
+		Response: repository.Page[model.Collection]{},
+	},
+	"GET /api/v1/collection/detail": {
+		Summary:  "专题详情",
+		Auth:     openapi.AuthOptional,
+		Params:   []*openapi.Parameter{openapi.QueryParam("id", "integer", "专题ID", true)},
+		Response: model.Collection{},
+	},
+	"POST /api/v1/collection/create": {Summary: "创建专题", Auth: openapi.AuthRequired, Body: service.CollectionService{}, Response: idData{}},
+	"POST /api/v1/collection/update": {Summary: "更新专题", Auth: openapi.AuthRequired, Body: service.CollectionService{}},
+	"POST /api/v1/collection/posts": {
+		Summary:     "重置专题帖子顺序",
+		Description: "post_ids 为专题中帖子的完整顺序",
+		Auth:        openapi.AuthRequired,
+		Body:        service.CollectionService{},
+	},
+	"DELETE /api/v1/collection/delete": {Summary: "删除专题", Auth: openapi.AuthRequired, Body: service.CollectionService{}},
+}
+
+// BuildOpenAPI 由已注册的路由生成 OpenAPI 文档，返回缺少接口说明的路由
+// 缺少说明的路由仍会出现在文档中，仅包含路径与请求方法
+func BuildOpenAPI(routes gin.RoutesInfo) (*openapi.Document, gin.RoutesInfo) {
+	b := openapi.NewBuilder(openapi.Info{
+		Title: "qwqserver API",
+		Description: "接口统一返回 {code, error, msg, data, request_id}，code 与 HTTP 状态码一致，出错时 error 为错误码；" +
+			"msg 的语言依次按登录用户的语言偏好、Accept-Language 协商（zh-CN / en-US），响应头 Content-Language 为实际使用的语言",
+		Version: "v1",
+	})
+	b.Envelope = common.HTTPResult{}
+	b.TagOf = apiTagOf
+	b.Unauthorized = "未登录或令牌无效"
+	b.Security[openapi.AuthRequired] = []openapi.SecurityRequirement{{securityBearer: {}}}
+	b.Security[openapi.AuthOptional] = []openapi.SecurityRequirement{{securityBearer: {}}, {}}
+	b.Gen.Override(gorm.DeletedAt{}, &openapi.Schema{Type: "string", Format: "date-time", Nullable: true})
+
+	b.Doc.Components.SecuritySchemes[securityBearer] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  fmt.Sprintf("登录返回的访问令牌，有效期 %s；即将过期时响应头 New-Token 返回新的令牌", common.TokenExpireTime),
+	}
+	b.Doc.Components.SecuritySchemes[securityRefresh] = &openapi.SecurityScheme{
+		Type: "apiKey",
+		In:   "header",
+		Name: authv2.HeaderRefreshToken,
+		Description: fmt.Sprintf("刷新令牌（认证 v2），访问令牌过期时携带以换取新令牌，新令牌由响应头 %s / %s 返回",
+			authv2.HeaderNewAccessToken, authv2.HeaderNewRefreshToken),
+	}
+	for _, tag := range apiTags {
+		b.Doc.AddTag(tag.Name, tag.Description)
+	}
+
+	var missing gin.RoutesInfo
+	for _, route := range routes {
+		doc, ok := apiDocs[route.Method+" "+route.Path]
+		if !ok {
+			missing = append(missing, route)
+		}
+		b.Add(route.Method, route.Path, doc)
+	}
+
+	// 认证 v2 的请求结构
+	for _, v := range []any{authv2.LoginRequest{}, authv2.RegisterRequest{}} {
+		b.Gen.Schema(v)
+	}
+
+	// 统一响应的错误码取值
+	if envelope := b.Doc.Components.Schemas[b.Gen.Name(common.HTTPResult{})]; envelope != nil {
+		if prop := envelope.Properties["error"]; prop != nil {
+			prop.Description = "错误码，成功时为空"
+			for _, e := range errcode.Catalog() {
+				prop.Enum = append(prop.Enum, e.Code)
+			}
+		}
+	}
+
+	b.Doc.SortTags()
+	return b.Doc, missing
+}
+
+// registerOpenAPI 注册 OpenAPI 文档与 Swagger UI，文档在首次访问时由全部已注册的路由生成
+func registerOpenAPI(r *gin.Engine) {
+	var (
+		once    sync.Once
+		spec    []byte
+		specErr error
+	)
+	r.GET(openAPIPath, func(c *gin.Context) {
+		once.Do(func() {
+			doc, missing := BuildOpenAPI(r.Routes())
+			for _, route := range missing {
+				warn("接口缺少文档说明: %s %s", route.Method, route.Path)
+			}
+			spec, specErr = doc.JSON()
+		})
+		if specErr != nil {
+			middleware.Abort(c, specErr)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	})
+
+	page, pageErr := openapi.SwaggerHTML("qwqserver API", openAPIPath)
+	r.GET(swaggerPath+"/*any", func(c *gin.Context) {
+		p := c.Param("any")
+		if data, contentType, ok := openapi.SwaggerAsset(strings.TrimPrefix(p, "/")); ok {
+			c.Data(http.StatusOK, contentType, data)
+			return
+		}
+		if p != "/" && p != "/index.html" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if pageErr != nil {
+			c.String(http.StatusInternalServerError, pageErr.Error())
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	})
+}
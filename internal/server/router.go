@@ -3,51 +3,87 @@ package server
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"path/filepath"
 	"qwqserver/internal/auth"
+	"qwqserver/internal/config"
+	"qwqserver/internal/errcode"
 	"qwqserver/internal/handler"
 	"qwqserver/internal/middleware"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/docsite"
 	"qwqserver/pkg/util"
+	"qwqserver/pkg/validate"
 )
 
-func RouterApiV1() {
-	r := New()
+// RouterApiV1 创建路由并注册全部接口，d 为处理器使用的服务层依赖
+func RouterApiV1(d *service.Deps) *gin.Engine {
+	r := gin.New()
+
+	// 请求 ID、错误渲染与 panic 恢复
+	r.Use(middleware.ErrorMiddleware())
 
 	// 限流
 	r.Use(middleware.QueueLimitMiddleware())
 
-	// 健康检测
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "ok",
-		})
-	})
-
-	mdDir := util.WorkDir("resources/docs")
 	mdTemplateDir := util.WorkDir("resources/templates/markdown")
 
-	fmt.Println("mdDir: ", mdDir, "mdTemplateDir: ", mdTemplateDir)
+	// 文档站点由应用初始化（含目录轮询），未初始化时直接构建
+	docs := docsite.Default()
+	if docs == nil {
+		var err error
+		docs, err = docsite.New(util.WorkDir("resources/docs"), docsite.Options{BaseURL: "/docs"})
+		if err != nil {
+			fmt.Println("构建文档站点失败: ", err)
+		}
+	}
+	liveReload := false
+	if cfg := config.New(); cfg.Docs != nil {
+		liveReload = cfg.Docs.LiveReload
+	}
 
-	// 添加静态文件服务 - 关键部分
-	r.Static("/docs/img", mdDir)
+	fmt.Println("mdDir: ", docs.Root(), "mdTemplateDir: ", mdTemplateDir)
 
 	// 加载模板
 	r.LoadHTMLGlob(filepath.Join(mdTemplateDir, "*.html"))
 
-	// 获取所有 Markdown 文件
+	Register(r, d, docs, liveReload)
+	return r
+}
+
+// Register 注册全部路由，新增路由需同时在 apidoc.go 中补充接口说明
+func Register(r *gin.Engine, d *service.Deps, docs *docsite.Site, liveReload bool) {
+	// 登录用户的语言偏好优先于 Accept-Language
+	middleware.SetUserLocaleResolver(d.UserLocale)
+	// 请求参数按结构体的 binding 标签校验，JSON 与查询参数绑定均生效
+	binding.Validator = validate.Default
+
+	// 未匹配的路由返回统一的错误响应
+	r.NoRoute(func(c *gin.Context) {
+		middleware.Abort(c, errcode.New(errcode.NotFound))
+	})
+
+	// 健康检测
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"message": "ok",
+		})
+	})
+
+	// 文档首页与搜索（?q=）
 	r.GET("/docs", func(c *gin.Context) {
-		util.ListMarkdownFiles(mdDir, c)
+		handler.NewDocs(docs, liveReload).Page(c)
 	})
 
-	// 渲染 Markdown 文件
-	r.GET("/docs/:filename", func(c *gin.Context) {
-		util.RenderMarkdown(mdDir, c)
+	// 文档页面与文档目录中的静态资源（图片等）
+	r.GET("/docs/*path", func(c *gin.Context) {
+		handler.NewDocs(docs, liveReload).Page(c)
 	})
 
 	apiV1Group := r.Group("/api/v1")
 
 	// 认证中间件
-	apiV1Group.Use(middleware.AuthMiddleware())
+	apiV1Group.Use(middleware.AuthMiddleware(d.Sessions))
 
 	// 认证路由
 	authGroup := apiV1Group.Group("/auth")
@@ -55,31 +91,51 @@ func RouterApiV1() {
 		// 注册
 		authGroup.POST(auth.RegisterPath, func(c *gin.Context) {
 			// get user service handler
-			handle := handler.NewUserHandler()
+			handle := handler.NewUserHandler(d)
 			res := handle.Register(c)
-			c.JSON(res.Code, res)
+			middleware.Render(c, res)
 		})
 		// 登录
 		authGroup.POST(auth.LoginPath, func(c *gin.Context) {
 			// get user service handler
-			handle := handler.NewUserHandler()
+			handle := handler.NewUserHandler(d)
 			res := handle.Login(c)
-			c.JSON(res.Code, res)
+			middleware.Render(c, res)
 		})
 		// 登出
 		authGroup.POST(auth.LogoutPath, func(c *gin.Context) {
 			// get user service handler
-			handle := handler.NewUserHandler()
+			handle := handler.NewUserHandler(d)
 			res := handle.Logout(c)
-			c.JSON(res.Code, res)
+			middleware.Render(c, res)
 		})
 		// --------- 用户操作 --------- //
 		// 删除用户
 		authGroup.DELETE(auth.DelIDPath, func(c *gin.Context) {
 			// get user service handler
-			handle := handler.NewUserHandler()
+			handle := handler.NewUserHandler(d)
 			res := handle.DelID(c)
-			c.JSON(res.Code, res)
+			middleware.Render(c, res)
+		})
+		// 从回收站恢复用户（管理员）
+		authGroup.POST(auth.RestorePath, func(c *gin.Context) {
+			res := handler.NewUserHandler(d).RestoreUser(c)
+			middleware.Render(c, res)
+		})
+		// 设置语言偏好
+		authGroup.POST(auth.LocalePath, func(c *gin.Context) {
+			res := handler.NewUserHandler(d).SetLocale(c)
+			middleware.Render(c, res)
+		})
+		// 修改密码
+		authGroup.POST(auth.ChangePasswordPath, func(c *gin.Context) {
+			res := handler.NewUserHandler(d).ChangePassword(c)
+			middleware.Render(c, res)
+		})
+		// 重置用户密码（管理员）
+		authGroup.POST(auth.ResetPasswordPath, func(c *gin.Context) {
+			res := handler.NewUserHandler(d).ResetPassword(c)
+			middleware.Render(c, res)
 		})
 
 	}
@@ -89,10 +145,196 @@ func RouterApiV1() {
 	{
 		// 创建文章
 		postGroup.POST("/create", func(c *gin.Context) {
-			handle := handler.NewPost()
+			handle := handler.NewPost(d)
 			res := handle.Create(c)
-			c.JSON(res.Code, res)
+			middleware.Render(c, res)
+		})
+		// 更新文章
+		postGroup.POST("/update", func(c *gin.Context) {
+			res := handler.NewPost(d).Update(c)
+			middleware.Render(c, res)
+		})
+		// 删除文章（移入回收站）
+		postGroup.DELETE("/delete", func(c *gin.Context) {
+			res := handler.NewPost(d).Delete(c)
+			middleware.Render(c, res)
+		})
+		// 回收站
+		postGroup.GET("/trash", func(c *gin.Context) {
+			res := handler.NewPost(d).Trash(c)
+			middleware.Render(c, res)
+		})
+		postGroup.POST("/restore", func(c *gin.Context) {
+			res := handler.NewPost(d).Restore(c)
+			middleware.Render(c, res)
+		})
+		postGroup.DELETE("/purge", func(c *gin.Context) {
+			res := handler.NewPost(d).Purge(c)
+			middleware.Render(c, res)
+		})
+		// 修订历史
+		postGroup.GET("/revisions", func(c *gin.Context) {
+			res := handler.NewPost(d).Revisions(c)
+			middleware.Render(c, res)
+		})
+		postGroup.GET("/revision", func(c *gin.Context) {
+			res := handler.NewPost(d).Revision(c)
+			middleware.Render(c, res)
+		})
+		postGroup.GET("/revision/diff", func(c *gin.Context) {
+			res := handler.NewPost(d).RevisionDiff(c)
+			middleware.Render(c, res)
+		})
+		postGroup.POST("/revision/rollback", func(c *gin.Context) {
+			res := handler.NewPost(d).Rollback(c)
+			middleware.Render(c, res)
+		})
+		// 自动保存草稿
+		postGroup.POST("/draft", func(c *gin.Context) {
+			res := handler.NewEditing(d).SaveDraft(c)
+			middleware.Render(c, res)
+		})
+		postGroup.GET("/draft", func(c *gin.Context) {
+			res := handler.NewEditing(d).GetDraft(c)
+			middleware.Render(c, res)
+		})
+		postGroup.DELETE("/draft", func(c *gin.Context) {
+			res := handler.NewEditing(d).DeleteDraft(c)
+			middleware.Render(c, res)
+		})
+		// 编辑状态（心跳/查看/退出）
+		postGroup.POST("/editing", func(c *gin.Context) {
+			res := handler.NewEditing(d).Heartbeat(c)
+			middleware.Render(c, res)
+		})
+		postGroup.GET("/editing", func(c *gin.Context) {
+			res := handler.NewEditing(d).Status(c)
+			middleware.Render(c, res)
+		})
+		postGroup.DELETE("/editing", func(c *gin.Context) {
+			res := handler.NewEditing(d).Leave(c)
+			middleware.Render(c, res)
+		})
+		// 文章列表（公开）
+		postGroup.GET("/list", func(c *gin.Context) {
+			res := handler.NewPost(d).List(c)
+			middleware.Render(c, res)
+		})
+		// 搜索文章（公开）
+		postGroup.GET("/search", func(c *gin.Context) {
+			res := handler.NewPost(d).Search(c)
+			middleware.Render(c, res)
+		})
+		// 帖子详情（公开，记录浏览）
+		postGroup.GET("/detail", func(c *gin.Context) {
+			res := handler.NewInteraction(d).Detail(c)
+			middleware.Render(c, res)
+		})
+		// 热门帖子（公开）
+		postGroup.GET("/popular", func(c *gin.Context) {
+			res := handler.NewInteraction(d).Popular(c)
+			middleware.Render(c, res)
+		})
+		// 点赞
+		postGroup.POST("/like", func(c *gin.Context) {
+			res := handler.NewInteraction(d).Like(c)
+			middleware.Render(c, res)
+		})
+		// 取消点赞
+		postGroup.DELETE("/like", func(c *gin.Context) {
+			res := handler.NewInteraction(d).Unlike(c)
+			middleware.Render(c, res)
+		})
+		// 收藏
+		postGroup.POST("/bookmark", func(c *gin.Context) {
+			res := handler.NewInteraction(d).Bookmark(c)
+			middleware.Render(c, res)
+		})
+		// 取消收藏
+		postGroup.DELETE("/bookmark", func(c *gin.Context) {
+			res := handler.NewInteraction(d).Unbookmark(c)
+			middleware.Render(c, res)
+		})
+		// 我的收藏
+		postGroup.GET("/bookmarks", func(c *gin.Context) {
+			res := handler.NewInteraction(d).Bookmarks(c)
+			middleware.Render(c, res)
+		})
+		// 设置精华
+		postGroup.POST("/feature", func(c *gin.Context) {
+			res := handler.NewFeature(d).Feature(c)
+			middleware.Render(c, res)
+		})
+		// 取消精华
+		postGroup.DELETE("/feature", func(c *gin.Context) {
+			res := handler.NewFeature(d).Unfeature(c)
+			middleware.Render(c, res)
+		})
+		// 精华帖子列表（公开）
+		postGroup.GET("/featured", func(c *gin.Context) {
+			res := handler.NewFeature(d).Featured(c)
+			middleware.Render(c, res)
+		})
+		// 推荐帖子
+		postGroup.GET("/recommended", func(c *gin.Context) {
+			res := handler.NewFeature(d).Recommended(c)
+			middleware.Render(c, res)
+		})
+	}
+
+	// 专题路由
+	collectionGroup := apiV1Group.Group("/collection")
+	{
+		// 专题列表（公开）
+		collectionGroup.GET("/list", func(c *gin.Context) {
+			res := handler.NewFeature(d).CollectionList(c)
+			middleware.Render(c, res)
+		})
+		// 专题详情（公开）
+		collectionGroup.GET("/detail", func(c *gin.Context) {
+			res := handler.NewFeature(d).CollectionDetail(c)
+			middleware.Render(c, res)
+		})
+		// 创建专题
+		collectionGroup.POST("/create", func(c *gin.Context) {
+			res := handler.NewFeature(d).CreateCollection(c)
+			middleware.Render(c, res)
+		})
+		// 更新专题
+		collectionGroup.POST("/update", func(c *gin.Context) {
+			res := handler.NewFeature(d).UpdateCollection(c)
+			middleware.Render(c, res)
+		})
+		// 重置专题帖子顺序
+		collectionGroup.POST("/posts", func(c *gin.Context) {
+			res := handler.NewFeature(d).SetCollectionPosts(c)
+			middleware.Render(c, res)
+		})
+		// 删除专题
+		collectionGroup.DELETE("/delete", func(c *gin.Context) {
+			res := handler.NewFeature(d).DeleteCollection(c)
+			middleware.Render(c, res)
+		})
+	}
+
+	// 文档
+	docsGroup := apiV1Group.Group("/docs")
+	{
+		// 搜索文档（公开）
+		docsGroup.GET("/search", func(c *gin.Context) {
+			res := handler.NewDocs(docs, liveReload).Search(c)
+			middleware.Render(c, res)
 		})
+		// 文档实时刷新（SSE，仅开发模式）
+		if liveReload {
+			docsGroup.GET("/livereload", func(c *gin.Context) {
+				handler.NewDocs(docs, liveReload).LiveReload(c)
+			})
+			// 长连接不占用并发名额
+			middleware.SkipQueueLimit(docsGroup.BasePath() + "/livereload")
+		}
 	}
 
+	// 接口文档（OpenAPI 与 Swagger UI）
+	registerOpenAPI(r)
 }
@@ -27,3 +27,22 @@ func New() *gin.Engine {
 func Run(addr string) error {
 	return New().Run(addr)
 }
+
+// Logger 路由注册与接口文档生成时使用的日志
+type Logger interface {
+	Warn(msg string, args ...any)
+}
+
+var logger Logger
+
+// SetLogger 设置服务器日志，应用启动时在创建路由前调用，未设置时不输出
+func SetLogger(l Logger) {
+	logger = l
+}
+
+// warn 输出警告日志，未设置日志时忽略
+func warn(msg string, args ...any) {
+	if logger != nil {
+		logger.Warn(msg, args...)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"qwqserver/pkg/cache"
+	cache "qwqserver/pkg/cache/v8"
 	"strings"
 	"time"
 )
@@ -74,18 +74,18 @@ type IdentityResult struct {
 
 // 登录请求结构体
 type LoginRequest struct {
-	Name      string `json:"Name"`
-	Password  string `json:"password"`
+	Name      string `json:"Name" binding:"required,max=128"` // 用户名或邮箱
+	Password  string `json:"password" binding:"required,max=72"`
 	DeviceID  string `json:"device_id"`
 	IPAddress string `json:"ipaddress"`
 }
 
 // RegisterRequest 用户注册请求结构体
 type RegisterRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Email    string `json:"email"`
-	Nickname string `json:"nickname"`
+	Username string `json:"username" binding:"required,min=3,max=32,pattern=username"`
+	Password string `json:"password" binding:"required"` // 长度、强度等由密码策略检查
+	Email    string `json:"email" binding:"required,max=128,email"`
+	Nickname string `json:"nickname" binding:"required,max=32"`
 }
 
 // 自定义 JWT Claims
@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"net/http"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
 	"qwqserver/internal/model"
 	"qwqserver/internal/repository"
-	"qwqserver/pkg/cache"
+	cache "qwqserver/pkg/cache/v8"
 	"qwqserver/pkg/util"
 	"qwqserver/pkg/util/network/client"
 	"qwqserver/pkg/util/passsec"
@@ -15,133 +16,100 @@ import (
 )
 
 // 注册处理逻辑
-func Register(c *gin.Context) *model.Result {
-	res := &model.Result{}
+func Register(c *gin.Context, userRepo repository.UserRepository) *common.HTTPResult {
 	req := RegisterRequest{}
 
-	if err := c.ShouldBindJSON(&req); err != nil || req.Username == "" || req.Password == "" || req.Nickname == "" || req.Email == "" {
-		res.Code = http.StatusBadRequest
-		res.Message = "注册用户参数错误"
-		return res
-	}
-
-	userRepo, err := repository.NewUserRepository()
-	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Message = "获取数据库连接失败: " + err.Error()
-		return res
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
 	}
 
 	// 检查用户名是否已存在
 	if ok, _ := userRepo.ExistUsername(context.Background(), req.Username); ok {
-		res.Code = http.StatusConflict
-		res.Message = "您输入的用户名已经存在"
-		return res
+		return common.Fail(errcode.New(errcode.UserUsernameTaken))
 	}
 
 	// 检查邮箱是否已存在
 	if ok, _ := userRepo.ExistEmail(context.Background(), req.Email); ok {
-		res.Code = http.StatusConflict
-		res.Message = "您输入的邮箱已经存在"
-		return res
+		return common.Fail(errcode.New(errcode.UserEmailTaken))
+	}
+
+	// 密码策略
+	violations, err := passsec.CurrentPolicy().Check(req.Password, passsec.UserInfo{Username: req.Username, Email: req.Email}, nil)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if len(violations) > 0 {
+		return common.Fail(errcode.PasswordViolations("password", violations))
 	}
 
 	// 密码哈希
-	pwd, err := passsec.Hash(req.Password)
+	pwd, err := passsec.HashPassword(req.Password)
 	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Message = "密码哈希失败: " + err.Error()
-		return res
+		return common.Fail(fmt.Errorf("密码哈希失败: %w", err))
 	}
 
 	// 创建新用户
 	newUser := model.User{
-		Username:     req.Username,
-		Password:     pwd,
-		PasswordSalt: pwd,
-		PasswordHash: pwd,
-		Nickname:     req.Nickname,
-		Email:        req.Email,
-		Status:       1,
+		Username: req.Username,
+		Password: pwd,
+		Nickname: req.Nickname,
+		Email:    req.Email,
+		Status:   1,
 	}
 	if err = userRepo.Create(context.Background(), &newUser); err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Message = "创建用户失败 Error: " + err.Error()
-		return res
+		return common.Fail(fmt.Errorf("创建用户失败: %w", err))
 	}
 
-	res.Code = http.StatusOK
-	res.Data = gin.H{"user_id": newUser.ID}
-	res.Message = "注册成功！"
-	return res
+	return common.Success("user.registered", gin.H{"user_id": newUser.ID})
 }
 
 // 登录处理函数
-func Login(c *gin.Context) *model.Result {
-	res := &model.Result{}
+func Login(c *gin.Context, userRepo repository.UserRepository) *common.HTTPResult {
 	req := LoginRequest{}
 	userInfo := &model.User{}
+	var err error
 
 	// 参数校验
-	if err := c.ShouldBindJSON(&req); err != nil || req.Name == "" || req.Password == "" {
-		res.Code = http.StatusBadRequest
-		res.Message = "invalid request"
-		return res
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return common.BindError(err)
 	}
 
-	userRepo, err := repository.NewUserRepository()
-	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Message = "获取数据库连接失败: " + err.Error()
-		return res
-	}
-
-	// 验证输入的是用户名还是邮箱
+	// 验证输入的是用户名还是邮箱，用户不存在与密码错误返回相同的错误码
 	if util.IsEmail(req.Name) {
 		if ok, _ := userRepo.ExistEmail(context.Background(), req.Name); !ok {
-			res.Code = http.StatusUnauthorized
-			res.Message = "Email不存在"
-			return res
+			return common.Fail(errcode.New(errcode.AuthInvalidCredentials))
 		}
 		userInfo, err = userRepo.FindByEmail(context.Background(), req.Name)
-		if err != nil {
-			res.Code = http.StatusInternalServerError
-			res.Message = "获取用户信息失败 Error: " + err.Error()
-			return res
-		}
 	} else {
 		if ok, _ := userRepo.ExistUsername(context.Background(), req.Name); !ok {
-			res.Code = http.StatusUnauthorized
-			res.Message = "用户名不存在"
-			return res
+			return common.Fail(errcode.New(errcode.AuthInvalidCredentials))
 		}
 		userInfo, err = userRepo.FindByUsername(context.Background(), req.Name)
-		if err != nil {
-			res.Code = http.StatusInternalServerError
-			res.Message = "获取用户信息失败 Error: " + err.Error()
-			return res
-		}
+	}
+	if err != nil {
+		return common.Fail(fmt.Errorf("获取用户信息失败: %w", err))
 	}
 
-	// 校验密码（应使用安全哈希，略）
+	// 校验密码
 	var ok bool
-	if ok, err = passsec.Check(req.Password, userInfo.Password); err != nil {
-		res.Code = http.StatusUnauthorized
-		res.Message = "密码校验出错"
-		return res
+	if ok, err = passsec.Verify(req.Password, userInfo.Password); err != nil {
+		return common.Fail(errcode.Wrap(errcode.AuthInvalidCredentials, err))
 	}
 
 	if !ok {
-		res.Code = http.StatusUnauthorized
-		res.Message = "密码错误"
-		return res
+		return common.Fail(errcode.New(errcode.AuthInvalidCredentials))
+	}
+
+	// 旧算法或旧参数的哈希在验证通过后重新哈希，失败不影响登录
+	if passsec.NeedsRehash(userInfo.Password) {
+		if pwd, err := passsec.HashPassword(req.Password); err == nil {
+			_ = userRepo.RehashPassword(context.Background(), userInfo.ID, userInfo.Password, pwd)
+		}
 	}
 
 	// 禁用状态
 	if userInfo.Status == 0 {
-		res.Code = http.StatusForbidden
-		res.Message = "用户已被封禁"
-		return res
+		return common.Fail(errcode.New(errcode.UserDisabled))
 	}
 
 	userID := fmt.Sprintf("%d", userInfo.ID)
@@ -150,72 +118,52 @@ func Login(c *gin.Context) *model.Result {
 
 	// 清除旧设备 session
 	if err := TerminateOtherSessions(userID, deviceID); err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Message = "failed to terminate other sessions"
-		return res
+		return common.Fail(fmt.Errorf("清除其他设备会话失败: %w", err))
 	}
 
 	// 生成 token
 	accessToken, refreshToken, err := GenerateTokenPair(userID, deviceID, ipaddr)
 	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Message = "failed to generate tokens"
-		return res
+		return common.Fail(fmt.Errorf("生成令牌失败: %w", err))
 	}
 
 	// 存储 session 信息到 Redis
 	if err = saveTokenInfo(userID, deviceID, refreshToken, ipaddr, req.DeviceID); err != nil {
 		cache.Delete(context.Background(), UserSessionCachePrefixToString(userID, deviceID, ipaddr))
-		res.Code = http.StatusInternalServerError
-		res.Message = "failed to save token info"
-		return res
+		return common.Fail(fmt.Errorf("保存会话失败: %w", err))
 	}
 
-	res.Code = http.StatusOK
-	res.Message = "登录成功"
 	// 成功响应
-	res.Data = gin.H{
+	return common.Success("user.logged_in", gin.H{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
-	}
-	return res
+	})
 }
 
 // 登出处理函数
-func Logout(c *gin.Context) *model.Result {
+func Logout(c *gin.Context) *common.HTTPResult {
 	userID, _ := c.Get(ContextKeyUserID)
 	deviceID, _ := c.Get(ContextKeyDeviceID)
 	ipaddr := client.GetClientIP(c.Request)
 	//
 	ctx := context.Background()
 	key := UserSessionCachePrefixToString(userID.(string), deviceID.(string), ipaddr)
-	fmt.Println(ipaddr, "ipaddr")
 	if err := cache.Delete(ctx, key); err != nil {
-		return &model.Result{
-			Code:    http.StatusInternalServerError,
-			Message: "登出失败",
-		}
+		return common.Fail(fmt.Errorf("登出失败: %w", err))
 	}
 
-	return &model.Result{
-		Code:    http.StatusOK,
-		Message: "成功登出",
-	}
+	return common.Success("user.logged_out", nil)
 }
 
 // 用户信息查询处理函数
-func UserInfo(c *gin.Context) *model.Result {
+func UserInfo(c *gin.Context) *common.HTTPResult {
 	userID, _ := c.Get(ContextKeyUserID)
 	deviceID, _ := c.Get(ContextKeyDeviceID)
 
-	return &model.Result{
-		Code:    http.StatusOK,
-		Message: "ok",
-		Data: gin.H{
-			"user_id":   userID,
-			"device_id": deviceID,
-			"username":  strings.TrimPrefix(userID.(string), "user_"),
-			"role":      "user",
-		},
-	}
+	return common.OK(gin.H{
+		"user_id":   userID,
+		"device_id": deviceID,
+		"username":  strings.TrimPrefix(userID.(string), "user_"),
+		"role":      "user",
+	})
 }
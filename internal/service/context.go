@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"qwqserver/pkg/database"
+)
+
+// userContext 当前用户的请求上下文，用户写入后的短时间内读取使用主库（读写分离时的读己之写）
+// userID 为 0（未登录）时不设置粘滞标识
+func userContext(userID uint) context.Context {
+	ctx := context.Background()
+	if userID == 0 {
+		return ctx
+	}
+	return database.WithStickyKey(ctx, "user:"+strconv.FormatUint(uint64(userID), 10))
+}
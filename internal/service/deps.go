@@ -0,0 +1,37 @@
+package service
+
+import (
+	"sync"
+
+	"qwqserver/internal/counter"
+	"qwqserver/internal/editing"
+	"qwqserver/internal/ranking"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/search"
+	"qwqserver/internal/session"
+)
+
+// Deps 服务层依赖，由应用启动时创建并经处理器传递给各服务
+type Deps struct {
+	Repos    *repository.Repositories
+	Counter  *counter.Counter // 帖子计数写回
+	Ranker   *ranking.Ranker  // 热门排行，为 nil 时退回数据库计算
+	Search   search.Engine    // 帖子搜索
+	Editing  *editing.Editing // 草稿与编辑状态，为 nil 时不可用
+	Sessions session.Store    // 登录令牌，为 nil 时无法登录
+
+	// localeCache 用户ID → 语言偏好，见 UserLocale
+	localeCache sync.Map
+}
+
+// NewDeps 使用给定的仓库创建服务依赖：计数直接写库、不使用热度榜、搜索使用数据库查询、不支持草稿、
+// 登录令牌保存在进程内
+// 应用启动时按配置替换其中的组件，测试可直接传入内存实现的仓库
+func NewDeps(repos *repository.Repositories) *Deps {
+	return &Deps{
+		Repos:    repos,
+		Counter:  counter.New(nil, repos.Posts, counter.Config{}, nil),
+		Search:   search.New(search.Config{Engine: search.EngineSQL}, repos.Posts, nil),
+		Sessions: session.NewMemory(),
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/editing"
+	"qwqserver/internal/errcode"
+	"qwqserver/pkg/markup"
+	"qwqserver/pkg/perm"
+)
+
+// DraftService 自动保存草稿服务，PostID 为 0 表示尚未创建的新帖
+type DraftService struct {
+	PostID      uint   `json:"post_id" form:"post_id"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Mod         string `json:"mod"`
+	BaseVersion int64  `json:"base_version"` // 开始编辑时帖子的版本号
+}
+
+// checkDraftAccess 校验草稿访问权限：新帖需要 PostCreate 权限，已有帖子需要编辑权限
+func checkDraftAccess(ctx context.Context, d *Deps, postID, userID uint) *common.HTTPResult {
+	if postID == 0 {
+		return checkPerm(ctx, d.Repos.Users, userID, perm.PostCreate)
+	}
+	_, res := loadEditablePost(ctx, d.Repos, postID, userID)
+	return res
+}
+
+// editingError 把编辑辅助的错误转换为响应
+func editingError(err error) *common.HTTPResult {
+	switch {
+	case errors.Is(err, editing.ErrUnavailable):
+		return common.Fail(errcode.Wrap(errcode.EditingUnavailable, err))
+	case errors.Is(err, editing.ErrDraftTooLarge):
+		return common.Fail(errcode.New(errcode.DraftTooLarge))
+	case errors.Is(err, editing.ErrNoPost):
+		return common.Fail(errcode.New(errcode.MissingParam).With("field", "post_id"))
+	default:
+		return common.Fail(err)
+	}
+}
+
+// Save 保存草稿
+func (s *DraftService) Save(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if res = checkDraftAccess(ctx, d, s.PostID, userID); res != nil {
+		return
+	}
+
+	format, err := markup.ParseFormat(s.Mod)
+	if err != nil {
+		return common.Fail(errcode.Wrap(errcode.PostFormatUnsupported, err).With("format", s.Mod))
+	}
+
+	draft := &editing.Draft{
+		PostID:      s.PostID,
+		Title:       s.Title,
+		Content:     s.Content,
+		Mod:         string(format),
+		BaseVersion: s.BaseVersion,
+	}
+	if err = d.Editing.SaveDraft(ctx, userID, draft); err != nil {
+		return editingError(err)
+	}
+
+	return common.Success("draft.saved", draft)
+}
+
+// Get 获取草稿，并提示草稿是否基于过期的帖子版本
+func (s *DraftService) Get(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if res = checkDraftAccess(ctx, d, s.PostID, userID); res != nil {
+		return
+	}
+
+	draft, err := d.Editing.GetDraft(ctx, userID, s.PostID)
+	if err != nil {
+		return editingError(err)
+	}
+	if draft == nil {
+		return common.Fail(errcode.New(errcode.DraftNotFound))
+	}
+
+	data := gin.H{"draft": draft, "stale": false}
+	if s.PostID != 0 {
+		if post, err := d.Repos.Posts.FindByID(ctx, s.PostID); err == nil && post != nil {
+			data["stale"] = draft.BaseVersion != post.Version
+			data["current_version"] = post.Version
+		}
+	}
+	return common.OK(data)
+}
+
+// Delete 删除草稿
+func (s *DraftService) Delete(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if res = checkDraftAccess(ctx, d, s.PostID, userID); res != nil {
+		return
+	}
+	if err := d.Editing.DeleteDraft(ctx, userID, s.PostID); err != nil {
+		return editingError(err)
+	}
+	return common.Success("draft.deleted", nil)
+}
+
+// checkEditingAccess 校验编辑状态的访问权限：只针对已存在的帖子，需要编辑权限
+// 新帖的草稿按用户保存，不共享编辑状态与编辑锁
+func checkEditingAccess(ctx context.Context, d *Deps, postID, userID uint) *common.HTTPResult {
+	if postID == 0 {
+		return common.Fail(errcode.New(errcode.MissingParam).With("field", "post_id"))
+	}
+	return checkDraftAccess(ctx, d, postID, userID)
+}
+
+// EditingService 编辑状态服务，只针对已存在的帖子
+type EditingService struct {
+	PostID uint `json:"post_id" form:"post_id"`
+}
+
+// editorInfo 正在编辑的用户
+type editorInfo struct {
+	ID       uint   `json:"id"`
+	Nickname string `json:"nickname"`
+	Holder   bool   `json:"holder"` // 是否持有编辑锁
+}
+
+// presenceResult 组装编辑状态响应，附带用户昵称供界面显示"某某正在编辑"
+func presenceResult(ctx context.Context, d *Deps, userID uint, p *editing.Presence) *common.HTTPResult {
+	editors := make([]editorInfo, 0, len(p.Editors))
+	for _, id := range p.Editors {
+		info := editorInfo{ID: id, Holder: id == p.LockHolder}
+		if u, err := d.Repos.Users.FindByID(ctx, id); err == nil && u != nil {
+			info.Nickname = u.Nickname
+		}
+		editors = append(editors, info)
+	}
+
+	return common.OK(gin.H{
+		"lock_holder":       p.LockHolder,
+		"locked_by_other":   p.LockHolder != 0 && p.LockHolder != userID,
+		"editors":           editors,
+		"heartbeat_seconds": int(d.Editing.PresenceTTL().Seconds()),
+	})
+}
+
+// Heartbeat 标记正在编辑并续期编辑锁，客户端编辑期间定时调用
+func (s *EditingService) Heartbeat(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if res = checkEditingAccess(ctx, d, s.PostID, userID); res != nil {
+		return
+	}
+	p, err := d.Editing.Heartbeat(ctx, s.PostID, userID)
+	if err != nil {
+		return editingError(err)
+	}
+	return presenceResult(ctx, d, userID, p)
+}
+
+// Status 查看编辑状态（不加入编辑）
+func (s *EditingService) Status(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if res = checkEditingAccess(ctx, d, s.PostID, userID); res != nil {
+		return
+	}
+	p, err := d.Editing.Presence(ctx, s.PostID)
+	if err != nil {
+		return editingError(err)
+	}
+	return presenceResult(ctx, d, userID, p)
+}
+
+// Leave 退出编辑并释放编辑锁
+func (s *EditingService) Leave(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if userID == 0 {
+		return common.Fail(errcode.New(errcode.AuthLoginRequired))
+	}
+	if s.PostID == 0 {
+		return common.Fail(errcode.New(errcode.MissingParam).With("field", "post_id"))
+	}
+	if err := d.Editing.Leave(ctx, s.PostID, userID); err != nil {
+		return editingError(err)
+	}
+	return common.Success("editing.left", nil)
+}
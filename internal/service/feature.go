@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/perm"
+	"time"
+)
+
+// FeatureService 精华内容服务
+type FeatureService struct {
+	PostID   uint       `json:"post_id" binding:"required"`
+	Reason   string     `json:"reason" binding:"max=512"`
+	ExpireAt *time.Time `json:"expire_at"`
+}
+
+// Feature 设置精华
+func (s *FeatureService) Feature(d *Deps, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.ContentFeature); res != nil {
+		return
+	}
+
+	if s.ExpireAt != nil && !s.ExpireAt.After(time.Now()) {
+		return common.Fail(errcode.New(errcode.FeatureExpiryInPast))
+	}
+
+	if res = checkPublished(ctx, d.Repos.Posts, s.PostID); res != nil {
+		return
+	}
+
+	featureRepo := d.Repos.Features
+
+	feature := &model.PostFeature{
+		PostID:     s.PostID,
+		OperatorID: operatorID,
+		Reason:     s.Reason,
+		ExpireAt:   s.ExpireAt,
+	}
+	if err := featureRepo.Feature(ctx, feature); err != nil {
+		return common.Fail(err)
+	}
+
+	return common.Success("feature.set", feature)
+}
+
+// Unfeature 取消精华
+func (s *FeatureService) Unfeature(d *Deps, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.ContentFeature); res != nil {
+		return
+	}
+
+	featureRepo := d.Repos.Features
+
+	if err := featureRepo.Unfeature(ctx, s.PostID); err != nil {
+		return common.Fail(notFoundAs(err, errcode.PostNotFeatured))
+	}
+
+	return common.Success("feature.unset", gin.H{"post_id": s.PostID})
+}
+
+// FeaturedList 获取精华帖子列表
+func FeaturedList(d *Deps, page, pageSize int) (res *common.HTTPResult) {
+
+	featureRepo := d.Repos.Features
+
+	features, total, err := featureRepo.ListFeatured(context.Background(), page, pageSize)
+	if err != nil {
+		return common.Fail(err)
+	}
+
+	return common.OK(repository.NewPage(features, total, page, pageSize))
+}
+
+// RecommendedList 获取用户的推荐帖子
+func RecommendedList(d *Deps, userID uint, limit int) (res *common.HTTPResult) {
+
+	postRepo := d.Repos.Posts
+
+	posts, err := postRepo.ListRecommended(userContext(userID), userID, limit)
+	if err != nil {
+		return common.Fail(err)
+	}
+
+	return common.OK(posts)
+}
+
+// CollectionService 专题服务
+type CollectionService struct {
+	ID          uint   `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	PostIDs     []uint `json:"post_ids"`
+}
+
+// Create 创建专题，可同时指定帖子顺序
+func (s *CollectionService) Create(d *Deps, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.ContentFeature); res != nil {
+		return
+	}
+
+	if s.Title == "" {
+		return common.Fail(errcode.New(errcode.MissingParam).With("field", "title"))
+	}
+
+	if res = checkPublished(ctx, d.Repos.Posts, s.PostIDs...); res != nil {
+		return
+	}
+
+	featureRepo := d.Repos.Features
+
+	collection := &model.Collection{
+		Title:       s.Title,
+		Description: s.Description,
+		CreatorID:   operatorID,
+	}
+	err := featureRepo.WithTransaction(ctx, func(repo repository.FeatureRepository) error {
+		if err := repo.CreateCollection(ctx, collection); err != nil {
+			return err
+		}
+		return repo.SetCollectionPosts(ctx, collection.ID, s.PostIDs)
+	})
+	if err != nil {
+		return common.Fail(err)
+	}
+
+	return common.Success("collection.created", gin.H{"id": collection.ID})
+}
+
+// Update 更新专题标题与描述
+func (s *CollectionService) Update(d *Deps, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.ContentFeature); res != nil {
+		return
+	}
+
+	if s.ID == 0 {
+		return common.Fail(errcode.New(errcode.MissingParam).With("field", "id"))
+	}
+	if s.Title == "" {
+		return common.Fail(errcode.New(errcode.MissingParam).With("field", "title"))
+	}
+
+	featureRepo := d.Repos.Features
+
+	collection := &model.Collection{Title: s.Title, Description: s.Description}
+	collection.ID = s.ID
+	if err := featureRepo.UpdateCollection(ctx, collection); err != nil {
+		return common.Fail(notFoundAs(err, errcode.CollectionNotFound))
+	}
+
+	return common.Success("collection.updated", nil)
+}
+
+// SetPosts 按顺序重置专题中的帖子
+func (s *CollectionService) SetPosts(d *Deps, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.ContentFeature); res != nil {
+		return
+	}
+
+	if res = checkPublished(ctx, d.Repos.Posts, s.PostIDs...); res != nil {
+		return
+	}
+
+	featureRepo := d.Repos.Features
+
+	if err := featureRepo.SetCollectionPosts(ctx, s.ID, s.PostIDs); err != nil {
+		return common.Fail(notFoundAs(err, errcode.CollectionNotFound))
+	}
+
+	return common.Success("collection.posts_updated", nil)
+}
+
+// Delete 删除专题
+func (s *CollectionService) Delete(d *Deps, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.ContentFeature); res != nil {
+		return
+	}
+
+	featureRepo := d.Repos.Features
+
+	if err := featureRepo.DeleteCollection(ctx, s.ID); err != nil {
+		return common.Fail(notFoundAs(err, errcode.CollectionNotFound))
+	}
+
+	return common.Success("collection.deleted", nil)
+}
+
+// CollectionDetail 获取专题详情
+func CollectionDetail(d *Deps, id uint) (res *common.HTTPResult) {
+
+	featureRepo := d.Repos.Features
+
+	collection, err := featureRepo.FindCollection(context.Background(), id)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if collection == nil {
+		return common.Fail(errcode.New(errcode.CollectionNotFound))
+	}
+
+	return common.OK(collection)
+}
+
+// CollectionList 获取专题列表
+func CollectionList(d *Deps, page, pageSize int) (res *common.HTTPResult) {
+
+	featureRepo := d.Repos.Features
+
+	collections, total, err := featureRepo.ListCollections(context.Background(), page, pageSize)
+	if err != nil {
+		return common.Fail(err)
+	}
+
+	return common.OK(repository.NewPage(collections, total, page, pageSize))
+}
+
+// checkPublished 只有已发布的帖子可以加精或加入专题，不存在或未发布时返回帖子不存在
+func checkPublished(ctx context.Context, posts repository.PostRepository, postIDs ...uint) *common.HTTPResult {
+	for _, id := range postIDs {
+		post, err := posts.FindByID(ctx, id)
+		if err != nil {
+			return common.Fail(err)
+		}
+		if post == nil || post.Status != "published" {
+			return common.Fail(errcode.New(errcode.PostNotFound).With("post_id", id))
+		}
+	}
+	return nil
+}
+
+// notFoundAs 记录不存在时转换为指定错误码，其他错误按内部错误处理
+func notFoundAs(err error, code errcode.Code) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return errcode.Wrap(code, err)
+	}
+	return err
+}
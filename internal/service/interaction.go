@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/counter"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/hotrank"
+	"qwqserver/pkg/markup"
+)
+
+// InteractionService 点赞与收藏服务
+type InteractionService struct {
+	PostID uint `json:"post_id" binding:"required"`
+}
+
+// Like 点赞（重复点赞不会重复计数）
+func (s *InteractionService) Like(d *Deps, userID uint) *common.HTTPResult {
+	return s.toggle(d, userID, repository.CountColumnLike, 1, "post.liked",
+		func(ctx context.Context, repo repository.InteractionRepository) (bool, error) {
+			return repo.Like(ctx, userID, s.PostID)
+		})
+}
+
+// Unlike 取消点赞
+func (s *InteractionService) Unlike(d *Deps, userID uint) *common.HTTPResult {
+	return s.toggle(d, userID, repository.CountColumnLike, -1, "post.unliked",
+		func(ctx context.Context, repo repository.InteractionRepository) (bool, error) {
+			return repo.Unlike(ctx, userID, s.PostID)
+		})
+}
+
+// Bookmark 收藏
+func (s *InteractionService) Bookmark(d *Deps, userID uint) *common.HTTPResult {
+	return s.toggle(d, userID, repository.CountColumnBookmark, 1, "post.bookmarked",
+		func(ctx context.Context, repo repository.InteractionRepository) (bool, error) {
+			return repo.Bookmark(ctx, userID, s.PostID)
+		})
+}
+
+// Unbookmark 取消收藏
+func (s *InteractionService) Unbookmark(d *Deps, userID uint) *common.HTTPResult {
+	return s.toggle(d, userID, repository.CountColumnBookmark, -1, "post.unbookmarked",
+		func(ctx context.Context, repo repository.InteractionRepository) (bool, error) {
+			return repo.Unbookmark(ctx, userID, s.PostID)
+		})
+}
+
+// toggle 执行幂等的点赞/收藏操作，仅在状态改变时累加计数
+func (s *InteractionService) toggle(d *Deps, userID uint, column string, delta int64, okKey string,
+	op func(ctx context.Context, repo repository.InteractionRepository) (bool, error)) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	if userID == 0 {
+		return common.Fail(errcode.New(errcode.AuthLoginRequired))
+	}
+
+	// 不可见的帖子按不存在处理
+	post, err := d.Repos.Posts.FindByID(ctx, s.PostID)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if post == nil || !canViewPost(ctx, d.Repos.Users, userID, post) {
+		return common.Fail(errcode.New(errcode.PostNotFound))
+	}
+
+	interactionRepo := d.Repos.Interactions
+
+	changed, err := op(ctx, interactionRepo)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if changed {
+		if err = recordCount(ctx, d, s.PostID, column, delta); err != nil {
+			return common.Fail(fmt.Errorf("更新计数失败: %w", err))
+		}
+	}
+
+	return common.Success(okKey, gin.H{"post_id": s.PostID, "changed": changed})
+}
+
+// BookmarkList 获取用户收藏列表
+func BookmarkList(d *Deps, userID uint, page, pageSize int) (res *common.HTTPResult) {
+
+	interactionRepo := d.Repos.Interactions
+
+	bookmarks, total, err := interactionRepo.ListBookmarks(userContext(userID), userID, page, pageSize)
+	if err != nil {
+		return common.Fail(err)
+	}
+
+	return common.OK(repository.NewPage(bookmarks, total, page, pageSize))
+}
+
+// PostDetail 获取帖子详情并记录浏览，未发布的帖子只有作者与拥有 PostEditAny 权限的用户可见
+func PostDetail(d *Deps, postID, userID uint, ip string) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	postRepo := d.Repos.Posts
+
+	post, err := postRepo.FindByID(ctx, postID)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if post == nil || !canViewPost(ctx, d.Repos.Users, userID, post) {
+		return common.Fail(errcode.New(errcode.PostNotFound))
+	}
+
+	c := d.Counter
+	if counted, _ := c.RecordView(ctx, postID, counter.ViewerKey(userID, ip)); counted {
+		_ = d.Ranker.Incr(ctx, postID, hotrank.KindView, 1)
+		if !c.Buffered() {
+			// 无缓冲时计数已直接写库，这里同步展示值
+			post.ViewCount++
+		}
+	}
+	mergePendingCounts(post, c.Pending(ctx, postID))
+
+	data := gin.H{"post": post}
+	if format, err := markup.ParseFormat(post.Mod); err == nil {
+		data["render"] = markup.Default().Render(format, post.Content)
+	}
+	if userID != 0 {
+		liked, _ := d.Repos.Interactions.IsLiked(ctx, userID, postID)
+		bookmarked, _ := d.Repos.Interactions.IsBookmarked(ctx, userID, postID)
+		data["liked"] = liked
+		data["bookmarked"] = bookmarked
+	}
+
+	return common.OK(data)
+}
+
+// countKinds 计数列对应的热度事件类型
+var countKinds = map[string]string{
+	repository.CountColumnView:     hotrank.KindView,
+	repository.CountColumnLike:     hotrank.KindLike,
+	repository.CountColumnComment:  hotrank.KindComment,
+	repository.CountColumnBookmark: hotrank.KindBookmark,
+}
+
+// recordCount 累加帖子计数并同步更新热度，热度更新失败不影响计数
+func recordCount(ctx context.Context, d *Deps, postID uint, column string, delta int64) error {
+	if err := d.Counter.Add(ctx, postID, column, delta); err != nil {
+		return err
+	}
+	_ = d.Ranker.Incr(ctx, postID, countKinds[column], delta)
+	return nil
+}
+
+// mergePendingCounts 把尚未落库的计数增量合并到帖子上
+func mergePendingCounts(post *model.Post, delta repository.CountDelta) {
+	post.ViewCount += delta[repository.CountColumnView]
+	post.LikeCount += delta[repository.CountColumnLike]
+	post.CommentCount += delta[repository.CountColumnComment]
+	post.BookmarkCount += delta[repository.CountColumnBookmark]
+}
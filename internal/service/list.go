@@ -0,0 +1,30 @@
+package service
+
+import (
+	"errors"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/repository"
+	"strconv"
+	"strings"
+)
+
+// parseUintParam 解析无符号整数的过滤参数
+func parseUintParam(s string) (any, error) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	return uint(v), err
+}
+
+// listError 把列表查询的错误转换为响应，参数错误（过滤、排序字段不在白名单或游标无效）返回 400
+func listError(err error) *common.HTTPResult {
+	var pe *repository.ListParamError
+	if !errors.As(err, &pe) {
+		return common.Fail(err)
+	}
+	if len(pe.Values) > 0 {
+		return common.Fail(errcode.New(errcode.InvalidEnum).
+			With("field", pe.Param).
+			With("values", strings.Join(pe.Values, ", ")))
+	}
+	return common.Fail(errcode.New(errcode.InvalidParam).With("field", pe.Param))
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/locale"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localeCacheTTL 用户语言偏好的进程内缓存时间，每个响应都要协商语言，避免每次查询数据库
+const localeCacheTTL = time.Minute
+
+type localeEntry struct {
+	locale  string
+	expires time.Time
+}
+
+// UserLocale 查询用户的语言偏好，用于响应语言协商；未设置或查询失败返回空
+func (d *Deps) UserLocale(ctx context.Context, userID string) string {
+	if v, ok := d.localeCache.Load(userID); ok {
+		if e := v.(localeEntry); time.Now().Before(e.expires) {
+			return e.locale
+		}
+	}
+
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return ""
+	}
+	user, err := d.Repos.Users.FindByID(ctx, uint(id))
+	if err != nil {
+		return ""
+	}
+	var lang string
+	if user != nil {
+		lang = user.Locale
+	}
+	d.localeCache.Store(userID, localeEntry{locale: lang, expires: time.Now().Add(localeCacheTTL)})
+	return lang
+}
+
+// canonicalLocale 校验语言偏好并返回规范标签，为空表示不设置偏好
+func canonicalLocale(lang string) (string, *common.HTTPResult) {
+	if lang == "" {
+		return "", nil
+	}
+	canonical, ok := locale.Canonical(lang)
+	if !ok {
+		return "", common.Fail(errcode.New(errcode.InvalidEnum).
+			With("field", "locale").
+			With("values", strings.Join(locale.Languages(), ", ")))
+	}
+	return canonical, nil
+}
+
+// SetLocale 设置当前用户的语言偏好，为空时清除偏好（按 Accept-Language 协商）
+func (s *AuthService) SetLocale(d *Deps, uid uint) *common.HTTPResult {
+	if uid == 0 {
+		return common.Fail(errcode.New(errcode.AuthLoginRequired))
+	}
+	lang, res := canonicalLocale(s.Locale)
+	if res != nil {
+		return res
+	}
+
+	ctx := userContext(uid)
+	userRepo := d.Repos.Users
+	if user, err := userRepo.FindByID(ctx, uid); err != nil {
+		return common.Fail(err)
+	} else if user == nil {
+		return common.Fail(errcode.New(errcode.UserNotFound))
+	}
+	if err := userRepo.UpdateLocale(ctx, uid, lang); err != nil {
+		return common.Fail(err)
+	}
+
+	// 立即生效：本次响应即使用新的语言
+	d.localeCache.Store(strconv.FormatUint(uint64(uid), 10), localeEntry{locale: lang, expires: time.Now().Add(localeCacheTTL)})
+	return common.Success("user.locale_updated", gin.H{"locale": lang})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/perm"
+	"qwqserver/pkg/util/passsec"
+	"time"
+)
+
+// ChangePasswordService 修改自己的密码
+type ChangePasswordService struct {
+	OldPassword string `json:"old_password" binding:"required,max=72"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ResetPasswordService 管理员重置用户的密码
+type ResetPasswordService struct {
+	UserID      uint   `json:"user_id" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// Change 校验旧密码后按密码策略设置新密码
+func (s *ChangePasswordService) Change(d *Deps, userID uint) *common.HTTPResult {
+	if userID == 0 {
+		return common.Fail(errcode.New(errcode.AuthLoginRequired))
+	}
+	ctx := userContext(userID)
+	userRepo := d.Repos.Users
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if user == nil {
+		return common.Fail(errcode.New(errcode.UserNotFound))
+	}
+
+	if ok, _ := passsec.Verify(s.OldPassword, user.Password); !ok {
+		return common.Fail(errcode.New(errcode.AuthInvalidCredentials))
+	}
+	if res := checkNewPassword(ctx, userRepo, user, "new_password", s.NewPassword); res != nil {
+		return res
+	}
+	if res := setPassword(ctx, userRepo, user.ID, s.NewPassword); res != nil {
+		return res
+	}
+	return common.Success("user.password_changed", nil)
+}
+
+// Reset 为指定用户设置新密码，需要编辑任意用户资料的权限，新密码同样受密码策略约束
+func (s *ResetPasswordService) Reset(d *Deps, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.UserProfileEditAny); res != nil {
+		return
+	}
+	userRepo := d.Repos.Users
+	user, err := userRepo.FindByID(ctx, s.UserID)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if user == nil {
+		return common.Fail(errcode.New(errcode.UserNotFound))
+	}
+
+	if res = checkNewPassword(ctx, userRepo, user, "new_password", s.NewPassword); res != nil {
+		return
+	}
+	if res = setPassword(ctx, userRepo, user.ID, s.NewPassword); res != nil {
+		return
+	}
+	return common.Success("user.password_reset", nil)
+}
+
+// checkNewPassword 按全局密码策略检查新密码，user 为空时（注册）不检查密码历史
+func checkNewPassword(ctx context.Context, userRepo repository.UserRepository, user *model.User, field, password string) *common.HTTPResult {
+	policy := passsec.CurrentPolicy()
+	info := passsec.UserInfo{}
+	var history []string
+	if user != nil {
+		info = passsec.UserInfo{Username: user.Username, Email: user.Email}
+		if policy.HistorySize > 0 {
+			var err error
+			if history, err = userRepo.PasswordHistory(ctx, user.ID, policy.HistorySize); err != nil {
+				return common.Fail(err)
+			}
+		}
+	}
+	return checkPassword(policy, info, field, password, history)
+}
+
+// checkPassword 按密码策略检查密码，不满足时返回全部未满足的策略项
+func checkPassword(policy passsec.Policy, info passsec.UserInfo, field, password string, history []string) *common.HTTPResult {
+	violations, err := policy.Check(password, info, history)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if len(violations) > 0 {
+		return common.Fail(errcode.PasswordViolations(field, violations))
+	}
+	return nil
+}
+
+// setPassword 保存新密码并记录到密码历史
+func setPassword(ctx context.Context, userRepo repository.UserRepository, userID uint, password string) *common.HTTPResult {
+	hash, err := passsec.HashPassword(password)
+	if err != nil {
+		return common.Fail(fmt.Errorf("密码哈希失败: %w", err))
+	}
+	if err = userRepo.UpdatePassword(ctx, userID, hash, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return common.Fail(errcode.New(errcode.UserNotFound))
+		}
+		return common.Fail(err)
+	}
+	if err = userRepo.AddPasswordHistory(ctx, userID, hash, passsec.CurrentPolicy().HistorySize); err != nil {
+		return common.Fail(err)
+	}
+	return nil
+}
+
+// passwordExpired 用户的密码是否已超过有效期，未记录修改时间时按注册时间计算
+func passwordExpired(user *model.User) bool {
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return passsec.CurrentPolicy().Expired(changedAt, time.Now())
+}
+
+// rehashPassword 登录验证通过后，若哈希使用旧算法或旧参数则按当前算法重新哈希
+// 只替换哈希本身，不改变密码修改时间与密码历史；失败不影响登录，下次登录再试
+func rehashPassword(userRepo repository.UserRepository, user *model.User, password string) {
+	if !passsec.NeedsRehash(user.Password) {
+		return
+	}
+	hash, err := passsec.HashPassword(password)
+	if err != nil {
+		return
+	}
+	if err = userRepo.RehashPassword(context.Background(), user.ID, user.Password, hash); err == nil {
+		user.Password = hash
+	}
+}
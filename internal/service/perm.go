@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/perm"
+)
+
+// checkPerm 校验用户是否拥有指定权限，校验通过返回 nil
+func checkPerm(ctx context.Context, users repository.UserRepository, userID uint, p perm.Permission) *common.HTTPResult {
+	if userID == 0 {
+		return common.Fail(errcode.New(errcode.AuthLoginRequired))
+	}
+
+	user, err := users.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return common.Fail(errcode.New(errcode.AuthTokenInvalid))
+	}
+
+	if !perm.Permission(user.Perms).Has(p) {
+		return common.Fail(errcode.New(errcode.AuthPermissionDenied))
+	}
+	return nil
+}
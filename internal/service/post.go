@@ -2,13 +2,23 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/url"
 	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
 	"qwqserver/internal/model"
 	"qwqserver/internal/repository"
+	"qwqserver/pkg/markup"
+	"qwqserver/pkg/perm"
+	"time"
 )
 
 type PostService struct {
 	*model.Post
+	BoardID *uint  `json:"board_id"`                  // 版块ID，0 为未分版块，不传时保持原版块
+	Summary string `json:"summary" binding:"max=255"` // 修订说明，仅更新时使用
 }
 
 func NewPost(post *model.Post) *PostService {
@@ -17,39 +27,245 @@ func NewPost(post *model.Post) *PostService {
 	}
 }
 
-// Create 创建文章
-func (s *PostService) Create() (res *common.HTTPResult) {
-	res = &common.HTTPResult{}
+// CreatePostRequest 创建文章的参数，作者为当前用户，计数与版本号由服务端维护
+type CreatePostRequest struct {
+	Title   string `json:"title" binding:"required,max=1024"`
+	Content string `json:"content" binding:"required"`
+	Mod     string `json:"mod"`      // 内容模型 markdown/plain/html
+	BoardID uint   `json:"board_id"` // 版块ID，0 为未分版块
+	Status  string `json:"status"`   // draft/published/pending，为空时为 draft
+}
 
-	//
-	postRepo, err := repository.NewPostRepository()
+// Create 创建文章，作者为 userID
+func (s *CreatePostRequest) Create(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if res = checkPerm(ctx, d.Repos.Users, userID, perm.None); res != nil {
+		return
+	}
+
+	format, err := markup.ParseFormat(s.Mod)
 	if err != nil {
-		res.Code = 500
-		res.Msg = "获取数据库连接失败: " + err.Error()
+		return common.Fail(errcode.Wrap(errcode.PostFormatUnsupported, err).With("format", s.Mod))
+	}
+	if res = checkPostStatus(s.Status); res != nil {
 		return
 	}
 
-	if err = postRepo.Create(context.Background(), s.Post); err != nil {
-		res.Code = 500
-		res.Msg = "创建文章失败: " + err.Error()
+	post := &model.Post{
+		AuthorID: uint64(userID),
+		BoardID:  s.BoardID,
+		Mod:      string(format),
+		Title:    s.Title,
+		Content:  s.Content,
+		Status:   s.Status,
+	}
+	if post.Status == "" {
+		post.Status = "draft"
+	}
+	if post.Status == "published" {
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+
+	postRepo := d.Repos.Posts
+	if err = postRepo.Create(ctx, post); err != nil {
+		return common.Fail(fmt.Errorf("创建文章失败: %w", err))
+	}
+
+	// 登记热度榜与搜索索引，失败不影响创建结果
+	syncPostIndexes(ctx, d, post)
+
+	return common.Success("post.created", post)
+}
+
+// checkPostStatus 校验帖子状态，空值表示不修改或使用默认值
+func checkPostStatus(status string) *common.HTTPResult {
+	switch status {
+	case "", "draft", "published", "pending":
+		return nil
+	}
+	return common.Fail(errcode.New(errcode.InvalidEnum).
+		With("field", "status").
+		With("values", "draft, published, pending"))
+}
+
+// Update 更新文章并记录修订，作者本人需拥有 PostEditOwn 权限，其他人需拥有 PostEditAny 权限
+func (s *PostService) Update(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	postRepo := d.Repos.Posts
+
+	post, err := postRepo.FindByIDWithTags(ctx, s.ID)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if post == nil {
+		return common.Fail(errcode.New(errcode.PostNotFound))
+	}
+	if res = checkPostEdit(ctx, d.Repos.Users, userID, post); res != nil {
+		return
+	}
+
+	// 乐观锁：客户端必须提交开始编辑时的版本号
+	if s.Version <= 0 {
+		return common.Fail(errcode.New(errcode.MissingParam).With("field", "version"))
+	}
+	if s.Version != post.Version {
+		return versionConflict(post)
+	}
+
+	if s.Mod != "" {
+		format, err := markup.ParseFormat(s.Mod)
+		if err != nil {
+			return common.Fail(errcode.Wrap(errcode.PostFormatUnsupported, err).With("format", s.Mod))
+		}
+		post.Mod = string(format)
+	}
+
+	if res = checkPostStatus(s.Status); res != nil {
 		return
 	}
 
-	res.Code = 200
-	res.Msg = "创建文章成功"
-	res.Data = s
-	return
+	post.Title = s.Title
+	post.Content = s.Content
+	if s.BoardID != nil {
+		post.BoardID = *s.BoardID
+	}
+	if s.Status != "" {
+		if s.Status == "published" && post.PublishedAt == nil {
+			now := time.Now()
+			post.PublishedAt = &now
+		}
+		post.Status = s.Status
+	}
+	revision, err := postRepo.UpdateWithRevision(ctx, post, userID, s.Summary)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return reloadConflict(ctx, postRepo, post.ID)
+	}
+	if err != nil {
+		return common.Fail(fmt.Errorf("更新文章失败: %w", err))
+	}
+
+	syncPostIndexes(ctx, d, post)
+	// 保存成功后清除该用户的自动保存草稿
+	_ = d.Editing.DeleteDraft(ctx, userID, post.ID)
+
+	return common.Success("post.updated", gin.H{"post": post, "revision": revision.Version})
+}
+
+// versionConflict 版本冲突响应，返回服务器上的最新帖子供客户端合并
+func versionConflict(server *model.Post) *common.HTTPResult {
+	return common.Fail(errcode.New(errcode.PostVersionConflict).WithDetails(gin.H{"server": server}))
+}
+
+// reloadConflict 保存时检测到并发修改，重新读取最新帖子并返回冲突响应
+func reloadConflict(ctx context.Context, postRepo repository.PostRepository, postID uint) *common.HTTPResult {
+	server, err := postRepo.FindByIDWithTags(ctx, postID)
+	if err != nil || server == nil {
+		return common.Fail(errcode.New(errcode.PostVersionConflict))
+	}
+	return versionConflict(server)
+}
+
+// checkPostEdit 校验用户是否可以编辑帖子，校验通过返回 nil
+func checkPostEdit(ctx context.Context, users repository.UserRepository, userID uint, post *model.Post) *common.HTTPResult {
+	required := perm.PostEditAny
+	if uint(post.AuthorID) == userID {
+		required = perm.PostEditOwn
+	}
+	return checkPerm(ctx, users, userID, required)
 }
 
-// PostList 获取文章列表
-func PostList(page, pageSize int) (res *model.Result) {
-	res = &model.Result{}
+// canViewPost 用户是否可以查看帖子：已发布的帖子所有人可见，未发布的帖子只有作者与拥有 PostEditAny 权限的用户可见
+func canViewPost(ctx context.Context, users repository.UserRepository, userID uint, post *model.Post) bool {
+	if post.Status == "published" {
+		return true
+	}
+	if userID == 0 {
+		return false
+	}
+	return uint(post.AuthorID) == userID || checkPerm(ctx, users, userID, perm.PostEditAny) == nil
+}
 
-	_, err := repository.NewPostRepository()
+// checkPostDelete 校验用户是否可以删除、恢复或彻底删除帖子，校验通过返回 nil
+func checkPostDelete(ctx context.Context, users repository.UserRepository, userID uint, post *model.Post) *common.HTTPResult {
+	required := perm.PostDeleteAny
+	if uint(post.AuthorID) == userID {
+		required = perm.PostDeleteOwn
+	}
+	return checkPerm(ctx, users, userID, required)
+}
+
+// DeletePost 将文章移入回收站，作者本人需拥有 PostDeleteOwn 权限，其他人需拥有 PostDeleteAny 权限
+func DeletePost(d *Deps, postID, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	// 先确认已登录，具体权限取决于是否为作者
+	if res = checkPerm(ctx, d.Repos.Users, userID, perm.None); res != nil {
+		return
+	}
+
+	postRepo := d.Repos.Posts
+
+	post, err := postRepo.FindByID(ctx, postID)
 	if err != nil {
-		res.Code = 500
-		res.Message = "获取数据库连接失败: " + err.Error()
+		return common.Fail(err)
+	}
+	if post == nil {
+		return common.Fail(errcode.New(errcode.PostNotFound))
+	}
+
+	if res = checkPostDelete(ctx, d.Repos.Users, userID, post); res != nil {
 		return
 	}
-	panic("not implemented")
+
+	if err = postRepo.Delete(ctx, postID); err != nil {
+		return common.Fail(fmt.Errorf("删除文章失败: %w", err))
+	}
+
+	_ = d.Search.Remove(ctx, post.ID)
+	_ = d.Ranker.Remove(ctx, post.ID, post.BoardID)
+
+	return common.Success("post.deleted", nil)
+}
+
+// syncPostIndexes 同步帖子的热度榜与搜索索引，失败不影响主流程
+func syncPostIndexes(ctx context.Context, d *Deps, post *model.Post) {
+	_ = d.Ranker.Touch(ctx, post)
+	_ = d.Search.Index(ctx, post)
+}
+
+// postListSpec 帖子列表允许的过滤与排序字段
+var postListSpec = repository.ListSpec{
+	Filters: map[string]repository.FilterField{
+		"status":    {Column: "status", Values: []string{"draft", "pending", "published"}},
+		"board_id":  {Column: "board_id", Parse: parseUintParam},
+		"author_id": {Column: "author_id", Parse: parseUintParam},
+	},
+	Sorts: map[string]string{
+		"id":            "id",
+		"created_at":    "created_at",
+		"updated_at":    "updated_at",
+		"view_count":    "view_count",
+		"like_count":    "like_count",
+		"comment_count": "comment_count",
+	},
+	DefaultSort: "-created_at",
+}
+
+// PostList 获取文章列表，支持按状态、版块、作者过滤与排序，偏移或游标分页
+// 只有按自己的 author_id 查询时才返回未发布的帖子，其余情况只返回已发布的帖子
+func PostList(d *Deps, userID uint, values url.Values) (res *common.HTTPResult) {
+	q, err := repository.ParseListQuery(values, postListSpec)
+	if err != nil {
+		return listError(err)
+	}
+	if authors := q.FilterValues("author_id"); userID == 0 || len(authors) != 1 || authors[0] != any(userID) {
+		q.Where("status", "published")
+	}
+
+	page, err := d.Repos.Posts.List(userContext(userID), q)
+	if err != nil {
+		return listError(err)
+	}
+	return common.OK(page)
 }
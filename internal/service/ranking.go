@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/model"
+)
+
+// PopularList 获取热门帖子，boardID 为 nil 时为全站热门
+// 优先使用 Redis 热度榜，不可用时退回数据库计算
+func PopularList(d *Deps, boardID *uint, days, limit int) (res *common.HTTPResult) {
+	ctx := context.Background()
+
+	postRepo := d.Repos.Posts
+
+	var (
+		posts  []*model.Post
+		source = "redis"
+	)
+	ids, err := d.Ranker.Top(ctx, boardID, days, limit)
+	if err == nil {
+		posts, err = postRepo.FindByIDs(ctx, ids)
+	}
+	if err != nil {
+		source = "sql"
+		if boardID != nil {
+			posts, err = postRepo.ListPopularByBoard(ctx, *boardID, days, limit)
+		} else {
+			posts, err = postRepo.ListPopular(ctx, days, limit)
+		}
+	}
+	if err != nil {
+		return common.Fail(err)
+	}
+
+	return common.OK(gin.H{
+		"list":   posts,
+		"source": source,
+	})
+}
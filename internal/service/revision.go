@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/locale"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/diff"
+	"qwqserver/pkg/i18n"
+)
+
+// diffContext 差异上下文行数
+const diffContext = 3
+
+// RevisionService 帖子修订历史服务
+type RevisionService struct {
+	PostID  uint `json:"post_id" form:"post_id" binding:"required"`
+	Version int  `json:"version" form:"version" binding:"required,min=1"`
+}
+
+// loadEditablePost 加载帖子并校验编辑权限（查看修订与回滚需要与编辑相同的权限）
+func loadEditablePost(ctx context.Context, repos *repository.Repositories, postID, userID uint) (*model.Post, *common.HTTPResult) {
+	post, err := repos.Posts.FindByIDWithTags(ctx, postID)
+	if err != nil {
+		return nil, common.Fail(err)
+	}
+	if post == nil {
+		return nil, common.Fail(errcode.New(errcode.PostNotFound))
+	}
+	if res := checkPostEdit(ctx, repos.Users, userID, post); res != nil {
+		return nil, res
+	}
+	return post, nil
+}
+
+// List 获取修订列表
+func (s *RevisionService) List(d *Deps, userID uint, page, pageSize int) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	postRepo := d.Repos.Posts
+	if _, res = loadEditablePost(ctx, d.Repos, s.PostID, userID); res != nil {
+		return
+	}
+
+	revisions, total, err := postRepo.ListRevisions(ctx, s.PostID, page, pageSize)
+	if err != nil {
+		return common.Fail(err)
+	}
+
+	return common.OK(repository.NewPage(revisions, total, page, pageSize))
+}
+
+// Detail 获取指定版本的完整内容
+func (s *RevisionService) Detail(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	postRepo := d.Repos.Posts
+	if _, res = loadEditablePost(ctx, d.Repos, s.PostID, userID); res != nil {
+		return
+	}
+
+	revision, err := postRepo.FindRevision(ctx, s.PostID, s.Version)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if revision == nil {
+		return common.Fail(errcode.New(errcode.RevisionNotFound))
+	}
+
+	return common.OK(revision)
+}
+
+// RevisionDiffService 修订差异
+type RevisionDiffService struct {
+	PostID uint   `form:"post_id" binding:"required"`
+	From   int    `form:"from" binding:"min=0"`                     // 旧版本，为 0 时取 To 的前一个版本
+	To     int    `form:"to" binding:"min=0"`                       // 新版本，为 0 时取最新版本
+	Mode   string `form:"mode" binding:"omitempty,oneof=line word"` // line（默认）按行比较，word 额外计算行内按词差异
+}
+
+// Diff 比较两个版本
+func (s *RevisionDiffService) Diff(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	postRepo := d.Repos.Posts
+	if _, res = loadEditablePost(ctx, d.Repos, s.PostID, userID); res != nil {
+		return
+	}
+
+	var (
+		to  *model.PostRevision
+		err error
+	)
+	if s.To == 0 {
+		to, err = postRepo.LatestRevision(ctx, s.PostID)
+	} else {
+		to, err = postRepo.FindRevision(ctx, s.PostID, s.To)
+	}
+	if err != nil {
+		return common.Fail(err)
+	}
+	if to == nil {
+		return common.Fail(errcode.New(errcode.RevisionNotFound))
+	}
+
+	fromVersion := s.From
+	if fromVersion == 0 {
+		fromVersion = to.Version - 1
+	}
+	from, err := postRepo.FindRevision(ctx, s.PostID, fromVersion)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if from == nil {
+		return common.Fail(errcode.New(errcode.RevisionNotFound))
+	}
+
+	data := gin.H{
+		"from":          from.Version,
+		"to":            to.Version,
+		"title_changed": from.Title != to.Title,
+		"title":         diff.Words(from.Title, to.Title),
+		"too_large":     false,
+	}
+	// 内容超过大小限制时不比较，客户端提示差异过大
+	if diff.TooLarge(from.Content, to.Content) {
+		data["too_large"] = true
+		data["hunks"], data["unified"] = []diff.Hunk{}, ""
+		return common.OK(data)
+	}
+	oldName := fmt.Sprintf("v%d", from.Version)
+	newName := fmt.Sprintf("v%d", to.Version)
+	data["hunks"] = diff.Hunks(from.Content, to.Content, diffContext, s.Mode == "word")
+	data["unified"] = diff.Unified(oldName, newName, from.Content, to.Content, diffContext)
+	return common.OK(data)
+}
+
+// Rollback 回滚到指定版本，回滚本身也会生成一条新修订
+func (s *RevisionService) Rollback(d *Deps, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	postRepo := d.Repos.Posts
+	post, res := loadEditablePost(ctx, d.Repos, s.PostID, userID)
+	if res != nil {
+		return
+	}
+
+	revision, err := postRepo.FindRevision(ctx, s.PostID, s.Version)
+	if err != nil {
+		return common.Fail(err)
+	}
+	if revision == nil {
+		return common.Fail(errcode.New(errcode.RevisionNotFound))
+	}
+
+	post.Title = revision.Title
+	post.Content = revision.Content
+	post.Mod = revision.Mod
+	created, err := postRepo.UpdateWithRevision(ctx, post, userID, locale.T(locale.DefaultLang(), "revision.rollback_summary", i18n.Args{"version": revision.Version}))
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return reloadConflict(ctx, postRepo, post.ID)
+	}
+	if err != nil {
+		return common.Fail(fmt.Errorf("回滚失败: %w", err))
+	}
+
+	syncPostIndexes(ctx, d, post)
+
+	return common.Success("revision.rolled_back", gin.H{"post": post, "revision": created.Version})
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"qwqserver/internal/common"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/search"
+	"qwqserver/pkg/i18n"
+	"time"
+)
+
+// SearchService 帖子搜索
+type SearchService struct {
+	Query    string     `form:"q"`                             // 查询词，双引号包裹的部分为短语
+	Tag      string     `form:"tag"`                           // 标签名
+	BoardID  *uint      `form:"board_id"`                      // 版块ID
+	AuthorID uint       `form:"author_id"`                     // 作者ID
+	From     *time.Time `form:"from" time_format:"2006-01-02"` // 起始日期（含）
+	To       *time.Time `form:"to" time_format:"2006-01-02"`   // 截止日期（含）
+}
+
+// Search 搜索帖子
+func (s *SearchService) Search(d *Deps, page, pageSize int) (res *common.HTTPResult) {
+
+	filter := repository.SearchFilter{
+		Tag:      s.Tag,
+		BoardID:  s.BoardID,
+		AuthorID: s.AuthorID,
+		From:     s.From,
+	}
+	if s.To != nil {
+		// 截止日期包含当天
+		to := s.To.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	result, err := d.Search.Search(context.Background(), search.Query{
+		Text:         s.Query,
+		SearchFilter: filter,
+		Page:         page,
+		PageSize:     pageSize,
+	})
+	if err != nil {
+		return common.Fail(fmt.Errorf("搜索失败: %w", err))
+	}
+
+	return common.Success("post.found", gin.H{
+		"list":      result.Hits,
+		"total":     result.Total,
+		"engine":    result.Engine,
+		"page":      page,
+		"page_size": pageSize,
+	}).WithArgs(i18n.Args{i18n.CountArg: result.Total})
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/url"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/pkg/perm"
+)
+
+// postTrashSpec 回收站列表允许的过滤与排序字段
+var postTrashSpec = repository.ListSpec{
+	Filters: map[string]repository.FilterField{
+		"board_id":  {Column: "board_id", Parse: parseUintParam},
+		"author_id": {Column: "author_id", Parse: parseUintParam},
+	},
+	Sorts: map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+		"deleted_at": "deleted_at",
+	},
+	DefaultSort: "-deleted_at",
+}
+
+// PostTrash 获取回收站中的文章，拥有 PostDeleteAny 权限时返回全部，否则只返回自己的（需拥有 PostDeleteOwn 权限）
+func PostTrash(d *Deps, userID uint, values url.Values) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+
+	var scope func(db *gorm.DB) *gorm.DB
+	if checkPerm(ctx, d.Repos.Users, userID, perm.PostDeleteAny) != nil {
+		if res = checkPerm(ctx, d.Repos.Users, userID, perm.PostDeleteOwn); res != nil {
+			return
+		}
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("author_id = ?", userID)
+		}
+	}
+
+	q, err := repository.ParseListQuery(values, postTrashSpec)
+	if err != nil {
+		return listError(err)
+	}
+	page, err := d.Repos.Posts.Trash(ctx, q, scope)
+	if err != nil {
+		return listError(err)
+	}
+	return common.OK(page)
+}
+
+// findTrashedPost 获取回收站中的帖子并校验删除权限，校验失败时返回错误响应
+func findTrashedPost(ctx context.Context, d *Deps, postID, userID uint) (*model.Post, *common.HTTPResult) {
+	// 先确认已登录，具体权限取决于是否为作者
+	if res := checkPerm(ctx, d.Repos.Users, userID, perm.None); res != nil {
+		return nil, res
+	}
+
+	post, err := d.Repos.Posts.FindTrashed(ctx, postID)
+	if err != nil {
+		return nil, common.Fail(err)
+	}
+	if post == nil {
+		return nil, common.Fail(errcode.New(errcode.PostNotFound))
+	}
+	if res := checkPostDelete(ctx, d.Repos.Users, userID, post); res != nil {
+		return nil, res
+	}
+	return post, nil
+}
+
+// RestorePost 从回收站恢复文章并重新加入热度榜与搜索索引，权限同删除
+func RestorePost(d *Deps, postID, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if _, res = findTrashedPost(ctx, d, postID, userID); res != nil {
+		return
+	}
+
+	postRepo := d.Repos.Posts
+	if err := postRepo.Restore(ctx, postID); err != nil {
+		return common.Fail(notFoundAs(err, errcode.PostNotFound))
+	}
+
+	if post, err := postRepo.FindByIDWithTags(ctx, postID); err == nil && post != nil {
+		syncPostIndexes(ctx, d, post)
+	}
+
+	return common.Success("post.restored", gin.H{"id": postID})
+}
+
+// PurgePost 彻底删除回收站中的文章及其关联数据，权限同删除
+func PurgePost(d *Deps, postID, userID uint) (res *common.HTTPResult) {
+	ctx := userContext(userID)
+	if _, res = findTrashedPost(ctx, d, postID, userID); res != nil {
+		return
+	}
+
+	if err := d.Repos.Posts.Purge(ctx, postID); err != nil {
+		return common.Fail(notFoundAs(err, errcode.PostNotFound))
+	}
+
+	return common.Success("post.purged", nil)
+}
+
+// RestoreUser 从回收站恢复用户及随用户删除的帖子，帖子重新加入热度榜与搜索索引，需要编辑任意用户资料的权限
+func RestoreUser(d *Deps, uid, operatorID uint) (res *common.HTTPResult) {
+	ctx := userContext(operatorID)
+	if res = checkPerm(ctx, d.Repos.Users, operatorID, perm.UserProfileEditAny); res != nil {
+		return
+	}
+
+	if err := d.Repos.Users.Restore(ctx, uid); err != nil {
+		return common.Fail(notFoundAs(err, errcode.UserNotFound))
+	}
+
+	// 索引同步失败不影响恢复结果
+	if posts, err := listUserPosts(ctx, d, uid); err == nil {
+		for _, p := range posts {
+			if post, err := d.Repos.Posts.FindByIDWithTags(ctx, p.ID); err == nil && post != nil {
+				syncPostIndexes(ctx, d, post)
+			}
+		}
+	}
+
+	return common.Success("user.restored", gin.H{"id": uid})
+}
+
+// listUserPosts 获取用户未删除的全部帖子
+func listUserPosts(ctx context.Context, d *Deps, userID uint) ([]*model.Post, error) {
+	const pageSize = repository.MaxPageSize
+	var all []*model.Post
+	for page := 1; ; page++ {
+		posts, _, err := d.Repos.Posts.ListByUserID(ctx, userID, page, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("查询用户帖子失败: %w", err)
+		}
+		all = append(all, posts...)
+		if len(posts) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// removePostIndexes 把帖子移出热度榜与搜索索引，失败不影响主流程
+func removePostIndexes(ctx context.Context, d *Deps, posts []*model.Post) {
+	for _, post := range posts {
+		_ = d.Search.Remove(ctx, post.ID)
+		_ = d.Ranker.Remove(ctx, post.ID, post.BoardID)
+	}
+}
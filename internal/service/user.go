@@ -5,14 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"qwqserver/internal/auth"
 	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
 	"qwqserver/internal/model"
-	"qwqserver/internal/repository"
-	"qwqserver/pkg/cache"
+	"qwqserver/internal/session"
 	"qwqserver/pkg/util/passsec"
 	"strconv"
+	"time"
 )
 
 type AuthService struct {
@@ -21,117 +21,119 @@ type AuthService struct {
 	Password string `json:"password"`
 	Email    string `json:"email"`
 	Nickname string `json:"nickname"`
+	Locale   string `json:"locale"` // 语言偏好（如 zh-CN、en-US），为空时按 Accept-Language
 }
 
-// Del 删除用户
-func (s *AuthService) Del(uid uint, platform, deviceID string) (res *common.HTTPResult) {
-	// 初始化 返回结果
-	res = &common.HTTPResult{}
-	user := &model.User{}
+// RegisterRequest 注册参数
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=32,pattern=username"`
+	Password string `json:"password" binding:"required"` // 长度、强度等由密码策略检查
+	Email    string `json:"email" binding:"required,max=128,email"`
+	Nickname string `json:"nickname" binding:"required,max=32"`
+	Locale   string `json:"locale" binding:"omitempty,max=16"` // 语言偏好（如 zh-CN、en-US），为空时按 Accept-Language
+}
+
+// LoginRequest 登录参数，使用用户名或邮箱登录
+type LoginRequest struct {
+	Username string `json:"username" binding:"omitempty,max=128"`
+	Email    string `json:"email" binding:"omitempty,max=128,email"`
+	Password string `json:"password" binding:"required,max=72"`
+}
 
+// UserIDRequest 登出、删除、恢复用户的参数
+type UserIDRequest struct {
+	ID uint `json:"id" binding:"required"`
+}
+
+// LocaleRequest 设置语言偏好的参数
+type LocaleRequest struct {
+	Locale string `json:"locale" binding:"omitempty,max=16"` // 为空时清除偏好
+}
+
+// Del 将用户及其帖子移入回收站，帖子同时移出热度榜与搜索索引
+func (s *AuthService) Del(d *Deps, uid uint, platform, deviceID string) (res *common.HTTPResult) {
 	if uid == 0 {
-		res.Code = 400
-		res.Msg = "用户ID不能为: 0"
-		return
+		return common.Fail(errcode.New(errcode.BadRequest))
 	}
 
-	userRepo, err := repository.NewUserRepository()
-	if err != nil {
-		res.Code = 500
-		res.Msg = "获取数据库连接失败: " + err.Error()
-		return
-	}
+	userRepo := d.Repos.Users
 
 	// 检查用户是否存在
-	if user, err = userRepo.FindByID(context.Background(), uid); err != nil || user == nil {
-		res.Code = 404
-		res.Msg = "用户不存在"
-		return
+	user, err := userRepo.FindByID(userContext(uid), uid)
+	if err != nil || user == nil {
+		return common.Fail(errcode.New(errcode.UserNotFound))
 	}
 
-	if err = userRepo.Delete(context.Background(), user.ID); err != nil {
-		res.Code = 500
-		res.Msg = "删除用户失败: " + err.Error()
-		return
+	// 删除后帖子进入回收站无法再按用户查询，先取出帖子，删除成功后再移出索引
+	posts, err := listUserPosts(userContext(uid), d, user.ID)
+	if err != nil {
+		return common.Fail(err)
 	}
-
-	res.Code = 200
-	res.Msg = "删除用户成功"
-	res.Data = map[string]any{
-		"uid":      user.ID,
-		"username": user.Username,
-		"email":    user.Email,
+	if err = userRepo.Delete(userContext(uid), user.ID); err != nil {
+		return common.Fail(fmt.Errorf("删除用户失败: %w", err))
 	}
+	removePostIndexes(userContext(uid), d, posts)
 
 	// 登出
-	return s.Logout(uid, platform, deviceID)
+	return s.Logout(d, uid, platform, deviceID)
 }
 
 // 注册
-func (s *AuthService) Register() *common.HTTPResult {
-	res := &common.HTTPResult{}
+func (s *AuthService) Register(d *Deps) *common.HTTPResult {
 	req := s
 
-	if req.Username == "" || req.Password == "" || req.Nickname == "" || req.Email == "" {
-		res.Code = http.StatusBadRequest
-		res.Msg = "注册用户参数错误"
+	lang, res := canonicalLocale(req.Locale)
+	if res != nil {
 		return res
 	}
 
-	userRepo, err := repository.NewUserRepository()
-	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "获取数据库连接失败: " + err.Error()
-		return res
-	}
+	userRepo := d.Repos.Users
 
 	// 检查用户名是否已存在
 	if ok, _ := userRepo.ExistUsername(context.Background(), req.Username); ok {
-		res.Code = http.StatusConflict
-		res.Msg = "您输入的用户名已经存在"
-		return res
+		return common.Fail(errcode.New(errcode.UserUsernameTaken))
 	}
 
 	// 检查邮箱是否已存在
 	if ok, _ := userRepo.ExistEmail(context.Background(), req.Email); ok {
-		res.Code = http.StatusConflict
-		res.Msg = "您输入的邮箱已经存在"
+		return common.Fail(errcode.New(errcode.UserEmailTaken))
+	}
+
+	// 密码策略
+	if res = checkPassword(passsec.CurrentPolicy(), passsec.UserInfo{Username: req.Username, Email: req.Email}, "password", req.Password, nil); res != nil {
 		return res
 	}
 
 	// 密码哈希
-	pwd, err := passsec.Hash(req.Password)
+	pwd, err := passsec.HashPassword(req.Password)
 	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "密码哈希失败: " + err.Error()
-		return res
+		return common.Fail(fmt.Errorf("密码哈希失败: %w", err))
 	}
+	now := time.Now()
 
 	// 创建新用户
 	newUser := model.User{
-		Username:     req.Username,
-		Password:     pwd,
-		PasswordSalt: pwd,
-		PasswordHash: pwd,
-		Nickname:     req.Nickname,
-		Email:        req.Email,
-		Status:       1,
+		Username: req.Username,
+		Password: pwd,
+		Nickname: req.Nickname,
+		Email:    req.Email,
+		Locale:   lang,
+		Status:   1,
+
+		PasswordChangedAt: &now,
 	}
 	if err = userRepo.Create(context.Background(), &newUser); err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "创建用户失败 Error: " + err.Error()
-		return res
+		return common.Fail(fmt.Errorf("创建用户失败: %w", err))
+	}
+	if err = userRepo.AddPasswordHistory(context.Background(), newUser.ID, pwd, passsec.CurrentPolicy().HistorySize); err != nil {
+		return common.Fail(err)
 	}
 
-	res.Code = http.StatusOK
-	res.Data = gin.H{"user_id": newUser.ID}
-	res.Msg = "注册成功！"
-	return res
+	return common.Success("user.registered", gin.H{"user_id": newUser.ID})
 }
 
 // 登录
-func (s *AuthService) Login(platform, deviceID string) (res *common.HTTPResult) {
-	res = &common.HTTPResult{}
+func (s *AuthService) Login(d *Deps, platform, deviceID string) (res *common.HTTPResult) {
 	token := &common.JWTResult{
 		DeviceID: deviceID,
 		Platform: platform,
@@ -139,153 +141,105 @@ func (s *AuthService) Login(platform, deviceID string) (res *common.HTTPResult)
 	mUser := &model.User{}
 	req := s
 
-	userRepo, err := repository.NewUserRepository()
-	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "获取数据库连接失败: " + err.Error()
-		return
-	}
+	userRepo := d.Repos.Users
 
+	// 用户不存在与密码错误返回相同的错误码，避免探测已注册的用户名与邮箱
 	if req.Username != "" {
-		if mUser, _ = userRepo.FindByUsername(context.Background(), req.Username); mUser == nil {
-			res.Code = http.StatusNotFound
-			res.Msg = "用户名不存在"
-			return
-		}
+		mUser, _ = userRepo.FindByUsername(context.Background(), req.Username)
 	} else {
-		if mUser, _ = userRepo.FindByEmail(context.Background(), req.Email); mUser == nil {
-			res.Code = http.StatusNotFound
-			res.Msg = "邮箱不存在"
-			return
-		}
+		mUser, _ = userRepo.FindByEmail(context.Background(), req.Email)
+	}
+	if mUser == nil {
+		return common.Fail(errcode.New(errcode.AuthInvalidCredentials))
 	}
 
-	// 校验密码（应使用安全哈希，略）
-	var ok bool
-	if ok, err = passsec.Check(req.Password, mUser.Password); err != nil {
-		res.Code = http.StatusUnauthorized
-		res.Msg = "密码校验出错"
-		return
+	// 校验密码
+	ok, err := passsec.Verify(req.Password, mUser.Password)
+	if err != nil {
+		return common.Fail(errcode.Wrap(errcode.AuthInvalidCredentials, err))
 	}
 
 	if !ok {
-		res.Code = http.StatusUnauthorized
-		res.Msg = "密码错误"
-		return
+		return common.Fail(errcode.New(errcode.AuthInvalidCredentials))
 	}
 
-	// 禁用状态
+	// 禁用状态，禁用的用户不更新密码哈希
 	if mUser.Status == 0 {
-		res.Code = http.StatusForbidden
-		res.Msg = "用户已被封禁"
-		return
+		return common.Fail(errcode.New(errcode.UserDisabled))
 	}
+	rehashPassword(userRepo, mUser, req.Password)
 
 	uid := strconv.Itoa(int(mUser.ID))
 	token.ID = mUser.ID
+	// 密码过期仍允许登录，由客户端引导修改密码
+	token.PasswordExpired = passwordExpired(mUser)
 
 	// 生成Token和RefreshToken
 	newToken, err := auth.GenerateToken(uid, platform, deviceID)
 	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "生成Token出错"
-		token.AccessToken = ""
-		token.RefreshToken = ""
-		return
+		return common.Fail(fmt.Errorf("生成Token出错: %w", err))
 	}
 	// token
 	token.AccessToken = newToken
 
 	refreshToken, err := auth.GenerateRefreshToken(uid, platform, deviceID)
 	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "生成RefreshToken出错"
-		token.AccessToken = ""
-		token.RefreshToken = ""
-		return
+		return common.Fail(fmt.Errorf("生成RefreshToken出错: %w", err))
 	}
 	// refresh token
 	token.RefreshToken = refreshToken
 
-	// 存储Token到Redis
-	tokenKey := common.RedisTokenPrefix + uid + ":" + platform + ":" + deviceID
-	cache.Set(tokenKey, token, common.TokenExpireTime)
-
-	// 存储RefreshToken
-	refreshKey := common.RedisRefreshPrefix + uid + ":" + platform + ":" + deviceID
-	cache.Set(refreshKey, refreshToken, common.RefreshTokenExpire)
-
-	// 记录用户设备关系
-	userDeviceKey := common.RedisUserDevicePrefix + uid
-	cache.HSet(userDeviceKey, platform, deviceID)
-
-	res.Code = http.StatusOK
-	res.Msg = "登录成功"
-	res.Data = token
+	// 保存令牌，覆盖该设备之前的登录
+	if d.Sessions == nil {
+		return common.Fail(errcode.New(errcode.Unavailable))
+	}
+	key := session.Key{UserID: uid, Platform: platform, DeviceID: deviceID}
+	if err = d.Sessions.Save(context.Background(), key, newToken, refreshToken); err != nil {
+		return common.Fail(err)
+	}
 
-	return
+	return common.Success("user.logged_in", token)
 }
 
 // 登出
-func (s *AuthService) Logout(uid uint, platform, deviceID string) (res *common.HTTPResult) {
-	//
-	res = &common.HTTPResult{}
-	mUser := &model.User{}
-	userRepo, err := repository.NewUserRepository()
-	if err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "获取数据库连接失败: " + err.Error()
-		return
-	}
+func (s *AuthService) Logout(d *Deps, uid uint, platform, deviceID string) (res *common.HTTPResult) {
+	userRepo := d.Repos.Users
 
 	// 判断用户是否存在
-	if mUser, err = userRepo.FindByID(context.Background(), uid); err != nil || mUser == nil {
-		res.Code = http.StatusNotFound
-		res.Msg = "用户不存在"
-		return
-	}
-
-	// 删除Token
-	tokenKey := fmt.Sprintf("%v:%v:%v:%v", common.RedisTokenPrefix, uid, platform, deviceID)
-	if err = cache.Del(tokenKey); err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "删除Token出错" + err.Error()
-		return
+	mUser, err := userRepo.FindByID(userContext(uid), uid)
+	if err != nil || mUser == nil {
+		return common.Fail(errcode.New(errcode.UserNotFound))
 	}
 
-	// 删除RefreshToken
-	//refreshKey := common.RedisRefreshPrefix + userID + ":" + platform + ":" + deviceID
-	refreshKey := fmt.Sprintf("%v:%v:%v:%v", common.RedisRefreshPrefix, uid, platform, deviceID)
-	if err = cache.Del(refreshKey); err != nil {
-		res.Code = http.StatusInternalServerError
-		res.Msg = "删除RefreshToken出错" + err.Error()
-		return
+	// 删除Token与RefreshToken
+	if d.Sessions != nil {
+		key := session.Key{UserID: strconv.Itoa(int(uid)), Platform: platform, DeviceID: deviceID}
+		if err = d.Sessions.Delete(userContext(uid), key); err != nil {
+			return common.Fail(err)
+		}
 	}
-
-	// 删除设备记录
-	userDeviceKey := fmt.Sprintf("%v%v", common.RedisUserDevicePrefix, uid)
-	cache.HDel(userDeviceKey, platform)
-	res.Msg = "登出成功"
-	res.Code = http.StatusOK
-	res.Data = map[string]any{
+	return common.Success("user.logged_out", map[string]any{
 		"uid":      uid,
 		"username": mUser.Username,
 		"email":    mUser.Email,
 		"nickname": mUser.Nickname,
-	}
-	return
+	})
 }
 
-// 刷新Token
-func (s *AuthService) RefreshToken(refreshToken string) (string, string, error) {
+// 刷新Token，旧的刷新令牌随之失效
+func (s *AuthService) RefreshToken(d *Deps, refreshToken string) (string, string, error) {
 	claims, err := auth.ParseToken(refreshToken)
 	if err != nil {
 		return "", "", err
 	}
+	if d.Sessions == nil {
+		return "", "", errcode.New(errcode.Unavailable)
+	}
 
 	// 验证RefreshToken是否有效
-	refreshKey := common.RedisRefreshPrefix + claims.UserID + ":" + claims.Platform + ":" + claims.DeviceID
-	storedRefresh, err := cache.Get(refreshKey)
+	ctx := context.Background()
+	key := session.Key{UserID: claims.UserID, Platform: claims.Platform, DeviceID: claims.DeviceID}
+	storedRefresh, err := d.Sessions.RefreshToken(ctx, key)
 	if err != nil || storedRefresh != refreshToken {
 		return "", "", errors.New("无效的刷新令牌")
 	}
@@ -301,13 +255,9 @@ func (s *AuthService) RefreshToken(refreshToken string) (string, string, error)
 		return "", "", err
 	}
 
-	// 更新Redis中的Token
-	tokenKey := common.RedisTokenPrefix + claims.UserID + ":" + claims.Platform + ":" + claims.DeviceID
-	cache.Set(tokenKey, newToken, common.TokenExpireTime)
-
-	// 更新RefreshToken
-	cache.Set(refreshKey, newRefreshToken, common.RefreshTokenExpire)
-
+	if err = d.Sessions.Save(ctx, key, newToken, newRefreshToken); err != nil {
+		return "", "", err
+	}
 	return newToken, newRefreshToken, nil
 }
 
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"qwqserver/internal/common"
+)
+
+// memoryStore 进程内令牌存储，并发安全，过期的令牌在读取时清理
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value    string
+	expireAt time.Time
+}
+
+// NewMemory 创建进程内令牌存储
+func NewMemory() Store {
+	return &memoryStore{entries: map[string]memoryEntry{}}
+}
+
+func (s *memoryStore) set(k, v string, ttl time.Duration) {
+	s.entries[k] = memoryEntry{value: v, expireAt: time.Now().Add(ttl)}
+}
+
+func (s *memoryStore) Save(ctx context.Context, key Key, token, refreshToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key.tokenKey(), token, common.TokenExpireTime)
+	s.set(key.refreshKey(), refreshToken, common.RefreshTokenExpire)
+	return nil
+}
+
+func (s *memoryStore) SetToken(ctx context.Context, key Key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key.tokenKey(), token, common.TokenExpireTime)
+	return nil
+}
+
+func (s *memoryStore) Token(ctx context.Context, key Key) (string, error) {
+	return s.get(key.tokenKey()), nil
+}
+
+func (s *memoryStore) RefreshToken(ctx context.Context, key Key) (string, error) {
+	return s.get(key.refreshKey()), nil
+}
+
+func (s *memoryStore) get(k string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[k]
+	if !ok {
+		return ""
+	}
+	if time.Now().After(e.expireAt) {
+		delete(s.entries, k)
+		return ""
+	}
+	return e.value
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key.tokenKey())
+	delete(s.entries, key.refreshKey())
+	return nil
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"qwqserver/internal/common"
+)
+
+// redisStore 基于 Redis 的令牌存储，多个实例共享
+type redisStore struct {
+	redis *redis.Client
+}
+
+// NewRedis 创建基于 Redis 的令牌存储
+func NewRedis(redisClient *redis.Client) Store {
+	return &redisStore{redis: redisClient}
+}
+
+func (s *redisStore) Save(ctx context.Context, key Key, token, refreshToken string) error {
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, key.tokenKey(), token, common.TokenExpireTime)
+	pipe.Set(ctx, key.refreshKey(), refreshToken, common.RefreshTokenExpire)
+	pipe.HSet(ctx, key.deviceKey(), key.Platform, key.DeviceID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("保存令牌失败: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) SetToken(ctx context.Context, key Key, token string) error {
+	if err := s.redis.Set(ctx, key.tokenKey(), token, common.TokenExpireTime).Err(); err != nil {
+		return fmt.Errorf("保存令牌失败: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) Token(ctx context.Context, key Key) (string, error) {
+	return s.get(ctx, key.tokenKey())
+}
+
+func (s *redisStore) RefreshToken(ctx context.Context, key Key) (string, error) {
+	return s.get(ctx, key.refreshKey())
+}
+
+func (s *redisStore) get(ctx context.Context, k string) (string, error) {
+	v, err := s.redis.Get(ctx, k).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("读取令牌失败: %w", err)
+	}
+	return v, nil
+}
+
+func (s *redisStore) Delete(ctx context.Context, key Key) error {
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, key.tokenKey(), key.refreshKey())
+	pipe.HDel(ctx, key.deviceKey(), key.Platform)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("删除令牌失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Package session 登录令牌存储
+//
+// 令牌本身为 JWT，存储中只保留每个用户在每个平台、设备上当前有效的一组令牌，
+// 用于登出或删除用户后令牌立即失效，以及刷新令牌只能使用一次。
+//
+// Redis 中维护：
+//   - user_token:{userID}:{platform}:{deviceID}     访问令牌，过期时间同令牌有效期
+//   - refresh_token:{userID}:{platform}:{deviceID}  刷新令牌，过期时间同刷新令牌有效期
+//   - user_device:{userID}                          平台 → 最近登录的设备ID（哈希）
+//
+// 未配置 Redis 时使用进程内存储，令牌只在当前进程中有效，适用于单实例部署与测试。
+package session
+
+import (
+	"context"
+
+	"qwqserver/internal/common"
+)
+
+// Key 令牌所属的用户、平台与设备
+type Key struct {
+	UserID   string
+	Platform string
+	DeviceID string
+}
+
+func (k Key) suffix() string {
+	return k.UserID + ":" + k.Platform + ":" + k.DeviceID
+}
+
+func (k Key) tokenKey() string {
+	return common.RedisTokenPrefix + k.suffix()
+}
+
+func (k Key) refreshKey() string {
+	return common.RedisRefreshPrefix + k.suffix()
+}
+
+func (k Key) deviceKey() string {
+	return common.RedisUserDevicePrefix + k.UserID
+}
+
+// Store 令牌存储
+type Store interface {
+	// Save 保存访问令牌与刷新令牌，覆盖同一设备之前的令牌
+	Save(ctx context.Context, key Key, token, refreshToken string) error
+	// SetToken 只替换访问令牌（无感刷新），刷新令牌不变
+	SetToken(ctx context.Context, key Key, token string) error
+	// Token 当前有效的访问令牌，不存在或已过期时返回空串
+	Token(ctx context.Context, key Key) (string, error)
+	// RefreshToken 当前有效的刷新令牌，不存在或已过期时返回空串
+	RefreshToken(ctx context.Context, key Key) (string, error)
+	// Delete 删除设备的全部令牌（登出）
+	Delete(ctx context.Context, key Key) error
+}
@@ -0,0 +1,99 @@
+// Package trash 回收站定期清理
+//
+// 软删除的记录进入回收站，后台协程按间隔彻底删除移入回收站超过保留时间的记录（含关联数据），
+// 具体清理的模型与顺序见 repository.Repositories.PurgeTrash。
+package trash
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"qwqserver/internal/repository"
+)
+
+// Config 回收站清理配置
+type Config struct {
+	Retention     time.Duration // 保留时间
+	PurgeInterval time.Duration // 清理间隔
+	PurgeBatch    int           // 每个事务彻底删除的记录数
+}
+
+// Logger 日志接口
+type Logger interface {
+	Error(msg string, args ...any)
+}
+
+// Purger 回收站定期清理
+type Purger struct {
+	repos *repository.Repositories
+	cfg   Config
+	log   Logger
+	now   func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// New 创建清理任务并启动后台协程
+func New(repos *repository.Repositories, cfg Config, l Logger) *Purger {
+	if cfg.Retention <= 0 {
+		cfg.Retention = 30 * 24 * time.Hour
+	}
+	if cfg.PurgeInterval <= 0 {
+		cfg.PurgeInterval = time.Hour
+	}
+	if cfg.PurgeBatch <= 0 {
+		cfg.PurgeBatch = 500
+	}
+	p := &Purger{
+		repos: repos,
+		cfg:   cfg,
+		log:   l,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Close 停止后台协程，正在进行的清理完成后返回
+func (p *Purger) Close() {
+	if p == nil {
+		return
+	}
+	p.once.Do(func() {
+		close(p.stop)
+		<-p.done
+	})
+}
+
+// Purge 立即彻底删除超过保留时间的回收站记录，返回删除的数量
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	return p.repos.PurgeTrash(ctx, p.now().Add(-p.cfg.Retention), p.cfg.PurgeBatch)
+}
+
+func (p *Purger) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.cfg.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.Purge(context.Background()); err != nil {
+			p.logError("清理回收站失败: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *Purger) logError(msg string, args ...any) {
+	if p.log != nil {
+		p.log.Error(msg, args...)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/driver/mysql"
@@ -17,14 +18,6 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-var (
-	dbInstance *gorm.DB
-	initErr    error
-	dbOnce     sync.Once
-	dbMutex    sync.RWMutex // 用于保护连接池状态
-	closed     bool         // 标记数据库是否已关闭
-)
-
 type Config struct {
 	MaxOpenConns             int           `qwq-default:"100"`                                                                           // 最大打开连接数
 	MaxIdleConns             int           `qwq-default:"10"`                                                                            // 最大空闲连接数
@@ -40,7 +33,9 @@ type Config struct {
 	TablePrefix              string        `qwq-default:""`                                                                              // 表前缀
 	SingularTable            bool          // 单数表名
 	NoLowerCase              bool          // 关闭小写转换
-	HealthCheckInterval      time.Duration `qwq-default:"1m"` // 健康检查间隔
+	HealthCheckInterval      time.Duration `qwq-default:"1m"` // 健康检查间隔，同时用于检查只读副本
+	Replicas                 []string      // 只读副本 DSN，为空时读写都使用主库
+	StickyWindow             time.Duration `qwq-default:"5s"` // 写入后读取粘滞到主库的时长，见 WithStickyKey
 	// log
 	GormLogger logger.Interface //  GORM 日志接口
 	LogName    string           `qwq-default:""` // 日志名
@@ -61,44 +56,86 @@ func IsLogger(log interface{}) bool {
 	return ok
 }
 
-// InitDB 初始化数据库连接池（线程安全）
-func InitDB(cfg *Config) (*gorm.DB, error) {
-	dbOnce.Do(func() {
-		if err := validateConfig(cfg); err != nil {
-			initErr = err
-			return
-		}
+// Conn 数据库连接池，包含读写分离路由与连接监控协程，由 Open 创建，Close 关闭
+type Conn struct {
+	db       *gorm.DB
+	resolver *Resolver // 配置了只读副本时的读写分离路由
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Open 创建数据库连接池并启动连接监控，可以同时打开多个互不影响的连接
+func Open(cfg *Config) (*Conn, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
 
-		maxRetries := 3
-		var db *gorm.DB
-		//var sqlDB *sql.DB
-		var err error
+	maxRetries := 3
+	var db *gorm.DB
+	var err error
 
-		for i := 0; i <= maxRetries; i++ {
-			db, _, err = connectWithRetry(cfg)
-			if err == nil {
-				break
+	for i := 0; i <= maxRetries; i++ {
+		db, _, err = connectWithRetry(cfg)
+		if err == nil {
+			break
+		}
+
+		if i < maxRetries {
+			waitTime := time.Duration(i+1) * 2 * time.Second
+			if IsLogger(cfg.Logger) {
+				cfg.Logger.Error("数据库连接失败，正在重试... (尝试 %d/%d) 错误: %v", i+1, maxRetries, err)
 			}
+			time.Sleep(waitTime)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %w", err)
+	}
 
-			if i < maxRetries {
-				waitTime := time.Duration(i+1) * 2 * time.Second
-				if IsLogger(cfg.Logger) {
-					cfg.Logger.Error("数据库连接失败，正在重试... (尝试 %d/%d) 错误: %v", i+1, maxRetries, err)
-				}
-				time.Sleep(waitTime)
+	c := &Conn{db: db, done: make(chan struct{})}
+	if len(cfg.Replicas) > 0 {
+		c.resolver = NewResolver(cfg.Driver, cfg.Replicas, cfg.StickyWindow, func(replica *gorm.DB) {
+			configureConnectionPool(replica, cfg)
+		}, cfg.Logger)
+		if err := db.Use(c.resolver); err != nil {
+			c.resolver.Close()
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
 			}
+			return nil, fmt.Errorf("注册读写分离失败: %w", err)
 		}
+	}
+	go c.monitor(cfg)
+	return c, nil
+}
 
-		if err != nil {
-			initErr = fmt.Errorf("数据库连接失败: %w", err)
+// DB 主库连接，配置了只读副本时读取自动路由到副本
+func (c *Conn) DB() *gorm.DB {
+	return c.db
+}
+
+// Resolver 读写分离路由，未配置只读副本时为 nil
+func (c *Conn) Resolver() *Resolver {
+	return c.resolver
+}
+
+// Close 停止连接监控并关闭主库与只读副本的连接池，重复调用无副作用
+func (c *Conn) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.done)
+		if c.resolver != nil {
+			c.resolver.Close()
+		}
+		sqlDB, dbErr := c.db.DB()
+		if dbErr != nil {
+			err = fmt.Errorf("获取底层连接失败: %w", dbErr)
 			return
 		}
-
-		dbInstance = db
-		// 启动全局监控协程（确保只启动一次）
-		go monitorConnection(cfg)
+		err = sqlDB.Close()
 	})
-	return dbInstance, initErr
+	return err
 }
 
 // 移除context超时控制
@@ -107,9 +144,10 @@ func connectWithRetry(cfg *Config) (*gorm.DB, *sql.DB, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	SetLogLevel(cfg.LogLevel)
 
 	gormConfig := &gorm.Config{
-		Logger:                   newGormLogger(cfg.LogLevel),
+		Logger:                   gormLog,
 		PrepareStmt:              cfg.PrepareStmt,
 		DisableNestedTransaction: cfg.DisableNestedTransaction,
 		NowFunc: func() time.Time {
@@ -204,6 +242,46 @@ func newGormLogger(level string) logger.Interface {
 	)
 }
 
+// levelLogger 可在运行时切换级别的 GORM 日志，按级别替换内部的日志实例
+type levelLogger struct {
+	current atomic.Value // logger.Interface
+}
+
+var gormLog = func() *levelLogger {
+	l := &levelLogger{}
+	l.current.Store(newGormLogger("warn"))
+	return l
+}()
+
+// SetLogLevel 调整数据库日志级别（silent/error/warn/info/debug），对已建立的连接立即生效
+func SetLogLevel(level string) {
+	gormLog.current.Store(newGormLogger(level))
+}
+
+func (l *levelLogger) get() logger.Interface {
+	return l.current.Load().(logger.Interface)
+}
+
+func (l *levelLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return l.get().LogMode(level)
+}
+
+func (l *levelLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Info(ctx, msg, data...)
+}
+
+func (l *levelLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Warn(ctx, msg, data...)
+}
+
+func (l *levelLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Error(ctx, msg, data...)
+}
+
+func (l *levelLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.get().Trace(ctx, begin, fc, err)
+}
+
 func getLogLevel(level string) logger.LogLevel {
 	switch level {
 	case "silent":
@@ -219,8 +297,8 @@ func getLogLevel(level string) logger.LogLevel {
 	}
 }
 
-// 监控协程改为使用全局实例
-func monitorConnection(cfg *Config) {
+// monitor 定期检查主库与只读副本，主库连接断开后由 database/sql 在下次使用时重新建立
+func (c *Conn) monitor(cfg *Config) {
 	if cfg.HealthCheckInterval <= 0 {
 		if IsLogger(cfg.Logger) {
 			cfg.Logger.Error("无效的健康检查间隔，跳过连接监控")
@@ -228,185 +306,29 @@ func monitorConnection(cfg *Config) {
 		return
 	}
 	ticker := time.NewTicker(cfg.HealthCheckInterval)
-
 	defer ticker.Stop()
 
-	for range ticker.C {
-		dbMutex.RLock()
-		if closed {
-			dbMutex.RUnlock()
+	for {
+		select {
+		case <-c.done:
 			return
+		case <-ticker.C:
 		}
-		dbMutex.RUnlock()
 
-		// 从全局实例获取当前连接
-		db, err := GetDB()
-		if err != nil {
-			if IsLogger(cfg.Logger) {
-				cfg.Logger.Error(cfg.LogName, "获取数据库实例失败: %v", err)
-			}
-			continue
+		// 检查只读副本，剔除不可用的副本
+		if c.resolver != nil {
+			c.resolver.CheckReplicas()
 		}
 
-		sqlDB, err := db.DB()
+		sqlDB, err := c.db.DB()
 		if err != nil {
 			if IsLogger(cfg.Logger) {
-				cfg.Logger.Error(cfg.LogName, "获取底层连接失败: %v", err)
+				cfg.Logger.Error("获取底层连接失败: %v", err)
 			}
 			continue
 		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		err = sqlDB.PingContext(ctx)
-		cancel()
-
-		if err != nil {
-			if IsLogger(cfg.Logger) {
-				cfg.Logger.Error(cfg.LogName, "数据库连接异常: %v", err)
-			}
-			reconnect(cfg)
+		if err := ping(sqlDB); err != nil && IsLogger(cfg.Logger) {
+			cfg.Logger.Error("数据库连接异常: %v", err)
 		}
 	}
 }
-
-func monitorConnection2(sqlDB *sql.DB, cfg *Config) {
-	ticker := time.NewTicker(cfg.PingInterval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		dbMutex.RLock()
-		if closed {
-			dbMutex.RUnlock()
-			return
-		}
-		dbMutex.RUnlock()
-
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		err := sqlDB.PingContext(ctx)
-		cancel()
-
-		if err != nil {
-			if IsLogger(cfg.Logger) {
-				cfg.Logger.Error(cfg.LogName, "数据库连接异常: %v", err)
-			}
-			// 自动重连
-			reconnect(cfg)
-		}
-	}
-}
-
-// 重连逻辑
-func reconnect(cfg *Config) {
-	dbMutex.Lock()
-	defer dbMutex.Unlock()
-
-	// 仅关闭旧连接池不修改全局状态
-	if dbInstance != nil {
-		if sqlDB, err := dbInstance.DB(); err == nil {
-			sqlDB.Close() // 直接关闭连接池
-		}
-	}
-
-	// 重置初始化状态
-	dbOnce = sync.Once{}
-	// 保持 closed=false 状态
-
-	// 重新初始化
-	_, err := InitDB(cfg)
-	if err != nil && IsLogger(cfg.Logger) {
-		cfg.Logger.Error(cfg.LogName, "数据库重连失败: %v", err)
-	}
-}
-
-// GetDB 获取数据库实例 增加双重检查
-func GetDB() (*gorm.DB, error) {
-	dbMutex.RLock()
-	defer dbMutex.RUnlock()
-
-	if closed {
-		return nil, errors.New("数据库连接已关闭")
-	}
-	if dbInstance == nil {
-		return nil, errors.New("数据库未初始化")
-	}
-	return dbInstance, nil
-}
-
-// Close 关闭数据库连接 增加状态保护
-func Close() error {
-	dbMutex.Lock()
-	defer dbMutex.Unlock()
-
-	if closed || dbInstance == nil {
-		return nil
-	}
-
-	sqlDB, err := dbInstance.DB()
-	if err != nil {
-		return fmt.Errorf("获取底层连接失败: %w", err)
-	}
-
-	closed = true // 标记为已关闭
-	return sqlDB.Close()
-}
-
-// WithTransaction 执行事务操作（支持上下文）
-func WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
-	db, err := GetDB()
-	if err != nil {
-		return err
-	}
-
-	tx := db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			panic(r)
-		}
-	}()
-
-	if err := fn(tx); err != nil {
-		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return fmt.Errorf("回滚失败: %w (原错误: %v)", rollbackErr, err)
-		}
-		return err
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("提交事务失败: %w", err)
-	}
-
-	return nil
-}
-
-// AutoMigrate 自动迁移模型（支持上下文）
-func AutoMigrate(ctx context.Context, models ...interface{}) error {
-	db, err := GetDB()
-	if err != nil {
-		return err
-	}
-
-	return db.WithContext(ctx).AutoMigrate(models...)
-}
-
-// HealthCheck 数据库健康检查
-func HealthCheck() error {
-	db, err := GetDB()
-	if err != nil {
-		return err
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		return err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	return sqlDB.PingContext(ctx)
-}
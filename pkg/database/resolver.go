@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 读写分离
+//
+// Resolver 作为 GORM 插件注册在主库连接上：查询（Find、First、Count、Raw SELECT 等）
+// 轮询路由到健康的只读副本，写操作、事务、Connection 固定的连接与 SELECT ... FOR UPDATE 使用主库。
+// 上下文通过 WithStickyKey 带有粘滞标识（如用户ID）时，该标识写入后的 StickyWindow 内读取也使用主库，
+// 避免主从延迟导致读不到刚写入的数据；粘滞状态只保存在当前进程中。
+// 副本由连接监控协程定期 Ping，失败时剔除，恢复后重新加入；没有健康的副本时读取回落到主库。
+
+type ctxKey int
+
+const (
+	stickyCtxKey ctxKey = iota
+	primaryCtxKey
+)
+
+// WithStickyKey 为上下文设置读写粘滞标识，同一标识写入后的短时间内读取使用主库
+func WithStickyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, stickyCtxKey, key)
+}
+
+// UsePrimary 使该上下文中的读取始终使用主库
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryCtxKey, true)
+}
+
+// replica 一个只读副本
+type replica struct {
+	dsn     string
+	pool    *sql.DB // 连接失败时为 nil，健康检查时重试
+	healthy atomic.Bool
+}
+
+// ReplicaStatus 只读副本的状态
+type ReplicaStatus struct {
+	Index   int // 在配置中的序号，从 0 开始
+	Healthy bool
+}
+
+// Resolver 主库与只读副本之间的连接路由
+type Resolver struct {
+	driver string
+	window time.Duration
+	config func(db *gorm.DB) // 副本连接池配置
+	log    Logger
+
+	mu       sync.RWMutex
+	replicas []*replica
+	next     atomic.Uint64
+
+	stickyMu sync.Mutex
+	sticky   map[string]time.Time // 粘滞标识 -> 过期时间
+}
+
+// NewResolver 连接只读副本并创建路由，连接失败的副本标记为不健康，由 CheckReplicas 重试
+// stickyWindow 为写入后读取使用主库的时长，pool 用于配置副本连接池，可以为 nil
+func NewResolver(driver string, dsns []string, stickyWindow time.Duration, pool func(db *gorm.DB), log Logger) *Resolver {
+	r := &Resolver{driver: driver, window: stickyWindow, config: pool, log: log, sticky: map[string]time.Time{}}
+	for _, dsn := range dsns {
+		rep := &replica{dsn: dsn}
+		if err := r.connect(rep); err != nil {
+			r.logError("只读副本 %d 连接失败: %v", len(r.replicas), err)
+		} else {
+			rep.healthy.Store(true)
+		}
+		r.replicas = append(r.replicas, rep)
+	}
+	return r
+}
+
+// Name 插件名
+func (r *Resolver) Name() string {
+	return "qwq:resolver"
+}
+
+// Initialize 注册路由回调
+func (r *Resolver) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("qwq:resolver", r.routeRead); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("qwq:resolver", r.routeRead); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("qwq:resolver", r.markWrite); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("qwq:resolver", r.markWrite); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("qwq:resolver", r.markWrite); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("qwq:resolver", r.markWrite)
+}
+
+func (r *Resolver) logError(format string, args ...any) {
+	if IsLogger(r.log) {
+		r.log.Error(format, args...)
+	}
+}
+
+func (r *Resolver) logInfo(format string, args ...any) {
+	if IsLogger(r.log) {
+		r.log.Info(format, args...)
+	}
+}
+
+// connect 打开副本连接池并测试连接
+func (r *Resolver) connect(rep *replica) error {
+	dialector, err := createDialector(r.driver, rep.dsn)
+	if err != nil {
+		return err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: gormLog})
+	if err != nil {
+		return err
+	}
+	if r.config != nil {
+		r.config(db)
+	}
+	pool, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := ping(pool); err != nil {
+		pool.Close()
+		return err
+	}
+	r.mu.Lock()
+	rep.pool = pool
+	r.mu.Unlock()
+	return nil
+}
+
+func ping(pool *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return pool.PingContext(ctx)
+}
+
+// routeRead 读取路由到只读副本
+func (r *Resolver) routeRead(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || r.pinned(stmt.ConnPool) {
+		return
+	}
+	if _, ok := stmt.Clauses[clause.Locking{}.Name()]; ok {
+		return
+	}
+	// Raw 构造的语句只路由 SELECT
+	if stmt.SQL.Len() > 0 && !isReadSQL(stmt.SQL.String()) {
+		return
+	}
+	if r.usePrimary(stmt.Context) {
+		return
+	}
+	if pool := r.pick(); pool != nil {
+		stmt.ConnPool = pool
+	}
+}
+
+// markWrite 写入成功后记录粘滞标识
+func (r *Resolver) markWrite(db *gorm.DB) {
+	if db.Error != nil || r.window <= 0 || db.Statement.Context == nil {
+		return
+	}
+	if db.Statement.SQL.Len() > 0 && isReadSQL(db.Statement.SQL.String()) {
+		return
+	}
+	key, _ := db.Statement.Context.Value(stickyCtxKey).(string)
+	if key == "" {
+		return
+	}
+	r.stickyMu.Lock()
+	r.sticky[key] = time.Now().Add(r.window)
+	r.stickyMu.Unlock()
+}
+
+// pinned 事务或 Connection 固定的连接必须继续使用主库
+func (r *Resolver) pinned(pool gorm.ConnPool) bool {
+	switch pool.(type) {
+	case gorm.TxCommitter, *sql.Conn:
+		return true
+	}
+	return false
+}
+
+// usePrimary 上下文要求使用主库，或粘滞标识仍在窗口内
+func (r *Resolver) usePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	if v, _ := ctx.Value(primaryCtxKey).(bool); v {
+		return true
+	}
+	key, _ := ctx.Value(stickyCtxKey).(string)
+	if key == "" {
+		return false
+	}
+	r.stickyMu.Lock()
+	defer r.stickyMu.Unlock()
+	until, ok := r.sticky[key]
+	if ok && time.Now().After(until) {
+		delete(r.sticky, key)
+		return false
+	}
+	return ok
+}
+
+// pick 轮询选择健康的副本，没有时返回 nil
+func (r *Resolver) pick() *sql.DB {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := len(r.replicas)
+	start := int(r.next.Add(1))
+	for i := 0; i < n; i++ {
+		rep := r.replicas[(start+i)%n]
+		if rep.pool != nil && rep.healthy.Load() {
+			return rep.pool
+		}
+	}
+	return nil
+}
+
+// CheckReplicas 检查全部副本，剔除不可用的副本并恢复已可用的副本，同时清理过期的粘滞标识
+func (r *Resolver) CheckReplicas() {
+	r.mu.RLock()
+	replicas := append([]*replica(nil), r.replicas...)
+	r.mu.RUnlock()
+	for i, rep := range replicas {
+		r.mu.RLock()
+		pool := rep.pool
+		r.mu.RUnlock()
+
+		var err error
+		if pool == nil {
+			err = r.connect(rep)
+		} else {
+			err = ping(pool)
+		}
+		switch {
+		case err != nil && rep.healthy.Swap(false):
+			r.logError("只读副本 %d 不可用，已停止向其路由读取: %v", i, err)
+		case err == nil && !rep.healthy.Swap(true):
+			r.logInfo("只读副本 %d 已恢复", i)
+		}
+	}
+
+	now := time.Now()
+	r.stickyMu.Lock()
+	for key, until := range r.sticky {
+		if now.After(until) {
+			delete(r.sticky, key)
+		}
+	}
+	r.stickyMu.Unlock()
+}
+
+// Status 各副本的状态
+func (r *Resolver) Status() []ReplicaStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	status := make([]ReplicaStatus, len(r.replicas))
+	for i, rep := range r.replicas {
+		status[i] = ReplicaStatus{Index: i, Healthy: rep.pool != nil && rep.healthy.Load()}
+	}
+	return status
+}
+
+// Close 关闭全部副本连接
+func (r *Resolver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rep := range r.replicas {
+		if rep.pool != nil {
+			rep.pool.Close()
+			rep.pool = nil
+		}
+		rep.healthy.Store(false)
+	}
+}
+
+// isReadSQL 是否为不加锁的只读语句
+func isReadSQL(s string) bool {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.HasPrefix(s, "SELECT") && !strings.Contains(s, " FOR UPDATE") && !strings.Contains(s, " FOR SHARE")
+}
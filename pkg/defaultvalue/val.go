@@ -14,15 +14,26 @@ const (
 
 // SetDefaults 设置结构体的默认值（接受结构体指针）
 func SetDefaults(ptr interface{}) error {
+	return SetDefaultsWithTags(ptr, DefaultValTagName)
+}
+
+// SetDefaultsWithTags 按标签顺序查找默认值并设置，前面的标签优先，
+// 例如 SetDefaultsWithTags(&cfg, "qwq-default", "env-default")
+func SetDefaultsWithTags(ptr interface{}, tags ...string) error {
 	v := reflect.ValueOf(ptr)
 	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("input must be a pointer to struct")
 	}
-	return setDefaults(v.Elem(), true)
+	return setDefaults(v.Elem(), tags)
+}
+
+// SetField 将字符串解析为字段的类型并赋值，支持基本类型、time.Duration 及其指针
+func SetField(field reflect.Value, value string) error {
+	return setFieldValue(field, value)
 }
 
 // 递归设置默认值的核心函数
-func setDefaults(v reflect.Value, checkTag bool) error {
+func setDefaults(v reflect.Value, tags []string) error {
 	//t := v.Type()
 
 	// 如果是nil指针，则创建新实例
@@ -50,7 +61,13 @@ func setDefaults(v reflect.Value, checkTag bool) error {
 			continue
 		}
 
-		tagValue := fieldType.Tag.Get(DefaultValTagName)
+		tagValue := ""
+		for _, tag := range tags {
+			if tv, ok := fieldType.Tag.Lookup(tag); ok {
+				tagValue = tv
+				break
+			}
+		}
 		fieldKind := field.Kind()
 
 		// 处理嵌套结构体
@@ -68,7 +85,7 @@ func setDefaults(v reflect.Value, checkTag bool) error {
 				target = field.Elem()
 			}
 
-			if err := setDefaults(target, true); err != nil {
+			if err := setDefaults(target, tags); err != nil {
 				return err
 			}
 			continue
@@ -0,0 +1,382 @@
+// Package diff 文本差异比较
+//
+// 基于线性空间的 Myers O(ND) 算法，支持按行比较、行内按词比较以及输出统一格式（unified diff）。
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Op 差异操作
+type Op string
+
+// 差异操作类型
+const (
+	OpEqual  Op = " "
+	OpInsert Op = "+"
+	OpDelete Op = "-"
+)
+
+// edit 编辑脚本中的一步
+type edit struct {
+	op   Op
+	a, b int // 在旧、新序列中的下标（插入时 a 无意义，删除时 b 无意义）
+}
+
+// 超过以下大小的文本不逐行比较，见 TooLarge
+const (
+	MaxLines = 5000    // 单侧最大行数
+	MaxBytes = 1 << 20 // 单侧最大字节数
+)
+
+// maxWords 按词比较时单侧的最大词数，超过时整行视为删除后插入
+const maxWords = 5000
+
+// TooLarge 文本是否超过逐行比较的大小限制
+// 超过限制时 Hunks 与 Unified 不再逐行比较，把旧文本整体视为删除、新文本整体视为插入
+func TooLarge(a, b string) bool {
+	if len(a) > MaxBytes || len(b) > MaxBytes {
+		return true
+	}
+	return strings.Count(a, "\n") >= MaxLines || strings.Count(b, "\n") >= MaxLines
+}
+
+// compute 使用线性空间的 Myers 算法（middle snake 分治）计算把 a 变为 b 的最短编辑脚本
+// 任一序列长度超过 limit 时不做比较，返回整体删除后整体插入的脚本
+func compute[T comparable](a, b []T, limit int) []edit {
+	edits := make([]edit, 0, len(a)+len(b))
+	if len(a) > limit || len(b) > limit {
+		return replaceAll(edits, 0, len(a), 0, len(b))
+	}
+	size := (len(a)+len(b)+1)/2 + 2
+	s := &myers[T]{a: a, b: b, vf: make([]int, 2*size+1), vb: make([]int, 2*size+1), edits: edits}
+	s.walk(0, len(a), 0, len(b))
+	return s.edits
+}
+
+// replaceAll 把 a[a0:a1] 整体删除、b[b0:b1] 整体插入
+func replaceAll(edits []edit, a0, a1, b0, b1 int) []edit {
+	for i := a0; i < a1; i++ {
+		edits = append(edits, edit{op: OpDelete, a: i, b: b0})
+	}
+	for j := b0; j < b1; j++ {
+		edits = append(edits, edit{op: OpInsert, a: a1, b: j})
+	}
+	return edits
+}
+
+// myers 线性空间 Myers 算法的状态，vf、vb 为正向与反向搜索在各对角线上到达的最远位置，各层递归共用
+type myers[T comparable] struct {
+	a, b   []T
+	vf, vb []int
+	edits  []edit
+}
+
+// walk 计算 a[a0:a1] 到 b[b0:b1] 的编辑脚本：去掉公共前后缀后按 middle snake 一分为二递归
+func (s *myers[T]) walk(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && s.a[a0] == s.b[b0] {
+		s.edits = append(s.edits, edit{op: OpEqual, a: a0, b: b0})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a1 > a0 && b1 > b0 && s.a[a1-1] == s.b[b1-1] {
+		a1--
+		b1--
+		suffix++
+	}
+
+	if a0 == a1 || b0 == b1 {
+		s.edits = replaceAll(s.edits, a0, a1, b0, b1)
+	} else {
+		x, y, u, v := s.middleSnake(a0, a1, b0, b1)
+		s.walk(a0, x, b0, y)
+		for ; x < u; x, y = x+1, y+1 {
+			s.edits = append(s.edits, edit{op: OpEqual, a: x, b: y})
+		}
+		s.walk(u, a1, v, b1)
+	}
+
+	for i := 0; i < suffix; i++ {
+		s.edits = append(s.edits, edit{op: OpEqual, a: a1 + i, b: b1 + i})
+	}
+}
+
+// middleSnake 同时从两端搜索，返回最短编辑路径中间的一段对角线 (x,y)→(u,v)
+// 反向搜索在倒序的序列上进行，对角线 k 对应正向的 delta-k
+func (s *myers[T]) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta&1 != 0
+	half := (n + m + 1) / 2
+	off := half + 1
+	vf, vb := s.vf, s.vb
+	vf[off+1], vb[off+1] = 0, 0
+
+	for d := 0; d <= half; d++ {
+		for k := -d; k <= d; k += 2 {
+			var px int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				px = vf[off+k+1]
+			} else {
+				px = vf[off+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && s.a[a0+px] == s.b[b0+py] {
+				px++
+				py++
+			}
+			vf[off+k] = px
+			if rk := delta - k; odd && rk >= -(d-1) && rk <= d-1 && px+vb[off+rk] >= n {
+				return a0 + sx, b0 + sy, a0 + px, b0 + py
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var px int
+			if k == -d || (k != d && vb[off+k-1] < vb[off+k+1]) {
+				px = vb[off+k+1]
+			} else {
+				px = vb[off+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && s.a[a1-1-px] == s.b[b1-1-py] {
+				px++
+				py++
+			}
+			vb[off+k] = px
+			if fk := delta - k; !odd && fk >= -d && fk <= d && px+vf[off+fk] >= n {
+				return a1 - px, b1 - py, a1 - sx, b1 - sy
+			}
+		}
+	}
+	panic("diff: middle snake not found")
+}
+
+// Segment 行内差异片段
+type Segment struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Line 差异行
+type Line struct {
+	Op    Op        `json:"op"`
+	Text  string    `json:"text"`
+	Words []Segment `json:"words,omitempty"` // 行内按词差异，仅在按词比较时填充
+}
+
+// Hunk 差异块
+type Hunk struct {
+	OldStart int    `json:"old_start"`
+	OldLines int    `json:"old_lines"`
+	NewStart int    `json:"new_start"`
+	NewLines int    `json:"new_lines"`
+	Lines    []Line `json:"lines"`
+}
+
+// Header 差异块头部，如 "@@ -1,3 +1,4 @@"
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", rangeString(h.OldStart, h.OldLines), rangeString(h.NewStart, h.NewLines))
+}
+
+func rangeString(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	if lines == 0 {
+		// 空范围按惯例指向前一行
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// SplitLines 按行切分文本，统一换行符并去掉末尾换行
+func SplitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// Hunks 按行比较文本，context 为差异前后保留的上下文行数，words 为 true 时计算行内按词差异
+func Hunks(a, b string, context int, words bool) []Hunk {
+	al, bl := SplitLines(a), SplitLines(b)
+	limit := MaxLines
+	if TooLarge(a, b) {
+		// 超过大小限制时整体替换，也不计算行内差异
+		limit, words = 0, false
+	}
+	edits := compute(al, bl, limit)
+
+	var (
+		hunks   []Hunk
+		lastEnd int
+	)
+	for i := 0; i < len(edits); {
+		// 找到下一处改动
+		for i < len(edits) && edits[i].op == OpEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// 向前扩展上下文
+		start := max(i-context, lastEnd)
+
+		// 向后延伸：相邻改动间的相同行不超过 2*context 时合并为一个块
+		end := i
+		for end < len(edits) {
+			if edits[end].op != OpEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].op == OpEqual {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end = min(end+context, len(edits))
+				break
+			}
+			end = run
+		}
+
+		h := newHunk(edits[start:end], al, bl)
+		if words {
+			addWordDiff(h.Lines)
+		}
+		hunks = append(hunks, h)
+		lastEnd, i = end, end
+	}
+	return hunks
+}
+
+// newHunk 由连续的编辑步骤生成差异块
+func newHunk(edits []edit, al, bl []string) Hunk {
+	var h Hunk
+	first := edits[0]
+	h.OldStart = first.a + 1
+	h.NewStart = first.b + 1
+	for _, e := range edits {
+		switch e.op {
+		case OpEqual:
+			h.Lines = append(h.Lines, Line{Op: OpEqual, Text: al[e.a]})
+			h.OldLines++
+			h.NewLines++
+		case OpDelete:
+			h.Lines = append(h.Lines, Line{Op: OpDelete, Text: al[e.a]})
+			h.OldLines++
+		case OpInsert:
+			h.Lines = append(h.Lines, Line{Op: OpInsert, Text: bl[e.b]})
+			h.NewLines++
+		}
+	}
+	return h
+}
+
+// addWordDiff 把相邻的删除行与插入行逐行配对并计算按词差异
+func addWordDiff(lines []Line) {
+	for i := 0; i < len(lines); {
+		if lines[i].Op != OpDelete {
+			i++
+			continue
+		}
+		delStart := i
+		for i < len(lines) && lines[i].Op == OpDelete {
+			i++
+		}
+		insStart := i
+		for i < len(lines) && lines[i].Op == OpInsert {
+			i++
+		}
+		pairs := min(insStart-delStart, i-insStart)
+		for p := 0; p < pairs; p++ {
+			del, ins := &lines[delStart+p], &lines[insStart+p]
+			for _, seg := range Words(del.Text, ins.Text) {
+				if seg.Op != OpInsert {
+					del.Words = append(del.Words, seg)
+				}
+				if seg.Op != OpDelete {
+					ins.Words = append(ins.Words, seg)
+				}
+			}
+		}
+	}
+}
+
+// Words 按词比较两段文本，相邻的同类片段会合并
+// 英文与数字按单词切分，中日韩文字按单字切分，空白与标点各自成词
+func Words(a, b string) []Segment {
+	at, bt := splitWords(a), splitWords(b)
+	var segs []Segment
+	for _, e := range compute(at, bt, maxWords) {
+		text := ""
+		if e.op == OpInsert {
+			text = bt[e.b]
+		} else {
+			text = at[e.a]
+		}
+		if n := len(segs); n > 0 && segs[n-1].Op == e.op {
+			segs[n-1].Text += text
+			continue
+		}
+		segs = append(segs, Segment{Op: e.op, Text: text})
+	}
+	return segs
+}
+
+// splitWords 把文本切分为词
+func splitWords(s string) []string {
+	var words []string
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		n := size
+		if isWordRune(r) {
+			for n < len(s) {
+				r2, size2 := utf8.DecodeRuneInString(s[n:])
+				if !isWordRune(r2) {
+					break
+				}
+				n += size2
+			}
+		}
+		words = append(words, s[:n])
+		s = s[n:]
+	}
+	return words
+}
+
+// isWordRune 可组成单词的字符（中日韩文字按单字处理，不组成单词）
+func isWordRune(r rune) bool {
+	if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// Unified 输出统一格式的差异文本
+func Unified(oldName, newName, a, b string, context int) string {
+	hunks := Hunks(a, b, context, false)
+	if len(hunks) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("--- " + oldName + "\n")
+	sb.WriteString("+++ " + newName + "\n")
+	for _, h := range hunks {
+		sb.WriteString(h.Header() + "\n")
+		for _, l := range h.Lines {
+			sb.WriteString(string(l.Op) + l.Text + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package docsite
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IndexEntry 静态搜索索引中的一项（可见页面）
+type IndexEntry struct {
+	URL      string   `json:"url"`
+	Title    string   `json:"title"`
+	Headings []string `json:"headings"`
+	Text     string   `json:"text"`
+}
+
+// SearchIndex 导出可见页面的搜索索引，供静态站点在浏览器中检索
+func (s *Site) SearchIndex() []IndexEntry {
+	pages := s.Pages()
+	entries := make([]IndexEntry, 0, len(pages))
+	for _, p := range pages {
+		headings := make([]string, 0, len(p.TOC))
+		for _, h := range p.TOC {
+			headings = append(headings, h.Text)
+		}
+		entries = append(entries, IndexEntry{URL: p.URL, Title: p.Title, Headings: headings, Text: p.Text})
+	}
+	return entries
+}
+
+// sitemapURLSet sitemap.xml 根元素
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL sitemap.xml 中的一个页面
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// WriteSitemap 输出可见页面的 sitemap.xml，siteURL 为站点地址（如 https://example.com）
+func (s *Site) WriteSitemap(w io.Writer, siteURL string) error {
+	siteURL = strings.TrimRight(siteURL, "/")
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, p := range s.Pages() {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     siteURL + escapePath(p.URL),
+			LastMod: p.ModTime.UTC().Format(time.RFC3339),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		return fmt.Errorf("生成 sitemap 失败: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Assets 文档目录中的静态资源（非 Markdown 文件），返回相对文档根目录的路径（使用 /）
+func (s *Site) Assets() ([]string, error) {
+	var assets []string
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != s.root {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || strings.EqualFold(filepath.Ext(p), ".md") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		assets = append(assets, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("扫描静态资源失败: %w", err)
+	}
+	return assets, nil
+}
+
+// escapePath 对链接路径逐段转义
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
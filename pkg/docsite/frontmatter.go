@@ -0,0 +1,55 @@
+package docsite
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter 文档头部的 YAML 元数据，以单独一行的 --- 包裹：
+//
+//	---
+//	title: 安装指南
+//	order: 1
+//	hidden: false
+//	---
+type FrontMatter struct {
+	Title  string `yaml:"title"`  // 标题，为空时使用去掉数字前缀的文件名
+	Order  *int   `yaml:"order"`  // 排序，覆盖文件名中的数字前缀
+	Hidden bool   `yaml:"hidden"` // 隐藏：不出现在目录、搜索与上下篇中，但仍可通过链接访问
+}
+
+// parseFrontMatter 拆分头部元数据与正文，没有元数据时原样返回正文
+func parseFrontMatter(src []byte) (FrontMatter, []byte, error) {
+	var fm FrontMatter
+	src = bytes.TrimPrefix(src, []byte("\xef\xbb\xbf"))
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(src, []byte("---\n")) {
+		return fm, src, nil
+	}
+
+	rest := src[len("---\n"):]
+	var head, body []byte
+	switch {
+	case bytes.HasPrefix(rest, []byte("---\n")):
+		body = rest[len("---\n"):]
+	default:
+		end := bytes.Index(rest, []byte("\n---\n"))
+		if end < 0 {
+			if !bytes.HasSuffix(rest, []byte("\n---")) {
+				// 没有结束标记，视为普通的分隔线
+				return fm, src, nil
+			}
+			end = len(rest) - len("\n---")
+			head, body = rest[:end], nil
+		} else {
+			head, body = rest[:end], rest[end+len("\n---\n"):]
+		}
+	}
+
+	if err := yaml.Unmarshal(head, &fm); err != nil {
+		return fm, body, fmt.Errorf("解析文档元数据失败: %w", err)
+	}
+	return fm, body, nil
+}
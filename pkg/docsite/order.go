@@ -0,0 +1,65 @@
+package docsite
+
+import (
+	"strconv"
+	"strings"
+)
+
+// splitPrefix 拆分文件名中的数字前缀，返回前缀数字与去掉前缀后的标题
+//
+//	"1.1安装"          -> [1 1], "安装"
+//	"0_开发学习"       -> [0],   "开发学习"
+//	"0.1-Git_入门与使用" -> [0 1], "Git_入门与使用"
+//	"README"          -> nil,   "README"
+func splitPrefix(name string) ([]int, string) {
+	i := 0
+	for i < len(name) && (isDigit(name[i]) || name[i] == '.') {
+		i++
+	}
+	prefix := strings.TrimRight(name[:i], ".")
+	if prefix == "" {
+		return nil, name
+	}
+
+	var nums []int
+	for _, part := range strings.Split(prefix, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			// 如 "1..2"，不视为前缀
+			return nil, name
+		}
+		nums = append(nums, n)
+	}
+
+	title := strings.TrimLeft(name[i:], "_- ")
+	if title == "" {
+		// 文件名全是数字时保留原名作为标题
+		title = name
+	}
+	return nums, title
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// compareOrder 比较两个排序键，有序号的排在没有序号的前面
+func compareOrder(a, b []int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
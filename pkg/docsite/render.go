@@ -0,0 +1,149 @@
+package docsite
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Heading 页内目录项
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// rendered 单个文档的渲染结果
+type rendered struct {
+	HTML     string
+	TOC      []Heading
+	Text     string // 纯文本，用于搜索与摘要
+	Headings string // 所有标题文本，用于搜索加权
+}
+
+// render 渲染 Markdown，dir 为文档所在目录（相对文档根目录），用于把相对链接改写为站点链接
+func render(src []byte, dir string, opts Options) rendered {
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs)
+	doc := p.Parse(src)
+
+	var (
+		res      rendered
+		text     strings.Builder
+		headings []string
+		seen     = make(map[string]int)
+	)
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch n := node.(type) {
+		case *ast.Heading:
+			if !entering {
+				text.WriteString("\n")
+				return ast.GoToNext
+			}
+			title := plainText(n)
+			n.HeadingID = uniqueID(seen, n.HeadingID, len(res.TOC))
+			res.TOC = append(res.TOC, Heading{Level: n.Level, ID: n.HeadingID, Text: title})
+			headings = append(headings, title)
+		case *ast.Link:
+			if entering {
+				n.Destination = []byte(rewriteURL(string(n.Destination), dir, opts))
+			}
+		case *ast.Image:
+			if entering {
+				n.Destination = []byte(rewriteURL(string(n.Destination), dir, opts))
+			}
+		case *ast.HTMLBlock, *ast.HTMLSpan:
+			// 文档中直接书写的 HTML（如 <img src="logo.png">）
+			if entering {
+				leaf := node.AsLeaf()
+				leaf.Literal = rewriteHTMLURLs(leaf.Literal, dir, opts)
+			}
+		case *ast.Text, *ast.Code:
+			if entering {
+				text.Write(node.AsLeaf().Literal)
+			}
+		case *ast.CodeBlock:
+			if entering {
+				text.Write(n.Literal)
+				text.WriteString("\n")
+			}
+		case *ast.Paragraph, *ast.ListItem, *ast.TableCell:
+			if !entering {
+				text.WriteString("\n")
+			}
+		}
+		return ast.GoToNext
+	})
+
+	renderer := html.NewRenderer(html.RendererOptions{Flags: html.CommonFlags | html.HrefTargetBlank})
+	res.HTML = string(markdown.Render(doc, renderer))
+	res.Text = strings.TrimSpace(text.String())
+	res.Headings = strings.Join(headings, "\n")
+	return res
+}
+
+// plainText 提取节点内的纯文本
+func plainText(node ast.Node) string {
+	var sb strings.Builder
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if leaf := n.AsLeaf(); entering && leaf != nil {
+			switch n.(type) {
+			case *ast.Text, *ast.Code:
+				sb.Write(leaf.Literal)
+			}
+		}
+		return ast.GoToNext
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+// uniqueID 保证页内标题锚点唯一，标题无法生成锚点时使用序号
+func uniqueID(seen map[string]int, id string, index int) string {
+	if id == "" {
+		id = "section-" + strconv.Itoa(index+1)
+	}
+	n := seen[id]
+	seen[id] = n + 1
+	if n == 0 {
+		return id
+	}
+	return id + "-" + strconv.Itoa(n)
+}
+
+// htmlURLAttr 内嵌 HTML 中的链接属性
+var htmlURLAttr = regexp.MustCompile(`\b(src|href)="([^"]*)"`)
+
+// rewriteHTMLURLs 改写内嵌 HTML 中 src、href 属性的相对链接
+func rewriteHTMLURLs(literal []byte, dir string, opts Options) []byte {
+	return htmlURLAttr.ReplaceAllFunc(literal, func(m []byte) []byte {
+		sub := htmlURLAttr.FindSubmatch(m)
+		return []byte(string(sub[1]) + `="` + rewriteURL(string(sub[2]), dir, opts) + `"`)
+	})
+}
+
+// rewriteURL 把文档中的相对链接改写为站点链接：
+// 指向 .md 文件的链接改写为页面地址，其他相对路径（如图片）改写为以 BaseURL 开头的绝对路径
+func rewriteURL(raw, dir string, opts Options) string {
+	if raw == "" || strings.HasPrefix(raw, "#") || strings.HasPrefix(raw, "/") || strings.Contains(raw, ":") {
+		return raw
+	}
+
+	target, fragment := raw, ""
+	if i := strings.IndexByte(raw, '#'); i >= 0 {
+		target, fragment = raw[:i], raw[i:]
+	}
+	target = path.Join(dir, target)
+	if strings.HasPrefix(target, "..") {
+		// 指向文档目录之外，保持原样
+		return raw
+	}
+	if strings.EqualFold(path.Ext(target), ".md") {
+		return pageURL(opts, pagePath(target)) + fragment
+	}
+	return joinURL(opts.BaseURL, target) + fragment
+}
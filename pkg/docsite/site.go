@@ -0,0 +1,608 @@
+// Package docsite 文档站点
+//
+// 从一个 Markdown 目录构建文档站点：
+//   - 按文件名中的数字前缀（如 "1.1安装.md"、"0_开发学习/"）排序生成侧边栏目录树，
+//     目录下的 README.md 或 index.md 作为该目录的首页
+//   - 支持文件头部的 YAML 元数据（title、order、hidden）
+//   - 为每个页面生成页内目录、上一篇/下一篇链接
+//   - 基于 pkg/fulltext 的全文检索索引
+//
+// 站点在创建时构建一次，之后可调用 Watch 启动后台轮询，或在请求中调用 Refresh，
+// 在文件变化时重建。重建时未变化的文件复用上次的渲染结果。
+package docsite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"qwqserver/pkg/fulltext"
+)
+
+// Options 站点选项
+type Options struct {
+	BaseURL       string        // 站点链接前缀，默认 /docs，"/" 表示站点根目录
+	PageSuffix    string        // 页面链接后缀，静态导出时为 ".html"，此时首页链接为 BaseURL/index.html
+	CheckInterval time.Duration // Refresh 检查文件变化的最小间隔，默认 2 秒
+}
+
+// Crumb 面包屑导航项
+type Crumb struct {
+	Title string `json:"title"`
+	URL   string `json:"url"` // 为空表示没有对应页面的目录
+}
+
+// Page 文档页面
+type Page struct {
+	Path    string    `json:"path"`  // 页面路径：相对文档根目录、去掉 .md 后缀，目录首页为目录路径，站点首页为空
+	URL     string    `json:"url"`   // 页面链接
+	Title   string    `json:"title"` // 标题
+	Hidden  bool      `json:"hidden"`
+	File    string    `json:"file"` // 源文件，相对文档根目录
+	ModTime time.Time `json:"mod_time"`
+	HTML    string    `json:"-"`
+	TOC     []Heading `json:"toc"`
+	Text    string    `json:"-"` // 纯文本内容
+	Crumbs  []Crumb   `json:"crumbs"`
+	Prev    *Page     `json:"-"` // 上一篇，隐藏页面为 nil
+	Next    *Page     `json:"-"` // 下一篇，隐藏页面为 nil
+
+	id       uint64
+	headings string
+}
+
+// NavItem 侧边栏目录项
+type NavItem struct {
+	Title    string     `json:"title"`
+	URL      string     `json:"url,omitempty"` // 为空表示没有首页的目录
+	Path     string     `json:"path"`
+	Active   bool       `json:"active,omitempty"` // 当前页面
+	Open     bool       `json:"open,omitempty"`   // 包含当前页面的目录
+	Children []*NavItem `json:"children,omitempty"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Page    *Page   `json:"page"`
+	Title   string  `json:"title"`   // 高亮后的标题，已做 HTML 转义
+	Snippet string  `json:"snippet"` // 高亮后的摘要，已做 HTML 转义
+	Score   float64 `json:"score"`
+}
+
+// snapshot 某一时刻构建出的站点内容，构建后只读
+type snapshot struct {
+	pages       []*Page // 可见页面，按目录顺序
+	byPath      map[string]*Page
+	nav         []*NavItem
+	index       *fulltext.Index
+	byID        map[uint64]*Page
+	fingerprint string
+	warnings    []error
+	builtAt     time.Time
+	modTime     time.Time // 所有文档中最晚的修改时间
+	version     uint64
+}
+
+// Site 文档站点
+type Site struct {
+	root string
+	opts Options
+
+	current   atomic.Pointer[snapshot]
+	mu        sync.Mutex             // 串行化重建，保护 cache
+	cache     map[string]*cacheEntry // 渲染缓存，键为源文件相对路径
+	lastCheck atomic.Int64
+
+	watchMu  sync.Mutex
+	watching atomic.Bool
+	stop     chan struct{}
+	done     chan struct{}
+	subMu    sync.Mutex
+	subs     map[chan uint64]struct{}
+}
+
+// cacheEntry 渲染缓存项，文件大小与修改时间不变时复用
+type cacheEntry struct {
+	size    int64
+	modTime time.Time
+	fm      FrontMatter
+	fmErr   error
+	out     rendered
+}
+
+// New 从 root 目录构建文档站点
+func New(root string, opts Options) (*Site, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "/docs"
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = 2 * time.Second
+	}
+
+	s := &Site{root: root, opts: opts, cache: make(map[string]*cacheEntry), subs: make(map[chan uint64]struct{})}
+	s.current.Store(&snapshot{byPath: map[string]*Page{}, index: fulltext.NewIndex(), byID: map[uint64]*Page{}})
+	if err := s.Reload(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Root 文档根目录
+func (s *Site) Root() string {
+	return s.root
+}
+
+// BaseURL 站点链接前缀
+func (s *Site) BaseURL() string {
+	return s.opts.BaseURL
+}
+
+// HomeURL 首页链接
+func (s *Site) HomeURL() string {
+	return pageURL(s.opts, "")
+}
+
+// Reload 重新构建站点
+func (s *Site) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fp, err := fingerprint(s.root)
+	if err != nil {
+		return err
+	}
+	return s.rebuild(fp)
+}
+
+// Refresh 检查文档目录是否有变化，有变化时重建，返回是否重建
+// 两次检查的间隔不小于 CheckInterval，适合在每次请求时调用；已启动 Watch 时不做任何事
+func (s *Site) Refresh() (bool, error) {
+	if s.watching.Load() {
+		return false, nil
+	}
+	now := time.Now().UnixNano()
+	last := s.lastCheck.Load()
+	if now-last < int64(s.opts.CheckInterval) || !s.lastCheck.CompareAndSwap(last, now) {
+		return false, nil
+	}
+	return s.check()
+}
+
+// check 比较目录指纹，有变化时重建
+func (s *Site) check() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fp, err := fingerprint(s.root)
+	if err != nil {
+		return false, err
+	}
+	if fp == s.current.Load().fingerprint {
+		return false, nil
+	}
+	return true, s.rebuild(fp)
+}
+
+// BuiltAt 最近一次构建时间
+func (s *Site) BuiltAt() time.Time {
+	return s.current.Load().builtAt
+}
+
+// Version 站点版本号，每次重建加一
+func (s *Site) Version() uint64 {
+	return s.current.Load().version
+}
+
+// ETag 站点内容的实体标签，任一文档变化时改变
+func (s *Site) ETag() string {
+	fp := s.current.Load().fingerprint
+	if len(fp) > 20 {
+		fp = fp[:20]
+	}
+	return `"` + fp + `"`
+}
+
+// LastModified 所有文档中最晚的修改时间
+func (s *Site) LastModified() time.Time {
+	return s.current.Load().modTime
+}
+
+// Warnings 最近一次构建中出现的非致命问题（如元数据格式错误）
+func (s *Site) Warnings() []error {
+	return s.current.Load().warnings
+}
+
+// Page 按路径获取页面（含隐藏页面），路径可带 .md 后缀与首尾斜杠
+func (s *Site) Page(p string) *Page {
+	return s.current.Load().byPath[cleanPath(p)]
+}
+
+// Pages 可见页面，按目录顺序
+func (s *Site) Pages() []*Page {
+	return s.current.Load().pages
+}
+
+// AllPages 所有页面（含隐藏页面），按目录顺序，隐藏页面在最后
+func (s *Site) AllPages() []*Page {
+	snap := s.current.Load()
+	all := append([]*Page(nil), snap.pages...)
+	var hidden []*Page
+	for _, p := range snap.byPath {
+		if p.Hidden {
+			hidden = append(hidden, p)
+		}
+	}
+	sort.Slice(hidden, func(i, j int) bool { return hidden[i].Path < hidden[j].Path })
+	return append(all, hidden...)
+}
+
+// Nav 侧边栏目录树，active 为当前页面路径，用于标记当前项与展开的目录
+func (s *Site) Nav(active string) []*NavItem {
+	return copyNav(s.current.Load().nav, cleanPath(active))
+}
+
+func copyNav(items []*NavItem, active string) []*NavItem {
+	out := make([]*NavItem, 0, len(items))
+	for _, it := range items {
+		c := *it
+		c.Children = copyNav(it.Children, active)
+		c.Active = c.URL != "" && c.Path == active
+		c.Open = c.Active
+		for _, child := range c.Children {
+			if child.Active || child.Open {
+				c.Open = true
+			}
+		}
+		out = append(out, &c)
+	}
+	return out
+}
+
+// Search 全文检索可见页面，limit 小于等于0时返回全部结果
+func (s *Site) Search(q string, limit int) []SearchResult {
+	query := fulltext.ParseQuery(q)
+	if query.Empty() {
+		return nil
+	}
+	snap := s.current.Load()
+	hits := snap.index.Search(query, nil)
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	terms := query.AllTerms()
+	results := make([]SearchResult, 0, len(hits))
+	for _, h := range hits {
+		p := snap.byID[h.ID]
+		if p == nil {
+			continue
+		}
+		results = append(results, SearchResult{
+			Page:    p,
+			Title:   fulltext.Highlight(p.Title, terms, fulltext.HighlightOptions{}),
+			Snippet: fulltext.Highlight(p.Text, terms, fulltext.HighlightOptions{MaxLen: 160}),
+			Score:   h.Score,
+		})
+	}
+	return results
+}
+
+// node 构建过程中的目录树节点
+type node struct {
+	name     string
+	title    string
+	order    []int
+	dir      bool
+	page     *Page // 文件页面，或目录首页
+	children []*node
+}
+
+// rebuild 重新读取文档目录，调用方持有 s.mu
+func (s *Site) rebuild(fp string) error {
+	snap := &snapshot{
+		byPath:      make(map[string]*Page),
+		byID:        make(map[uint64]*Page),
+		index:       fulltext.NewIndex(),
+		fingerprint: fp,
+		builtAt:     time.Now(),
+		version:     s.current.Load().version + 1,
+	}
+	seen := make(map[string]bool)
+
+	rootNode, err := s.buildDir(snap, seen, "", "")
+	if err != nil {
+		return err
+	}
+
+	// 首页：根目录的 README，排在所有页面之前
+	if rootNode.page != nil && !rootNode.page.Hidden {
+		snap.pages = append(snap.pages, rootNode.page)
+	}
+	snap.nav = s.collect(snap, rootNode.children, nil)
+
+	// 上一篇/下一篇与搜索索引
+	for i, p := range snap.pages {
+		if i > 0 {
+			p.Prev = snap.pages[i-1]
+		}
+		if i < len(snap.pages)-1 {
+			p.Next = snap.pages[i+1]
+		}
+		p.id = uint64(i + 1)
+		snap.byID[p.id] = p
+		snap.index.Add(fulltext.Document{ID: p.id, Fields: []fulltext.Field{
+			{Name: "title", Text: p.Title, Boost: 3},
+			{Name: "headings", Text: p.headings, Boost: 2},
+			{Name: "content", Text: p.Text},
+		}})
+	}
+
+	for rel := range s.cache {
+		if !seen[rel] {
+			delete(s.cache, rel)
+		}
+	}
+	s.current.Store(snap)
+	s.notify(snap.version)
+	return nil
+}
+
+// collect 深度优先遍历目录树，按顺序收集可见页面并生成侧边栏
+// 目录首页隐藏时整个目录都不出现在侧边栏中
+func (s *Site) collect(snap *snapshot, nodes []*node, crumbs []Crumb) []*NavItem {
+	var items []*NavItem
+	for _, n := range nodes {
+		if n.page != nil && n.page.Hidden {
+			continue
+		}
+		item := &NavItem{Title: n.title}
+		if n.page != nil {
+			n.page.Crumbs = crumbs
+			item.URL, item.Path = n.page.URL, n.page.Path
+			snap.pages = append(snap.pages, n.page)
+		}
+		if n.dir {
+			crumb := Crumb{Title: n.title, URL: item.URL}
+			item.Children = s.collect(snap, n.children, append(append([]Crumb(nil), crumbs...), crumb))
+			if len(item.Children) == 0 && n.page == nil {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// buildDir 读取目录并构建目录树节点，rel 为相对文档根目录的路径（使用 /）
+func (s *Site) buildDir(snap *snapshot, seen map[string]bool, rel, name string) (*node, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, fmt.Errorf("读取文档目录失败: %w", err)
+	}
+
+	order, title := splitPrefix(name)
+	dir := &node{name: name, title: title, order: order, dir: true}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		childRel := path.Join(rel, e.Name())
+
+		if e.IsDir() {
+			child, err := s.buildDir(snap, seen, childRel, e.Name())
+			if err != nil {
+				return nil, err
+			}
+			if child.page != nil || len(child.children) > 0 {
+				dir.children = append(dir.children, child)
+			}
+			continue
+		}
+		if !strings.EqualFold(path.Ext(e.Name()), ".md") {
+			continue
+		}
+
+		seen[childRel] = true
+		page, fm, err := s.loadPage(snap, childRel)
+		if err != nil {
+			return nil, err
+		}
+		if isIndexFile(e.Name()) {
+			// 目录首页：元数据作用于整个目录
+			dir.page = page
+			if fm.Title != "" {
+				dir.title = fm.Title
+			}
+			if fm.Order != nil {
+				dir.order = []int{*fm.Order}
+			}
+			continue
+		}
+
+		base := strings.TrimSuffix(e.Name(), path.Ext(e.Name()))
+		n := &node{name: base, page: page}
+		n.order, n.title = splitPrefix(base)
+		if fm.Order != nil {
+			n.order = []int{*fm.Order}
+		}
+		dir.children = append(dir.children, n)
+	}
+
+	if dir.page != nil && dir.page.Title == "" {
+		dir.page.Title = dir.title
+	}
+	for _, child := range dir.children {
+		if child.page != nil && child.page.Title == "" {
+			child.page.Title = child.title
+		}
+	}
+	if dir.page != nil && name == "" && dir.page.Title == "" {
+		dir.page.Title = "文档首页"
+	}
+
+	sort.SliceStable(dir.children, func(i, j int) bool {
+		a, b := dir.children[i], dir.children[j]
+		if c := compareOrder(a.order, b.order); c != 0 {
+			return c < 0
+		}
+		return a.name < b.name
+	})
+	return dir, nil
+}
+
+// loadPage 读取并渲染页面（文件未变化时使用渲染缓存），标题为空表示由调用方使用文件名
+func (s *Site) loadPage(snap *snapshot, rel string) (*Page, FrontMatter, error) {
+	file := filepath.Join(s.root, filepath.FromSlash(rel))
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, FrontMatter{}, fmt.Errorf("读取文档失败: %w", err)
+	}
+
+	entry := s.cache[rel]
+	if entry == nil || entry.size != info.Size() || !entry.modTime.Equal(info.ModTime()) {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, FrontMatter{}, fmt.Errorf("读取文档失败: %w", err)
+		}
+		fm, body, fmErr := parseFrontMatter(src)
+		entry = &cacheEntry{
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			fm:      fm,
+			fmErr:   fmErr,
+			out:     render(body, path.Dir(rel), s.opts),
+		}
+		s.cache[rel] = entry
+	}
+	if entry.fmErr != nil {
+		snap.warnings = append(snap.warnings, fmt.Errorf("%s: %w", rel, entry.fmErr))
+	}
+	if info.ModTime().After(snap.modTime) {
+		snap.modTime = info.ModTime()
+	}
+	fm, out := entry.fm, entry.out
+
+	p := pagePath(rel)
+	page := &Page{
+		Path:     p,
+		URL:      pageURL(s.opts, p),
+		Title:    fm.Title,
+		Hidden:   fm.Hidden,
+		File:     rel,
+		ModTime:  info.ModTime(),
+		HTML:     out.HTML,
+		TOC:      out.TOC,
+		Text:     out.Text,
+		headings: out.Headings,
+	}
+	if exist, ok := snap.byPath[p]; ok {
+		// README.md 与 index.md 同时存在时保留先读到的
+		snap.warnings = append(snap.warnings, fmt.Errorf("%s: 与 %s 的页面路径重复", rel, exist.File))
+		return page, fm, nil
+	}
+	snap.byPath[p] = page
+	return page, fm, nil
+}
+
+// isIndexFile 是否为目录首页文件
+func isIndexFile(name string) bool {
+	base := strings.ToLower(strings.TrimSuffix(name, path.Ext(name)))
+	return base == "readme" || base == "index"
+}
+
+// pagePath 由源文件相对路径得到页面路径
+func pagePath(rel string) string {
+	rel = strings.TrimSuffix(rel, path.Ext(rel))
+	dir, base := path.Split(rel)
+	if isIndexFile(base) {
+		return strings.TrimSuffix(dir, "/")
+	}
+	return rel
+}
+
+// cleanPath 规范化请求的页面路径
+func cleanPath(p string) string {
+	p = strings.Trim(path.Clean("/"+p), "/")
+	if strings.EqualFold(path.Ext(p), ".md") {
+		p = pagePath(p)
+	}
+	return p
+}
+
+// pageURL 页面链接
+func pageURL(opts Options, p string) string {
+	if p == "" {
+		if opts.PageSuffix != "" {
+			return joinURL(opts.BaseURL, "index"+opts.PageSuffix)
+		}
+		if opts.BaseURL == "" {
+			return "/"
+		}
+		return opts.BaseURL
+	}
+	return joinURL(opts.BaseURL, p) + opts.PageSuffix
+}
+
+// joinURL 拼接链接
+func joinURL(baseURL, p string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(p, "/")
+}
+
+// fingerprint 计算文档目录的指纹（文件路径、大小与修改时间），用于检测变化
+func fingerprint(root string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != root {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".md") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		fmt.Fprintf(h, "%s|%d|%d\n", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("扫描文档目录失败: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+var (
+	defaultSite *Site
+	defaultMu   sync.RWMutex
+)
+
+// SetDefault 设置全局文档站点
+func SetDefault(s *Site) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultSite = s
+}
+
+// Default 获取全局文档站点，未设置时返回 nil
+func Default() *Site {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultSite
+}
@@ -0,0 +1,94 @@
+package docsite
+
+import (
+	"time"
+)
+
+// Watch 启动后台轮询：每隔 interval 检查一次文档目录，有变化时重建并通知订阅者
+// 重复调用无效，interval 小于等于0时使用 1 秒
+func (s *Site) Watch(interval time.Duration, onError func(error)) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	if s.watching.Load() {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.watching.Store(true)
+
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := s.check(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}(s.stop, s.done)
+}
+
+// Close 停止后台轮询，并关闭所有订阅
+func (s *Site) Close() {
+	s.watchMu.Lock()
+	if s.watching.Load() {
+		close(s.stop)
+		<-s.done
+		s.watching.Store(false)
+	}
+	s.watchMu.Unlock()
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		close(ch)
+		delete(s.subs, ch)
+	}
+}
+
+// Subscribe 订阅站点重建通知，通道中收到的是新的站点版本号
+// 处理不及时时只保留最新的一次通知；返回的函数用于取消订阅
+func (s *Site) Subscribe() (<-chan uint64, func()) {
+	ch := make(chan uint64, 1)
+	s.subMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	return ch, func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		if _, ok := s.subs[ch]; ok {
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// notify 通知所有订阅者
+func (s *Site) notify(version uint64) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- version:
+		default:
+			// 丢弃未读取的旧通知，保留最新的
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- version:
+			default:
+			}
+		}
+	}
+}
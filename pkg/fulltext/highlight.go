@@ -0,0 +1,118 @@
+package fulltext
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// HighlightOptions 高亮选项
+type HighlightOptions struct {
+	MaxLen int    // 摘要最大字符数，小于等于0时返回全文
+	Pre    string // 命中前缀，默认 <mark>
+	Post   string // 命中后缀，默认 </mark>
+}
+
+// span 原文中的字节区间
+type span struct{ start, end int }
+
+// Highlight 在文本中标记命中的词并截取命中最密集的片段
+// 返回值已做 HTML 转义，可直接嵌入页面
+func Highlight(text string, terms []string, opts HighlightOptions) string {
+	if opts.Pre == "" && opts.Post == "" {
+		opts.Pre, opts.Post = "<mark>", "</mark>"
+	}
+
+	set := make(map[string]struct{}, len(terms))
+	for _, t := range terms {
+		set[t] = struct{}{}
+	}
+
+	// 收集命中区间，二元组与单字会互相重叠，需要合并
+	var spans []span
+	for _, t := range IndexTokens(text) {
+		if _, ok := set[t.Term]; !ok {
+			continue
+		}
+		if n := len(spans); n > 0 && t.Start <= spans[n-1].end {
+			if t.End > spans[n-1].end {
+				spans[n-1].end = t.End
+			}
+			continue
+		}
+		spans = append(spans, span{t.Start, t.End})
+	}
+
+	start, end := 0, len(text)
+	if opts.MaxLen > 0 && utf8.RuneCountInString(text) > opts.MaxLen {
+		start, end = bestWindow(text, spans, opts.MaxLen)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	cursor := start
+	for _, s := range spans {
+		if s.end <= start || s.start >= end {
+			continue
+		}
+		s.start = max(s.start, start)
+		s.end = min(s.end, end)
+		b.WriteString(html.EscapeString(text[cursor:s.start]))
+		b.WriteString(opts.Pre)
+		b.WriteString(html.EscapeString(text[s.start:s.end]))
+		b.WriteString(opts.Post)
+		cursor = s.end
+	}
+	b.WriteString(html.EscapeString(text[cursor:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// bestWindow 选择包含命中最多的、长度为 maxLen 个字符的片段，片段前保留少量上下文
+func bestWindow(text string, spans []span, maxLen int) (int, int) {
+	// offsets[i] 为第 i 个字符的字节偏移
+	offsets := make([]int, 0, len(text))
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
+	runeAt := func(byteOff int) int {
+		lo, hi := 0, len(offsets)-1
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if offsets[mid] < byteOff {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		return lo
+	}
+
+	total := len(offsets) - 1
+	window := func(first int) (int, int) {
+		first = max(0, min(first, total-maxLen))
+		return first, first + maxLen
+	}
+
+	bestStart, bestCount := 0, -1
+	context := maxLen / 4
+	for _, s := range spans {
+		from, to := window(runeAt(s.start) - context)
+		count := 0
+		for _, o := range spans {
+			if r := runeAt(o.start); r >= from && runeAt(o.end) <= to {
+				count++
+			}
+		}
+		if count > bestCount {
+			bestStart, bestCount = from, count
+		}
+	}
+	from, to := window(bestStart)
+	return offsets[from], offsets[to]
+}
@@ -0,0 +1,268 @@
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field 文档字段，Boost 为字段权重（如标题高于正文），小于等于0时按1处理
+type Field struct {
+	Name  string
+	Text  string
+	Boost float64
+}
+
+// Document 待索引的文档
+type Document struct {
+	ID     uint64
+	Fields []Field
+}
+
+// Hit 搜索命中
+type Hit struct {
+	ID    uint64
+	Score float64
+}
+
+// occurrence 词在文档中的一次出现
+type occurrence struct {
+	field int
+	pos   int
+}
+
+// docEntry 已索引文档信息
+type docEntry struct {
+	terms  []string  // 文档包含的词（去重），删除时使用
+	length int       // 文档词数
+	boosts []float64 // 各字段权重
+}
+
+// Index 线程安全的内存倒排索引
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[uint64][]occurrence
+	docs     map[uint64]*docEntry
+	totalLen int
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[uint64][]occurrence),
+		docs:     make(map[uint64]*docEntry),
+	}
+}
+
+// Len 已索引的文档数
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Has 文档是否已索引
+func (idx *Index) Has(id uint64) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	_, ok := idx.docs[id]
+	return ok
+}
+
+// Add 添加或替换文档
+func (idx *Index) Add(doc Document) {
+	entry := &docEntry{boosts: make([]float64, len(doc.Fields))}
+	occs := make(map[string][]occurrence)
+	for f, field := range doc.Fields {
+		entry.boosts[f] = field.Boost
+		if entry.boosts[f] <= 0 {
+			entry.boosts[f] = 1
+		}
+		for _, t := range IndexTokens(field.Text) {
+			occs[t.Term] = append(occs[t.Term], occurrence{field: f, pos: t.Pos})
+			if !t.Extra {
+				entry.length++
+			}
+		}
+	}
+	entry.terms = make([]string, 0, len(occs))
+	for term := range occs {
+		entry.terms = append(entry.terms, term)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(doc.ID)
+	for term, list := range occs {
+		docs := idx.postings[term]
+		if docs == nil {
+			docs = make(map[uint64][]occurrence)
+			idx.postings[term] = docs
+		}
+		docs[doc.ID] = list
+	}
+	idx.docs[doc.ID] = entry
+	idx.totalLen += entry.length
+}
+
+// Remove 删除文档
+func (idx *Index) Remove(id uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id uint64) {
+	entry, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range entry.terms {
+		docs := idx.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLen -= entry.length
+	delete(idx.docs, id)
+}
+
+// Search 执行查询，filter 不为 nil 时只保留 filter 返回 true 的文档
+// 结果按 BM25 相关度降序排列，相关度相同时ID大的（较新的）在前
+func (idx *Index) Search(q Query, filter func(id uint64) bool) []Hit {
+	if q.Empty() {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var candidates map[uint64]struct{}
+	for _, clause := range q.Clauses {
+		matched := idx.matchClause(clause, candidates)
+		if len(matched) == 0 {
+			return nil
+		}
+		candidates = matched
+	}
+
+	avgLen := 1.0
+	if len(idx.docs) > 0 && idx.totalLen > 0 {
+		avgLen = float64(idx.totalLen) / float64(len(idx.docs))
+	}
+	terms := q.AllTerms()
+
+	hits := make([]Hit, 0, len(candidates))
+	for id := range candidates {
+		if filter != nil && !filter(id) {
+			continue
+		}
+		hits = append(hits, Hit{ID: id, Score: idx.score(id, terms, avgLen)})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+	return hits
+}
+
+// matchClause 获取命中子句的文档，within 不为 nil 时只在其中查找
+func (idx *Index) matchClause(clause Clause, within map[uint64]struct{}) map[uint64]struct{} {
+	if len(clause.Terms) == 0 {
+		return within
+	}
+
+	// 从最稀有的词开始求交集
+	lists := make([]map[uint64][]occurrence, len(clause.Terms))
+	rarest := 0
+	for i, term := range clause.Terms {
+		lists[i] = idx.postings[term]
+		if len(lists[i]) == 0 {
+			return nil
+		}
+		if len(lists[i]) < len(lists[rarest]) {
+			rarest = i
+		}
+	}
+
+	matched := make(map[uint64]struct{})
+	for id := range lists[rarest] {
+		if within != nil {
+			if _, ok := within[id]; !ok {
+				continue
+			}
+		}
+		all := true
+		for _, list := range lists {
+			if _, ok := list[id]; !ok {
+				all = false
+				break
+			}
+		}
+		if !all {
+			continue
+		}
+		if clause.Phrase && !isPhrase(lists, id) {
+			continue
+		}
+		matched[id] = struct{}{}
+	}
+	return matched
+}
+
+// isPhrase 检查各词是否在同一字段中依次相邻出现
+func isPhrase(lists []map[uint64][]occurrence, id uint64) bool {
+	next := make([]map[occurrence]struct{}, len(lists))
+	for i := 1; i < len(lists); i++ {
+		next[i] = make(map[occurrence]struct{}, len(lists[i][id]))
+		for _, o := range lists[i][id] {
+			next[i][o] = struct{}{}
+		}
+	}
+	for _, start := range lists[0][id] {
+		ok := true
+		for i := 1; i < len(lists); i++ {
+			if _, found := next[i][occurrence{field: start.field, pos: start.pos + i}]; !found {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// score 计算 BM25 相关度，词频按字段权重加权
+func (idx *Index) score(id uint64, terms []string, avgLen float64) float64 {
+	entry := idx.docs[id]
+	n := float64(len(idx.docs))
+	norm := 1 - bm25B + bm25B*float64(entry.length)/avgLen
+
+	var score float64
+	for _, term := range terms {
+		docs := idx.postings[term]
+		occs := docs[id]
+		if len(occs) == 0 {
+			continue
+		}
+		var tf float64
+		for _, o := range occs {
+			tf += entry.boosts[o.field]
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+	}
+	return score
+}
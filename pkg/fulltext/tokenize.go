@@ -0,0 +1,216 @@
+// Package fulltext 内存倒排索引与分词
+//
+// 分词规则：
+//   - 字母与数字连续片段作为一个词，统一转为小写
+//   - 中日韩文字连续片段按二元组（bigram）切分，单字片段保留单字
+//   - 其余字符（空白、标点等）作为分隔符
+//
+// 同一片段内相邻词的位置连续，因此短语查询只需检查位置是否相邻。
+// 建立索引时（IndexTokens）中日韩片段还会额外切出单字，单字与从该字开始的二元组位置相同，
+// 使单字查询（如“猫”）也能命中“我的猫咪”。
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token 分词结果
+type Token struct {
+	Term  string // 归一化后的词
+	Pos   int    // 词在文本中的序号
+	Start int    // 在原文中的起始字节偏移
+	End   int    // 在原文中的结束字节偏移
+	Extra bool   // 建立索引时额外切出的中日韩单字，不计入文档长度
+}
+
+// isCJK 是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// isWord 是否为字母或数字（不含中日韩文字）
+func isWord(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// Tokenize 对文本分词，用于解析查询
+func Tokenize(text string) []Token {
+	return tokenize(text, false)
+}
+
+// IndexTokens 对待索引的文本分词，在 Tokenize 的基础上为多字的中日韩片段额外切出单字
+// 同一起始位置的二元组在前、单字在后，结果按起始偏移排序
+func IndexTokens(text string) []Token {
+	return tokenize(text, true)
+}
+
+func tokenize(text string, unigrams bool) []Token {
+	var (
+		tokens []Token
+		pos    int
+	)
+
+	// cjk 记录当前中日韩片段中各字符的字节偏移
+	var cjk []int
+	flushCJK := func(end int) {
+		switch len(cjk) {
+		case 0:
+			return
+		case 1:
+			tokens = append(tokens, Token{Term: text[cjk[0]:end], Pos: pos, Start: cjk[0], End: end})
+			pos++
+		default:
+			next := func(i int) int {
+				if i+1 < len(cjk) {
+					return cjk[i+1]
+				}
+				return end
+			}
+			for i := range cjk {
+				if i+1 < len(cjk) {
+					stop := next(i + 1)
+					tokens = append(tokens, Token{Term: text[cjk[i]:stop], Pos: pos + i, Start: cjk[i], End: stop})
+				}
+				if unigrams {
+					stop := next(i)
+					tokens = append(tokens, Token{Term: text[cjk[i]:stop], Pos: pos + i, Start: cjk[i], End: stop, Extra: true})
+				}
+			}
+			pos += len(cjk) - 1
+		}
+		cjk = cjk[:0]
+	}
+
+	wordStart := -1
+	flushWord := func(end int) {
+		if wordStart < 0 {
+			return
+		}
+		tokens = append(tokens, Token{Term: strings.ToLower(text[wordStart:end]), Pos: pos, Start: wordStart, End: end})
+		pos++
+		wordStart = -1
+	}
+
+	for i, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord(i)
+			cjk = append(cjk, i)
+		case isWord(r):
+			flushCJK(i)
+			if wordStart < 0 {
+				wordStart = i
+			}
+		default:
+			flushWord(i)
+			flushCJK(i)
+		}
+	}
+	flushWord(len(text))
+	flushCJK(len(text))
+	return tokens
+}
+
+// Terms 对文本分词并只返回词
+func Terms(text string) []string {
+	tokens := Tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, t := range tokens {
+		terms[i] = t.Term
+	}
+	return terms
+}
+
+// Clause 查询子句，Terms 为子句分词结果，Phrase 为 true 时要求词在文档中相邻出现
+type Clause struct {
+	Terms  []string
+	Phrase bool
+}
+
+// Query 解析后的查询，所有子句均需命中
+type Query struct {
+	Clauses []Clause
+}
+
+// Empty 查询是否为空
+func (q Query) Empty() bool {
+	return len(q.Clauses) == 0
+}
+
+// AllTerms 获取查询中出现的全部词（去重），用于高亮
+func (q Query) AllTerms() []string {
+	seen := make(map[string]struct{})
+	var terms []string
+	for _, c := range q.Clauses {
+		for _, t := range c.Terms {
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// ParseQuery 解析查询字符串
+// 双引号包裹的部分为短语查询，其余按空白切分为普通子句。
+// 中日韩文字组成的普通子句同样要求二元组相邻，以免“数据库”命中只含“数据”和“据库”的文档。
+func ParseQuery(s string) Query {
+	var q Query
+	add := func(text string, phrase bool) {
+		tokens := Tokenize(text)
+		if len(tokens) == 0 {
+			return
+		}
+		if !phrase && len(tokens) > 1 {
+			// 普通子句内不同片段（如 "go语言"）各自独立，只对中日韩片段要求相邻
+			phrase = allCJK(text)
+		}
+		terms := make([]string, len(tokens))
+		for i, t := range tokens {
+			terms[i] = t.Term
+		}
+		q.Clauses = append(q.Clauses, Clause{Terms: terms, Phrase: phrase})
+	}
+
+	for {
+		open := strings.IndexByte(s, '"')
+		if open < 0 {
+			break
+		}
+		for _, f := range strings.Fields(s[:open]) {
+			add(f, false)
+		}
+		rest := s[open+1:]
+		end := strings.IndexByte(rest, '"')
+		if end < 0 {
+			// 引号未闭合，按普通文本处理
+			s = rest
+			break
+		}
+		add(rest[:end], true)
+		s = rest[end+1:]
+	}
+	for _, f := range strings.Fields(s) {
+		add(f, false)
+	}
+	return q
+}
+
+// allCJK 文本中的字母数字是否全部为中日韩文字
+func allCJK(s string) bool {
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if isWord(r) {
+			return false
+		}
+		s = s[size:]
+	}
+	return true
+}
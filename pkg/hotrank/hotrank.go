@@ -0,0 +1,134 @@
+// Package hotrank 热度排序算法
+//
+// 采用 Hacker News 式的时间衰减：
+//
+//	score = points / (ageHours + AgeOffset) ^ Gravity
+//	points = LikeWeight*likes + CommentWeight*comments + ViewWeight*views + BookmarkWeight*bookmarks
+//
+// Gravity 越大，旧内容的热度衰减越快。
+package hotrank
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Params 热度计算参数
+type Params struct {
+	LikeWeight     float64 // 点赞权重
+	CommentWeight  float64 // 评论权重
+	ViewWeight     float64 // 浏览权重
+	BookmarkWeight float64 // 收藏权重
+	Gravity        float64 // 时间衰减指数
+	AgeOffset      float64 // 年龄偏移（小时），避免新内容分母过小
+}
+
+// DefaultParams 默认热度计算参数
+var DefaultParams = Params{
+	LikeWeight:     1,
+	CommentWeight:  2,
+	ViewWeight:     0.05,
+	BookmarkWeight: 1.5,
+	Gravity:        1.8,
+	AgeOffset:      2,
+}
+
+// Counts 参与热度计算的计数
+type Counts struct {
+	Likes     int64
+	Comments  int64
+	Views     int64
+	Bookmarks int64
+}
+
+var (
+	current   = DefaultParams
+	currentMu sync.RWMutex
+)
+
+// SetDefault 设置全局热度参数，未设置的字段保持默认值
+func SetDefault(p Params) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = p.withDefaults()
+}
+
+// Default 获取全局热度参数
+func Default() Params {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
+
+// withDefaults 使用默认值补齐非法参数
+func (p Params) withDefaults() Params {
+	if p.LikeWeight < 0 {
+		p.LikeWeight = DefaultParams.LikeWeight
+	}
+	if p.CommentWeight < 0 {
+		p.CommentWeight = DefaultParams.CommentWeight
+	}
+	if p.ViewWeight < 0 {
+		p.ViewWeight = DefaultParams.ViewWeight
+	}
+	if p.BookmarkWeight < 0 {
+		p.BookmarkWeight = DefaultParams.BookmarkWeight
+	}
+	if p.Gravity <= 0 {
+		p.Gravity = DefaultParams.Gravity
+	}
+	if p.AgeOffset <= 0 {
+		p.AgeOffset = DefaultParams.AgeOffset
+	}
+	return p
+}
+
+// Points 计算互动得分（不含时间衰减）
+func (p Params) Points(c Counts) float64 {
+	return p.LikeWeight*float64(c.Likes) +
+		p.CommentWeight*float64(c.Comments) +
+		p.ViewWeight*float64(c.Views) +
+		p.BookmarkWeight*float64(c.Bookmarks)
+}
+
+// Weight 获取单项计数的权重，kind 取值 like/comment/view/bookmark
+func (p Params) Weight(kind string) float64 {
+	switch kind {
+	case KindLike:
+		return p.LikeWeight
+	case KindComment:
+		return p.CommentWeight
+	case KindView:
+		return p.ViewWeight
+	case KindBookmark:
+		return p.BookmarkWeight
+	default:
+		return 0
+	}
+}
+
+// 计数类型
+const (
+	KindLike     = "like"
+	KindComment  = "comment"
+	KindView     = "view"
+	KindBookmark = "bookmark"
+)
+
+// Decay 根据互动得分和发布时间计算衰减后的热度
+func (p Params) Decay(points float64, createdAt, now time.Time) float64 {
+	if points <= 0 {
+		return 0
+	}
+	age := now.Sub(createdAt).Hours()
+	if age < 0 {
+		age = 0
+	}
+	return points / math.Pow(age+p.AgeOffset, p.Gravity)
+}
+
+// Score 计算热度
+func (p Params) Score(c Counts, createdAt, now time.Time) float64 {
+	return p.Decay(p.Points(c), createdAt, now)
+}
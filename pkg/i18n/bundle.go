@@ -0,0 +1,257 @@
+// Package i18n 消息目录与语言协商
+//
+// 每种语言一个 YAML 目录文件，文件名即语言标签（如 zh-CN.yaml、en-US.yaml）。
+// 嵌套的键以 . 连接为消息键，消息中的 {name} 由参数替换；
+// 只包含复数分类（zero/one/two/few/many/other）的节点视为复数消息，按参数 count 选择：
+//
+//	post:
+//	  created: 创建文章成功
+//	search:
+//	  found:
+//	    one: "Found {count} post"
+//	    other: "Found {count} posts"
+package i18n
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Args 消息参数
+type Args map[string]any
+
+// CountArg 复数消息按该参数选择形式
+const CountArg = "count"
+
+// Message 一条消息，非复数消息只有 Other
+type Message struct {
+	Zero  string `yaml:"zero"`
+	One   string `yaml:"one"`
+	Two   string `yaml:"two"`
+	Few   string `yaml:"few"`
+	Many  string `yaml:"many"`
+	Other string `yaml:"other"`
+}
+
+// form 指定复数分类的形式，缺失时使用 Other
+func (m Message) form(category string) string {
+	var s string
+	switch category {
+	case "zero":
+		s = m.Zero
+	case "one":
+		s = m.One
+	case "two":
+		s = m.Two
+	case "few":
+		s = m.Few
+	case "many":
+		s = m.Many
+	}
+	if s == "" {
+		return m.Other
+	}
+	return s
+}
+
+// Bundle 各语言的消息目录，并发安全
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback string
+	catalogs map[string]map[string]Message // 语言 → 消息键 → 消息
+}
+
+// NewBundle 创建消息目录，fallback 为缺少消息或无法协商时使用的语言
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{fallback: fallback, catalogs: map[string]map[string]Message{}}
+}
+
+// Fallback 回退语言
+func (b *Bundle) Fallback() string {
+	return b.fallback
+}
+
+// Add 添加消息，已有的同名消息被覆盖
+func (b *Bundle) Add(lang string, messages map[string]Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	catalog := b.catalogs[lang]
+	if catalog == nil {
+		catalog = make(map[string]Message, len(messages))
+		b.catalogs[lang] = catalog
+	}
+	for k, m := range messages {
+		catalog[k] = m
+	}
+}
+
+// Parse 解析 YAML 目录并添加到指定语言
+func (b *Bundle) Parse(lang string, data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	messages := map[string]Message{}
+	if len(root.Content) > 0 {
+		if err := flatten("", root.Content[0], messages); err != nil {
+			return err
+		}
+	}
+	b.Add(lang, messages)
+	return nil
+}
+
+// LoadFS 加载目录 dir 下的全部 *.yaml / *.yml 目录文件，文件名（去掉扩展名）为语言标签
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := b.Parse(strings.TrimSuffix(e.Name(), ext), data); err != nil {
+			return fmt.Errorf("%s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// LoadDir 加载本地目录下的目录文件
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// Languages 已加载的语言，按标签排序
+func (b *Bundle) Languages() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	langs := make([]string, 0, len(b.catalogs))
+	for lang := range b.catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Keys 指定语言的全部消息键，按键排序
+func (b *Bundle) Keys(lang string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	keys := make([]string, 0, len(b.catalogs[lang]))
+	for k := range b.catalogs[lang] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Has 指定语言是否有该消息（不回退）
+func (b *Bundle) Has(lang, key string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.catalogs[lang][key]
+	return ok
+}
+
+// Lookup 查找消息，指定语言缺少时回退到 fallback 语言
+func (b *Bundle) Lookup(lang, key string) (Message, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if m, ok := b.catalogs[lang][key]; ok {
+		return m, true
+	}
+	m, ok := b.catalogs[b.fallback][key]
+	return m, ok
+}
+
+// T 翻译消息：按 count 参数选择复数形式并替换参数；找不到消息时返回键本身
+func (b *Bundle) T(lang, key string, args Args) string {
+	m, ok := b.Lookup(lang, key)
+	if !ok {
+		return Interpolate(key, args)
+	}
+	msg := m.Other
+	if n, ok := count(args); ok {
+		// zero 形式对所有语言生效，用于“暂无结果”这类特殊表达
+		if n == 0 && m.Zero != "" {
+			msg = m.Zero
+		} else {
+			msg = m.form(PluralCategory(lang, n))
+		}
+	}
+	return Interpolate(msg, args)
+}
+
+// Interpolate 替换消息中的 {name} 参数，未提供的参数保持原样
+func Interpolate(msg string, args Args) string {
+	if len(args) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(args)*2)
+	for k, v := range args {
+		pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// pluralKeys 复数分类
+var pluralKeys = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true}
+
+// flatten 把嵌套的映射展开为 a.b.c 形式的消息键
+func flatten(prefix string, node *yaml.Node, out map[string]Message) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if prefix == "" {
+			return fmt.Errorf("line %d: 目录根节点必须是映射", node.Line)
+		}
+		out[prefix] = Message{Other: node.Value}
+		return nil
+	case yaml.MappingNode:
+		if prefix != "" && isPlural(node) {
+			var m Message
+			if err := node.Decode(&m); err != nil {
+				return err
+			}
+			out[prefix] = m
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flatten(key, node.Content[i+1], out); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("line %d: %s 的值必须是字符串或映射", node.Line, prefix)
+}
+
+// isPlural 映射的键全部是复数分类且包含 other
+func isPlural(node *yaml.Node) bool {
+	hasOther := false
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k := node.Content[i].Value
+		if !pluralKeys[k] || node.Content[i+1].Kind != yaml.ScalarNode {
+			return false
+		}
+		hasOther = hasOther || k == "other"
+	}
+	return hasOther
+}
@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage 解析 Accept-Language，按权重从高到低返回语言标签，忽略 q=0 的语言
+//
+//	"en-GB,en;q=0.9,zh-CN;q=0.8" → [en-GB en zh-CN]
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.TrimSpace(k) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.tag
+	}
+	return out
+}
+
+// Match 按偏好顺序选择已加载的语言
+//   - 标签完全匹配（不区分大小写，_ 视同 -）优先，如 en-us → en-US
+//   - 其次按主语言匹配，如 en-GB、en → en-US，zh-TW、zh-Hans → zh-CN
+//   - * 或没有可用语言时返回回退语言
+func (b *Bundle) Match(prefs ...string) string {
+	langs := b.Languages()
+	for _, pref := range prefs {
+		pref = normalize(pref)
+		if pref == "" {
+			continue
+		}
+		if pref == "*" {
+			return b.fallback
+		}
+		for _, lang := range langs {
+			if strings.EqualFold(pref, lang) {
+				return lang
+			}
+		}
+		base := primary(pref)
+		// 同一主语言有多个目录时优先回退语言，其次按标签顺序
+		if strings.EqualFold(primary(b.fallback), base) {
+			return b.fallback
+		}
+		for _, lang := range langs {
+			if strings.EqualFold(primary(lang), base) {
+				return lang
+			}
+		}
+	}
+	return b.fallback
+}
+
+// Negotiate 依次按用户偏好（可为空）与 Accept-Language 协商语言
+func (b *Bundle) Negotiate(preference, acceptLanguage string) string {
+	prefs := ParseAcceptLanguage(acceptLanguage)
+	if preference != "" {
+		prefs = append([]string{preference}, prefs...)
+	}
+	return b.Match(prefs...)
+}
+
+// Canonical 已加载语言的规范标签（标签完全匹配，不区分大小写），如 en_us → en-US
+func (b *Bundle) Canonical(lang string) (string, bool) {
+	for _, l := range b.Languages() {
+		if strings.EqualFold(l, normalize(lang)) {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+func normalize(tag string) string {
+	return strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+}
+
+// primary 主语言子标签：zh-CN → zh
+func primary(tag string) string {
+	base, _, _ := strings.Cut(normalize(tag), "-")
+	return base
+}
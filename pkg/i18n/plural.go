@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"math"
+	"strings"
+)
+
+// 没有单复数区分的语言
+var noPlural = map[string]bool{"zh": true, "ja": true, "ko": true, "vi": true, "th": true, "id": true, "ms": true}
+
+// PluralCategory 数量 n 在指定语言下的复数分类（CLDR 规则的常用子集）
+//   - 中文、日文等：other
+//   - 法语、葡萄牙语：0 与 1 为 one
+//   - 俄语、乌克兰语：one / few / many
+//   - 其他语言（英语等）：1 为 one
+func PluralCategory(lang string, n float64) string {
+	base := strings.ToLower(primary(lang))
+	if noPlural[base] {
+		return "other"
+	}
+	integer := n == math.Trunc(n)
+	i := int64(math.Abs(n))
+	switch base {
+	case "fr", "pt":
+		if i == 0 || i == 1 {
+			return "one"
+		}
+	case "ru", "uk":
+		if !integer {
+			return "other"
+		}
+		switch {
+		case i%10 == 1 && i%100 != 11:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if integer && i == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// count 参数中的数量
+func count(args Args) (float64, bool) {
+	switch v := args[CountArg].(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,50 @@
+package markup
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// markdownToHTML 把 Markdown 转换为未清洗的 HTML，标题锚点由后处理统一生成
+func markdownToHTML(content string) string {
+	p := parser.NewWithExtensions(parser.CommonExtensions)
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{Flags: mdhtml.CommonFlags})
+	return string(markdown.ToHTML([]byte(content), p, renderer))
+}
+
+// urlPattern 纯文本中的网址
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'，。；！？）】]+`)
+
+// plainToHTML 把纯文本转换为 HTML：空行分段，单个换行转为 <br>，网址自动转为链接
+func plainToHTML(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, para := range strings.Split(content, "\n\n") {
+		para = strings.Trim(para, "\n")
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		for i, line := range strings.Split(para, "\n") {
+			if i > 0 {
+				b.WriteString("<br>\n")
+			}
+			last := 0
+			for _, loc := range urlPattern.FindAllStringIndex(line, -1) {
+				b.WriteString(html.EscapeString(line[last:loc[0]]))
+				u := html.EscapeString(line[loc[0]:loc[1]])
+				b.WriteString(`<a href="` + u + `">` + u + `</a>`)
+				last = loc[1]
+			}
+			b.WriteString(html.EscapeString(line[last:]))
+		}
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
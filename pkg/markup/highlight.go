@@ -0,0 +1,222 @@
+package markup
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 高亮输出的 CSS 类名
+const (
+	classKeyword = "hl-kw"
+	classString  = "hl-str"
+	classComment = "hl-com"
+	classNumber  = "hl-num"
+)
+
+// lexer 语言的词法规则
+type lexer struct {
+	keywords     map[string]bool
+	lineComments []string  // 行注释前缀
+	blockComment [2]string // 块注释起止，为空表示不支持
+	quotes       string    // 字符串定界符
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	cStyle = [2]string{"/*", "*/"}
+
+	lexerGo = &lexer{
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var
+			true false nil iota any error string int int8 int16 int32 int64 uint uint8 uint16 uint32 uint64
+			uintptr byte rune bool float32 float64 complex64 complex128`),
+		lineComments: []string{"//"},
+		blockComment: cStyle,
+		quotes:       "\"'`",
+	}
+	lexerJS = &lexer{
+		keywords: words(`async await break case catch class const continue debugger default delete do else
+			export extends finally for from function if import in instanceof let new of return static super
+			switch this throw try typeof var void while with yield true false null undefined
+			interface type enum implements private protected public readonly`),
+		lineComments: []string{"//"},
+		blockComment: cStyle,
+		quotes:       "\"'`",
+	}
+	lexerPython = &lexer{
+		keywords: words(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda nonlocal not or pass raise return try while with yield
+			True False None self`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	lexerJava = &lexer{
+		keywords: words(`abstract boolean break byte case catch char class const continue default do double
+			else enum extends final finally float for if implements import instanceof int interface long
+			native new package private protected public return short static super switch synchronized this
+			throw throws try void volatile while true false null var val fun when object`),
+		lineComments: []string{"//"},
+		blockComment: cStyle,
+		quotes:       "\"'",
+	}
+	lexerC = &lexer{
+		keywords: words(`auto break case char const continue default do double else enum extern float for
+			goto if inline int long register return short signed sizeof static struct switch typedef union
+			unsigned void volatile while class namespace template typename public private protected virtual
+			new delete this true false nullptr using include define`),
+		lineComments: []string{"//"},
+		blockComment: cStyle,
+		quotes:       "\"'",
+	}
+	lexerRust = &lexer{
+		keywords: words(`as async await break const continue crate else enum extern false fn for if impl in
+			let loop match mod move mut pub ref return self Self static struct super trait true type unsafe
+			use where while dyn Some None Ok Err`),
+		lineComments: []string{"//"},
+		blockComment: cStyle,
+		quotes:       "\"",
+	}
+	lexerSQL = &lexer{
+		keywords: words(`select from where and or not insert into values update set delete create table
+			drop alter add index primary key foreign references join left right inner outer on group by
+			order having limit offset as distinct union all null is in like between case when then else end
+			SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER ADD
+			INDEX PRIMARY KEY FOREIGN REFERENCES JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT
+			OFFSET AS DISTINCT UNION ALL NULL IS IN LIKE BETWEEN CASE WHEN THEN ELSE END`),
+		lineComments: []string{"--"},
+		blockComment: cStyle,
+		quotes:       "'\"",
+	}
+	lexerShell = &lexer{
+		keywords: words(`if then else elif fi for while until do done case esac in function return export
+			local readonly echo exit set unset source`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	lexerJSON = &lexer{
+		keywords: words(`true false null`),
+		quotes:   "\"",
+	}
+	lexerYAML = &lexer{
+		keywords:     words(`true false null yes no on off`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+)
+
+// lexers 语言名（含别名）到词法规则的映射
+var lexers = map[string]*lexer{
+	"go": lexerGo, "golang": lexerGo,
+	"js": lexerJS, "javascript": lexerJS, "ts": lexerJS, "typescript": lexerJS, "jsx": lexerJS, "tsx": lexerJS,
+	"py": lexerPython, "python": lexerPython,
+	"java": lexerJava, "kotlin": lexerJava, "kt": lexerJava,
+	"c": lexerC, "cpp": lexerC, "c++": lexerC, "h": lexerC, "hpp": lexerC,
+	"rust": lexerRust, "rs": lexerRust,
+	"sql": lexerSQL, "mysql": lexerSQL, "postgresql": lexerSQL,
+	"sh": lexerShell, "bash": lexerShell, "shell": lexerShell, "zsh": lexerShell,
+	"json": lexerJSON,
+	"yaml": lexerYAML, "yml": lexerYAML,
+}
+
+// Highlight 对代码做语法高亮，返回转义后的 HTML，未知语言只做转义
+func Highlight(lang, code string) string {
+	lx := lexers[strings.ToLower(lang)]
+	if lx == nil {
+		return html.EscapeString(code)
+	}
+
+	var b strings.Builder
+	span := func(class, text string) {
+		b.WriteString(`<span class="` + class + `">`)
+		b.WriteString(html.EscapeString(text))
+		b.WriteString(`</span>`)
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		// 注释
+		if n := lx.comment(rest); n > 0 {
+			span(classComment, rest[:n])
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case strings.ContainsRune(lx.quotes, r):
+			n := stringLen(rest, r)
+			span(classString, rest[:n])
+			i += n
+		case unicode.IsDigit(r):
+			n := tokenLen(rest, func(r rune) bool { return unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.' || r == '_' })
+			span(classNumber, rest[:n])
+			i += n
+		case unicode.IsLetter(r) || r == '_':
+			n := tokenLen(rest, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' })
+			if lx.keywords[rest[:n]] {
+				span(classKeyword, rest[:n])
+			} else {
+				b.WriteString(html.EscapeString(rest[:n]))
+			}
+			i += n
+		default:
+			b.WriteString(html.EscapeString(rest[:size]))
+			i += size
+		}
+	}
+	return b.String()
+}
+
+// comment 返回 s 开头注释的长度，不是注释时返回 0
+func (lx *lexer) comment(s string) int {
+	for _, prefix := range lx.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			if end := strings.IndexByte(s, '\n'); end >= 0 {
+				return end
+			}
+			return len(s)
+		}
+	}
+	if open := lx.blockComment[0]; open != "" && strings.HasPrefix(s, open) {
+		if end := strings.Index(s[len(open):], lx.blockComment[1]); end >= 0 {
+			return len(open) + end + len(lx.blockComment[1])
+		}
+		return len(s)
+	}
+	return 0
+}
+
+// stringLen 返回以 quote 开头的字符串字面量长度，支持反斜杠转义，未闭合时到行尾（反引号到文本末尾）
+func stringLen(s string, quote rune) int {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quote != '`':
+			i++
+		case rune(c) == quote:
+			return i + 1
+		case c == '\n' && quote != '`':
+			return i
+		}
+	}
+	return len(s)
+}
+
+// tokenLen 返回 s 开头满足 ok 的连续字符长度
+func tokenLen(s string, ok func(rune) bool) int {
+	for i, r := range s {
+		if !ok(r) {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,151 @@
+// Package markup 帖子内容渲染管线
+//
+// 渲染流程：
+//
+//	源文本 --(markdown / plain / html 转换)--> HTML --(后处理)--> 安全 HTML
+//
+// 后处理在一次遍历中完成：
+//   - 按白名单清洗标签与属性，丢弃 script/style 等危险元素及其内容
+//   - 为标题生成锚点并提取目录
+//   - 为代码块做语法高亮
+//   - 把正文中的 @用户 和 #标签 展开为链接（链接与代码内除外）
+//
+// 渲染结果按内容哈希缓存。
+package markup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Format 内容格式（对应 model.Post.Mod）
+type Format string
+
+// 支持的内容格式
+const (
+	FormatMarkdown Format = "markdown"
+	FormatPlain    Format = "plain"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat 解析内容格式，空字符串视为 markdown
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", "md":
+		return FormatMarkdown, nil
+	case FormatMarkdown, FormatPlain, FormatHTML:
+		return f, nil
+	case "text", "txt":
+		return FormatPlain, nil
+	default:
+		return "", fmt.Errorf("不支持的内容格式: %s", s)
+	}
+}
+
+// TOCItem 目录项
+type TOCItem struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// Result 渲染结果，可能被缓存共享，调用方不应修改
+type Result struct {
+	HTML     string    `json:"html"`
+	TOC      []TOCItem `json:"toc"`
+	Mentions []string  `json:"mentions,omitempty"` // 提及的用户名
+	Tags     []string  `json:"tags,omitempty"`     // 出现的话题标签
+}
+
+// Options 渲染选项
+type Options struct {
+	MentionURL string // 用户链接模板，{name} 会被替换为用户名
+	TagURL     string // 标签链接模板，{name} 会被替换为标签名
+}
+
+// DefaultOptions 默认渲染选项
+var DefaultOptions = Options{
+	MentionURL: "/user/{name}",
+	TagURL:     "/tag/{name}",
+}
+
+// Renderer 带缓存的渲染器
+type Renderer struct {
+	opts  Options
+	cache *lru
+}
+
+// NewRenderer 创建渲染器，cacheSize 小于等于0时不缓存
+func NewRenderer(opts Options, cacheSize int) *Renderer {
+	if opts.MentionURL == "" {
+		opts.MentionURL = DefaultOptions.MentionURL
+	}
+	if opts.TagURL == "" {
+		opts.TagURL = DefaultOptions.TagURL
+	}
+	r := &Renderer{opts: opts}
+	if cacheSize > 0 {
+		r.cache = newLRU(cacheSize)
+	}
+	return r
+}
+
+var (
+	defaultRenderer *Renderer
+	defaultMu       sync.RWMutex
+)
+
+// SetDefault 设置全局渲染器
+func SetDefault(r *Renderer) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRenderer = r
+}
+
+// Default 获取全局渲染器，未设置时使用默认选项且不缓存
+func Default() *Renderer {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultRenderer == nil {
+		return NewRenderer(DefaultOptions, 0)
+	}
+	return defaultRenderer
+}
+
+// Render 渲染内容
+func (r *Renderer) Render(format Format, content string) *Result {
+	key := cacheKey(format, content)
+	if r.cache != nil {
+		if res, ok := r.cache.get(key); ok {
+			return res
+		}
+	}
+
+	var raw string
+	switch format {
+	case FormatPlain:
+		raw = plainToHTML(content)
+	case FormatHTML:
+		raw = content
+	default:
+		raw = markdownToHTML(content)
+	}
+	res := postprocess(raw, r.opts)
+
+	if r.cache != nil {
+		r.cache.add(key, res)
+	}
+	return res
+}
+
+// cacheKey 缓存键：格式与内容的 SHA-256
+func cacheKey(format Format, content string) string {
+	h := sha256.New()
+	h.Write([]byte(format))
+	h.Write([]byte{0})
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
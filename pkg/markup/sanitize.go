@@ -0,0 +1,436 @@
+package markup
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// allowedTags 允许的标签及其允许的属性
+var allowedTags = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"p":          {},
+	"br":         {},
+	"hr":         {},
+	"strong":     {},
+	"b":          {},
+	"em":         {},
+	"i":          {},
+	"u":          {},
+	"s":          {},
+	"del":        {},
+	"ins":        {},
+	"mark":       {},
+	"sup":        {},
+	"sub":        {},
+	"kbd":        {},
+	"blockquote": {},
+	"pre":        {},
+	"code":       {"class": true},
+	"ul":         {},
+	"ol":         {"start": true},
+	"li":         {},
+	"dl":         {},
+	"dt":         {},
+	"dd":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"table":      {},
+	"thead":      {},
+	"tbody":      {},
+	"tr":         {},
+	"th":         {"align": true},
+	"td":         {"align": true},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"details":    {},
+	"summary":    {},
+}
+
+// voidTags 无结束标签的元素
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// droppedTags 连同内容一起丢弃的元素
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "textarea": true, "title": true, "head": true, "template": true,
+	"svg": true, "math": true, "select": true, "frame": true, "frameset": true,
+}
+
+// blockTags 会隐式闭合段落的块级元素
+var blockTags = map[string]bool{
+	"p": true, "hr": true, "blockquote": true, "pre": true, "ul": true, "ol": true, "dl": true,
+	"table": true, "details": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// headingLevels 标题标签对应的级别
+var headingLevels = map[string]int{"h1": 1, "h2": 2, "h3": 3, "h4": 4, "h5": 5, "h6": 6}
+
+var (
+	languageClass = regexp.MustCompile(`^language-[A-Za-z0-9_+#-]{1,32}$`)
+	alignValue    = regexp.MustCompile(`^(left|right|center)$`)
+	// mentionPattern 匹配 @用户 与 #标签
+	mentionPattern = regexp.MustCompile(`[@#][\p{L}\p{N}_]{1,32}`)
+)
+
+// processor 后处理状态
+type processor struct {
+	opts Options
+	out  strings.Builder
+
+	stack    []string // 已输出且未闭合的标签
+	dropping int      // 丢弃元素的嵌套深度
+
+	// 标题收集：标题内容先写入 heading，闭合时再生成锚点
+	heading      *strings.Builder
+	headingLevel int
+	headingText  strings.Builder
+	ids          map[string]int
+
+	// 代码块收集：代码文本先写入 code，闭合时再高亮
+	code     *strings.Builder
+	codeLang string
+
+	res      *Result
+	mentions map[string]bool
+	tags     map[string]bool
+}
+
+// postprocess 清洗 HTML 并生成锚点、目录、高亮与链接
+func postprocess(raw string, opts Options) *Result {
+	p := &processor{
+		opts:     opts,
+		ids:      make(map[string]int),
+		res:      &Result{TOC: []TOCItem{}},
+		mentions: make(map[string]bool),
+		tags:     make(map[string]bool),
+	}
+
+	z := xhtml.NewTokenizer(strings.NewReader(raw))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			p.start(tok, tt == xhtml.SelfClosingTagToken)
+		case xhtml.EndTagToken:
+			p.end(tok.Data)
+		case xhtml.TextToken:
+			p.text(tok.Data)
+		}
+	}
+	// 闭合所有未闭合的标签
+	for len(p.stack) > 0 {
+		p.end(p.stack[len(p.stack)-1])
+	}
+
+	p.res.HTML = p.out.String()
+	return p.res
+}
+
+// w 当前输出位置
+func (p *processor) w() *strings.Builder {
+	if p.heading != nil {
+		return p.heading
+	}
+	return &p.out
+}
+
+// inside 是否处于指定标签内
+func (p *processor) inside(tag string) bool {
+	for _, t := range p.stack {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *processor) start(tok xhtml.Token, selfClosing bool) {
+	name := tok.Data
+	if p.dropping > 0 || droppedTags[name] {
+		// 丢弃元素内部按标签嵌套计数，遇到对应的结束标签时恢复
+		if !selfClosing && !voidTags[name] {
+			p.dropping++
+		}
+		return
+	}
+	allowed, ok := allowedTags[name]
+	if !ok || p.code != nil {
+		// 不在白名单中的标签只保留内容；代码块内不允许嵌套标签
+		return
+	}
+
+	// 块级元素不能出现在段落中，按 HTML 规则隐式闭合段落
+	if blockTags[name] && len(p.stack) > 0 && p.stack[len(p.stack)-1] == "p" {
+		p.end("p")
+	}
+
+	if level, isHeading := headingLevels[name]; isHeading {
+		if p.heading != nil {
+			return
+		}
+		p.heading = &strings.Builder{}
+		p.headingLevel = level
+		p.headingText.Reset()
+		p.stack = append(p.stack, name)
+		return
+	}
+
+	attrs := p.attrs(name, tok.Attr, allowed)
+	if name == "code" && p.inside("pre") {
+		p.code = &strings.Builder{}
+		p.codeLang = strings.TrimPrefix(attrs["class"], "language-")
+		p.stack = append(p.stack, name)
+		return
+	}
+
+	w := p.w()
+	w.WriteString("<" + name)
+	for _, key := range attrOrder {
+		if v, ok := attrs[key]; ok {
+			w.WriteString(" " + key + `="` + html.EscapeString(v) + `"`)
+		}
+	}
+	w.WriteString(">")
+	if !voidTags[name] {
+		p.stack = append(p.stack, name)
+	}
+}
+
+// attrOrder 属性输出顺序，保证输出稳定
+var attrOrder = []string{"href", "src", "alt", "title", "width", "height", "start", "align", "class", "rel", "target"}
+
+// attrs 过滤属性
+func (p *processor) attrs(name string, in []xhtml.Attribute, allowed map[string]bool) map[string]string {
+	out := make(map[string]string)
+	for _, a := range in {
+		key := strings.ToLower(a.Key)
+		if !allowed[key] || a.Namespace != "" {
+			continue
+		}
+		val := strings.TrimSpace(a.Val)
+		switch key {
+		case "href", "src":
+			if !safeURL(val) {
+				continue
+			}
+		case "class":
+			if !languageClass.MatchString(val) {
+				continue
+			}
+		case "align":
+			if !alignValue.MatchString(val) {
+				continue
+			}
+		case "width", "height", "start":
+			if _, err := strconv.Atoi(val); err != nil {
+				continue
+			}
+		}
+		out[key] = val
+	}
+	if name == "a" {
+		if u, err := url.Parse(out["href"]); err == nil && u.IsAbs() {
+			out["rel"] = "nofollow noopener noreferrer"
+			out["target"] = "_blank"
+		}
+	}
+	return out
+}
+
+// safeURL 只允许 http/https/mailto 与相对地址
+func safeURL(raw string) bool {
+	if raw == "" {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *processor) end(name string) {
+	if p.dropping > 0 {
+		if !voidTags[name] {
+			p.dropping--
+		}
+		return
+	}
+
+	// 只闭合已打开的标签，中间未闭合的标签一并闭合
+	idx := -1
+	for i := len(p.stack) - 1; i >= 0; i-- {
+		if p.stack[i] == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return
+	}
+	for len(p.stack) > idx {
+		top := p.stack[len(p.stack)-1]
+		p.stack = p.stack[:len(p.stack)-1]
+		p.close(top)
+	}
+}
+
+// close 输出结束标签
+func (p *processor) close(name string) {
+	switch {
+	case name == "code" && p.code != nil:
+		w := p.w()
+		w.WriteString("<code")
+		if p.codeLang != "" {
+			w.WriteString(` class="language-` + html.EscapeString(p.codeLang) + `"`)
+		}
+		w.WriteString(">")
+		w.WriteString(Highlight(p.codeLang, p.code.String()))
+		w.WriteString("</code>")
+		p.code = nil
+		p.codeLang = ""
+	case headingLevels[name] > 0 && p.heading != nil:
+		text := strings.TrimSpace(p.headingText.String())
+		id := p.uniqueID(slugify(text))
+		p.res.TOC = append(p.res.TOC, TOCItem{Level: p.headingLevel, ID: id, Text: text})
+		inner := p.heading.String()
+		p.heading = nil
+		p.out.WriteString("<" + name + ` id="` + html.EscapeString(id) + `">`)
+		p.out.WriteString(inner)
+		p.out.WriteString("</" + name + ">")
+	default:
+		p.w().WriteString("</" + name + ">")
+	}
+}
+
+func (p *processor) text(s string) {
+	if p.dropping > 0 {
+		return
+	}
+	if p.code != nil {
+		p.code.WriteString(s)
+		return
+	}
+	if p.heading != nil {
+		p.headingText.WriteString(s)
+	}
+	if p.inside("a") || p.inside("code") || p.inside("pre") {
+		p.w().WriteString(html.EscapeString(s))
+		return
+	}
+	p.w().WriteString(p.expand(s))
+}
+
+// expand 把 @用户 与 #标签 展开为链接，返回转义后的 HTML
+func (p *processor) expand(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range mentionPattern.FindAllStringIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		name := s[start+1 : end]
+		// 前一个字符为英文字母、数字或部分符号时不展开，避免误伤邮箱和网址
+		if prev, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 &&
+			(isASCIIWord(prev) || strings.ContainsRune("./-:&@#", prev)) {
+			continue
+		}
+		var link string
+		if s[start] == '@' {
+			link = `<a class="mention" href="` + html.EscapeString(linkURL(p.opts.MentionURL, name)) + `">@` + html.EscapeString(name) + `</a>`
+			p.addMention(name)
+		} else {
+			if isDigits(name) {
+				continue
+			}
+			link = `<a class="hashtag" href="` + html.EscapeString(linkURL(p.opts.TagURL, name)) + `">#` + html.EscapeString(name) + `</a>`
+			p.addTag(name)
+		}
+		b.WriteString(html.EscapeString(s[last:start]))
+		b.WriteString(link)
+		last = end
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return b.String()
+}
+
+func (p *processor) addMention(name string) {
+	if !p.mentions[name] {
+		p.mentions[name] = true
+		p.res.Mentions = append(p.res.Mentions, name)
+	}
+}
+
+func (p *processor) addTag(name string) {
+	if !p.tags[name] {
+		p.tags[name] = true
+		p.res.Tags = append(p.res.Tags, name)
+	}
+}
+
+// uniqueID 生成文档内唯一的锚点
+func (p *processor) uniqueID(id string) string {
+	n := p.ids[id]
+	p.ids[id] = n + 1
+	if n == 0 {
+		return id
+	}
+	return id + "-" + strconv.Itoa(n)
+}
+
+// slugify 把标题文本转换为锚点：保留字母数字（含中文），空白与连字符转为 "-"
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// linkURL 用名称替换链接模板中的 {name}
+func linkURL(tpl, name string) string {
+	return strings.ReplaceAll(tpl, "{name}", url.PathEscape(name))
+}
+
+func isASCIIWord(r rune) bool {
+	return r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
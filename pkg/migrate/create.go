@@ -0,0 +1,83 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// goTemplate 新建 Go 迁移的模板，参数依次为包名、版本号、名称
+const goTemplate = `package %s
+
+import (
+	"gorm.io/gorm"
+	"qwqserver/pkg/migrate"
+)
+
+func init() {
+	register(migrate.Migration{
+		Version: %d,
+		Name:    %q,
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
+`
+
+// Create 在 dir 中生成一个以当前时间为版本号的迁移，kind 为 go 或 sql，返回生成的文件
+//
+// Go 迁移生成 <版本号>_<名称>.go，包名取 dir 的目录名，通过包内的 register 注册；
+// SQL 迁移生成 sql 子目录下通用的 up/down 两个文件，需要按方言区分时改为 <名称>.<方言>.up.sql。
+func Create(dir, name, kind string, now time.Time) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("迁移名称 %q 无效，只能包含字母、数字和下划线", name)
+	}
+	version := now.UTC().Format("20060102150405")
+	var files map[string]string
+	switch kind {
+	case "", "go":
+		v, _ := strconv.ParseInt(version, 10, 64)
+		files = map[string]string{
+			filepath.Join(dir, version+"_"+name+".go"): fmt.Sprintf(goTemplate, filepath.Base(dir), v, name),
+		}
+	case "sql":
+		base := filepath.Join(dir, "sql", version+"_"+name)
+		files = map[string]string{
+			base + ".up.sql":   "-- " + name + "\n",
+			base + ".down.sql": "-- " + name + " 的回滚，不可回滚时删除本文件\n",
+		}
+	default:
+		return nil, fmt.Errorf("未知的迁移类型 %q，可选 go 或 sql", kind)
+	}
+
+	var created []string
+	for file, content := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return created, fmt.Errorf("创建目录失败: %w", err)
+		}
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return created, fmt.Errorf("创建迁移文件失败: %w", err)
+		}
+		_, err = f.WriteString(content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return created, fmt.Errorf("写入迁移文件失败: %w", err)
+		}
+		created = append(created, file)
+	}
+	sort.Strings(created)
+	return created, nil
+}
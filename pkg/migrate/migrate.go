@@ -0,0 +1,327 @@
+// Package migrate 版本化的数据库迁移
+//
+// 每个迁移有一个唯一的版本号（通常为创建时间 YYYYMMDDHHMMSS）与 up/down 两个方向，
+// 可以是 Go 函数，也可以是按方言区分的 SQL 文件（见 LoadFS）。已执行的版本记录在
+// schema_migrations 表中，Up 按版本号顺序执行全部未执行的迁移，Down 按相反顺序回滚。
+//
+// 每个迁移在单独的事务中执行，并与 schema_migrations 的记录一同提交。
+// 注意 MySQL 的 DDL 会隐式提交事务，迁移中途失败时需要手工检查表结构。
+//
+// 执行迁移前会获取迁移锁（MySQL 使用 GET_LOCK，PostgreSQL 使用 advisory lock，
+// 其余数据库使用 schema_migrations_lock 表），多个实例同时启动时只有一个执行迁移，
+// 其余实例等待其完成后发现没有待执行的迁移。
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Table 记录已执行迁移的表
+	Table = "schema_migrations"
+	// LockTable 不支持会话锁的数据库使用的迁移锁表
+	LockTable = "schema_migrations_lock"
+)
+
+var (
+	// ErrIrreversible 迁移没有 down 方向，无法回滚
+	ErrIrreversible = errors.New("迁移不可回滚")
+	// ErrLockTimeout 等待迁移锁超时
+	ErrLockTimeout = errors.New("等待迁移锁超时")
+)
+
+// Migration 一个版本化的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error // 为 nil 时不可回滚
+}
+
+// String 版本号与名称，如 20250101000000_baseline
+func (m Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Missing   bool // 数据库中有记录，但代码中已不存在该迁移
+}
+
+// record schema_migrations 中的一行
+type record struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (record) TableName() string {
+	return Table
+}
+
+// lockRecord schema_migrations_lock 中的一行，id 固定为 1
+type lockRecord struct {
+	ID       int       `gorm:"primaryKey;autoIncrement:false"`
+	LockedAt time.Time `gorm:"not null"`
+}
+
+func (lockRecord) TableName() string {
+	return LockTable
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+
+	LockTimeout time.Duration // 等待迁移锁的最长时间，默认 1 分钟
+	StaleLock   time.Duration // 锁表中的锁超过该时长视为持有者已崩溃，可被接管，默认 10 分钟
+	Log         func(format string, args ...any)
+}
+
+// New 创建迁移执行器，迁移按版本号排序，版本号重复或无效时返回错误
+func New(db *gorm.DB, migrations []Migration) (*Migrator, error) {
+	ms := append([]Migration(nil), migrations...)
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	for i, m := range ms {
+		if m.Version <= 0 || m.Up == nil {
+			return nil, fmt.Errorf("迁移 %s 无效: 版本号必须为正数且必须有 up", m)
+		}
+		if i > 0 && ms[i-1].Version == m.Version {
+			return nil, fmt.Errorf("迁移版本号重复: %s 与 %s", ms[i-1], m)
+		}
+	}
+	return &Migrator{
+		db:          db,
+		migrations:  ms,
+		LockTimeout: time.Minute,
+		StaleLock:   10 * time.Minute,
+	}, nil
+}
+
+// Migrations 全部迁移，按版本号排序
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+func (m *Migrator) logf(format string, args ...any) {
+	if m.Log != nil {
+		m.Log(format, args...)
+	}
+}
+
+// Up 执行全部未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.UpTo(ctx, 0)
+}
+
+// UpTo 执行版本号不大于 version 的未执行迁移，version 为 0 时执行全部
+func (m *Migrator) UpTo(ctx context.Context, version int64) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if version > 0 && mig.Version > version {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			m.logf("执行迁移 %s", mig)
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := mig.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&record{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("执行迁移 %s 失败: %w", mig, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 按版本号从大到小回滚最近执行的 steps 个迁移（小于 1 时按 1 处理），返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		steps = 1
+	}
+	byVersion := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		var records []record
+		if err := conn.Order("version DESC").Limit(steps).Find(&records).Error; err != nil {
+			return fmt.Errorf("读取迁移记录失败: %w", err)
+		}
+		for _, r := range records {
+			mig, ok := byVersion[r.Version]
+			if !ok {
+				return fmt.Errorf("回滚 %d_%s 失败: 代码中不存在该迁移", r.Version, r.Name)
+			}
+			if mig.Down == nil {
+				return fmt.Errorf("回滚 %s 失败: %w", mig, ErrIrreversible)
+			}
+			m.logf("回滚迁移 %s", mig)
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := mig.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&record{}, "version = ?", mig.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("回滚迁移 %s 失败: %w", mig, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status 全部迁移的执行状态，按版本号排序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn := m.db.WithContext(ctx)
+	applied, err := m.applied(conn)
+	if err != nil {
+		return nil, err
+	}
+	var out []Status
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if r, ok := applied[mig.Version]; ok {
+			at := r.AppliedAt
+			s.Applied, s.AppliedAt = true, &at
+			delete(applied, mig.Version)
+		}
+		out = append(out, s)
+	}
+	for _, r := range applied {
+		at := r.AppliedAt
+		out = append(out, Status{Version: r.Version, Name: r.Name, Applied: true, AppliedAt: &at, Missing: true})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// applied 已执行的迁移，必要时创建 schema_migrations 表
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]record, error) {
+	if err := conn.AutoMigrate(&record{}); err != nil {
+		return nil, fmt.Errorf("创建 %s 表失败: %w", Table, err)
+	}
+	var records []record
+	if err := conn.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+	applied := make(map[int64]record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// withLock 在同一个数据库连接上持有迁移锁并执行 fn
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// 新会话仍使用同一个连接，避免各条语句的条件互相叠加
+		conn = conn.Session(&gorm.Session{NewDB: true})
+		unlock, err := m.lock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return fn(conn)
+	})
+}
+
+// lock 获取迁移锁，返回释放函数
+func (m *Migrator) lock(ctx context.Context, conn *gorm.DB) (func(), error) {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		var got sql.NullInt64
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", Table, int(m.LockTimeout.Seconds())).Scan(&got).Error; err != nil {
+			return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if !got.Valid || got.Int64 != 1 {
+			return nil, ErrLockTimeout
+		}
+		return func() { conn.Exec("SELECT RELEASE_LOCK(?)", Table) }, nil
+	case "postgres":
+		h := fnv.New64a()
+		h.Write([]byte(Table))
+		key := int64(h.Sum64())
+		err := m.retry(ctx, func() (bool, error) {
+			var got bool
+			err := conn.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&got).Error
+			return got, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return func() { conn.Exec("SELECT pg_advisory_unlock(?)", key) }, nil
+	default:
+		return m.tableLock(ctx, conn)
+	}
+}
+
+// tableLock 以 schema_migrations_lock 表中 id=1 的行作为锁，用于 SQLite 等没有会话锁的数据库
+func (m *Migrator) tableLock(ctx context.Context, conn *gorm.DB) (func(), error) {
+	if err := conn.AutoMigrate(&lockRecord{}); err != nil {
+		return nil, fmt.Errorf("创建 %s 表失败: %w", LockTable, err)
+	}
+	err := m.retry(ctx, func() (bool, error) {
+		// 接管持有者崩溃后遗留的锁
+		if m.StaleLock > 0 {
+			conn.Where("id = 1 AND locked_at < ?", time.Now().Add(-m.StaleLock)).Delete(&lockRecord{})
+		}
+		res := conn.Exec("INSERT INTO "+LockTable+" (id, locked_at) SELECT 1, ? WHERE NOT EXISTS (SELECT 1 FROM "+LockTable+" WHERE id = 1)", time.Now())
+		return res.Error == nil && res.RowsAffected == 1, res.Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() { conn.Delete(&lockRecord{}, "id = 1") }, nil
+}
+
+// retry 反复尝试获取锁直到成功或超过 LockTimeout
+func (m *Migrator) retry(ctx context.Context, try func() (bool, error)) error {
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		ok, err := try()
+		if err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SQL 迁移文件名：<版本号>_<名称>[.<方言>].(up|down).sql
+//
+//	20250101000000_add_index.up.sql          所有数据库通用
+//	20250101000000_add_index.mysql.up.sql    仅 MySQL，优先于通用文件
+//
+// 方言为 mysql、postgres、sqlite（与 database.driver 一致）。当前数据库既没有专用文件也没有通用文件时，
+// 该方向在此数据库上不执行任何操作但仍记录版本；任何方言都没有 down 文件时迁移不可回滚。
+var sqlFilePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)(?:\.(mysql|postgres|sqlite))?\.(up|down)\.sql$`)
+
+// sqlMigration 同一版本的 SQL 文件，键为方言，通用文件的键为空串
+type sqlMigration struct {
+	version int64
+	name    string
+	up      map[string]string
+	down    map[string]string
+}
+
+// LoadFS 读取 dir 目录下的 SQL 迁移文件，不符合命名规则的文件被忽略
+func LoadFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移目录失败: %w", err)
+	}
+	byVersion := map[int64]*sqlMigration{}
+	var order []int64
+	for _, e := range entries {
+		sub := sqlFilePattern.FindStringSubmatch(e.Name())
+		if e.IsDir() || sub == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(sub[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件 %s 的版本号无效: %w", e.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件失败: %w", err)
+		}
+
+		sm := byVersion[version]
+		if sm == nil {
+			sm = &sqlMigration{version: version, name: sub[2], up: map[string]string{}, down: map[string]string{}}
+			byVersion[version] = sm
+			order = append(order, version)
+		} else if sm.name != sub[2] {
+			return nil, fmt.Errorf("迁移版本号重复: %d_%s 与 %d_%s", version, sm.name, version, sub[2])
+		}
+		if sub[4] == "up" {
+			sm.up[sub[3]] = string(data)
+		} else {
+			sm.down[sub[3]] = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(order))
+	for _, v := range order {
+		sm := byVersion[v]
+		mig := Migration{Version: sm.version, Name: sm.name, Up: execSQL(sm.up)}
+		if len(sm.down) > 0 {
+			mig.Down = execSQL(sm.down)
+		}
+		migrations = append(migrations, mig)
+	}
+	return migrations, nil
+}
+
+// execSQL 按当前数据库的方言选择 SQL 并逐条执行
+func execSQL(byDialect map[string]string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		script, ok := byDialect[tx.Dialector.Name()]
+		if !ok {
+			script = byDialect[""]
+		}
+		for _, stmt := range SplitStatements(script) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// SplitStatements 把 SQL 脚本拆分为单条语句
+//
+// 语句以位于行尾的分号结束，以 -- 开头的整行注释被忽略。
+// 不解析字符串字面量，行尾分号出现在多行字符串中的脚本需要拆成单独的 Go 迁移。
+func SplitStatements(script string) []string {
+	var stmts []string
+	var cur strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			stmts = append(stmts, s)
+		}
+		cur.Reset()
+	}
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		if strings.HasSuffix(trimmed, ";") {
+			cur.WriteString(strings.TrimSuffix(trimmed, ";"))
+			flush()
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+	}
+	flush()
+	return stmts
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Auth 接口的认证要求
+type Auth int
+
+const (
+	AuthNone     Auth = iota // 无需认证
+	AuthOptional             // 可选认证：携带令牌时识别用户，未携带时匿名访问
+	AuthRequired             // 必须认证
+)
+
+// Route 接口说明，与路由注册信息（请求方法 + 路径）对应
+type Route struct {
+	Summary     string
+	Description string
+	Tag         string       // 分组，为空时由 Builder.TagOf 根据路径推断
+	Auth        Auth         // 认证要求
+	Query       any          // 查询参数结构体，参数名取 form 标签
+	Params      []*Parameter // 额外的参数，与 Query 生成的同名参数会覆盖之
+	Body        any          // JSON 请求体类型
+	Response    any          // 统一响应包装中 data 字段的类型，nil 表示不限定
+	ContentType string       // 非空时响应不使用统一包装，按该内容类型原样输出（如 text/html）
+	Deprecated  bool
+}
+
+// QueryParam 创建查询参数，typ 为 JSON Schema 类型（string / integer / boolean 等）
+func QueryParam(name, typ, description string, required bool) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Required: required, Schema: &Schema{Type: typ}}
+}
+
+// Builder 由路由与接口说明构建文档
+type Builder struct {
+	Doc *Document
+	Gen *Generator
+
+	// Envelope 统一响应包装类型，Route.Response 会替换其中 EnvelopeData 字段的类型
+	Envelope     any
+	EnvelopeData string
+
+	// Security 各认证要求对应的 SecurityRequirement
+	Security map[Auth][]SecurityRequirement
+
+	// Unauthorized 必须认证的接口额外声明的 401 响应说明，为空时不声明
+	Unauthorized string
+
+	// TagOf 由路径推断分组，为空时取路径的第一段
+	TagOf func(path string) string
+}
+
+// NewBuilder 创建文档构建器
+func NewBuilder(info Info) *Builder {
+	doc := New(info)
+	return &Builder{
+		Doc:          doc,
+		Gen:          NewGenerator(doc),
+		EnvelopeData: "data",
+		Security:     map[Auth][]SecurityRequirement{},
+	}
+}
+
+// Add 添加接口，path 为 gin 路由路径（支持 :param 与 *param）
+func (b *Builder) Add(method, path string, r Route) {
+	oaPath, pathParams := ConvertPath(path)
+
+	tag := r.Tag
+	if tag == "" {
+		if b.TagOf != nil {
+			tag = b.TagOf(path)
+		} else {
+			tag, _, _ = strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		}
+	}
+
+	op := &Operation{
+		Summary:     r.Summary,
+		Description: r.Description,
+		OperationID: operationID(method, oaPath),
+		Responses:   map[string]*Response{},
+		Security:    b.Security[r.Auth],
+		Deprecated:  r.Deprecated,
+	}
+	if tag != "" {
+		op.Tags = []string{tag}
+		b.Doc.AddTag(tag, "")
+	}
+
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if r.Query != nil {
+		op.Parameters = mergeParams(op.Parameters, b.Gen.Parameters(r.Query, "query"))
+	}
+	op.Parameters = mergeParams(op.Parameters, r.Params)
+
+	if r.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: b.Gen.Schema(r.Body)}},
+		}
+	}
+
+	ok := &Response{Description: http.StatusText(http.StatusOK)}
+	if r.ContentType != "" {
+		ok.Content = map[string]*MediaType{r.ContentType: {Schema: b.responseSchema(r.Response, false)}}
+	} else {
+		ok.Content = map[string]*MediaType{"application/json": {Schema: b.responseSchema(r.Response, b.Envelope != nil)}}
+	}
+	op.Responses["200"] = ok
+	if r.Auth == AuthRequired && b.Unauthorized != "" {
+		op.Responses["401"] = &Response{
+			Description: b.Unauthorized,
+			Content:     map[string]*MediaType{"application/json": {Schema: b.responseSchema(nil, b.Envelope != nil)}},
+		}
+	}
+
+	b.Doc.AddOperation(oaPath, method, op)
+}
+
+// responseSchema 响应结构，wrap 为 true 时包装为统一响应并替换 data 字段
+func (b *Builder) responseSchema(data any, wrap bool) *Schema {
+	if !wrap {
+		if data == nil {
+			return nil
+		}
+		return b.Gen.Schema(data)
+	}
+	envelope := b.Gen.Schema(b.Envelope)
+	if data == nil {
+		return envelope
+	}
+	return &Schema{AllOf: []*Schema{
+		envelope,
+		{Type: "object", Properties: map[string]*Schema{b.EnvelopeData: b.Gen.Schema(data)}},
+	}}
+}
+
+// mergeParams 合并参数，同名同位置的参数以后者为准
+func mergeParams(params, extra []*Parameter) []*Parameter {
+	for _, p := range extra {
+		replaced := false
+		for i, old := range params {
+			if old.Name == p.Name && old.In == p.In {
+				params[i] = p
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// ConvertPath 把 gin 路由路径转换为 OpenAPI 路径，返回路径参数名
+// 如 /docs/*path → /docs/{path}，/user/:id → /user/{id}
+func ConvertPath(path string) (string, []string) {
+	segs := strings.Split(path, "/")
+	var params []string
+	for i, seg := range segs {
+		if seg == "" {
+			continue
+		}
+		if seg[0] == ':' || seg[0] == '*' {
+			params = append(params, seg[1:])
+			segs[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segs, "/"), params
+}
+
+// operationID 由请求方法与路径生成操作 ID，如 POST /api/v1/post/create → post_api_v1_post_create
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.Trim(seg, "{}")
+		if seg == "" {
+			continue
+		}
+		sb.WriteByte('_')
+		sb.WriteString(seg)
+	}
+	return sb.String()
+}
@@ -0,0 +1,434 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Generator 由 Go 类型生成 Schema，结构体登记到文档的 components/schemas 并以引用返回
+//
+// 字段名与可见性遵循 encoding/json 的规则：json 标签改名、"-" 忽略、匿名嵌入字段展开，
+// 同名字段取嵌入层级最浅者。binding:"required" 的字段标记为必填，
+// binding 标签中的 min、max、len、oneof、email 规则转换为对应的约束（见 pkg/validate），
+// gorm 标签中的 comment 作为字段说明。
+type Generator struct {
+	doc       *Document
+	overrides map[reflect.Type]*Schema
+	names     map[reflect.Type]string
+}
+
+// NewGenerator 创建 Schema 生成器
+func NewGenerator(doc *Document) *Generator {
+	g := &Generator{
+		doc:       doc,
+		overrides: map[reflect.Type]*Schema{},
+		names:     map[reflect.Type]string{},
+	}
+	g.Override(time.Time{}, &Schema{Type: "string", Format: "date-time"})
+	g.Override(time.Duration(0), &Schema{Type: "integer", Format: "int64", Description: "纳秒"})
+	return g
+}
+
+// Override 为类型指定固定的 Schema，用于自定义 JSON 序列化的类型（如 gorm.DeletedAt）
+func (g *Generator) Override(v any, s *Schema) {
+	g.overrides[typeOf(v)] = s
+}
+
+// Schema 生成类型的 Schema，v 可以是值、指针或 reflect.Type，nil 表示任意类型
+func (g *Generator) Schema(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return g.schema(typeOf(v))
+}
+
+// Name 类型在 components/schemas 中的名称，如 model.Post
+func (g *Generator) Name(v any) string {
+	t := typeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return g.name(t)
+}
+
+// Parameters 由结构体生成查询等参数，参数名取 form 标签（与 gin 的绑定规则一致），未设置时使用字段名
+func (g *Generator) Parameters(v any, in string) []*Parameter {
+	t := typeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		tag := f.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			params = append(params, g.Parameters(ft, in)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		s := g.schema(f.Type)
+		if s.Ref != "" || s.Type == "object" {
+			// 查询参数不支持嵌套对象
+			continue
+		}
+		constrain(s, f)
+		params = append(params, &Parameter{
+			Name:        name,
+			In:          in,
+			Description: fieldDescription(f),
+			Required:    in == "path" || hasRequired(f),
+			Schema:      s,
+		})
+	}
+	return params
+}
+
+func typeOf(v any) reflect.Type {
+	if t, ok := v.(reflect.Type); ok {
+		return t
+	}
+	return reflect.TypeOf(v)
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	if s, ok := g.overrides[t]; ok {
+		cp := *s
+		return &cp
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64", Minimum: new(float64)}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: new(float64)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := g.name(t)
+		if _, ok := g.doc.Components.Schemas[name]; !ok {
+			// 先占位再展开字段，支持自引用的类型
+			g.doc.Components.Schemas[name] = &Schema{Type: "object"}
+			g.doc.Components.Schemas[name] = g.object(t)
+		}
+		return RefSchema(name)
+	default:
+		// interface{} 等任意类型
+		return &Schema{}
+	}
+}
+
+// object 生成结构体的对象 Schema
+func (g *Generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range jsonFields(t) {
+		fs := g.schema(f.field.Type)
+		if f.asString {
+			fs = &Schema{Type: "string"}
+		}
+		constrain(fs, f.field)
+		if desc := fieldDescription(f.field); desc != "" {
+			if fs.Ref != "" {
+				// 3.0 中 $ref 的兄弟属性会被忽略，用 allOf 包一层以保留说明
+				fs = &Schema{AllOf: []*Schema{fs}}
+			}
+			fs.Description = desc
+		}
+		s.Properties[f.name] = fs
+		if hasRequired(f.field) {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	typeArgPkgPath   = regexp.MustCompile(`[\w.-]+/`)
+)
+
+// name 组件名称：包名.类型名，泛型等类型中的非法字符替换为下划线
+func (g *Generator) name(t reflect.Type) string {
+	if n, ok := g.names[t]; ok {
+		return n
+	}
+	// 泛型类型参数只保留包名，如 pageData[qwqserver/internal/model.Post] → pageData[model.Post]
+	n := typeArgPkgPath.ReplaceAllString(t.Name(), "")
+	if pkg := t.PkgPath(); pkg != "" {
+		n = pkg[strings.LastIndex(pkg, "/")+1:] + "." + n
+	}
+	n = strings.Trim(invalidNameChars.ReplaceAllString(n, "_"), "_")
+
+	// 不同包下同名的类型追加序号区分
+	base := n
+	for i := 2; g.nameTaken(n, t); i++ {
+		n = base + "_" + strconv.Itoa(i)
+	}
+	g.names[t] = n
+	return n
+}
+
+func (g *Generator) nameTaken(n string, t reflect.Type) bool {
+	for other, name := range g.names {
+		if name == n && other != t {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonField 参与 JSON 序列化的字段
+type jsonField struct {
+	name     string
+	field    reflect.StructField
+	depth    int
+	tagged   bool
+	asString bool
+}
+
+// jsonFields 按 encoding/json 的规则列出结构体序列化后的字段
+func jsonFields(t reflect.Type) []jsonField {
+	var all []jsonField
+	collectFields(t, 0, map[reflect.Type]bool{}, &all)
+
+	// 同名字段：取层级最浅者，同层级时取唯一带标签者，否则都忽略
+	byName := map[string][]int{}
+	var order []string
+	for i, f := range all {
+		if _, ok := byName[f.name]; !ok {
+			order = append(order, f.name)
+		}
+		byName[f.name] = append(byName[f.name], i)
+	}
+
+	var fields []jsonField
+	for _, name := range order {
+		idx := byName[name]
+		minDepth := all[idx[0]].depth
+		for _, i := range idx {
+			minDepth = min(minDepth, all[i].depth)
+		}
+		var best []jsonField
+		for _, i := range idx {
+			if all[i].depth == minDepth {
+				best = append(best, all[i])
+			}
+		}
+		if len(best) > 1 {
+			var tagged []jsonField
+			for _, f := range best {
+				if f.tagged {
+					tagged = append(tagged, f)
+				}
+			}
+			best = tagged
+		}
+		if len(best) == 1 {
+			fields = append(fields, best[0])
+		}
+	}
+	return fields
+}
+
+func collectFields(t reflect.Type, depth int, visited map[reflect.Type]bool, out *[]jsonField) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if !f.IsExported() && ft.Kind() != reflect.Struct {
+				continue
+			}
+			if name == "" && ft.Kind() == reflect.Struct {
+				collectFields(ft, depth+1, visited, out)
+				continue
+			}
+		} else if !f.IsExported() {
+			continue
+		}
+
+		tagged := name != ""
+		if !tagged {
+			name = f.Name
+		}
+		*out = append(*out, jsonField{
+			name:     name,
+			field:    f,
+			depth:    depth,
+			tagged:   tagged,
+			asString: hasOption(opts, "string") && isScalar(f.Type),
+		})
+	}
+}
+
+func isScalar(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+func hasOption(opts, want string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == want {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRequired 字段是否声明 binding:"required"
+func hasRequired(f reflect.StructField) bool {
+	return hasOption(f.Tag.Get("binding"), "required")
+}
+
+// constrain 按 binding 标签中的校验规则设置约束，引用的组件不修改
+func constrain(s *Schema, f reflect.StructField) {
+	if s.Ref != "" {
+		return
+	}
+	for _, part := range strings.Split(f.Tag.Get("binding"), ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch rule {
+		case "email":
+			if s.Type == "string" {
+				s.Format = "email"
+			}
+		case "oneof":
+			s.Enum = nil
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(s.Type, v))
+			}
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			if rule == "min" || rule == "len" {
+				setBound(s, n, true)
+			}
+			if rule == "max" || rule == "len" {
+				setBound(s, n, false)
+			}
+		}
+	}
+}
+
+// setBound 设置下限或上限：字符串为长度，数组为元素数，数字为数值
+func setBound(s *Schema, n float64, lower bool) {
+	i := int(n)
+	switch s.Type {
+	case "string":
+		if lower {
+			s.MinLength = &i
+		} else {
+			s.MaxLength = &i
+		}
+	case "array":
+		if lower {
+			s.MinItems = &i
+		} else {
+			s.MaxItems = &i
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+// enumValue 枚举值按 Schema 类型转换，无法转换时保留字符串
+func enumValue(typ, v string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// fieldDescription 字段说明，取 gorm 标签中的 comment
+func fieldDescription(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("gorm"), ";") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(part), "comment:"); ok {
+			return v
+		}
+	}
+	return ""
+}
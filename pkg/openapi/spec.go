@@ -0,0 +1,228 @@
+// Package openapi OpenAPI 3 接口文档生成
+//
+// 由路由注册信息与请求/响应结构体类型生成 OpenAPI 3.0 文档：
+// 结构体通过反射生成 JSON Schema 并登记到 components/schemas，
+// 路由路径中的 gin 参数（:id、*path）转换为 OpenAPI 路径参数。
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Version 生成的 OpenAPI 规范版本
+const Version = "3.0.3"
+
+// Document OpenAPI 文档
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Info 文档信息
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server 服务地址
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag 接口分组
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 同一路径下各请求方法的操作
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Head   *Operation `json:"head,omitempty"`
+}
+
+// Operation 返回请求方法对应的操作
+func (p *PathItem) Operation(method string) *Operation {
+	if op := p.slot(method); op != nil {
+		return *op
+	}
+	return nil
+}
+
+// SetOperation 设置请求方法对应的操作，不支持的方法返回 false
+func (p *PathItem) SetOperation(method string, op *Operation) bool {
+	slot := p.slot(method)
+	if slot == nil {
+		return false
+	}
+	*slot = op
+	return true
+}
+
+func (p *PathItem) slot(method string) **Operation {
+	switch method {
+	case "GET":
+		return &p.Get
+	case "PUT":
+		return &p.Put
+	case "POST":
+		return &p.Post
+	case "DELETE":
+		return &p.Delete
+	case "PATCH":
+		return &p.Patch
+	case "HEAD":
+		return &p.Head
+	}
+	return nil
+}
+
+// Operation 接口操作
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// Parameter 请求参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // query / path / header / cookie
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header 响应头
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// MediaType 内容类型对应的数据结构
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components 可复用组件
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme 认证方式
+type SecurityScheme struct {
+	Type         string `json:"type"` // http / apiKey
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"` // apiKey 的参数名
+	In           string `json:"in,omitempty"`   // apiKey 的位置：header / query / cookie
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// SecurityRequirement 认证要求，键为 SecurityScheme 名称；空对象表示可匿名访问
+type SecurityRequirement map[string][]string
+
+// Schema JSON Schema（OpenAPI 3.0 子集）
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// RefSchema 引用 components/schemas 中的组件
+func RefSchema(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// New 创建空文档
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+	}
+}
+
+// AddOperation 添加接口操作，同一路径与方法重复添加时覆盖
+func (d *Document) AddOperation(path, method string, op *Operation) bool {
+	item := d.Paths[path]
+	if item == nil {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	return item.SetOperation(method, op)
+}
+
+// AddTag 添加接口分组，已存在时补充说明
+func (d *Document) AddTag(name, description string) {
+	for i := range d.Tags {
+		if d.Tags[i].Name == name {
+			if d.Tags[i].Description == "" {
+				d.Tags[i].Description = description
+			}
+			return
+		}
+	}
+	d.Tags = append(d.Tags, Tag{Name: name, Description: description})
+}
+
+// SortTags 按名称排序分组
+func (d *Document) SortTags() {
+	sort.SliceStable(d.Tags, func(i, j int) bool { return d.Tags[i].Name < d.Tags[j].Name })
+}
+
+// JSON 输出格式化的 JSON 文档
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"path"
+)
+
+//go:generate sh swaggerui/fetch.sh 5.17.14
+
+// SwaggerUIVersion 内置的 Swagger UI 版本，静态文件位于 swaggerui 目录，升级方法见其中的 README.md
+const SwaggerUIVersion = "5.17.14"
+
+// swaggerUI 内置的静态文件，文件缺失时编译失败
+//
+//go:embed swaggerui/swagger-ui-bundle.js swaggerui/swagger-ui.css
+var swaggerUI embed.FS
+
+// swaggerAssets 对外提供的 Swagger UI 静态文件及其类型
+var swaggerAssets = map[string]string{
+	"swagger-ui-bundle.js": "application/javascript; charset=utf-8",
+	"swagger-ui.css":       "text/css; charset=utf-8",
+}
+
+// SwaggerAsset 读取内置的 Swagger UI 静态文件，name 不是静态文件时 ok 为 false
+func SwaggerAsset(name string) (data []byte, contentType string, ok bool) {
+	contentType, ok = swaggerAssets[name]
+	if !ok {
+		return nil, "", false
+	}
+	data, err := fs.ReadFile(swaggerUI, path.Join("swaggerui", name))
+	if err != nil {
+		return nil, "", false
+	}
+	return data, contentType, true
+}
+
+// swaggerTemplate 静态文件使用相对地址，由 Swagger UI 页面所在的路由一并提供
+var swaggerTemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="swagger-ui.css">
+    <style>body { margin: 0; }</style>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="swagger-ui-bundle.js"></script>
+<script>
+    window.ui = SwaggerUIBundle({
+        url: {{.SpecURL}},
+        dom_id: "#swagger-ui",
+        deepLinking: true,
+        persistAuthorization: true
+    });
+</script>
+</body>
+</html>
+`))
+
+// SwaggerHTML 生成加载 specURL 文档的 Swagger UI 页面，静态文件见 SwaggerAsset
+func SwaggerHTML(title, specURL string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := swaggerTemplate.Execute(&buf, map[string]string{
+		"Title":   title,
+		"SpecURL": specURL,
+	})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,21 @@
+#!/bin/sh
+# 下载指定版本的 Swagger UI 静态文件到本目录，由 pkg/openapi/swagger.go 的 go:generate 调用
+# 文件取自 swagger-api/swagger-ui 对应标签的 dist 目录，版本与 SHA256SUMS 一致时校验通过
+# 用法：sh swaggerui/fetch.sh <版本>
+set -eu
+
+version=$1
+dir=$(cd "$(dirname "$0")" && pwd)
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+curl -fsSL "https://github.com/swagger-api/swagger-ui/archive/refs/tags/v$version.tar.gz" | tar -xz -C "$tmp"
+src="$tmp/swagger-ui-$version"
+cp "$src/dist/swagger-ui-bundle.js" "$src/dist/swagger-ui.css" "$src/LICENSE" "$src/NOTICE" "$dir/"
+
+cd "$dir"
+if ! sha256sum -c SHA256SUMS; then
+	echo "校验失败：升级版本时确认文件无误后执行 sha256sum swagger-ui-bundle.js swagger-ui.css > SHA256SUMS" >&2
+	exit 1
+fi
+echo "Swagger UI $version 已下载到 $dir"
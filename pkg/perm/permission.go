@@ -64,6 +64,10 @@ const (
 	AttachmentDeleteOwn // 删除自己的附件
 	AttachmentDeleteAny // 删除任意附件
 
+	/* Post 帖子扩展权限（追加在末尾，保持已存储的权限位不变） */
+
+	PostEditAny // 编辑任意帖子（含查看修订历史与回滚）
+
 	/* Other 其他权限 */
 
 	permMax // 权限校验边界
@@ -72,3 +76,13 @@ const (
 type PermissionGroup struct {
 	Permission Permission // 权限组
 }
+
+// Has 判断是否拥有指定的全部权限
+func (p Permission) Has(target Permission) bool {
+	return p&target == target
+}
+
+// HasAny 判断是否拥有指定权限中的任意一个
+func (p Permission) HasAny(target Permission) bool {
+	return p&target != 0
+}
@@ -16,6 +16,8 @@ import (
 )
 
 // ListMarkdownFiles 列出所有 Markdown 文件（支持子目录）
+//
+// Deprecated: 文档站点已改用 pkg/docsite
 func ListMarkdownFiles(mdDir string, c *gin.Context) {
 	files, err := getMarkdownFiles(mdDir)
 	if err != nil {
@@ -41,6 +43,8 @@ func ListMarkdownFiles(mdDir string, c *gin.Context) {
 }
 
 // RenderMarkdown 渲染 Markdown 文件（支持子目录）
+//
+// Deprecated: 文档站点已改用 pkg/docsite
 func RenderMarkdown(mdDir string, c *gin.Context) {
 	// 获取路径参数，支持子目录
 	pathParam := c.Param("filename")
@@ -49,7 +49,7 @@ func TestEncrypt(t *testing.T) {
 			salt:   "0011223344556677",
 			rounds: 1,
 			// 计算方式: sha256(hex"0011223344556677" + "password123")
-			expected: "f0f8b3a5e7e8c7a9c1e4e7d5e7c1a1d8e2c0a3d7e1c3a5d8e2c0a3d7e1c3a5d8",
+			expected: "49bc1872bfa05dbeff5762504a77893c3240aeae28d3e89073f4df3907d0f890",
 		},
 		{
 			name:   "MultipleRounds",
@@ -57,7 +57,7 @@ func TestEncrypt(t *testing.T) {
 			salt:   "",
 			rounds: 3,
 			// 计算方式: sha256(sha256(sha256("test")))
-			expected: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			expected: "be2ef4971b24405df0541f95fe6158ac661e69971337ff7260b219d1056ebc3b",
 		},
 		{
 			name:    "InvalidRounds",
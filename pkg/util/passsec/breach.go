@@ -0,0 +1,118 @@
+package passsec
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// BreachChecker 泄露密码查询（k-anonymity）
+//
+// 调用方只提交密码 SHA-1 的前 5 位十六进制，查询方返回同前缀哈希的后 35 位，
+// 由调用方在本地比对，密码与完整哈希都不离开调用方。
+// 本地列表与 Have I Been Pwned 的 range 接口都可以实现该接口。
+type BreachChecker interface {
+	Range(prefix string) ([]string, error)
+}
+
+// Breached 密码是否出现在泄露列表中
+func Breached(c BreachChecker, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	suffixes, err := c.Range(hash[:5])
+	if err != nil {
+		return false, err
+	}
+	for _, s := range suffixes {
+		if strings.EqualFold(s, hash[5:]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HashList 本地的泄露密码 SHA-1 列表，按前 5 位索引
+type HashList struct {
+	ranges map[string][]string
+}
+
+// NewHashList 创建空列表
+func NewHashList() *HashList {
+	return &HashList{ranges: map[string][]string{}}
+}
+
+// Parse 读取列表：每行一个 SHA-1 十六进制，可带 :出现次数，空行与 # 开头的行忽略
+func (h *HashList) Parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		s, _, _ = strings.Cut(s, ":")
+		if _, err := hex.DecodeString(s); err != nil || len(s) != sha1.Size*2 {
+			return fmt.Errorf("第 %d 行不是 SHA-1: %q", line, s)
+		}
+		s = strings.ToUpper(s)
+		h.ranges[s[:5]] = append(h.ranges[s[:5]], s[5:])
+	}
+	return scanner.Err()
+}
+
+// LoadFile 读取列表文件并合并到当前列表
+func (h *HashList) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := h.Parse(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Range 实现 BreachChecker
+func (h *HashList) Range(prefix string) ([]string, error) {
+	return h.ranges[strings.ToUpper(prefix)], nil
+}
+
+// Len 列表中的哈希数
+func (h *HashList) Len() int {
+	n := 0
+	for _, r := range h.ranges {
+		n += len(r)
+	}
+	return n
+}
+
+//go:embed data/common-passwords.sha1
+var commonPasswords string
+
+var commonList = sync.OnceValue(func() *HashList {
+	h := NewHashList()
+	if err := h.Parse(strings.NewReader(commonPasswords)); err != nil {
+		panic(err)
+	}
+	return h
+})
+
+// CommonPasswords 内置的常见弱密码列表，为共享实例，需要追加其他列表时使用 NewCommonHashList
+func CommonPasswords() *HashList {
+	return commonList()
+}
+
+// NewCommonHashList 包含内置常见弱密码的新列表，可继续加载其他列表文件
+func NewCommonHashList() *HashList {
+	h := NewHashList()
+	_ = h.Parse(strings.NewReader(commonPasswords))
+	return h
+}
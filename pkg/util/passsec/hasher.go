@@ -0,0 +1,308 @@
+package passsec
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+
+	"qwqserver/pkg/util/multih256"
+)
+
+// 自描述哈希格式
+//
+// 存储的密码哈希以 $<算法>$ 开头，参数与盐值都保存在哈希串中，验证时按前缀选择算法：
+//
+//	bcrypt     $2a$12$<盐值与哈希>
+//	argon2id   $argon2id$v=19$m=65536,t=3,p=2$<盐值 base64>$<哈希 base64>
+//	multih256  $multih256$r=3$<盐值 hex>$<哈希 hex>（旧方案，只用于验证与迁移）
+
+// 算法标识
+const (
+	AlgBcrypt    = "bcrypt"
+	AlgArgon2id  = "argon2id"
+	AlgMultiH256 = "multih256"
+)
+
+var (
+	// ErrUnknownHash 无法识别哈希的算法
+	ErrUnknownHash = errors.New("无法识别的密码哈希格式")
+	// ErrMalformedHash 哈希的参数或编码无效
+	ErrMalformedHash = errors.New("密码哈希格式无效")
+)
+
+// Hasher 密码哈希算法
+type Hasher interface {
+	// ID 算法标识
+	ID() string
+	// Hash 生成自描述格式的哈希
+	Hash(password string) (string, error)
+	// Verify 验证密码是否匹配该算法生成的哈希
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash 哈希的参数是否与当前配置不同
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	hashers   = map[string]Hasher{}
+	hasherIDs = map[string]string{} // 哈希前缀 -> 算法标识
+	current   Hasher
+	hasherMu  sync.RWMutex
+)
+
+func init() {
+	RegisterHasher(Bcrypt{Cost: DefaultCost}, "2a", "2b", "2y")
+	RegisterHasher(DefaultArgon2id)
+	RegisterHasher(MultiH256{Rounds: multih256.DefaultRounds, SaltLength: multih256.DefaultSaltLength})
+	current = hashers[AlgBcrypt]
+}
+
+// RegisterHasher 登记算法，prefixes 为哈希串中的前缀（$ 之间的部分），未指定时使用 ID；
+// 重复登记会替换同一算法的参数
+func RegisterHasher(h Hasher, prefixes ...string) {
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	if len(prefixes) == 0 {
+		prefixes = []string{h.ID()}
+	}
+	hashers[h.ID()] = h
+	for _, p := range prefixes {
+		hasherIDs[p] = h.ID()
+	}
+	if current != nil && current.ID() == h.ID() {
+		current = h
+	}
+}
+
+// SetHasher 设置生成新哈希使用的算法，同时登记其参数
+func SetHasher(h Hasher) {
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	if _, ok := hashers[h.ID()]; !ok {
+		hasherIDs[h.ID()] = h.ID()
+	}
+	hashers[h.ID()] = h
+	current = h
+}
+
+// CurrentHasher 生成新哈希使用的算法
+func CurrentHasher() Hasher {
+	hasherMu.RLock()
+	defer hasherMu.RUnlock()
+	return current
+}
+
+// LookupHasher 按算法标识查找已登记的算法
+func LookupHasher(id string) (Hasher, bool) {
+	hasherMu.RLock()
+	defer hasherMu.RUnlock()
+	h, ok := hashers[id]
+	return h, ok
+}
+
+// Identify 按哈希前缀识别算法
+func Identify(encoded string) (Hasher, error) {
+	if !strings.HasPrefix(encoded, "$") {
+		return nil, ErrUnknownHash
+	}
+	prefix, _, ok := strings.Cut(encoded[1:], "$")
+	if !ok {
+		return nil, ErrUnknownHash
+	}
+	hasherMu.RLock()
+	defer hasherMu.RUnlock()
+	if h, ok := hashers[hasherIDs[prefix]]; ok {
+		return h, nil
+	}
+	return nil, ErrUnknownHash
+}
+
+// HashPassword 使用当前算法生成密码哈希
+func HashPassword(password string) (string, error) {
+	return CurrentHasher().Hash(password)
+}
+
+// Verify 按哈希前缀选择算法验证密码，无法识别的格式返回 ErrUnknownHash
+func Verify(password, encoded string) (bool, error) {
+	h, err := Identify(encoded)
+	if err != nil {
+		return false, err
+	}
+	return h.Verify(password, encoded)
+}
+
+// NeedsRehash 哈希使用的算法或参数与当前配置不同时返回 true，应在验证通过后重新哈希
+func NeedsRehash(encoded string) bool {
+	h, err := Identify(encoded)
+	if err != nil {
+		return true
+	}
+	cur := CurrentHasher()
+	return h.ID() != cur.ID() || cur.NeedsRehash(encoded)
+}
+
+// Bcrypt bcrypt 算法
+type Bcrypt struct {
+	Cost int // 工作因子，0 为 DefaultCost
+}
+
+func (b Bcrypt) cost() int {
+	if b.Cost == 0 {
+		return DefaultCost
+	}
+	return b.Cost
+}
+
+// ID 实现 Hasher
+func (b Bcrypt) ID() string { return AlgBcrypt }
+
+// Hash 实现 Hasher
+func (b Bcrypt) Hash(password string) (string, error) { return Hash(password, b.cost()) }
+
+// Verify 实现 Hasher
+func (b Bcrypt) Verify(password, encoded string) (bool, error) { return Check(password, encoded) }
+
+// NeedsRehash 工作因子与配置不同
+func (b Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := GetCost(encoded)
+	return err != nil || cost != b.cost()
+}
+
+// Argon2id argon2id 算法
+type Argon2id struct {
+	Memory  uint32 // 内存（KiB）
+	Time    uint32 // 迭代次数
+	Threads uint8  // 并行度
+	SaltLen uint32 // 盐值长度（字节）
+	KeyLen  uint32 // 哈希长度（字节）
+}
+
+// DefaultArgon2id argon2id 的默认参数（64 MiB、3 次迭代、2 线程）
+var DefaultArgon2id = Argon2id{Memory: 64 * 1024, Time: 3, Threads: 2, SaltLen: 16, KeyLen: 32}
+
+// ID 实现 Hasher
+func (a Argon2id) ID() string { return AlgArgon2id }
+
+// Hash 实现 Hasher
+func (a Argon2id) Hash(password string) (string, error) {
+	if a.Memory == 0 || a.Time == 0 || a.Threads == 0 || a.SaltLen == 0 || a.KeyLen == 0 {
+		return "", fmt.Errorf("无效的 argon2id 参数: %+v", a)
+	}
+	salt := make([]byte, a.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成盐值失败: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Threads, a.KeyLen)
+	return a.encode(salt, key), nil
+}
+
+func (a Argon2id) encode(salt, key []byte) string {
+	b64 := base64.RawStdEncoding
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgArgon2id, argon2.Version, a.Memory, a.Time, a.Threads, b64.EncodeToString(salt), b64.EncodeToString(key))
+}
+
+// decodeArgon2id 解析 argon2id 哈希，返回其参数、盐值与哈希
+func decodeArgon2id(encoded string) (Argon2id, []byte, []byte, error) {
+	var p Argon2id
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgArgon2id {
+		return p, nil, nil, ErrMalformedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrMalformedHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+	b64 := base64.RawStdEncoding
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+	key, err := b64.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrMalformedHash
+	}
+	p.SaltLen, p.KeyLen = uint32(len(salt)), uint32(len(key))
+	return p, salt, key, nil
+}
+
+// Verify 实现 Hasher，使用哈希中记录的参数计算
+func (a Argon2id) Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash 内存、迭代次数、并行度或长度与配置不同
+func (a Argon2id) NeedsRehash(encoded string) bool {
+	p, _, _, err := decodeArgon2id(encoded)
+	return err != nil || p != a
+}
+
+// MultiH256 旧的多轮加盐 SHA-256 方案，只为兼容已有数据，不应用作当前算法
+type MultiH256 struct {
+	Rounds     int // 哈希轮次
+	SaltLength int // 盐值长度（字节）
+}
+
+// ID 实现 Hasher
+func (m MultiH256) ID() string { return AlgMultiH256 }
+
+// Hash 实现 Hasher
+func (m MultiH256) Hash(password string) (string, error) {
+	hash, salt, err := multih256.EncryptWithSalt(password, m.SaltLength, m.Rounds)
+	if err != nil {
+		return "", err
+	}
+	return EncodeMultiH256(hash, salt, m.Rounds), nil
+}
+
+// EncodeMultiH256 将旧方案分列保存的哈希、盐值与轮次转为自描述格式
+func EncodeMultiH256(hash, salt string, rounds int) string {
+	return fmt.Sprintf("$%s$r=%d$%s$%s", AlgMultiH256, rounds, salt, hash)
+}
+
+// decodeMultiH256 解析 multih256 哈希，返回轮次、盐值与哈希
+func decodeMultiH256(encoded string) (rounds int, salt, hash string, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[1] != AlgMultiH256 || !strings.HasPrefix(parts[2], "r=") {
+		return 0, "", "", ErrMalformedHash
+	}
+	if rounds, err = strconv.Atoi(parts[2][2:]); err != nil || rounds < 1 {
+		return 0, "", "", ErrMalformedHash
+	}
+	return rounds, parts[3], parts[4], nil
+}
+
+// Verify 实现 Hasher
+func (m MultiH256) Verify(password, encoded string) (bool, error) {
+	rounds, salt, hash, err := decodeMultiH256(encoded)
+	if err != nil {
+		return false, err
+	}
+	return multih256.Verify(password, hash, salt, rounds)
+}
+
+// NeedsRehash 轮次或盐值长度与配置不同
+func (m MultiH256) NeedsRehash(encoded string) bool {
+	rounds, salt, _, err := decodeMultiH256(encoded)
+	return err != nil || rounds != m.Rounds || len(salt) != m.SaltLength*2
+}
+
+var (
+	_ Hasher = Bcrypt{}
+	_ Hasher = Argon2id{}
+	_ Hasher = MultiH256{}
+)
@@ -15,6 +15,12 @@ import (
 // 默认工作因子 - 平衡安全性和性能
 const DefaultCost = 12
 
+// bcrypt 工作因子的有效范围
+const (
+	MinCost = bcrypt.MinCost
+	MaxCost = bcrypt.MaxCost
+)
+
 // PasswordStrength 密码强度级别
 type PasswordStrength int
 
@@ -0,0 +1,198 @@
+package passsec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// 策略项，用于 Violation.Rule
+const (
+	RuleMinLength = "min_length" // 长度不足，参数为最小长度
+	RuleMaxLength = "max_length" // 超过最大长度，参数为最大字节数
+	RuleClasses   = "classes"    // 字符种类不足，参数为最少种类数
+	RuleStrength  = "strength"   // 强度不足，参数为最低强度名称
+	RuleUserInfo  = "user_info"  // 包含用户名或邮箱
+	RuleBreached  = "breached"   // 出现在泄露或常见密码列表中
+	RuleReused    = "reused"     // 与最近使用过的密码相同，参数为检查的历史数量
+)
+
+// Policy 密码策略，零值表示不做任何限制
+type Policy struct {
+	MinStrength      PasswordStrength // 最低强度（CheckStrength 的结果）
+	MinLength        int              // 最小长度（字符数）
+	MaxLength        int              // 最大长度（字节数，bcrypt 只使用前 72 字节），0 为不限
+	MinClasses       int              // 至少包含的字符种类数：小写字母、大写字母、数字、符号
+	DisallowUserInfo bool             // 禁止包含用户名或邮箱的用户名部分（不区分大小写，3 个字符以上时检查）
+	Breached         BreachChecker    // 泄露与常见密码列表，nil 为不检查
+	HistorySize      int              // 禁止与最近 N 个密码相同，0 为不检查
+	MaxAge           time.Duration    // 密码有效期，0 为不过期
+}
+
+// DefaultPolicy 默认密码策略
+var DefaultPolicy = Policy{
+	MinStrength:      Moderate,
+	MinLength:        8,
+	MaxLength:        72,
+	MinClasses:       2,
+	DisallowUserInfo: true,
+	Breached:         CommonPasswords(),
+	HistorySize:      5,
+}
+
+var (
+	currentPolicy   = DefaultPolicy
+	currentPolicyMu sync.RWMutex
+)
+
+// SetPolicy 设置全局密码策略
+func SetPolicy(p Policy) {
+	currentPolicyMu.Lock()
+	defer currentPolicyMu.Unlock()
+	currentPolicy = p
+}
+
+// CurrentPolicy 全局密码策略
+func CurrentPolicy() Policy {
+	currentPolicyMu.RLock()
+	defer currentPolicyMu.RUnlock()
+	return currentPolicy
+}
+
+// UserInfo 检查密码是否包含用户信息时使用
+type UserInfo struct {
+	Username string
+	Email    string
+}
+
+// Violation 未满足的策略项
+type Violation struct {
+	Rule  string
+	Param string
+}
+
+// Violations 全部未满足的策略项
+type Violations []Violation
+
+func (v Violations) Error() string {
+	parts := make([]string, len(v))
+	for i, item := range v {
+		parts[i] = item.Rule
+		if item.Param != "" {
+			parts[i] += "=" + item.Param
+		}
+	}
+	return "密码不符合策略: " + strings.Join(parts, ", ")
+}
+
+// Check 检查新密码，返回全部未满足的策略项；history 为最近使用过的密码哈希（新的在前）
+// 泄露列表查询失败时返回错误，此时不判定为违反策略
+func (p Policy) Check(password string, user UserInfo, history []string) (Violations, error) {
+	var v Violations
+	if n := len([]rune(password)); n < p.MinLength {
+		v = append(v, Violation{Rule: RuleMinLength, Param: strconv.Itoa(p.MinLength)})
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		v = append(v, Violation{Rule: RuleMaxLength, Param: strconv.Itoa(p.MaxLength)})
+	}
+	if p.MinClasses > 0 && CharClasses(password) < p.MinClasses {
+		v = append(v, Violation{Rule: RuleClasses, Param: strconv.Itoa(p.MinClasses)})
+	}
+	if CheckStrength(password) < p.MinStrength {
+		v = append(v, Violation{Rule: RuleStrength, Param: p.MinStrength.Name()})
+	}
+	if p.DisallowUserInfo && containsUserInfo(password, user) {
+		v = append(v, Violation{Rule: RuleUserInfo})
+	}
+	if p.Breached != nil {
+		breached, err := Breached(p.Breached, password)
+		if err != nil {
+			return v, fmt.Errorf("查询泄露密码列表失败: %w", err)
+		}
+		if breached {
+			v = append(v, Violation{Rule: RuleBreached})
+		}
+	}
+	if p.HistorySize > 0 {
+		if len(history) > p.HistorySize {
+			history = history[:p.HistorySize]
+		}
+		for _, hashed := range history {
+			if ok, _ := Verify(password, hashed); ok {
+				v = append(v, Violation{Rule: RuleReused, Param: strconv.Itoa(p.HistorySize)})
+				break
+			}
+		}
+	}
+	return v, nil
+}
+
+// Expired 在 changedAt 修改的密码到 now 时是否已过期
+func (p Policy) Expired(changedAt, now time.Time) bool {
+	return p.MaxAge > 0 && !changedAt.IsZero() && now.Sub(changedAt) > p.MaxAge
+}
+
+// CharClasses 密码包含的字符种类数：小写字母、大写字母、数字、其他字符
+func CharClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// containsUserInfo 密码是否包含用户名或邮箱的用户名部分，过短的信息不检查以免误判
+func containsUserInfo(password string, user UserInfo) bool {
+	lower := strings.ToLower(password)
+	local, _, _ := strings.Cut(user.Email, "@")
+	for _, info := range []string{user.Username, local} {
+		if info = strings.ToLower(strings.TrimSpace(info)); len([]rune(info)) >= 3 && strings.Contains(lower, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// 密码强度名称，用于配置与规则参数
+var strengthNames = []string{"veryweak", "weak", "moderate", "strong", "verystrong"}
+
+// Name 强度的英文名称：veryweak、weak、moderate、strong、verystrong
+func (s PasswordStrength) Name() string {
+	if s >= 0 && int(s) < len(strengthNames) {
+		return strengthNames[s]
+	}
+	return strconv.Itoa(int(s))
+}
+
+// ParseStrength 解析密码强度：名称（不区分大小写）或数值（0-4）
+func ParseStrength(s string) (PasswordStrength, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range strengthNames {
+		if s == name {
+			return PasswordStrength(i), nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < int(VeryWeak) || n > int(VeryStrong) {
+		return 0, fmt.Errorf("未知的密码强度 %q", s)
+	}
+	return PasswordStrength(n), nil
+}
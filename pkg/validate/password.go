@@ -0,0 +1,31 @@
+package validate
+
+import (
+	"reflect"
+
+	"qwqserver/pkg/util/passsec"
+)
+
+// RegisterPasswordRule 登记 password=level 规则：passsec.CheckStrength 的结果不低于 level（名称或 0-4），
+// 未指定 level 时使用全局密码策略的最低强度
+func (v *Validator) RegisterPasswordRule() {
+	v.RegisterRule("password", func(f reflect.Value, p string) bool {
+		if f.Kind() != reflect.String {
+			return true
+		}
+		min := passsec.CurrentPolicy().MinStrength
+		if p != "" {
+			min, _ = passsec.ParseStrength(p)
+		}
+		return passsec.CheckStrength(f.String()) >= min
+	})
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.params["password"] = func(p string) error {
+		if p == "" {
+			return nil
+		}
+		_, err := passsec.ParseStrength(p)
+		return err
+	}
+}
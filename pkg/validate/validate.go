@@ -0,0 +1,430 @@
+// Package validate 基于结构体标签的参数校验
+//
+// 规则写在 binding 标签中，以逗号分隔，与 gin 的绑定标签一致：
+//
+//	type RegisterRequest struct {
+//		Username string `json:"username" binding:"required,min=3,max=32,pattern=username"`
+//		Email    string `json:"email" binding:"required,email"`
+//		Format   string `json:"format" binding:"omitempty,oneof=markdown html"`
+//		Password string `json:"password" binding:"required,password=moderate"`
+//	}
+//
+// 一次校验返回全部字段的错误（Errors），字段名取 json 标签，其次 form 标签，都未设置时使用字段名；
+// 嵌套的结构体与结构体切片逐层校验，字段名为 a.b、items[0].name 形式。
+// Validator 实现了 gin 的 binding.StructValidator，设置为 binding.Validator 后 JSON 与查询参数绑定都会校验。
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"qwqserver/pkg/util"
+)
+
+// TagName 规则所在的结构体标签
+const TagName = "binding"
+
+// FieldError 字段校验错误
+type FieldError struct {
+	Field string // 字段名，嵌套字段为 a.b
+	Rule  string // 未通过的规则，如 required、min
+	Param string // 规则参数，如 min=3 中的 3
+	Kind  string // 字段类别：string、number、array、object，用于选择提示消息
+}
+
+func (e FieldError) Error() string {
+	if e.Param == "" {
+		return e.Field + ": " + e.Rule
+	}
+	return e.Field + ": " + e.Rule + "=" + e.Param
+}
+
+// Errors 全部字段的校验错误
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Error()
+	}
+	return "参数校验失败: " + strings.Join(parts, "; ")
+}
+
+// TagError 规则声明错误（未知规则、参数格式错误），属于程序错误而非请求错误
+type TagError struct {
+	Type  reflect.Type
+	Field string
+	Err   error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("%s.%s 的校验规则错误: %v", e.Type, e.Field, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// RuleFunc 规则函数，v 为字段值（已解引用），返回是否通过
+type RuleFunc func(v reflect.Value, param string) bool
+
+// Validator 校验器，规则与命名正则可扩展，并发安全
+type Validator struct {
+	mu       sync.RWMutex
+	rules    map[string]RuleFunc
+	patterns map[string]*regexp.Regexp
+	params   map[string]func(param string) error // 规则参数的检查，在解析标签时执行
+	cache    sync.Map                            // reflect.Type → []field
+}
+
+// New 创建包含内置规则的校验器
+//   - required：非零值（字符串非空、指针非 nil、切片非空）
+//   - omitempty：零值时跳过其余规则
+//   - min / max / len：字符串按字符数、数字按数值、切片与映射按元素数
+//   - email：邮箱格式
+//   - oneof=a b c：枚举，空格分隔
+//   - pattern=name：匹配以 RegisterPattern 登记的命名正则
+//   - password=level：密码强度不低于 level（见 RegisterPasswordRule）
+func New() *Validator {
+	v := &Validator{
+		rules:    map[string]RuleFunc{},
+		patterns: map[string]*regexp.Regexp{},
+		params:   map[string]func(string) error{},
+	}
+	v.RegisterRule("required", func(f reflect.Value, _ string) bool { return !isZero(f) })
+	v.RegisterRule("min", func(f reflect.Value, p string) bool { n, ok := measure(f); return !ok || n >= mustFloat(p) })
+	v.RegisterRule("max", func(f reflect.Value, p string) bool { n, ok := measure(f); return !ok || n <= mustFloat(p) })
+	v.RegisterRule("len", func(f reflect.Value, p string) bool { n, ok := measure(f); return !ok || n == mustFloat(p) })
+	v.RegisterRule("email", func(f reflect.Value, _ string) bool {
+		return f.Kind() != reflect.String || util.IsEmail(f.String())
+	})
+	v.RegisterRule("oneof", func(f reflect.Value, p string) bool {
+		s := fmt.Sprint(f.Interface())
+		for _, o := range strings.Fields(p) {
+			if s == o {
+				return true
+			}
+		}
+		return false
+	})
+	v.RegisterRule("pattern", func(f reflect.Value, p string) bool {
+		v.mu.RLock()
+		re := v.patterns[p]
+		v.mu.RUnlock()
+		return f.Kind() != reflect.String || re.MatchString(f.String())
+	})
+	for _, rule := range []string{"min", "max", "len"} {
+		v.params[rule] = func(p string) error { _, err := strconv.ParseFloat(p, 64); return err }
+	}
+	v.params["oneof"] = func(p string) error {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("oneof 缺少可选值")
+		}
+		return nil
+	}
+	v.params["pattern"] = func(p string) error {
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		if _, ok := v.patterns[p]; !ok {
+			return fmt.Errorf("未登记的正则 %q", p)
+		}
+		return nil
+	}
+	v.RegisterPattern("username", `^[A-Za-z0-9_\-]+$`)
+	v.RegisterPattern("slug", `^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	v.RegisterPasswordRule()
+	return v
+}
+
+// Default 默认校验器
+var Default = New()
+
+// Struct 使用默认校验器校验结构体
+func Struct(obj any) error {
+	return Default.Struct(obj)
+}
+
+// RegisterRule 登记规则，已有的同名规则被覆盖；应在首次校验前登记，已解析的结构体不会重新解析
+func (v *Validator) RegisterRule(name string, fn RuleFunc) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = fn
+}
+
+// RegisterPattern 登记命名正则，供 pattern=name 使用；表达式错误时 panic
+func (v *Validator) RegisterPattern(name, expr string) {
+	re := regexp.MustCompile(expr)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.patterns[name] = re
+}
+
+// Struct 校验结构体（或其指针），未通过时返回 Errors，规则声明错误时返回 *TagError
+func (v *Validator) Struct(obj any) error {
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs Errors
+	if err := v.validateStruct(rv, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateStruct 实现 gin 的 binding.StructValidator：结构体直接校验，切片与数组逐个元素校验
+func (v *Validator) ValidateStruct(obj any) error {
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return v.Struct(rv.Interface())
+	case reflect.Slice, reflect.Array:
+		var errs Errors
+		for i := 0; i < rv.Len(); i++ {
+			if err := v.validateValue(rv.Index(i), "["+strconv.Itoa(i)+"]", &errs); err != nil {
+				return err
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+	return nil
+}
+
+// Engine 实现 gin 的 binding.StructValidator
+func (v *Validator) Engine() any {
+	return v
+}
+
+// rule 解析后的一条规则
+type rule struct {
+	name  string
+	param string
+	fn    RuleFunc
+}
+
+// field 解析后的字段
+type field struct {
+	index     int
+	name      string // 对外的字段名，匿名嵌入的结构体为空
+	omitEmpty bool
+	rules     []rule
+}
+
+// fields 解析结构体的字段规则，结果按类型缓存
+func (v *Validator) fields(t reflect.Type) ([]field, error) {
+	if cached, ok := v.cache.Load(t); ok {
+		return cached.([]field), nil
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+		f := field{index: i, name: fieldName(sf)}
+		if f.name == "-" {
+			continue
+		}
+		for _, part := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch name {
+			case "":
+				continue
+			case "omitempty":
+				f.omitEmpty = true
+				continue
+			}
+			v.mu.RLock()
+			fn, ok := v.rules[name]
+			check := v.params[name]
+			v.mu.RUnlock()
+			if !ok {
+				return nil, &TagError{Type: t, Field: sf.Name, Err: fmt.Errorf("未知规则 %q", name)}
+			}
+			if check != nil {
+				if err := check(param); err != nil {
+					return nil, &TagError{Type: t, Field: sf.Name, Err: err}
+				}
+			}
+			f.rules = append(f.rules, rule{name: name, param: param, fn: fn})
+		}
+		fields = append(fields, f)
+	}
+	v.cache.Store(t, fields)
+	return fields, nil
+}
+
+// fieldName 字段对外的名称：json 标签、form 标签、字段名；匿名嵌入且未改名的结构体返回空，其字段直接展开
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(key), ","); name != "" {
+			return name
+		}
+	}
+	if sf.Anonymous {
+		return ""
+	}
+	return sf.Name
+}
+
+func (v *Validator) validateStruct(rv reflect.Value, prefix string, errs *Errors) error {
+	fields, err := v.fields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		fv := rv.Field(f.index)
+		path := joinPath(prefix, f.name)
+		if f.omitEmpty && isZero(fv) {
+			continue
+		}
+		failed := false
+		for _, r := range f.rules {
+			if r.name != "required" && isNilPointer(fv) {
+				// 可选的指针字段未提供时只检查 required
+				continue
+			}
+			if !r.fn(indirect(fv), r.param) {
+				*errs = append(*errs, FieldError{Field: path, Rule: r.name, Param: r.param, Kind: kindOf(fv)})
+				failed = true
+				break
+			}
+		}
+		if !failed {
+			if err := v.validateValue(fv, path, errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateValue 校验嵌套的结构体与结构体切片
+func (v *Validator) validateValue(fv reflect.Value, path string, errs *Errors) error {
+	fv = indirect(fv)
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() == timeType {
+			return nil
+		}
+		return v.validateStruct(fv, path, errs)
+	case reflect.Slice, reflect.Array:
+		et := fv.Type().Elem()
+		for et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+		if et.Kind() != reflect.Struct || et == timeType {
+			return nil
+		}
+		for i := 0; i < fv.Len(); i++ {
+			if err := v.validateValue(fv.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func joinPath(prefix, name string) string {
+	switch {
+	case name == "":
+		return prefix
+	case prefix == "" || strings.HasPrefix(name, "["):
+		return prefix + name
+	}
+	return prefix + "." + name
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func isNilPointer(v reflect.Value) bool {
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Invalid:
+		return true
+	}
+	return v.IsZero()
+}
+
+// measure min / max / len 比较的量：字符串的字符数、数字的数值、切片与映射的元素数
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+// mustFloat 规则参数在解析标签时已检查
+func mustFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// kindOf 字段类别，用于选择提示消息（如字符串的 min 为长度，数字的 min 为数值）
+func kindOf(v reflect.Value) string {
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "array"
+	}
+	return "object"
+}
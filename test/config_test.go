@@ -0,0 +1,215 @@
+package qwqtest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"qwqserver/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(file, []byte("listen:\n    port: 6000\n    log_level: info\ndatabase:\n    driver: postgres\n    dsn: host=db password=yaml\nredis:\n    db: 1\n"), 0644)
+	os.WriteFile(envFile, []byte("# comment\nexport DB_LOG_LEVEL=error\nREDIS_DB='2'\nSERVER_LOG_LEVEL=\"warn\" \n"), 0644)
+
+	l, err := config.Load(config.Options{
+		File:    file,
+		EnvFile: envFile,
+		Environ: []string{"REDIS_DB=3", "DB_DSN=host=db password=env"},
+		Args:    []string{"-listen.port", "7000", "-database.auto_migrate=false"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := l.Config
+	checks := []struct {
+		key    string
+		got    any
+		want   any
+		source config.Source
+	}{
+		{"listen.port", cfg.Listen.Port, 7000, config.SourceFlag},
+		{"listen.log_level", cfg.Listen.LogLevel, "warn", config.SourceDotEnv},
+		{"database.driver", cfg.Database.Driver, "postgres", config.SourceYAML},
+		{"database.dsn", cfg.Database.DSN, "host=db password=env", config.SourceEnv},
+		{"database.log_level", cfg.Database.LogLevel, "error", config.SourceDotEnv},
+		{"database.auto_migrate", cfg.Database.AutoMigrate, false, config.SourceFlag},
+		{"redis.db", cfg.Redis.DB, 3, config.SourceEnv},
+		{"redis.dial_timeout", cfg.Redis.DialTimeout, 5 * time.Second, config.SourceDefault},
+		// qwq-default 缺省时使用 env-default
+		{"listen.mode", cfg.Listen.Mode, "debug", config.SourceDefault},
+	}
+	for _, c := range checks {
+		if c.got != c.want || l.Origins[c.key].Source != c.source {
+			t.Errorf("%s = %v (%s), want %v (%s)", c.key, c.got, l.Origins[c.key], c.want, c.source)
+		}
+	}
+
+	var buf bytes.Buffer
+	l.Print(&buf)
+	out := buf.String()
+	if strings.Contains(out, "password=env") || !strings.Contains(out, "******") {
+		t.Errorf("敏感值未掩码:\n%s", out)
+	}
+	if !strings.Contains(out, "# flag -listen.port") || !strings.Contains(out, "# env DB_DSN") {
+		t.Errorf("缺少来源:\n%s", out)
+	}
+}
+
+func TestConfigLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+
+	// 文件不存在时以默认值创建
+	l, err := config.Load(config.Options{File: file, EnvFile: filepath.Join(dir, ".env"), Environ: []string{}})
+	if err != nil || l.Config.Listen.Port != 5000 {
+		t.Fatalf("Load = %+v %v", l, err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("未创建默认配置: %v", err)
+	}
+
+	var out bytes.Buffer
+	if _, err := config.Load(config.Options{File: file, Environ: []string{}, Args: []string{"-listen.port", "abc"}, Output: &out}); err == nil {
+		t.Error("非法参数应报错")
+	}
+	if _, err := config.Load(config.Options{File: file, Environ: []string{"SERVER_PORT=abc"}}); err == nil || !strings.Contains(err.Error(), "SERVER_PORT") {
+		t.Errorf("非法环境变量 err = %v", err)
+	}
+	if _, err := config.ParseEnv(strings.NewReader("NOVALUE\n")); err == nil {
+		t.Error("缺少 = 应报错")
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	write := func(s string) {
+		if err := os.WriteFile(file, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("listen:\n    log_level: info\n    queue_limit_max_concurrent: 10\ndatabase:\n    driver: mysql\n")
+	opts := config.Options{File: file, EnvFile: filepath.Join(dir, ".env"), Environ: []string{}}
+	l, err := config.Load(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := config.NewWatcher(l, opts, nil)
+
+	var got []config.Diff
+	w.Subscribe(func(cfg *config.Config, d config.Diff) { got = append(got, d) }, "listen.")
+	var passwordCalls int
+	w.Subscribe(func(cfg *config.Config, d config.Diff) { passwordCalls++ }, "password.")
+
+	// 不支持热更新的 database.driver 保持原值，其余变化生效并通知相关订阅者
+	write("listen:\n    log_level: warn\n    queue_limit_max_concurrent: 20\ndatabase:\n    driver: postgres\n")
+	diff, err := w.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 2 || !diff.Changed("listen.log_level") || diff.Changed("database.") {
+		t.Errorf("diff = %v", diff)
+	}
+	cfg := w.Config()
+	if cfg.Listen.LogLevel != "warn" || cfg.Listen.QueueLimitMaxConcurrent != 20 || cfg.Database.Driver != "mysql" {
+		t.Errorf("cfg = %+v %+v", cfg.Listen, cfg.Database)
+	}
+	if len(got) != 1 || passwordCalls != 0 {
+		t.Errorf("subscribers = %v %d", got, passwordCalls)
+	}
+
+	// 校验失败时保留当前配置
+	write("listen:\n    log_level: loud\n")
+	if _, err := w.Reload(); err == nil || !strings.Contains(err.Error(), "listen.log_level") {
+		t.Errorf("err = %v", err)
+	}
+	if w.Config() != cfg || len(got) != 1 {
+		t.Error("校验失败的配置不应生效")
+	}
+
+	// 与当前配置相同时不通知
+	write("listen:\n    log_level: warn\n    queue_limit_max_concurrent: 20\ndatabase:\n    driver: mysql\n")
+	if diff, err := w.Reload(); err != nil || len(diff) != 0 || len(got) != 1 {
+		t.Errorf("diff = %v %v", diff, err)
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	cfg, err := config.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("默认配置应通过校验: %v", err)
+	}
+
+	cfg.Listen.Port = 70000
+	cfg.Database.Driver = "oracle"
+	cfg.Database.DSN = ""
+	cfg.Database.ConnMaxLifetime = 30 * time.Second
+	cfg.Database.MaxIdleConns = 100
+	cfg.Password.BcryptCost = 40
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("应校验失败")
+	}
+	for _, want := range []string{
+		"listen.port: 不能大于 65535",
+		`database.driver: 必须是 mysql/postgres/sqlite 之一，当前为 "oracle"`,
+		"database.dsn: 不能为空",
+		"database.conn_max_lifetime: 不能小于 1m，当前为 30s",
+		"database.max_idle_conns: 不能大于 max_open_conns",
+		"password.bcrypt_cost: 不能大于 31",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("缺少 %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestConfigSecretRefs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	secret := filepath.Join(dir, "redis")
+	os.WriteFile(secret, []byte("from-file\n"), 0600)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_PASSWORD=from-dotenv\n"), 0644)
+	os.WriteFile(file, []byte("database:\n    dsn: root:${env:DB_PASSWORD}@tcp(db)/qwq\n"+
+		"redis:\n    password: ${file:"+secret+"}\nrender:\n    tag_url: /t/$${env:LITERAL}\n"), 0644)
+
+	opts := config.Options{File: file, EnvFile: filepath.Join(dir, ".env"), Environ: []string{}}
+	l, err := config.Load(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Config.Database.DSN != "root:from-dotenv@tcp(db)/qwq" || l.Config.Redis.Password != "from-file" {
+		t.Errorf("dsn = %q, redis = %q", l.Config.Database.DSN, l.Config.Redis.Password)
+	}
+	if l.Config.Render.TagURL != "/t/${env:LITERAL}" {
+		t.Errorf("转义 = %q", l.Config.Render.TagURL)
+	}
+	if refs := l.Origins["database.dsn"].Refs; len(refs) != 1 || refs[0] != "env:DB_PASSWORD" {
+		t.Errorf("refs = %v", refs)
+	}
+
+	// 环境变量优先于 .env
+	opts.Environ = []string{"DB_PASSWORD=from-env"}
+	if l, err = config.Load(opts); err != nil || l.Config.Database.DSN != "root:from-env@tcp(db)/qwq" {
+		t.Errorf("dsn = %v %v", l, err)
+	}
+
+	os.Remove(secret)
+	if _, err := config.Load(opts); err == nil || !strings.Contains(err.Error(), "redis.password") {
+		t.Errorf("缺少密钥文件 err = %v", err)
+	}
+	opts.EnvFile, opts.Environ = filepath.Join(dir, "none"), []string{}
+	os.WriteFile(secret, []byte("x"), 0600)
+	if _, err := config.Load(opts); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD 未设置") {
+		t.Errorf("缺少环境变量 err = %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package qwqtest
+
+import (
+	"context"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/perm"
+	"qwqserver/pkg/util/passsec"
+	"strconv"
+	"testing"
+)
+
+// newFakeDeps 只含内存用户仓库的服务依赖，返回普通用户与管理员
+func newFakeDeps(t *testing.T) (*service.Deps, *model.User, *model.User) {
+	t.Helper()
+	hash, err := passsec.HashPassword("Old-Secret-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Username: "alice", Email: "alice@example.com", Password: hash}
+	admin := &model.User{Username: "admin", Email: "admin@example.com", Perms: uint64(perm.UserProfileEditAny)}
+	return service.NewDeps(&repository.Repositories{Users: repotest.NewUsers(user, admin)}), user, admin
+}
+
+func TestDepsFakeUsers(t *testing.T) {
+	d, user, admin := newFakeDeps(t)
+
+	t.Run("reset requires permission", func(t *testing.T) {
+		s := &service.ResetPasswordService{UserID: admin.ID, NewPassword: "Brand-New-Pass-7"}
+		if res := s.Reset(d, user.ID); res.Error != errcode.AuthPermissionDenied {
+			t.Fatalf("期望 %s，实际 %+v", errcode.AuthPermissionDenied, res)
+		}
+	})
+
+	t.Run("change password", func(t *testing.T) {
+		wrong := &service.ChangePasswordService{OldPassword: "wrong", NewPassword: "Brand-New-Pass-7"}
+		if res := wrong.Change(d, user.ID); res.Error != errcode.AuthInvalidCredentials {
+			t.Fatalf("期望 %s，实际 %+v", errcode.AuthInvalidCredentials, res)
+		}
+		s := &service.ChangePasswordService{OldPassword: "Old-Secret-42", NewPassword: "Brand-New-Pass-7"}
+		if res := s.Change(d, user.ID); res.Error != "" {
+			t.Fatalf("修改密码失败: %+v", res)
+		}
+		got, _ := d.Repos.Users.FindByID(context.Background(), user.ID)
+		if ok, _ := passsec.Verify("Brand-New-Pass-7", got.Password); !ok || got.PasswordChangedAt == nil {
+			t.Fatalf("新密码未保存: %+v", got)
+		}
+	})
+
+	t.Run("reset rejects reused password", func(t *testing.T) {
+		s := &service.ResetPasswordService{UserID: user.ID, NewPassword: "Brand-New-Pass-7"}
+		if res := s.Reset(d, admin.ID); res.Error != errcode.PasswordPolicy {
+			t.Fatalf("期望 %s，实际 %+v", errcode.PasswordPolicy, res)
+		}
+	})
+
+	t.Run("user locale", func(t *testing.T) {
+		id := strconv.FormatUint(uint64(user.ID), 10)
+		if lang := d.UserLocale(context.Background(), id); lang != "" {
+			t.Fatalf("期望未设置语言偏好，实际 %q", lang)
+		}
+		s := &service.AuthService{Locale: "en-us"}
+		if res := s.SetLocale(d, user.ID); res.Error != "" {
+			t.Fatalf("设置语言偏好失败: %+v", res)
+		}
+		if lang := d.UserLocale(context.Background(), id); lang != "en-US" {
+			t.Fatalf("期望 en-US，实际 %q", lang)
+		}
+	})
+}
+
+// TestDepsIsolated 两组依赖使用各自的数据库，互不可见
+func TestDepsIsolated(t *testing.T) {
+	ctx := context.Background()
+	a, b := service.NewDeps(repotest.Open(t)), service.NewDeps(repotest.Open(t))
+
+	if err := a.Repos.Users.Create(ctx, &model.User{Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.Repos.Users.FindByUsername(ctx, "alice"); err != nil || got != nil {
+		t.Fatalf("另一个数据库不应看到该用户: %+v, %v", got, err)
+	}
+	if err := b.Repos.Users.Create(ctx, &model.User{Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatalf("另一个数据库中同名用户应能创建: %v", err)
+	}
+	if res := service.FeaturedList(a, 1, 10); res.Error != "" {
+		t.Fatalf("精华帖子列表失败: %+v", res)
+	}
+}
@@ -0,0 +1,136 @@
+package qwqtest
+
+import (
+	"fmt"
+	"math/rand"
+	"qwqserver/pkg/diff"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiffUnified(t *testing.T) {
+	a := "a\nb\nc\n"
+	b := "a\nB\nc\nd\n"
+	want := "--- v1\n+++ v2\n@@ -1,3 +1,4 @@\n a\n-b\n+B\n c\n+d\n"
+	if got := diff.Unified("v1", "v2", a, b, 3); got != want {
+		t.Fatalf("统一格式差异错误:\n%s\nwant:\n%s", got, want)
+	}
+	if got := diff.Unified("v1", "v2", a, a, 3); got != "" {
+		t.Fatalf("相同文本应无差异: %q", got)
+	}
+}
+
+func TestDiffHunksSplit(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n"
+	hunks := diff.Hunks(a, b, 1, false)
+	if len(hunks) != 2 {
+		t.Fatalf("期望 2 个差异块，实际 %d", len(hunks))
+	}
+	if h := hunks[1].Header(); h != "@@ -9,2 +9,2 @@" {
+		t.Fatalf("差异块头部错误: %s", h)
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	hunks := diff.Hunks("今天天气很好 ok\n", "今天天气不错 ok\n", 3, true)
+	if len(hunks) != 1 || len(hunks[0].Lines) != 2 {
+		t.Fatalf("差异块错误: %+v", hunks)
+	}
+	ins := hunks[0].Lines[1]
+	if ins.Op != diff.OpInsert || len(ins.Words) != 3 ||
+		ins.Words[1].Op != diff.OpInsert || ins.Words[1].Text != "不错" {
+		t.Fatalf("按词差异错误: %+v", ins.Words)
+	}
+}
+
+// lcs 最长公共子序列长度，用于校验编辑脚本最短
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
+
+func TestDiffMinimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		lines := make([]string, rnd.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rnd.Intn(3)))
+		}
+		return lines
+	}
+	for i := 0; i < 2000; i++ {
+		al, bl := gen(), gen()
+		a, b := strings.Join(al, "\n"), strings.Join(bl, "\n")
+		var oldLines, newLines []string
+		changed := 0
+		for _, h := range diff.Hunks(a, b, 100, false) {
+			for _, l := range h.Lines {
+				if l.Op != diff.OpInsert {
+					oldLines = append(oldLines, l.Text)
+				}
+				if l.Op != diff.OpDelete {
+					newLines = append(newLines, l.Text)
+				}
+				if l.Op != diff.OpEqual {
+					changed++
+				}
+			}
+		}
+		if changed == 0 {
+			oldLines, newLines = al, bl
+		}
+		if strings.Join(oldLines, "\n") != a || strings.Join(newLines, "\n") != b {
+			t.Fatalf("编辑脚本无法还原: %q → %q", a, b)
+		}
+		if want := len(al) + len(bl) - 2*lcs(al, bl); changed != want {
+			t.Fatalf("%q → %q 改动 %d 行，最短为 %d 行", a, b, changed, want)
+		}
+	}
+}
+
+func TestDiffLarge(t *testing.T) {
+	lines := func(prefix string, n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&sb, "%s%d\n", prefix, i)
+		}
+		return sb.String()
+	}
+
+	// 没有公共行的两段长文本，内存占用与行数线性相关
+	a, b := lines("a", 4000), lines("b", 4000)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	hunks := diff.Hunks(a, b, 3, true)
+	runtime.ReadMemStats(&after)
+	if len(hunks) != 1 || len(hunks[0].Lines) != 8000 {
+		t.Fatalf("差异块错误: %d", len(hunks))
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 32<<20 {
+		t.Fatalf("分配内存过多: %d", alloc)
+	}
+
+	// 超过大小限制时整体替换
+	a, b = lines("x", diff.MaxLines+1), lines("y", 1)+lines("x", diff.MaxLines)
+	if !diff.TooLarge(a, b) {
+		t.Fatal("应超过大小限制")
+	}
+	hunks = diff.Hunks(a, b, 3, true)
+	if len(hunks) != 1 || hunks[0].OldLines != diff.MaxLines+1 || hunks[0].NewLines != diff.MaxLines+1 {
+		t.Fatalf("超过限制时应整体替换: %+v", hunks[0].Header())
+	}
+}
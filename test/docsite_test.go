@@ -0,0 +1,197 @@
+package qwqtest
+
+import (
+	"os"
+	"path/filepath"
+	"qwqserver/pkg/docsite"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeDocs 在临时目录中写入文档
+func writeDocs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestDocsiteNavOrder(t *testing.T) {
+	root := writeDocs(t, map[string]string{
+		"README.md":         "# 首页\n",
+		"2_进阶/README.md":    "---\ntitle: 进阶指南\n---\n# 进阶\n",
+		"2_进阶/1高级.md":       "# 高级\n",
+		"1_入门/1.10附录.md":    "# 附录\n",
+		"1_入门/1.2配置.md":     "# 配置\n见[安装](1.1安装.md#下载)\n",
+		"1_入门/1.1安装.md":     "# 安装\n## 下载\n## 下载\n",
+		"1_入门/其他.md":        "# 其他\n",
+		"1_入门/草稿.md":        "---\nhidden: true\n---\n# 草稿\n",
+		"1_入门/置顶.md":        "---\ntitle: 置顶说明\norder: 0\n---\n正文\n",
+		"3_空目录/notes.txt":   "not markdown",
+		".git/ignored.md":   "# ignored\n",
+		"2_进阶/img/logo.png": "png",
+	})
+	site, err := docsite.New(root, docsite.Options{BaseURL: "/docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var titles []string
+	for _, p := range site.Pages() {
+		titles = append(titles, p.Title)
+	}
+	want := []string{"文档首页", "置顶说明", "安装", "配置", "附录", "其他", "进阶指南", "高级"}
+	if len(titles) != len(want) {
+		t.Fatalf("页面顺序错误: %v", titles)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Fatalf("页面顺序错误: %v", titles)
+		}
+	}
+
+	nav := site.Nav("1_入门/1.2配置.md")
+	if len(nav) != 2 || nav[0].Title != "入门" || !nav[0].Open || nav[1].URL != "/docs/2_进阶" {
+		t.Fatalf("侧边栏错误: %+v", nav)
+	}
+	if !nav[0].Children[2].Active {
+		t.Fatalf("当前页面未标记: %+v", nav[0].Children[2])
+	}
+
+	p := site.Page("/1_入门/1.2配置")
+	if p == nil || p.Prev.Title != "安装" || p.Next.Title != "附录" {
+		t.Fatalf("上一篇/下一篇错误: %+v", p)
+	}
+	if len(p.Crumbs) != 1 || p.Crumbs[0].Title != "入门" {
+		t.Fatalf("面包屑错误: %+v", p.Crumbs)
+	}
+
+	install := site.Page("1_入门/1.1安装")
+	if len(install.TOC) != 3 || install.TOC[1].ID == install.TOC[2].ID {
+		t.Fatalf("页内目录错误: %+v", install.TOC)
+	}
+
+	draft := site.Page("1_入门/草稿")
+	if draft == nil || !draft.Hidden || draft.Prev != nil {
+		t.Fatalf("隐藏页面应可访问但不参与导航: %+v", draft)
+	}
+}
+
+func TestDocsiteLinksAndSearch(t *testing.T) {
+	root := writeDocs(t, map[string]string{
+		"1_指南/1.1安装.md": "# 安装\n\n![logo](img/logo.png)\n\n[配置](1.2配置.md#端口) [外链](https://example.com)\n",
+		"1_指南/1.2配置.md": "# 配置\n\n## 端口\n\n服务默认监听 8080 端口。\n",
+		"1_指南/隐藏.md":    "---\nhidden: true\n---\n端口 端口 端口\n",
+	})
+	site, err := docsite.New(root, docsite.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := site.Page("1_指南/1.1安装").HTML
+	for _, s := range []string{`src="/docs/1_指南/img/logo.png"`, `href="/docs/1_指南/1.2配置#端口"`, `href="https://example.com"`} {
+		if !strings.Contains(html, s) {
+			t.Fatalf("链接改写错误，缺少 %s:\n%s", s, html)
+		}
+	}
+
+	results := site.Search("端口", 10)
+	if len(results) != 1 || results[0].Page.Title != "配置" || !strings.Contains(results[0].Snippet, "<mark>端口</mark>") {
+		t.Fatalf("搜索结果错误: %+v", results)
+	}
+}
+
+func TestDocsiteRefresh(t *testing.T) {
+	root := writeDocs(t, map[string]string{"a.md": "# A\n"})
+	site, err := docsite.New(root, docsite.Options{CheckInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "b.md"), []byte("# 新文档\n新增内容\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if changed, err := site.Refresh(); err != nil || !changed {
+		t.Fatalf("文档变化后应重建: %v %v", changed, err)
+	}
+	if site.Page("b") == nil || len(site.Search("新增", 0)) != 1 {
+		t.Fatal("重建后应能访问并搜索到新文档")
+	}
+}
+
+func TestDocsiteWatch(t *testing.T) {
+	root := writeDocs(t, map[string]string{"a.md": "# A\n"})
+	site, err := docsite.New(root, docsite.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	site.Watch(5*time.Millisecond, nil)
+	defer site.Close()
+
+	updates, cancel := site.Subscribe()
+	defer cancel()
+	etag := site.ETag()
+
+	if err := os.WriteFile(filepath.Join(root, "a.md"), []byte("# A\n修改后的内容\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-updates:
+		if v != site.Version() || site.ETag() == etag {
+			t.Fatalf("通知的版本或 ETag 错误: %d %d", v, site.Version())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("文档变化后未收到重建通知")
+	}
+	if len(site.Search("修改", 0)) != 1 {
+		t.Fatal("重建后应能搜索到修改后的内容")
+	}
+}
+
+func TestDocsiteStaticExport(t *testing.T) {
+	root := writeDocs(t, map[string]string{
+		"README.md":     "# 首页\n\n<img src=\"logo.png\">\n",
+		"1_指南/1.1安装.md": "# 安装\n\n[首页](../README.md)\n",
+		"1_指南/隐藏.md":    "---\nhidden: true\n---\n# 隐藏\n",
+		"logo.png":      "png",
+	})
+	site, err := docsite.New(root, docsite.Options{BaseURL: "/", PageSuffix: ".html"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if site.HomeURL() != "/index.html" || site.Page("1_指南/1.1安装").URL != "/1_指南/1.1安装.html" {
+		t.Fatalf("静态页面链接错误: %s", site.HomeURL())
+	}
+	if !strings.Contains(site.Page("").HTML, `src="/logo.png"`) ||
+		!strings.Contains(site.Page("1_指南/1.1安装").HTML, `href="/index.html"`) {
+		t.Fatal("内嵌 HTML 或页面链接未改写")
+	}
+
+	if index := site.SearchIndex(); len(index) != 2 || index[1].Title != "安装" {
+		t.Fatalf("搜索索引错误: %+v", index)
+	}
+
+	var sb strings.Builder
+	if err := site.WriteSitemap(&sb, "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "<loc>https://example.com/1_%E6%8C%87%E5%8D%97/1.1%E5%AE%89%E8%A3%85.html</loc>") ||
+		strings.Contains(sb.String(), "隐藏") {
+		t.Fatalf("sitemap 错误:\n%s", sb.String())
+	}
+
+	assets, err := site.Assets()
+	if err != nil || len(assets) != 1 || assets[0] != "logo.png" {
+		t.Fatalf("静态资源错误: %v %v", assets, err)
+	}
+}
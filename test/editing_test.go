@@ -0,0 +1,39 @@
+package qwqtest
+
+import (
+	"context"
+	"errors"
+	"qwqserver/internal/editing"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/perm"
+	"testing"
+)
+
+// TestEditingRequiresPost 新帖（post_id 为 0）没有共享的编辑状态与编辑锁
+func TestEditingRequiresPost(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	u := &model.User{Username: "writer", Email: "writer@example.com", Perms: uint64(perm.PostCreate)}
+	if err := d.Repos.Users.Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &service.EditingService{}
+	if res := s.Heartbeat(d, u.ID); res.Error != errcode.MissingParam {
+		t.Errorf("Heartbeat 期望 %s，实际 %+v", errcode.MissingParam, res)
+	}
+	if res := s.Status(d, u.ID); res.Error != errcode.MissingParam {
+		t.Errorf("Status 期望 %s，实际 %+v", errcode.MissingParam, res)
+	}
+	if res := s.Leave(d, u.ID); res.Error != errcode.MissingParam {
+		t.Errorf("Leave 期望 %s，实际 %+v", errcode.MissingParam, res)
+	}
+
+	e := editing.New(nil, editing.Config{})
+	if _, err := e.Heartbeat(ctx, 0, u.ID); !errors.Is(err, editing.ErrNoPost) {
+		t.Errorf("Heartbeat(0) err = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package qwqtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/locale"
+	"qwqserver/internal/middleware"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newErrorRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorMiddleware())
+	r.GET("/typed", func(c *gin.Context) {
+		middleware.Render(c, common.Fail(errcode.New(errcode.UserEmailTaken)))
+	})
+	r.GET("/legacy", func(c *gin.Context) {
+		middleware.Render(c, &common.HTTPResult{Code: http.StatusInternalServerError, Msg: "获取数据库连接失败: dial tcp 10.0.0.1:3306"})
+	})
+	r.GET("/c-error", func(c *gin.Context) {
+		_ = c.Error(errcode.New(errcode.PostNotFound))
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return r
+}
+
+func doErrorRequest(t *testing.T, r *gin.Engine, path string, header map[string]string) (*httptest.ResponseRecorder, common.HTTPResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var res common.HTTPResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s: %v %s", path, err, w.Body.String())
+	}
+	return w, res
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	r := newErrorRouter()
+
+	// 错误码决定状态码，消息按 Accept-Language 本地化，沿用客户端的请求 ID
+	w, res := doErrorRequest(t, r, "/typed", map[string]string{"Accept-Language": "en-US,en;q=0.9", "X-Request-ID": "req-1"})
+	if w.Code != http.StatusConflict || res.Code != http.StatusConflict || res.Error != errcode.UserEmailTaken {
+		t.Errorf("typed = %d %+v", w.Code, res)
+	}
+	if res.Msg != "Email is already registered" || res.RequestID != "req-1" || w.Header().Get("X-Request-ID") != "req-1" {
+		t.Errorf("typed = %+v", res)
+	}
+
+	// 未使用错误码的 5xx 响应不泄露内部错误
+	w, res = doErrorRequest(t, r, "/legacy", nil)
+	if w.Code != http.StatusInternalServerError || res.Error != errcode.Internal || strings.Contains(res.Msg, "3306") {
+		t.Errorf("legacy = %d %+v", w.Code, res)
+	}
+	if len(res.RequestID) != 32 {
+		t.Errorf("request id = %q", res.RequestID)
+	}
+
+	// c.Error 返回的错误由中间件渲染
+	w, res = doErrorRequest(t, r, "/c-error", nil)
+	if w.Code != http.StatusNotFound || res.Error != errcode.PostNotFound || res.Msg != "帖子不存在" {
+		t.Errorf("c.Error = %d %+v", w.Code, res)
+	}
+
+	// panic 恢复为内部错误
+	w, res = doErrorRequest(t, r, "/panic", nil)
+	if w.Code != http.StatusInternalServerError || res.Error != errcode.Internal {
+		t.Errorf("panic = %d %+v", w.Code, res)
+	}
+}
+
+func TestErrcodeCatalog(t *testing.T) {
+	for _, e := range errcode.Catalog() {
+		if e.Status < 400 || !locale.Bundle().Has(locale.ZH, e.Code.MessageKey()) || !locale.Bundle().Has(locale.EN, e.Code.MessageKey()) {
+			t.Errorf("%s: 状态码或消息缺失 %+v", e.Code, e)
+		}
+	}
+
+	cause := errors.New("db down")
+	err := error(errcode.Wrap(errcode.Internal, cause))
+	if !errors.Is(err, cause) || !errors.Is(err, errcode.New(errcode.Internal)) {
+		t.Error("errors.Is 应匹配内部原因与错误码")
+	}
+	if errcode.CodeOf(cause) != errcode.Internal || errcode.CodeOf(nil) != "" {
+		t.Error("CodeOf")
+	}
+}
@@ -0,0 +1,72 @@
+package qwqtest
+
+import (
+	"context"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/perm"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestFeatureVisibility 只有已发布的帖子可以加精或加入专题，专题详情不展示未发布的帖子
+func TestFeatureVisibility(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	admin := &model.User{Username: "admin", Email: "admin@example.com", Perms: uint64(perm.ContentFeature)}
+	if err := d.Repos.Users.Create(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	newPost := func(status string) *model.Post {
+		t.Helper()
+		p := &model.Post{AuthorID: uint64(admin.ID), Mod: "markdown", Title: status, Content: "内容", Status: status}
+		if err := d.Repos.Posts.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	published, other, draft := newPost("published"), newPost("published"), newPost("draft")
+
+	for _, id := range []uint{draft.ID, 9999} {
+		s := &service.FeatureService{PostID: id}
+		if res := s.Feature(d, admin.ID); res.Error != errcode.PostNotFound {
+			t.Fatalf("加精 %d: 期望 %s，实际 %+v", id, errcode.PostNotFound, res)
+		}
+	}
+	if res := (&service.FeatureService{PostID: published.ID}).Feature(d, admin.ID); res.Error != "" {
+		t.Fatalf("加精失败: %+v", res)
+	}
+
+	create := &service.CollectionService{Title: "专题", PostIDs: []uint{published.ID, draft.ID}}
+	if res := create.Create(d, admin.ID); res.Error != errcode.PostNotFound {
+		t.Fatalf("期望 %s，实际 %+v", errcode.PostNotFound, res)
+	}
+	create.PostIDs = []uint{published.ID, other.ID}
+	res := create.Create(d, admin.ID)
+	if res.Error != "" {
+		t.Fatalf("创建专题失败: %+v", res)
+	}
+	id := res.Data.(gin.H)["id"].(uint)
+
+	set := &service.CollectionService{ID: id, PostIDs: []uint{other.ID, 9999}}
+	if res := set.SetPosts(d, admin.ID); res.Error != errcode.PostNotFound {
+		t.Fatalf("期望 %s，实际 %+v", errcode.PostNotFound, res)
+	}
+
+	// 加入专题后撤回为草稿的帖子不再出现在详情中
+	other.Status = "draft"
+	if err := d.Repos.Posts.Update(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	res = service.CollectionDetail(d, id)
+	if res.Error != "" {
+		t.Fatalf("专题详情失败: %+v", res)
+	}
+	items := res.Data.(*model.Collection).Items
+	if len(items) != 1 || items[0].Post == nil || items[0].Post.ID != published.ID {
+		t.Fatalf("专题详情应只包含已发布的帖子: %+v", items)
+	}
+}
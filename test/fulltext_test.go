@@ -0,0 +1,107 @@
+package qwqtest
+
+import (
+	"qwqserver/pkg/fulltext"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFulltextTokenize(t *testing.T) {
+	got := fulltext.Terms("Go语言的ORM，支持MySQL")
+	want := []string{"go", "语言", "言的", "orm", "支持", "mysql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("分词结果错误: got %v want %v", got, want)
+	}
+}
+
+func TestFulltextSearch(t *testing.T) {
+	idx := fulltext.NewIndex()
+	idx.Add(fulltext.Document{ID: 1, Fields: []fulltext.Field{
+		{Name: "title", Text: "数据库设计入门", Boost: 3},
+		{Name: "content", Text: "介绍关系型数据库的范式与索引"},
+	}})
+	idx.Add(fulltext.Document{ID: 2, Fields: []fulltext.Field{
+		{Name: "title", Text: "Go 并发", Boost: 3},
+		{Name: "content", Text: "数据和据库不相邻；hello world"},
+	}})
+	idx.Add(fulltext.Document{ID: 3, Fields: []fulltext.Field{
+		{Name: "title", Text: "hello go world", Boost: 3},
+	}})
+
+	ids := func(q string) []uint64 {
+		var out []uint64
+		for _, h := range idx.Search(fulltext.ParseQuery(q), nil) {
+			out = append(out, h.ID)
+		}
+		return out
+	}
+
+	t.Run("cjk words require adjacent bigrams", func(t *testing.T) {
+		if got := ids("数据库"); !reflect.DeepEqual(got, []uint64{1}) {
+			t.Fatalf("got %v", got)
+		}
+	})
+
+	t.Run("phrase", func(t *testing.T) {
+		if got := ids(`"hello world"`); !reflect.DeepEqual(got, []uint64{2}) {
+			t.Fatalf("got %v", got)
+		}
+		if got := ids("hello world"); len(got) != 2 {
+			t.Fatalf("got %v", got)
+		}
+	})
+
+	t.Run("title boost ranks first", func(t *testing.T) {
+		if got := ids("go"); len(got) != 2 || got[0] != 3 {
+			t.Fatalf("got %v", got)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		idx.Remove(1)
+		if got := ids("数据库"); len(got) != 0 {
+			t.Fatalf("got %v", got)
+		}
+	})
+}
+
+func TestFulltextHighlight(t *testing.T) {
+	text := "<b>前言</b>：本文介绍关系型数据库的范式与索引设计，后面还有很多与主题无关的内容"
+	got := fulltext.Highlight(text, fulltext.ParseQuery("数据库").AllTerms(), fulltext.HighlightOptions{MaxLen: 20})
+	if !strings.Contains(got, "<mark>数据库</mark>") {
+		t.Fatalf("未标记命中词: %s", got)
+	}
+	if strings.Contains(got, "<b>") {
+		t.Fatalf("未转义 HTML: %s", got)
+	}
+}
+
+// TestFulltextSingleCJK 单字查询命中多字片段中的任意位置
+func TestFulltextSingleCJK(t *testing.T) {
+	idx := fulltext.NewIndex()
+	idx.Add(fulltext.Document{ID: 1, Fields: []fulltext.Field{{Name: "content", Text: "我的猫咪很可爱"}}})
+	idx.Add(fulltext.Document{ID: 2, Fields: []fulltext.Field{{Name: "content", Text: "小狗 go"}}})
+
+	for q, want := range map[string][]uint64{
+		"猫":       {1},
+		"爱":       {1},
+		"狗":       {2},
+		`"go 猫"`:  nil,
+		`"小狗 go"`: {2},
+		"猫咪":      {1},
+	} {
+		var got []uint64
+		for _, h := range idx.Search(fulltext.ParseQuery(q), nil) {
+			got = append(got, h.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v want %v", q, got, want)
+		}
+	}
+
+	got := fulltext.Highlight("我的猫咪很可爱", []string{"猫"}, fulltext.HighlightOptions{})
+	if got != "我的<mark>猫</mark>咪很可爱" {
+		t.Fatalf("高亮单字错误: %s", got)
+	}
+}
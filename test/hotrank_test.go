@@ -0,0 +1,39 @@
+package qwqtest
+
+import (
+	"qwqserver/pkg/hotrank"
+	"testing"
+	"time"
+)
+
+func TestHotrankScore(t *testing.T) {
+	p := hotrank.DefaultParams
+	now := time.Now()
+	counts := hotrank.Counts{Likes: 10, Comments: 5, Views: 200, Bookmarks: 2}
+
+	t.Run("newer posts rank higher", func(t *testing.T) {
+		fresh := p.Score(counts, now.Add(-time.Hour), now)
+		old := p.Score(counts, now.Add(-48*time.Hour), now)
+		if fresh <= old {
+			t.Fatalf("期望新帖子热度更高: fresh=%v old=%v", fresh, old)
+		}
+	})
+
+	t.Run("no interaction scores zero", func(t *testing.T) {
+		if s := p.Score(hotrank.Counts{}, now, now); s != 0 {
+			t.Fatalf("期望热度为 0，实际 %v", s)
+		}
+	})
+
+	t.Run("invalid params fall back to defaults", func(t *testing.T) {
+		hotrank.SetDefault(hotrank.Params{Gravity: -1, AgeOffset: 0, LikeWeight: 3})
+		defer hotrank.SetDefault(hotrank.DefaultParams)
+		got := hotrank.Default()
+		if got.Gravity != hotrank.DefaultParams.Gravity || got.AgeOffset != hotrank.DefaultParams.AgeOffset {
+			t.Fatalf("非法参数未回退默认值: %+v", got)
+		}
+		if got.LikeWeight != 3 {
+			t.Fatalf("期望保留 LikeWeight=3，实际 %v", got.LikeWeight)
+		}
+	})
+}
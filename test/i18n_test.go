@@ -0,0 +1,123 @@
+package qwqtest
+
+import (
+	"net/http"
+	"qwqserver/internal/common"
+	"qwqserver/internal/locale"
+	"qwqserver/internal/middleware"
+	"qwqserver/pkg/i18n"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testCatalogZH = `
+post:
+  created: 创建成功
+  found:
+    zero: 没有找到帖子
+    other: 找到 {count} 篇帖子
+`
+
+const testCatalogEN = `
+post:
+  created: Created
+  found:
+    zero: No posts found
+    one: Found {count} post
+    other: Found {count} posts
+`
+
+func TestI18nBundle(t *testing.T) {
+	b := i18n.NewBundle("zh-CN")
+	if err := b.Parse("zh-CN", []byte(testCatalogZH)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Parse("en-US", []byte(testCatalogEN)); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		lang, key string
+		args      i18n.Args
+		want      string
+	}{
+		{"en-US", "post.created", nil, "Created"},
+		{"en-US", "post.found", i18n.Args{i18n.CountArg: 0}, "No posts found"},
+		{"en-US", "post.found", i18n.Args{i18n.CountArg: 1}, "Found 1 post"},
+		{"en-US", "post.found", i18n.Args{i18n.CountArg: int64(3)}, "Found 3 posts"},
+		{"zh-CN", "post.found", i18n.Args{i18n.CountArg: 1}, "找到 1 篇帖子"},
+		{"zh-CN", "post.found", i18n.Args{i18n.CountArg: 0}, "没有找到帖子"},
+		// 缺少的语言回退，缺少的消息返回键本身
+		{"fr-FR", "post.created", nil, "创建成功"},
+		{"en-US", "post.missing", nil, "post.missing"},
+	}
+	for _, c := range cases {
+		if got := b.T(c.lang, c.key, c.args); got != c.want {
+			t.Errorf("T(%s, %s, %v) = %q, want %q", c.lang, c.key, c.args, got, c.want)
+		}
+	}
+
+	if got := i18n.Interpolate("{field} 不能为空 {missing}", i18n.Args{"field": "title"}); got != "title 不能为空 {missing}" {
+		t.Errorf("Interpolate = %q", got)
+	}
+	if got := i18n.PluralCategory("ru", 22); got != "few" {
+		t.Errorf("PluralCategory(ru, 22) = %s", got)
+	}
+}
+
+func TestI18nNegotiate(t *testing.T) {
+	if got, want := i18n.ParseAcceptLanguage("zh-CN;q=0.8, en-GB, fr;q=0, en;q=0.9"), []string{"en-GB", "en", "zh-CN"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAcceptLanguage = %v, want %v", got, want)
+	}
+
+	b := i18n.NewBundle("zh-CN")
+	b.Add("zh-CN", nil)
+	b.Add("en-US", nil)
+	cases := []struct {
+		preference, accept, want string
+	}{
+		{"", "", "zh-CN"},
+		{"", "en-GB,en;q=0.9", "en-US"},
+		{"", "en_us", "en-US"},
+		{"", "zh-TW", "zh-CN"},
+		{"", "de-DE, *;q=0.5", "zh-CN"},
+		{"", "de-DE, en;q=0.5", "en-US"},
+		{"en-US", "zh-CN", "en-US"}, // 用户偏好优先
+	}
+	for _, c := range cases {
+		if got := b.Negotiate(c.preference, c.accept); got != c.want {
+			t.Errorf("Negotiate(%q, %q) = %s, want %s", c.preference, c.accept, got, c.want)
+		}
+	}
+	if lang, ok := b.Canonical("EN_us"); !ok || lang != "en-US" {
+		t.Errorf("Canonical = %s %v", lang, ok)
+	}
+}
+
+func TestI18nRender(t *testing.T) {
+	for _, lang := range []string{locale.ZH, locale.EN} {
+		for _, key := range locale.Bundle().Keys(locale.ZH) {
+			if !locale.Bundle().Has(lang, key) {
+				t.Errorf("%s 缺少消息 %s", lang, key)
+			}
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorMiddleware())
+	r.GET("/found", func(c *gin.Context) {
+		middleware.Render(c, common.Success("post.found", nil).WithArgs(i18n.Args{i18n.CountArg: 2}))
+	})
+
+	w, res := doErrorRequest(t, r, "/found", map[string]string{"Accept-Language": "en-GB"})
+	if w.Code != http.StatusOK || res.Msg != "Found 2 posts" || w.Header().Get("Content-Language") != locale.EN {
+		t.Errorf("en = %d %+v %v", w.Code, res, w.Header())
+	}
+	w, res = doErrorRequest(t, r, "/found", nil)
+	if res.Msg != locale.T(locale.ZH, "post.found", i18n.Args{i18n.CountArg: 2}) || w.Header().Get("Content-Language") != locale.ZH {
+		t.Errorf("zh = %+v %v", res, w.Header())
+	}
+}
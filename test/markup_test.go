@@ -0,0 +1,59 @@
+package qwqtest
+
+import (
+	"qwqserver/pkg/markup"
+	"strings"
+	"testing"
+)
+
+func TestMarkupSanitize(t *testing.T) {
+	r := markup.NewRenderer(markup.DefaultOptions, 0)
+	res := r.Render(markup.FormatMarkdown,
+		"<script>alert(1)</script><img src=x onerror=alert(1)> [bad](javascript:alert(1)) <a href=\"https://e.com\" onclick=\"x\">ok</a>")
+
+	for _, bad := range []string{"<script", "alert(1)", "onerror", "onclick", "javascript:"} {
+		if strings.Contains(res.HTML, bad) {
+			t.Fatalf("清洗后仍包含 %q: %s", bad, res.HTML)
+		}
+	}
+	if !strings.Contains(res.HTML, `rel="nofollow noopener noreferrer"`) {
+		t.Fatalf("外部链接未添加 rel: %s", res.HTML)
+	}
+}
+
+func TestMarkupTOCAndLinks(t *testing.T) {
+	r := markup.NewRenderer(markup.Options{MentionURL: "/u/{name}", TagURL: "/t/{name}"}, 8)
+	src := "# 简介\n\n感谢 @bob 的 #golang 分享，邮件 a@b.com\n\n## Usage\n\n## Usage\n\n`@code`\n"
+	res := r.Render(markup.FormatMarkdown, src)
+
+	ids := make([]string, len(res.TOC))
+	for i, item := range res.TOC {
+		ids[i] = item.ID
+	}
+	if strings.Join(ids, ",") != "简介,usage,usage-1" {
+		t.Fatalf("目录锚点错误: %v", ids)
+	}
+	if !strings.Contains(res.HTML, `<a class="mention" href="/u/bob">@bob</a>`) ||
+		!strings.Contains(res.HTML, `<a class="hashtag" href="/t/golang">#golang</a>`) {
+		t.Fatalf("未展开提及或标签: %s", res.HTML)
+	}
+	if len(res.Mentions) != 1 || res.Mentions[0] != "bob" {
+		t.Fatalf("提及列表错误（邮箱和代码不应展开）: %v", res.Mentions)
+	}
+	if r.Render(markup.FormatMarkdown, src) != res {
+		t.Fatal("相同内容应命中缓存")
+	}
+}
+
+func TestMarkupHighlight(t *testing.T) {
+	got := markup.Highlight("go", "func main() { // hi\n\treturn \"<x>\" }")
+	for _, want := range []string{
+		`<span class="hl-kw">func</span>`,
+		`<span class="hl-com">// hi</span>`,
+		`<span class="hl-str">&#34;&lt;x&gt;&#34;</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("缺少 %s: %s", want, got)
+		}
+	}
+}
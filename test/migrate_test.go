@@ -0,0 +1,208 @@
+package qwqtest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"qwqserver/internal/migrations"
+	"qwqserver/internal/model"
+	"qwqserver/pkg/migrate"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	db := openSQLite(t)
+	files := fstest.MapFS{
+		"sql/2_add_notes.up.sql":           {Data: []byte("-- 注释\nCREATE TABLE notes (\n  id integer primary key\n);\nCREATE INDEX idx_notes ON notes (id);\n")},
+		"sql/2_add_notes.down.sql":         {Data: []byte("DROP TABLE notes;")},
+		"sql/3_mysql_only.mysql.up.sql":    {Data: []byte("ALTER TABLE widgets MODIFY name varchar(10);")},
+		"sql/3_mysql_only.sqlite.down.sql": {Data: []byte("SELECT 1;")},
+		"sql/README.md":                    {Data: []byte("忽略")},
+	}
+	sqlMigrations, err := migrate.LoadFS(files, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms := append(sqlMigrations,
+		migrate.Migration{Version: 1, Name: "widgets",
+			Up:   func(tx *gorm.DB) error { return tx.Exec("CREATE TABLE widgets (name text)").Error },
+			Down: func(tx *gorm.DB) error { return tx.Exec("DROP TABLE widgets").Error }},
+		migrate.Migration{Version: 4, Name: "irreversible",
+			Up: func(tx *gorm.DB) error { return nil }},
+	)
+	m, err := migrate.New(db, ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	done, err := m.UpTo(ctx, 2)
+	if err != nil || len(done) != 2 || done[0].Name != "widgets" || done[1].Name != "add_notes" {
+		t.Fatalf("UpTo = %v %v", done, err)
+	}
+	if !db.Migrator().HasTable("notes") || !db.Migrator().HasIndex("notes", "idx_notes") {
+		t.Error("SQL 迁移未执行")
+	}
+	// 当前数据库没有对应方言的 up 文件时只记录版本
+	if done, err = m.Up(ctx); err != nil || len(done) != 2 {
+		t.Fatalf("Up = %v %v", done, err)
+	}
+	if done, err = m.Up(ctx); err != nil || len(done) != 0 {
+		t.Fatalf("重复 Up = %v %v", done, err)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil || len(status) != 4 || !status[3].Applied || status[3].AppliedAt == nil {
+		t.Fatalf("Status = %+v %v", status, err)
+	}
+
+	if _, err := m.Down(ctx, 1); !errors.Is(err, migrate.ErrIrreversible) {
+		t.Errorf("回滚不可回滚的迁移 err = %v", err)
+	}
+
+	// 代码中已删除的迁移在状态中标记为 Missing
+	m2, _ := migrate.New(db, ms[:3])
+	if _, err := m2.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "代码中不存在") {
+		t.Errorf("回滚代码中不存在的迁移 err = %v", err)
+	}
+	status, _ = m2.Status(ctx)
+	if len(status) != 4 || !status[3].Missing {
+		t.Errorf("Status = %+v", status)
+	}
+
+	db.Exec("DELETE FROM schema_migrations WHERE version = 4")
+	if done, err = m.Down(ctx, 3); err != nil || len(done) != 3 {
+		t.Fatalf("Down = %v %v", done, err)
+	}
+	if db.Migrator().HasTable("notes") || db.Migrator().HasTable("widgets") {
+		t.Error("down 未执行")
+	}
+	status, _ = m.Status(ctx)
+	for _, s := range status {
+		if s.Applied {
+			t.Errorf("%d 应为待执行", s.Version)
+		}
+	}
+}
+
+func TestMigrateInvalid(t *testing.T) {
+	up := func(tx *gorm.DB) error { return nil }
+	if _, err := migrate.New(nil, []migrate.Migration{{Version: 1, Name: "a", Up: up}, {Version: 1, Name: "b", Up: up}}); err == nil {
+		t.Error("重复版本号应报错")
+	}
+	files := fstest.MapFS{
+		"1_a.up.sql": {Data: []byte("SELECT 1;")},
+		"1_b.up.sql": {Data: []byte("SELECT 1;")},
+	}
+	if _, err := migrate.LoadFS(files, "."); err == nil {
+		t.Error("SQL 文件版本号重复应报错")
+	}
+
+	got := migrate.SplitStatements("-- c\nCREATE TABLE a (\n  id int\n);\n\nINSERT INTO a VALUES (1);\nSELECT 1")
+	want := []string{"CREATE TABLE a (\n  id int\n)", "INSERT INTO a VALUES (1)", "SELECT 1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitStatements = %q", got)
+	}
+}
+
+func TestMigrateLock(t *testing.T) {
+	db := openSQLite(t)
+	m, err := migrate.New(db, []migrate.Migration{{Version: 1, Name: "noop", Up: func(tx *gorm.DB) error { return nil }}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.LockTimeout = 300 * time.Millisecond
+
+	// 其他实例持有锁时等待超时
+	db.Exec("CREATE TABLE schema_migrations_lock (id integer primary key, locked_at datetime not null)")
+	db.Exec("INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)", time.Now())
+	if _, err := m.Up(context.Background()); !errors.Is(err, migrate.ErrLockTimeout) {
+		t.Fatalf("err = %v", err)
+	}
+
+	// 超过 StaleLock 的锁被接管，执行后释放
+	m.StaleLock = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+	if done, err := m.Up(context.Background()); err != nil || len(done) != 1 {
+		t.Fatalf("Up = %v %v", done, err)
+	}
+	var n int64
+	db.Table(migrate.LockTable).Count(&n)
+	if n != 0 {
+		t.Error("锁未释放")
+	}
+}
+
+func TestMigrationsSQLite(t *testing.T) {
+	db := openSQLite(t)
+	m, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasTable(&model.Post{}) || db.Migrator().HasColumn(&model.User{}, "password_hash") {
+		t.Error("表结构不符合预期")
+	}
+	// 回滚统一软删除标记，is_deleted 列按 deleted_at 恢复
+	if _, err := m.Down(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasColumn(&model.Post{}, "is_deleted") {
+		t.Error("回滚后缺少 is_deleted 列")
+	}
+	// 回滚到基线之前
+	if _, err := m.Down(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, migrate.ErrIrreversible) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestMigrateCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	now := time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC)
+	files, err := migrate.Create(dir, "add_foo", "go", now)
+	if err != nil || len(files) != 1 || filepath.Base(files[0]) != "20250601083000_add_foo.go" {
+		t.Fatalf("Create = %v %v", files, err)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "package migrations") || !strings.Contains(string(data), "Version: 20250601083000") {
+		t.Errorf("内容:\n%s", data)
+	}
+	if files, err = migrate.Create(dir, "add_foo", "sql", now); err != nil || len(files) != 2 {
+		t.Fatalf("Create sql = %v %v", files, err)
+	}
+	if _, err := migrate.Create(dir, "add_foo", "go", now); err == nil {
+		t.Error("文件已存在时应报错")
+	}
+	if _, err := migrate.Create(dir, "bad name", "go", now); err == nil {
+		t.Error("非法名称应报错")
+	}
+}
@@ -0,0 +1,151 @@
+package qwqtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/server"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/docsite"
+	"qwqserver/pkg/openapi"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAPIRouter 注册全部路由（含仅开发模式注册的实时刷新）
+func newAPIRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	docs, err := docsite.New(writeDocs(t, map[string]string{"README.md": "# 首页\n"}), docsite.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	server.Register(r, service.NewDeps(repotest.Open(t)), docs, true)
+	return r
+}
+
+// TestOpenAPIAllRoutesDocumented 每个注册的路由都必须在 internal/server/apidoc.go 中有接口说明
+func TestOpenAPIAllRoutesDocumented(t *testing.T) {
+	r := newAPIRouter(t)
+	doc, missing := server.BuildOpenAPI(r.Routes())
+	for _, route := range missing {
+		t.Errorf("路由缺少接口说明: %s %s", route.Method, route.Path)
+	}
+
+	for _, route := range r.Routes() {
+		p, _ := openapi.ConvertPath(route.Path)
+		item := doc.Paths[p]
+		if item == nil || item.Operation(route.Method) == nil {
+			t.Errorf("文档中缺少 %s %s", route.Method, p)
+			continue
+		}
+		op := item.Operation(route.Method)
+		if op.Summary == "" || len(op.Tags) == 0 {
+			t.Errorf("%s %s 缺少摘要或分组", route.Method, p)
+		}
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	r := newAPIRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+
+	// 请求与响应结构
+	for _, name := range []string{"authv2.LoginRequest", "authv2.RegisterRequest", "common.HTTPResult", "model.Post", "service.RegisterRequest"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("缺少组件 %s", name)
+		}
+	}
+	post := doc.Components.Schemas["model.Post"]
+	if post != nil {
+		if post.Properties["title"] == nil || post.Properties["title"].Description != "标题" {
+			t.Errorf("model.Post.title = %+v", post.Properties["title"])
+		}
+		// 嵌入的 model.Model 展开
+		if post.Properties["id"] == nil || post.Properties["created_at"] == nil || post.Properties["deleted_at"] == nil {
+			t.Errorf("model.Post 字段不完整: %v", post.Properties)
+		}
+	}
+
+	// binding 标签转换为必填与约束
+	if reg := doc.Components.Schemas["service.RegisterRequest"]; reg != nil {
+		username, email := reg.Properties["username"], reg.Properties["email"]
+		if len(reg.Required) != 4 || username.MinLength == nil || *username.MinLength != 3 || *username.MaxLength != 32 || email.Format != "email" {
+			t.Errorf("service.RegisterRequest = %+v", reg)
+		}
+	}
+	if diff := doc.Paths["/api/v1/post/revision/diff"]; diff != nil {
+		for _, p := range diff.Get.Parameters {
+			if p.Name == "mode" && len(p.Schema.Enum) != 2 {
+				t.Errorf("mode = %+v", p.Schema)
+			}
+			if p.Name == "post_id" && !p.Required {
+				t.Errorf("post_id 应为必填")
+			}
+		}
+	}
+
+	// 认证方式与分组
+	if s := doc.Components.SecuritySchemes["BearerAuth"]; s == nil || s.Scheme != "bearer" {
+		t.Errorf("BearerAuth = %+v", s)
+	}
+	if s := doc.Components.SecuritySchemes["RefreshToken"]; s == nil || s.In != "header" || s.Name != "X-Refresh-Token" {
+		t.Errorf("RefreshToken = %+v", s)
+	}
+	create := doc.Paths["/api/v1/post/create"].Post
+	if len(create.Security) != 1 || create.Tags[0] != "post" || create.Responses["401"] == nil {
+		t.Errorf("create = %+v", create)
+	}
+	if login := doc.Paths["/api/v1/auth/login"].Post; len(login.Security) != 0 || login.Tags[0] != "auth" {
+		t.Errorf("login = %+v", login)
+	}
+	if search := doc.Paths["/api/v1/post/search"].Get; len(search.Security) != 2 {
+		t.Errorf("search security = %+v", search.Security)
+	}
+	if docsPage := doc.Paths["/docs/{path}"]; docsPage == nil || docsPage.Get.Parameters[0].In != "path" {
+		t.Errorf("/docs/{path} = %+v", docsPage)
+	}
+
+	// Swagger UI
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"/openapi.json"`) {
+		t.Errorf("swagger = %d %s", w.Code, w.Body.String())
+	}
+	// 静态文件内置，不从外部加载
+	if strings.Contains(w.Body.String(), "http://") || strings.Contains(w.Body.String(), "https://") {
+		t.Errorf("swagger 页面引用了外部资源: %s", w.Body.String())
+	}
+	for path, want := range map[string]string{
+		"/swagger/swagger-ui-bundle.js": "SwaggerUIBundle",
+		"/swagger/swagger-ui.css":       ".swagger-ui",
+	} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s = %d %s，静态文件缺失时执行 go generate ./pkg/openapi", path, w.Code, w.Header().Get("Content-Type"))
+		}
+	}
+	// 其他路径不回退到首页
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/swagger-ui.css.map", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("未知静态文件 = %d", w.Code)
+	}
+}
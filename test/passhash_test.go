@@ -0,0 +1,116 @@
+package qwqtest
+
+import (
+	"context"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/util/multih256"
+	"qwqserver/pkg/util/passsec"
+	"strings"
+	"testing"
+)
+
+func TestPasswordHashers(t *testing.T) {
+	defer passsec.SetHasher(passsec.Bcrypt{})
+	fast := passsec.Argon2id{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+	cases := []struct {
+		hasher passsec.Hasher
+		prefix string
+	}{
+		{passsec.Bcrypt{Cost: 4}, "$2a$04$"},
+		{fast, "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{passsec.MultiH256{Rounds: 3, SaltLength: 16}, "$multih256$r=3$"},
+	}
+	for _, c := range cases {
+		hash, err := c.hasher.Hash("Xk9#mLp2qZ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(hash, c.prefix) {
+			t.Errorf("%s: hash = %s", c.hasher.ID(), hash)
+		}
+		if h, err := passsec.Identify(hash); err != nil || h.ID() != c.hasher.ID() {
+			t.Errorf("%s: Identify = %v %v", c.hasher.ID(), h, err)
+		}
+		if ok, err := passsec.Verify("Xk9#mLp2qZ", hash); !ok || err != nil {
+			t.Errorf("%s: Verify = %v %v", c.hasher.ID(), ok, err)
+		}
+		if ok, _ := passsec.Verify("wrong", hash); ok {
+			t.Errorf("%s: 错误密码通过验证", c.hasher.ID())
+		}
+	}
+
+	if _, err := passsec.Verify("x", "plain-text"); err != passsec.ErrUnknownHash {
+		t.Errorf("未知格式 err = %v", err)
+	}
+	if _, err := passsec.Verify("x", "$argon2id$v=19$broken"); err == nil {
+		t.Error("损坏的哈希应报错")
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	defer passsec.SetHasher(passsec.Bcrypt{})
+	old, _ := passsec.Bcrypt{Cost: 4}.Hash("Xk9#mLp2qZ")
+
+	passsec.SetHasher(passsec.Bcrypt{Cost: 4})
+	if passsec.NeedsRehash(old) {
+		t.Error("算法与工作因子相同时不需要重新哈希")
+	}
+	passsec.SetHasher(passsec.Bcrypt{Cost: 5})
+	if !passsec.NeedsRehash(old) {
+		t.Error("工作因子变化时应重新哈希")
+	}
+
+	fast := passsec.Argon2id{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+	passsec.SetHasher(fast)
+	if !passsec.NeedsRehash(old) {
+		t.Error("算法变化时应重新哈希")
+	}
+	hash, err := passsec.HashPassword("Xk9#mLp2qZ")
+	if err != nil || !strings.HasPrefix(hash, "$argon2id$") || passsec.NeedsRehash(hash) {
+		t.Errorf("HashPassword = %s %v", hash, err)
+	}
+	fast.Time = 2
+	passsec.SetHasher(fast)
+	if !passsec.NeedsRehash(hash) {
+		t.Error("argon2id 参数变化时应重新哈希")
+	}
+	// 旧的分列数据转为自描述格式后可直接验证，且总是需要重新哈希
+	legacy, salt, _ := multih256.EncryptWithSalt("Xk9#mLp2qZ", 16, 10000)
+	encoded := passsec.EncodeMultiH256(legacy, salt, 10000)
+	if ok, err := passsec.Verify("Xk9#mLp2qZ", encoded); !ok || err != nil || !passsec.NeedsRehash(encoded) {
+		t.Errorf("legacy = %v %v", ok, err)
+	}
+}
+
+// TestLoginRehash 登录成功时按当前算法重新哈希密码，禁用的用户登录失败且不更新哈希
+func TestLoginRehash(t *testing.T) {
+	defer passsec.SetHasher(passsec.Bcrypt{})
+	old, _ := passsec.Bcrypt{Cost: 4}.Hash("Xk9#mLp2qZ")
+	active := &model.User{Username: "alice", Email: "alice@example.com", Password: old, Status: 1}
+	disabled := &model.User{Username: "bob", Email: "bob@example.com", Password: old}
+	d := service.NewDeps(&repository.Repositories{Users: repotest.NewUsers(active, disabled)})
+	disabled.Status = 0
+	if err := d.Repos.Users.Update(context.Background(), disabled); err != nil {
+		t.Fatal(err)
+	}
+	passsec.SetHasher(passsec.Bcrypt{Cost: 5})
+
+	if res := (&service.AuthService{Username: "bob", Password: "Xk9#mLp2qZ"}).Login(d, "web", "dev-1"); res.Error != errcode.UserDisabled {
+		t.Fatalf("期望 %s，实际 %+v", errcode.UserDisabled, res)
+	}
+	if got, _ := d.Repos.Users.FindByID(context.Background(), disabled.ID); got.Password != old {
+		t.Error("禁用的用户不应更新密码哈希")
+	}
+
+	if res := (&service.AuthService{Username: "alice", Password: "Xk9#mLp2qZ"}).Login(d, "web", "dev-1"); res.Error != "" {
+		t.Fatalf("登录失败: %+v", res)
+	}
+	if got, _ := d.Repos.Users.FindByID(context.Background(), active.ID); got.Password == old || passsec.NeedsRehash(got.Password) {
+		t.Errorf("登录后应按当前算法重新哈希: %s", got.Password)
+	}
+}
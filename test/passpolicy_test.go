@@ -0,0 +1,112 @@
+package qwqtest
+
+import (
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/locale"
+	"qwqserver/pkg/util/passsec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func violationRules(v passsec.Violations) []string {
+	rules := make([]string, len(v))
+	for i, item := range v {
+		rules[i] = item.Rule
+	}
+	return rules
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := passsec.DefaultPolicy
+	user := passsec.UserInfo{Username: "alice", Email: "wonder@example.com"}
+
+	old, err := passsec.Hash("Tr0ub4dor&3x", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		password string
+		want     []string
+	}{
+		{"Xk9#mLp2qZ", nil},
+		{"abc", []string{passsec.RuleMinLength, passsec.RuleClasses, passsec.RuleStrength}},
+		{"alice-Rocks-99", []string{passsec.RuleUserInfo}},
+		{"Wonder!Land42", []string{passsec.RuleUserInfo}},
+		{"P@ssw0rd", []string{passsec.RuleBreached}},
+		{"Tr0ub4dor&3x", []string{passsec.RuleReused}},
+		{strings.Repeat("Aa1!", 20), []string{passsec.RuleMaxLength}},
+	}
+	for _, c := range cases {
+		v, err := policy.Check(c.password, user, []string{old})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := violationRules(v); !reflect.DeepEqual(got, c.want) && !(len(got) == 0 && len(c.want) == 0) {
+			t.Errorf("Check(%q) = %v, want %v", c.password, got, c.want)
+		}
+	}
+
+	// 零值策略不做限制
+	if v, _ := (passsec.Policy{}).Check("a", user, nil); len(v) != 0 {
+		t.Errorf("zero policy = %v", v)
+	}
+
+	// 有效期
+	policy.MaxAge = 24 * time.Hour
+	now := time.Now()
+	if !policy.Expired(now.Add(-48*time.Hour), now) || policy.Expired(now.Add(-time.Hour), now) {
+		t.Error("Expired")
+	}
+	if (passsec.Policy{}).Expired(now.Add(-1000*time.Hour), now) {
+		t.Error("MaxAge 为 0 时不过期")
+	}
+
+	if s, err := passsec.ParseStrength("Strong"); err != nil || s != passsec.Strong || s.Name() != "strong" {
+		t.Errorf("ParseStrength = %v %v", s, err)
+	}
+}
+
+func TestBreachedHashList(t *testing.T) {
+	// 与 Have I Been Pwned 的 range 格式一致：SHA-1:次数，前缀查询只返回后缀
+	list := passsec.NewHashList()
+	err := list.Parse(strings.NewReader("# comment\n\n" +
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\n" + // password
+		"7c4a8d09ca3762af61e59520943dc26494f8941b\n")) // 123456
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suffixes, _ := list.Range("5baa6"); len(suffixes) != 1 || suffixes[0] != "1E4C9B93F3F0682250B6CF8331B7EE68FD8" {
+		t.Errorf("Range = %v", suffixes)
+	}
+	for pw, want := range map[string]bool{"password": true, "123456": true, "Xk9#mLp2qZ": false} {
+		if got, _ := passsec.Breached(list, pw); got != want {
+			t.Errorf("Breached(%q) = %v", pw, got)
+		}
+	}
+	if err := passsec.NewHashList().Parse(strings.NewReader("not-a-hash\n")); err == nil {
+		t.Error("非法行应报错")
+	}
+	if passsec.CommonPasswords().Len() < 100 {
+		t.Errorf("内置列表 = %d", passsec.CommonPasswords().Len())
+	}
+}
+
+func TestPasswordViolationMessages(t *testing.T) {
+	v, _ := passsec.DefaultPolicy.Check("abc", passsec.UserInfo{}, nil)
+	e := errcode.PasswordViolations("new_password", v)
+	if e.Code != errcode.PasswordPolicy || e.Status() != 400 {
+		t.Fatalf("err = %+v", e)
+	}
+	details := e.Details.(errcode.Localizer).Localize(locale.EN).(errcode.FieldErrors)
+	if len(details.Fields) != len(v) || details.Fields[0].Message != "new_password must be at least 8 characters" {
+		t.Errorf("details = %+v", details)
+	}
+	for _, f := range details.Fields {
+		if strings.Contains(f.Message, "is invalid") {
+			t.Errorf("%s 缺少消息", f.Rule)
+		}
+	}
+}
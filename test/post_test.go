@@ -0,0 +1,121 @@
+package qwqtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/perm"
+	"testing"
+)
+
+func TestPostCreate(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	author := &model.User{Username: "author", Email: "author@example.com"}
+	if err := d.Repos.Users.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &service.CreatePostRequest{Title: "标题", Content: "内容", Mod: "markdown"}
+	if res := req.Create(d, 0); res.Error != errcode.AuthLoginRequired {
+		t.Fatalf("期望 %s，实际 %+v", errcode.AuthLoginRequired, res)
+	}
+	res := req.Create(d, author.ID)
+	if res.Error != "" {
+		t.Fatalf("创建文章失败: %+v", res)
+	}
+	post := res.Data.(*model.Post)
+	if post.AuthorID != uint64(author.ID) || post.Status != "draft" || post.Version != 1 || post.LikeCount != 0 {
+		t.Fatalf("创建的文章 = %+v", post)
+	}
+
+	req.Status = "trash"
+	if res := req.Create(d, author.ID); res.Error != errcode.InvalidEnum {
+		t.Fatalf("期望 %s，实际 %+v", errcode.InvalidEnum, res)
+	}
+}
+
+func TestPostVisibility(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	author := &model.User{Username: "author", Email: "author@example.com"}
+	reader := &model.User{Username: "reader", Email: "reader@example.com"}
+	editor := &model.User{Username: "editor", Email: "editor@example.com", Perms: uint64(perm.PostEditAny)}
+	for _, u := range []*model.User{author, reader, editor} {
+		if err := d.Repos.Users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	draft := &model.Post{AuthorID: uint64(author.ID), Mod: "markdown", Title: "草稿", Content: "内容", Status: "draft"}
+	if err := d.Repos.Posts.Create(ctx, draft); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		userID uint
+		want   errcode.Code
+	}{
+		{0, errcode.PostNotFound},
+		{reader.ID, errcode.PostNotFound},
+		{author.ID, ""},
+		{editor.ID, ""},
+	} {
+		if res := service.PostDetail(d, draft.ID, c.userID, "127.0.0.1"); res.Error != c.want {
+			t.Errorf("用户 %d 查看草稿: 期望 %q，实际 %+v", c.userID, c.want, res)
+		}
+	}
+
+	like := &service.InteractionService{PostID: draft.ID}
+	if res := like.Like(d, reader.ID); res.Error != errcode.PostNotFound {
+		t.Fatalf("不应能点赞他人的草稿: %+v", res)
+	}
+	if res := like.Bookmark(d, reader.ID); res.Error != errcode.PostNotFound {
+		t.Fatalf("不应能收藏他人的草稿: %+v", res)
+	}
+	if res := like.Like(d, author.ID); res.Error != "" {
+		t.Fatalf("作者点赞自己的草稿失败: %+v", res)
+	}
+}
+
+// TestPostUpdateBoard 更新时不传 board_id 保持原版块，显式传 0 时移出版块
+func TestPostUpdateBoard(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	author := &model.User{Username: "author", Email: "author@example.com"}
+	editor := &model.User{Username: "editor", Email: "editor@example.com", Perms: uint64(perm.PostEditAny)}
+	for _, u := range []*model.User{author, editor} {
+		if err := d.Repos.Users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	post := &model.Post{AuthorID: uint64(author.ID), BoardID: 3, Mod: "markdown", Title: "标题", Content: "内容", Status: "published"}
+	if err := d.Repos.Posts.Create(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+
+	update := func(body string) *model.Post {
+		t.Helper()
+		s := service.NewPost(&model.Post{})
+		if err := json.Unmarshal([]byte(body), s); err != nil {
+			t.Fatal(err)
+		}
+		if res := s.Update(d, editor.ID); res.Error != "" {
+			t.Fatalf("更新文章失败: %+v", res)
+		}
+		got, _ := d.Repos.Posts.FindByID(ctx, post.ID)
+		return got
+	}
+
+	body := `{"id":%d,"version":%d,"title":"新标题","content":"新内容"}`
+	if got := update(fmt.Sprintf(body, post.ID, 1)); got.BoardID != 3 || got.Title != "新标题" {
+		t.Fatalf("未传 board_id 时应保持原版块: %+v", got)
+	}
+	body = `{"id":%d,"version":%d,"title":"新标题","content":"新内容","board_id":0}`
+	if got := update(fmt.Sprintf(body, post.ID, 2)); got.BoardID != 0 {
+		t.Fatalf("board_id 为 0 时应移出版块: %+v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package qwqtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/server"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/docsite"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPostList(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	author := &model.User{Username: "author", Email: "author@example.com"}
+	if err := d.Repos.Users.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"published", "draft", "published"} {
+		p := &model.Post{AuthorID: uint64(author.ID), Mod: "markdown", Title: status, Content: "内容", Status: status}
+		if err := d.Repos.Posts.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	own := url.Values{"author_id": {strconv.FormatUint(uint64(author.ID), 10)}}
+
+	list := func(userID uint, values url.Values) *repository.Page[model.Post] {
+		t.Helper()
+		res := service.PostList(d, userID, values)
+		if res.Error != "" {
+			t.Fatalf("帖子列表失败: %+v", res)
+		}
+		return res.Data.(*repository.Page[model.Post])
+	}
+	if page := list(0, own); len(page.List) != 2 || *page.Total != 2 {
+		t.Fatalf("未登录只能看到已发布的帖子: %+v", page)
+	}
+	if page := list(author.ID, own); len(page.List) != 3 {
+		t.Fatalf("作者应能看到自己的草稿: %+v", page)
+	}
+	if page := list(author.ID, url.Values{"status": {"draft"}}); len(page.List) != 0 {
+		t.Fatalf("不带 author_id 时不应返回草稿: %+v", page)
+	}
+
+	if res := service.PostList(d, 0, url.Values{"sort": {"password"}}); res.Error != errcode.InvalidEnum {
+		t.Fatalf("期望 %s，实际 %+v", errcode.InvalidEnum, res)
+	}
+	if res := service.PostList(d, 0, url.Values{"cursor": {"bad"}}); res.Error != errcode.InvalidParam {
+		t.Fatalf("期望 %s，实际 %+v", errcode.InvalidParam, res)
+	}
+}
+
+// TestPostListAnonymous 文章列表为公开接口，未登录也可以访问
+func TestPostListAnonymous(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	author := &model.User{Username: "author", Email: "author@example.com"}
+	if err := d.Repos.Users.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"published", "draft"} {
+		p := &model.Post{AuthorID: uint64(author.ID), Mod: "markdown", Title: status, Content: "内容", Status: status}
+		if err := d.Repos.Posts.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs, err := docsite.New(writeDocs(t, map[string]string{"README.md": "# 首页\n"}), docsite.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	server.Register(r, d, docs, false)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/post/list", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Data repository.Page[model.Post] `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Data.List) != 1 || body.Data.List[0].Title != "published" {
+		t.Fatalf("未登录只能看到已发布的帖子: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,66 @@
+package qwqtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"qwqserver/internal/config"
+	"qwqserver/internal/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestQueueLimitStreamRoutes 只有登记的长连接路由不占用并发名额，请求头不能绕过限制
+func TestQueueLimitStreamRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useTempConfig(t)
+	r := gin.New()
+	r.Use(middleware.ErrorMiddleware(), middleware.QueueLimitMiddleware())
+	middleware.SetQueueLimit(1)
+	t.Cleanup(func() { middleware.SetQueueLimit(config.New().Listen.QueueLimitMaxConcurrent) })
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	r.GET("/slow", func(c *gin.Context) {
+		close(entered)
+		<-release
+	})
+	r.GET("/fast", func(c *gin.Context) {})
+	r.GET("/stream/:id", func(c *gin.Context) {})
+	middleware.SkipQueueLimit("/stream/:id")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-entered
+	defer func() {
+		close(release)
+		<-done
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Accept: text/event-stream 不应绕过并发限制: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream/1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("登记的长连接路由不应受并发限制: %d", w.Code)
+	}
+}
+
+// useTempConfig 全局配置未加载时，从临时目录加载默认配置，避免在测试目录下生成 configs/config.yaml
+func useTempConfig(t *testing.T) {
+	t.Helper()
+	l, err := config.Load(config.Options{File: filepath.Join(t.TempDir(), "config.yaml"), Environ: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Set(l.Config)
+}
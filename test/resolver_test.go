@@ -0,0 +1,138 @@
+package qwqtest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"qwqserver/pkg/database"
+)
+
+type resolverNote struct {
+	ID     uint
+	Source string
+}
+
+// openResolverDBs 创建主库与一个只读副本，两边各写入一行标明来源的数据
+func openResolverDBs(t *testing.T, window time.Duration, extra ...string) (*gorm.DB, *database.Resolver) {
+	t.Helper()
+	primary := openSQLite(t)
+	replicaFile := filepath.Join(t.TempDir(), "replica.db")
+	primary.AutoMigrate(&resolverNote{})
+	primary.Create(&resolverNote{ID: 1, Source: "primary"})
+	replica, err := gorm.Open(sqlite.Open(replicaFile), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica.AutoMigrate(&resolverNote{})
+	replica.Create(&resolverNote{ID: 1, Source: "replica"})
+	if sqlDB, err := replica.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	r := database.NewResolver("sqlite", append(extra, replicaFile), window, nil, nil)
+	t.Cleanup(r.Close)
+	if err := primary.Use(r); err != nil {
+		t.Fatal(err)
+	}
+	return primary, r
+}
+
+func readSource(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var n resolverNote
+	if err := db.First(&n, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n.Source
+}
+
+func TestResolverRouting(t *testing.T) {
+	db, _ := openResolverDBs(t, time.Hour)
+	ctx := context.Background()
+
+	if got := readSource(t, db.WithContext(ctx)); got != "replica" {
+		t.Errorf("读取应使用副本，got %s", got)
+	}
+	var source string
+	db.Raw("SELECT source FROM resolver_notes WHERE id = 1").Scan(&source)
+	if source != "replica" {
+		t.Errorf("Raw SELECT 应使用副本，got %s", source)
+	}
+	if got := readSource(t, db.WithContext(database.UsePrimary(ctx))); got != "primary" {
+		t.Errorf("UsePrimary 应使用主库，got %s", got)
+	}
+	if got := readSource(t, db.Clauses(clause.Locking{Strength: "UPDATE"})); got != "primary" {
+		t.Errorf("加锁读取应使用主库，got %s", got)
+	}
+	db.Transaction(func(tx *gorm.DB) error {
+		if got := readSource(t, tx); got != "primary" {
+			t.Errorf("事务内读取应使用主库，got %s", got)
+		}
+		return nil
+	})
+
+	// 写入始终使用主库
+	if err := db.Model(&resolverNote{}).Where("id = 1").Update("source", "primary-updated").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := readSource(t, db.WithContext(database.UsePrimary(ctx))); got != "primary-updated" {
+		t.Errorf("写入未落在主库，got %s", got)
+	}
+}
+
+func TestResolverSticky(t *testing.T) {
+	db, _ := openResolverDBs(t, 200*time.Millisecond)
+	alice := database.WithStickyKey(context.Background(), "user:1")
+	bob := database.WithStickyKey(context.Background(), "user:2")
+
+	if got := readSource(t, db.WithContext(alice)); got != "replica" {
+		t.Fatalf("写入前应使用副本，got %s", got)
+	}
+	db.WithContext(alice).Create(&resolverNote{ID: 2, Source: "alice"})
+
+	// 写入者在窗口内读取主库，其他用户不受影响
+	if got := readSource(t, db.WithContext(alice)); got != "primary" {
+		t.Errorf("写入后应粘滞主库，got %s", got)
+	}
+	if got := readSource(t, db.WithContext(bob)); got != "replica" {
+		t.Errorf("其他用户应使用副本，got %s", got)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if got := readSource(t, db.WithContext(alice)); got != "replica" {
+		t.Errorf("窗口过后应恢复使用副本，got %s", got)
+	}
+}
+
+func TestResolverEjectsUnhealthyReplica(t *testing.T) {
+	// 第一个副本无法打开，被标记为不健康，读取只路由到健康的副本
+	bad := "file:" + filepath.Join(t.TempDir(), "missing", "x.db") + "?mode=ro"
+	db, r := openResolverDBs(t, 0, bad)
+	status := r.Status()
+	if len(status) != 2 || status[0].Healthy || !status[1].Healthy {
+		t.Fatalf("Status = %+v", status)
+	}
+	for i := 0; i < 4; i++ {
+		if got := readSource(t, db); got != "replica" {
+			t.Errorf("第 %d 次读取 got %s", i, got)
+		}
+	}
+
+	// 全部副本不可用时回落到主库，健康检查重新连接成功后恢复
+	r.Close()
+	if got := readSource(t, db); got != "primary" {
+		t.Errorf("没有健康副本时应使用主库，got %s", got)
+	}
+	r.CheckReplicas()
+	if status := r.Status(); status[0].Healthy || !status[1].Healthy {
+		t.Errorf("Status = %+v", status)
+	}
+	if got := readSource(t, db); got != "replica" {
+		t.Errorf("副本恢复后应重新使用，got %s", got)
+	}
+}
@@ -0,0 +1,89 @@
+package qwqtest
+
+import (
+	"net/http"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/middleware"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/util/passsec"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestSessionLogin 登录令牌保存在依赖注入的存储中：登录后令牌可用，登出后立即失效
+func TestSessionLogin(t *testing.T) {
+	hash, err := passsec.HashPassword("Old-Secret-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Username: "alice", Email: "alice@example.com", Password: hash, Status: 1}
+	d := service.NewDeps(&repository.Repositories{Users: repotest.NewUsers(user)})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorMiddleware(), middleware.AuthMiddleware(d.Sessions))
+	r.GET("/api/v1/me", func(c *gin.Context) {
+		middleware.Render(c, common.OK(nil))
+	})
+
+	login := func(t *testing.T) *common.JWTResult {
+		t.Helper()
+		s := &service.AuthService{Username: "alice", Password: "Old-Secret-42"}
+		res := s.Login(d, "web", "dev-1")
+		if res.Error != "" {
+			t.Fatalf("登录失败: %+v", res)
+		}
+		return res.Data.(*common.JWTResult)
+	}
+	me := func(token string) common.HTTPResult {
+		_, res := doErrorRequest(t, r, "/api/v1/me", map[string]string{"Authorization": "Bearer " + token})
+		return res
+	}
+
+	token := login(t)
+	if res := me(token.AccessToken); res.Code != http.StatusOK {
+		t.Fatalf("登录后的令牌应可用: %+v", res)
+	}
+
+	newToken, newRefresh, err := (&service.AuthService{}).RefreshToken(d, token.RefreshToken)
+	if err != nil {
+		t.Fatalf("刷新令牌失败: %v", err)
+	}
+	if res := me(newToken); res.Code != http.StatusOK {
+		t.Fatalf("刷新后的令牌应可用: %+v", res)
+	}
+
+	if res := (&service.AuthService{}).Logout(d, user.ID, "web", "dev-1"); res.Error != "" {
+		t.Fatalf("登出失败: %+v", res)
+	}
+	if res := me(newToken); res.Error != errcode.AuthTokenExpired {
+		t.Fatalf("登出后令牌应失效，实际 %+v", res)
+	}
+	if _, _, err = (&service.AuthService{}).RefreshToken(d, newRefresh); err == nil {
+		t.Fatal("登出后刷新令牌应失效")
+	}
+
+	t.Run("without store", func(t *testing.T) {
+		nd := service.NewDeps(&repository.Repositories{Users: repotest.NewUsers(&model.User{Username: "bob", Password: hash, Status: 1})})
+		nd.Sessions = nil
+		s := &service.AuthService{Username: "bob", Password: "Old-Secret-42"}
+		if res := s.Login(nd, "web", "dev-1"); res.Error != errcode.Unavailable {
+			t.Fatalf("期望 %s，实际 %+v", errcode.Unavailable, res)
+		}
+
+		nr := gin.New()
+		nr.Use(middleware.ErrorMiddleware(), middleware.AuthMiddleware(nil))
+		nr.GET("/api/v1/me", func(c *gin.Context) {
+			middleware.Render(c, common.OK(nil))
+		})
+		_, res := doErrorRequest(t, nr, "/api/v1/me", map[string]string{"Authorization": "Bearer " + token.AccessToken})
+		if res.Error != errcode.AuthTokenExpired {
+			t.Fatalf("期望 %s，实际 %+v", errcode.AuthTokenExpired, res)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package qwqtest
+
+import (
+	"context"
+	"net/url"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/model"
+	"qwqserver/internal/repository"
+	"qwqserver/internal/repository/repotest"
+	"qwqserver/internal/service"
+	"qwqserver/pkg/perm"
+	"testing"
+)
+
+func TestPostTrash(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	author := &model.User{Username: "author", Email: "author@example.com", Perms: uint64(perm.PostDeleteOwn)}
+	other := &model.User{Username: "other", Email: "other@example.com", Perms: uint64(perm.PostDeleteOwn)}
+	admin := &model.User{Username: "admin", Email: "admin@example.com", Perms: uint64(perm.PostDeleteAny)}
+	for _, u := range []*model.User{author, other, admin} {
+		if err := d.Repos.Users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var posts []*model.Post
+	for _, u := range []*model.User{author, other} {
+		p := &model.Post{AuthorID: uint64(u.ID), Mod: "markdown", Title: u.Username, Content: "内容", Status: "published"}
+		if err := d.Repos.Posts.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		if res := service.DeletePost(d, p.ID, u.ID); res.Error != "" {
+			t.Fatalf("删除帖子失败: %+v", res)
+		}
+		posts = append(posts, p)
+	}
+
+	trash := func(userID uint) *repository.Page[model.Post] {
+		t.Helper()
+		res := service.PostTrash(d, userID, url.Values{})
+		if res.Error != "" {
+			t.Fatalf("回收站列表失败: %+v", res)
+		}
+		return res.Data.(*repository.Page[model.Post])
+	}
+	if page := trash(author.ID); len(page.List) != 1 || page.List[0].ID != posts[0].ID {
+		t.Fatalf("作者只能看到自己删除的帖子: %+v", page)
+	}
+	if page := trash(admin.ID); len(page.List) != 2 {
+		t.Fatalf("PostDeleteAny 应能看到全部: %+v", page)
+	}
+
+	// 不能恢复他人的帖子
+	if res := service.RestorePost(d, posts[1].ID, author.ID); res.Error != errcode.AuthPermissionDenied {
+		t.Fatalf("期望 %s，实际 %+v", errcode.AuthPermissionDenied, res)
+	}
+	if res := service.RestorePost(d, posts[0].ID, author.ID); res.Error != "" {
+		t.Fatalf("恢复帖子失败: %+v", res)
+	}
+	if p, _ := d.Repos.Posts.FindByID(ctx, posts[0].ID); p == nil {
+		t.Fatal("恢复后找不到帖子")
+	}
+	if res := service.RestorePost(d, posts[0].ID, author.ID); res.Error != errcode.PostNotFound {
+		t.Fatalf("期望 %s，实际 %+v", errcode.PostNotFound, res)
+	}
+
+	if res := service.PurgePost(d, posts[1].ID, admin.ID); res.Error != "" {
+		t.Fatalf("彻底删除失败: %+v", res)
+	}
+	if page := trash(admin.ID); len(page.List) != 0 {
+		t.Fatalf("回收站应为空: %+v", page)
+	}
+}
+
+func TestUserTrash(t *testing.T) {
+	ctx := context.Background()
+	d := service.NewDeps(repotest.Open(t))
+	author := &model.User{Username: "author", Email: "author@example.com"}
+	admin := &model.User{Username: "admin", Email: "admin@example.com", Perms: uint64(perm.UserProfileEditAny)}
+	for _, u := range []*model.User{author, admin} {
+		if err := d.Repos.Users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	post := &model.Post{AuthorID: uint64(author.ID), Mod: "markdown", Title: "标题", Content: "内容", Status: "published"}
+	if err := d.Repos.Posts.Create(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Repos.Users.Delete(ctx, author.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 回收站中用户的用户名与邮箱仍被占用
+	register := &service.AuthService{Username: "author", Email: "new@example.com", Password: "x", Nickname: "n"}
+	if res := register.Register(d); res.Error != errcode.UserUsernameTaken {
+		t.Fatalf("期望 %s，实际 %+v", errcode.UserUsernameTaken, res)
+	}
+	register = &service.AuthService{Username: "newcomer", Email: "author@example.com", Password: "x", Nickname: "n"}
+	if res := register.Register(d); res.Error != errcode.UserEmailTaken {
+		t.Fatalf("期望 %s，实际 %+v", errcode.UserEmailTaken, res)
+	}
+
+	if res := service.RestoreUser(d, author.ID, author.ID); res.Error == "" {
+		t.Fatalf("已删除的用户不能恢复自己: %+v", res)
+	}
+	if res := service.RestoreUser(d, author.ID, admin.ID); res.Error != "" {
+		t.Fatalf("恢复用户失败: %+v", res)
+	}
+	if u, _ := d.Repos.Users.FindByID(ctx, author.ID); u == nil {
+		t.Fatal("恢复后找不到用户")
+	}
+	if p, _ := d.Repos.Posts.FindByID(ctx, post.ID); p == nil {
+		t.Fatal("恢复用户后帖子未恢复")
+	}
+	if res := service.RestoreUser(d, author.ID, admin.ID); res.Error != errcode.UserNotFound {
+		t.Fatalf("期望 %s，实际 %+v", errcode.UserNotFound, res)
+	}
+}
@@ -0,0 +1,134 @@
+package qwqtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"qwqserver/internal/common"
+	"qwqserver/internal/errcode"
+	"qwqserver/internal/middleware"
+	"qwqserver/pkg/validate"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type validateItem struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type validateRequest struct {
+	Username string         `json:"username" form:"username" binding:"required,min=3,max=8,pattern=username"`
+	Email    string         `json:"email" form:"email" binding:"omitempty,email"`
+	Password string         `json:"password" form:"password" binding:"omitempty,password=strong"`
+	Mode     string         `json:"mode" form:"mode" binding:"omitempty,oneof=line word"`
+	Age      *int           `json:"age" form:"age" binding:"min=18"`
+	Tags     []string       `json:"tags" form:"tags" binding:"max=2"`
+	Items    []validateItem `json:"items"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	age := 16
+	err := validate.Struct(&validateRequest{
+		Username: "a b",
+		Email:    "not-an-email",
+		Password: "abc12345",
+		Mode:     "char",
+		Age:      &age,
+		Tags:     []string{"a", "b", "c"},
+		Items:    []validateItem{{Name: "ok"}, {}},
+	})
+	var errs validate.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("err = %v", err)
+	}
+	// 一次返回全部字段的错误，每个字段只报告第一条未通过的规则
+	got := make([]string, len(errs))
+	for i, e := range errs {
+		got[i] = e.Error()
+	}
+	want := []string{"username: pattern=username", "email: email", "password: password=strong", "mode: oneof=line word", "age: min=18", "tags: max=2", "items[1].name: required"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %v\nwant %v", got, want)
+	}
+
+	// 可选字段为零值或 nil 时跳过
+	if err := validate.Struct(validateRequest{Username: "qwq_1"}); err != nil {
+		t.Errorf("valid = %v", err)
+	}
+
+	// 未知规则是声明错误，不是请求错误
+	type badRule struct {
+		Name string `binding:"required,unknown"`
+	}
+	var tagErr *validate.TagError
+	if err := validate.Struct(badRule{Name: "x"}); !errors.As(err, &tagErr) {
+		t.Errorf("unknown rule = %v", err)
+	}
+}
+
+func TestValidateBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	old := binding.Validator
+	binding.Validator = validate.Default
+	defer func() { binding.Validator = old }()
+
+	r := gin.New()
+	r.Use(middleware.ErrorMiddleware())
+	r.POST("/json", func(c *gin.Context) {
+		var req validateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			middleware.Render(c, common.BindError(err))
+			return
+		}
+		middleware.Render(c, common.OK(req.Username))
+	})
+	r.GET("/query", func(c *gin.Context) {
+		var req validateRequest
+		if err := c.ShouldBindQuery(&req); err != nil {
+			middleware.Render(c, common.BindError(err))
+			return
+		}
+		middleware.Render(c, common.OK(req.Username))
+	})
+
+	do := func(req *http.Request) (int, map[string]any) {
+		req.Header.Set("Accept-Language", "en-US")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var out map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+			t.Fatalf("%v %s", err, w.Body.String())
+		}
+		return w.Code, out
+	}
+
+	code, out := do(httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"username":"ab","email":"x"}`)))
+	if code != http.StatusBadRequest || out["error"] != string(errcode.ValidationFailed) || out["msg"] != "Validation failed: 2 invalid fields" {
+		t.Fatalf("json = %d %v", code, out)
+	}
+	fields := out["data"].(map[string]any)["fields"].([]any)
+	first := fields[0].(map[string]any)
+	if len(fields) != 2 || first["field"] != "username" || first["rule"] != "min" || first["message"] != "username must be at least 3 characters" {
+		t.Errorf("fields = %v", fields)
+	}
+
+	code, out = do(httptest.NewRequest(http.MethodGet, "/query?username=qwq&age=20&mode=word", nil))
+	if code != http.StatusOK || out["data"] != "qwq" {
+		t.Errorf("query = %d %v", code, out)
+	}
+	code, out = do(httptest.NewRequest(http.MethodGet, "/query?username=qwq&mode=char&tags=a&tags=b&tags=c", nil))
+	if code != http.StatusBadRequest || len(out["data"].(map[string]any)["fields"].([]any)) != 2 {
+		t.Errorf("query = %d %v", code, out)
+	}
+
+	// 字段类型错误仍指出字段
+	code, out = do(httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"username":1}`)))
+	if code != http.StatusBadRequest || out["error"] != string(errcode.InvalidParam) {
+		t.Errorf("type = %d %v", code, out)
+	}
+}